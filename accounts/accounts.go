@@ -0,0 +1,182 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/accounts/accounts.go
+
+// Package accounts authenticates users, with their password for a login
+// session or with an API token, on top of the UserAccounts and User_Tokens
+// tables in package sql.
+package accounts
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// The username and password did not match an account (which of them is wrong
+// is deliberately not said).
+var ErrBadCredentials = errors.New("unknown username or wrong password")
+
+// The account has been banned, so it may not log in or use its tokens.
+var ErrBanned = errors.New("account is banned")
+
+// Passwords are at least this many characters (and at most 72 bytes, the most
+// that bcrypt uses).
+const MinPasswordLength = 10
+
+const account_columns = `userID, username, fullname, about,
+	  COALESCE(discogsID, ''), COALESCE(avatarURL, ''), date_banned`
+
+func scan_account(rows *sql.Rows) (schema.UserAccount, error) {
+	var account schema.UserAccount
+	err := rows.Scan(&account.ID, &account.Username, &account.Fullname, &account.About,
+		&account.DiscogsID, &account.AvatarURL, &account.DateBanned)
+	return account, err
+}
+
+// The account, whether or not it is banned.
+func GetAccount(ctx context.Context, db database.Queryer, userID uint64) (schema.UserAccount, error) {
+	accounts, err := database.QueryList(ctx, db, scan_account, `
+	  SELECT `+account_columns+` FROM UserAccounts WHERE userID = ?`, userID)
+	if err != nil {
+		return schema.UserAccount{}, err
+	}
+	if len(accounts) == 0 {
+		return schema.UserAccount{}, fmt.Errorf("user %d: %w", userID, database.ErrNotFound)
+	}
+	return accounts[0], nil
+}
+
+// Creates the account, returning it with its userID.  The fullname defaults
+// to the username.
+func CreateAccount(ctx context.Context, db database.Queryer, account schema.UserAccount) (schema.UserAccount, error) {
+	if err := account.Validate(); err != nil {
+		return schema.UserAccount{}, err
+	}
+	if account.Fullname == "" {
+		account.Fullname = account.Username
+	}
+	err := db.QueryRowContext(ctx, `
+	  INSERT INTO UserAccounts (username, fullname, about, discogsID, avatarURL)
+	  VALUES (?, ?, ?, NULLIF(?, ''), NULLIF(?, ''))
+	  RETURNING userID`,
+		account.Username, account.Fullname, account.About, account.DiscogsID, account.AvatarURL,
+	).Scan(&account.ID)
+	if err != nil {
+		return schema.UserAccount{}, fmt.Errorf("user %q: %w", account.Username, database.Classify(err))
+	}
+	return account, nil
+}
+
+// Sets (or replaces) the password which the user logs in with.
+func SetPassword(ctx context.Context, db database.Queryer, userID uint64, password string) error {
+	if utf8.RuneCountInString(password) < MinPasswordLength || len(password) > 72 {
+		return &schema.ValidationError{Typename: "account", Fields: []schema.FieldError{{
+			Field: "password", Message: fmt.Sprintf(
+				"must have at least %d characters and at most 72 bytes", MinPasswordLength)}}}
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	result, err := db.ExecContext(ctx,
+		`UPDATE UserAccounts SET password_hash = ? WHERE userID = ?`, string(hash), userID)
+	if err != nil {
+		return database.Classify(err)
+	}
+	if count, err := result.RowsAffected(); err != nil {
+		return err
+	} else if count == 0 {
+		return fmt.Errorf("user %d: %w", userID, database.ErrNotFound)
+	}
+	return nil
+}
+
+// Whether the user is a curator, who may edit the catalog.
+func IsCurator(ctx context.Context, db database.Queryer, userID uint64) (bool, error) {
+	var curator bool
+	err := db.QueryRowContext(ctx,
+		`SELECT is_curator FROM UserAccounts WHERE userID = ?`, userID).Scan(&curator)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, fmt.Errorf("user %d: %w", userID, database.ErrNotFound)
+	}
+	return curator, err
+}
+
+// Makes the user a curator of the catalog, or no longer one.  Their sessions
+// and tokens have the catalog scope only while they are a curator.
+func SetCurator(ctx context.Context, db database.Queryer, userID uint64, curator bool) error {
+	result, err := db.ExecContext(ctx,
+		`UPDATE UserAccounts SET is_curator = ? WHERE userID = ?`, curator, userID)
+	if err != nil {
+		return database.Classify(err)
+	}
+	if count, err := result.RowsAffected(); err != nil {
+		return err
+	} else if count == 0 {
+		return fmt.Errorf("user %d: %w", userID, database.ErrNotFound)
+	}
+	return nil
+}
+
+// Compared against when the username is unknown, so that a login takes as
+// long whether or not the account exists.
+var unknown_user_hash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("unknown user"), bcrypt.DefaultCost)
+	return hash
+})
+
+// Checks the username and password, returning the account which they belong
+// to.  A banned account is an error (wrapping ErrBanned) even with the right
+// password; any other mismatch wraps ErrBadCredentials.
+func Login(ctx context.Context, db database.Queryer, username, password string) (schema.UserAccount, error) {
+	var hash sql.NullString
+	var userID uint64
+	err := db.QueryRowContext(ctx, `
+	  SELECT userID, password_hash FROM UserAccounts WHERE username = ?`, username,
+	).Scan(&userID, &hash)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return schema.UserAccount{}, err
+	}
+	if !hash.Valid {
+		bcrypt.CompareHashAndPassword(unknown_user_hash(), []byte(password))
+		return schema.UserAccount{}, ErrBadCredentials
+	}
+	if bcrypt.CompareHashAndPassword([]byte(hash.String), []byte(password)) != nil {
+		return schema.UserAccount{}, ErrBadCredentials
+	}
+
+	account, err := GetAccount(ctx, db, userID)
+	if err != nil {
+		return schema.UserAccount{}, err
+	}
+	if account.DateBanned != nil {
+		return schema.UserAccount{}, fmt.Errorf("user %q: %w", username, ErrBanned)
+	}
+	return account, nil
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/accounts/accounts_test.go
+
+package accounts
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func open_test_db(t *testing.T) *sql.DB {
+	db, err := database.Open(context.Background(), ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestLogin(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	account, err := CreateAccount(ctx, db, schema.UserAccount{Username: "kevin"})
+	require.NoError(t, err)
+	assert.Equal(t, "kevin", account.Fullname)
+
+	_, err = Login(ctx, db, "kevin", "correct horse")
+	assert.ErrorIs(t, err, ErrBadCredentials, "without a password, nobody may log in")
+	var invalid *schema.ValidationError
+	assert.ErrorAs(t, SetPassword(ctx, db, account.ID, "short"), &invalid)
+	require.NoError(t, SetPassword(ctx, db, account.ID, "correct horse"))
+
+	logged_in, err := Login(ctx, db, "kevin", "correct horse")
+	require.NoError(t, err)
+	assert.Equal(t, account, logged_in)
+	_, err = Login(ctx, db, "kevin", "battery staple")
+	assert.ErrorIs(t, err, ErrBadCredentials)
+	_, err = Login(ctx, db, "nobody", "correct horse")
+	assert.ErrorIs(t, err, ErrBadCredentials)
+
+	_, err = db.Exec(`UPDATE UserAccounts SET date_banned = "2025-05-01" WHERE userID = ?`, account.ID)
+	require.NoError(t, err)
+	_, err = Login(ctx, db, "kevin", "correct horse")
+	assert.ErrorIs(t, err, ErrBanned)
+}
+
+func TestTokens(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	account, err := CreateAccount(ctx, db, schema.UserAccount{Username: "kevin"})
+	require.NoError(t, err)
+
+	session, started, err := StartSession(ctx, db, account.ID)
+	require.NoError(t, err)
+	assert.True(t, started.Allows(ScopeAccount))
+	assert.False(t, started.Allows(ScopeCatalog), "only curators may edit the catalog")
+	secret, issued, err := IssueToken(ctx, db, account.ID,
+		TokenRequest{Label: "import script", Scopes: []Scope{ScopeCollection}, Days: 7})
+	require.NoError(t, err)
+	assert.NotEqual(t, session, secret)
+	assert.Equal(t, 7*24.0, issued.Expires.Sub(issued.Created.Time).Hours())
+
+	authenticated, token, err := Authenticate(ctx, db, secret)
+	require.NoError(t, err)
+	assert.Equal(t, account, authenticated)
+	assert.Equal(t, issued.ID, token.ID)
+	assert.True(t, token.Allows(ScopeCollection))
+	assert.False(t, token.Allows(ScopeLedger))
+	require.NotNil(t, token.LastUsed)
+	_, _, err = Authenticate(ctx, db, secret+"x")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	tokens, err := ListTokens(ctx, db, account.ID)
+	require.NoError(t, err)
+	assert.Len(t, tokens, 2)
+	require.NoError(t, RevokeToken(ctx, db, account.ID, issued.ID))
+	_, _, err = Authenticate(ctx, db, secret)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	require.NoError(t, RevokeSecret(ctx, db, session))
+	_, _, err = Authenticate(ctx, db, session)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// Expired tokens are not accepted (and are cleared out by the next one).
+	secret, issued, err = IssueToken(ctx, db, account.ID, TokenRequest{Scopes: []Scope{ScopeCollection, ScopeLedger}})
+	require.NoError(t, err)
+	_, err = db.Exec(`UPDATE User_Tokens SET date_expires = "2025-01-01 00:00:00" WHERE tokenID = ?`, issued.ID)
+	require.NoError(t, err)
+	_, _, err = Authenticate(ctx, db, secret)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, _, err = IssueToken(ctx, db, account.ID, TokenRequest{Scopes: []Scope{ScopeCollection, ScopeLedger}})
+	require.NoError(t, err)
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM User_Tokens`).Scan(&count))
+	assert.Equal(t, 1, count)
+
+	// Tokens of banned users are not accepted.
+	secret, _, err = IssueToken(ctx, db, account.ID, TokenRequest{Scopes: []Scope{ScopeCollection, ScopeLedger}})
+	require.NoError(t, err)
+	_, err = db.Exec(`UPDATE UserAccounts SET date_banned = "2025-05-01"`)
+	require.NoError(t, err)
+	_, _, err = Authenticate(ctx, db, secret)
+	assert.ErrorIs(t, err, ErrBanned)
+}
+
+func TestTokenRequestScopes(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	account, err := CreateAccount(ctx, db, schema.UserAccount{Username: "kevin"})
+	require.NoError(t, err)
+	for _, request := range []TokenRequest{
+		{},
+		{Scopes: []Scope{ScopeAccount}},
+		{Scopes: []Scope{"admin"}},
+		{Scopes: []Scope{ScopeLedger}, Days: MaxTokenDays + 1},
+		{Scopes: []Scope{ScopeCollection, ScopeCatalog}},
+	} {
+		_, _, err := IssueToken(ctx, db, account.ID, request)
+		var invalid *schema.ValidationError
+		assert.ErrorAs(t, err, &invalid, "%+v", request)
+	}
+}
+
+func TestCurators(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	account, err := CreateAccount(ctx, db, schema.UserAccount{Username: "kevin"})
+	require.NoError(t, err)
+	require.NoError(t, SetCurator(ctx, db, account.ID, true))
+
+	session, started, err := StartSession(ctx, db, account.ID)
+	require.NoError(t, err)
+	assert.True(t, started.Allows(ScopeCatalog))
+	secret, issued, err := IssueToken(ctx, db, account.ID, TokenRequest{Scopes: APIScopes})
+	require.NoError(t, err)
+	assert.True(t, issued.Allows(ScopeCatalog))
+
+	// Once no longer a curator, neither the session nor the token may edit the
+	// catalog, though they keep their other scopes.
+	require.NoError(t, SetCurator(ctx, db, account.ID, false))
+	for _, secret := range []string{session, secret} {
+		_, token, err := Authenticate(ctx, db, secret)
+		require.NoError(t, err)
+		assert.False(t, token.Allows(ScopeCatalog))
+		assert.True(t, token.Allows(ScopeCollection))
+	}
+	assert.ErrorIs(t, SetCurator(ctx, db, 999, true), database.ErrNotFound)
+}
@@ -0,0 +1,315 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/accounts/tokens.go
+
+package accounts
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
+)
+
+// The token is not one that was issued, or it was revoked or has expired.
+var ErrInvalidToken = errors.New("token is unknown, revoked or expired")
+
+type TokenKind string
+
+const (
+	// A login session (kept in a cookie), which has every scope (except
+	// catalog, unless the user is a curator).
+	TokenSession TokenKind = "session"
+	// A bearer token for the API, which has only the scopes it was granted.
+	TokenAPI TokenKind = "api"
+)
+
+// What a token allows its holder to change.  Anyone may read the catalog and
+// the users' collections and listings without a token, but only the buyer and
+// seller may see an order.
+type Scope string
+
+const (
+	// Add and edit artists and releases in the catalog.  Only curators have it.
+	ScopeCatalog Scope = "catalog"
+	// Change the user's copies, crates and tags.
+	ScopeCollection Scope = "collection"
+	// Open and change the user's listings, and see and change their orders.
+	ScopeLedger Scope = "ledger"
+	// Issue and revoke the user's API tokens.  Only sessions have it, so that
+	// a token cannot be used to issue more of them.
+	ScopeAccount Scope = "account"
+)
+
+// The scopes which an API token may be granted.
+var APIScopes = []Scope{ScopeCatalog, ScopeCollection, ScopeLedger}
+
+func ParseScope(scope string) (Scope, error) {
+	for _, known := range append(APIScopes, ScopeAccount) {
+		if Scope(scope) == known {
+			return known, nil
+		}
+	}
+	return "", fmt.Errorf("unknown scope %q", scope)
+}
+
+const (
+	SessionLifetime = 30 * 24 * time.Hour
+	// API tokens expire after DefaultTokenDays unless requested otherwise, and
+	// at the latest after MaxTokenDays.
+	DefaultTokenDays = 90
+	MaxTokenDays     = 365
+)
+
+// A session or API token, without its secret (which is only known when it is
+// issued).
+type Token struct {
+	ID     uint64    `json:"tokenID"`
+	UserID uint64    `json:"userID"`
+	Kind   TokenKind `json:"kind"`
+	Label  string    `json:"label,omitempty"`
+	Scopes []Scope   `json:"scopes"`
+
+	Created  schema.Timestamp  `json:"date_created"`
+	Expires  schema.Timestamp  `json:"date_expires"`
+	LastUsed *schema.Timestamp `json:"last_used,omitempty"`
+}
+
+// Whether the token allows changes of the scope.
+func (token Token) Allows(scope Scope) bool {
+	return slices.Contains(token.Scopes, scope)
+}
+
+// A request for a new API token.
+type TokenRequest struct {
+	// Describes what the token is for, e.g. "import script".
+	Label  string  `json:"label,omitempty"`
+	Scopes []Scope `json:"scopes"`
+	// How many days until the token expires (DefaultTokenDays if zero).
+	Days int `json:"days,omitempty"`
+}
+
+func (request TokenRequest) validate() error {
+	var problems []schema.FieldError
+	if len(request.Scopes) == 0 {
+		problems = append(problems, schema.FieldError{Field: "scopes", Message: "is required"})
+	}
+	for i, scope := range request.Scopes {
+		if !slices.Contains(APIScopes, scope) {
+			problems = append(problems, schema.FieldError{Field: fmt.Sprintf("scopes[%d]", i),
+				Message: fmt.Sprintf("%q is not one of catalog, collection or ledger", scope)})
+		}
+	}
+	if request.Days < 0 || request.Days > MaxTokenDays {
+		problems = append(problems, schema.FieldError{Field: "days",
+			Message: fmt.Sprintf("must be between 1 and %d", MaxTokenDays)})
+	}
+	if len(problems) > 0 {
+		return &schema.ValidationError{Typename: "token", Fields: problems}
+	}
+	return nil
+}
+
+// Starts a login session for the user, returning its secret (for a cookie).
+func StartSession(ctx context.Context, db database.Queryer, userID uint64) (string, Token, error) {
+	curator, err := IsCurator(ctx, db, userID)
+	if err != nil {
+		return "", Token{}, err
+	}
+	scopes := []Scope{ScopeCollection, ScopeLedger, ScopeAccount}
+	if curator {
+		scopes = append(scopes, ScopeCatalog)
+	}
+	return issue(ctx, db, userID, TokenSession, "", scopes, SessionLifetime)
+}
+
+// Issues an API token for the user, returning its secret (which cannot be
+// retrieved again) along with the token.
+func IssueToken(ctx context.Context, db database.Queryer, userID uint64, request TokenRequest) (string, Token, error) {
+	if err := request.validate(); err != nil {
+		return "", Token{}, err
+	}
+	if i := slices.Index(request.Scopes, ScopeCatalog); i >= 0 {
+		curator, err := IsCurator(ctx, db, userID)
+		if err != nil {
+			return "", Token{}, err
+		}
+		if !curator {
+			return "", Token{}, &schema.ValidationError{Typename: "token", Fields: []schema.FieldError{{
+				Field: fmt.Sprintf("scopes[%d]", i), Message: "only curators may edit the catalog"}}}
+		}
+	}
+	days := request.Days
+	if days == 0 {
+		days = DefaultTokenDays
+	}
+	return issue(ctx, db, userID, TokenAPI, request.Label, request.Scopes,
+		time.Duration(days)*24*time.Hour)
+}
+
+func issue(ctx context.Context, db database.Queryer, userID uint64, kind TokenKind, label string, scopes []Scope, lifetime time.Duration) (string, Token, error) {
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return "", Token{}, err
+	}
+	secret := base64.RawURLEncoding.EncodeToString(random)
+
+	now := schema.Now()
+	token := Token{UserID: userID, Kind: kind, Label: label, Scopes: scopes,
+		Created: now, Expires: schema.NewTimestamp(now.Add(lifetime))}
+	err := database.InTx(ctx, db, func(tx *sql.Tx) error {
+		// Expired tokens are of no further use, so they are cleared out here.
+		_, err := tx.ExecContext(ctx,
+			`DELETE FROM User_Tokens WHERE userID = ? AND date_expires <= ?`, userID, now)
+		if err != nil {
+			return err
+		}
+		return tx.QueryRowContext(ctx, `
+		  INSERT INTO User_Tokens (userID, token_hash, kind, scopes, label, date_created, date_expires)
+		  VALUES (?, ?, ?, ?, ?, ?, ?)
+		  RETURNING tokenID`,
+			userID, hash_token(secret), kind, join_scopes(scopes), label, token.Created, token.Expires,
+		).Scan(&token.ID)
+	})
+	if err != nil {
+		return "", Token{}, fmt.Errorf("token for user %d: %w", userID, err)
+	}
+	return secret, token, nil
+}
+
+func hash_token(secret string) []byte {
+	hash := sha256.Sum256([]byte(secret))
+	return hash[:]
+}
+
+func join_scopes(scopes []Scope) string {
+	names := make([]string, len(scopes))
+	for i, scope := range scopes {
+		names[i] = string(scope)
+	}
+	return strings.Join(names, " ")
+}
+
+const token_columns = `tokenID, userID, kind, label, scopes, date_created, date_expires, last_used`
+
+func scan_token(rows *sql.Rows) (Token, error) {
+	var token Token
+	var scopes string
+	err := rows.Scan(&token.ID, &token.UserID, &token.Kind, &token.Label, &scopes,
+		&token.Created, &token.Expires, &token.LastUsed)
+	if err != nil {
+		return Token{}, err
+	}
+	token.Scopes = []Scope{}
+	for _, scope := range strings.Fields(scopes) {
+		token.Scopes = append(token.Scopes, Scope(scope))
+	}
+	return token, nil
+}
+
+// How often a token's last_used time is updated (rather than on every use,
+// which would write to the database on every request).
+const last_used_resolution = time.Hour
+
+// The account and token of the secret, which must be an unexpired token of an
+// account which is not banned (or the error wraps ErrInvalidToken or ErrBanned).
+// The token no longer allows catalog changes if the user is no longer a
+// curator.
+func Authenticate(ctx context.Context, db database.Queryer, secret string) (schema.UserAccount, Token, error) {
+	now := schema.Now()
+	tokens, err := database.QueryList(ctx, db, scan_token, `
+	  SELECT `+token_columns+` FROM User_Tokens
+	  WHERE token_hash = ? AND date_expires > ?`, hash_token(secret), now)
+	if err != nil {
+		return schema.UserAccount{}, Token{}, err
+	}
+	if len(tokens) == 0 {
+		return schema.UserAccount{}, Token{}, ErrInvalidToken
+	}
+	token := tokens[0]
+	account, err := GetAccount(ctx, db, token.UserID)
+	if err != nil {
+		return schema.UserAccount{}, Token{}, err
+	}
+	if account.DateBanned != nil {
+		return schema.UserAccount{}, Token{}, fmt.Errorf("user %q: %w", account.Username, ErrBanned)
+	}
+	if token.Allows(ScopeCatalog) {
+		curator, err := IsCurator(ctx, db, account.ID)
+		if err != nil {
+			return schema.UserAccount{}, Token{}, err
+		}
+		if !curator {
+			token.Scopes = slices.DeleteFunc(token.Scopes, func(scope Scope) bool {
+				return scope == ScopeCatalog
+			})
+		}
+	}
+
+	if token.LastUsed == nil || now.Sub(token.LastUsed.Time) >= last_used_resolution {
+		_, err = db.ExecContext(ctx, `UPDATE User_Tokens SET last_used = ? WHERE tokenID = ?`,
+			now, token.ID)
+		if err != nil {
+			return schema.UserAccount{}, Token{}, err
+		}
+		token.LastUsed = &now
+	}
+	return account, token, nil
+}
+
+// The user's unexpired tokens, most recently created first.
+func ListTokens(ctx context.Context, db database.Queryer, userID uint64) ([]Token, error) {
+	return database.QueryList(ctx, db, scan_token, `
+	  SELECT `+token_columns+` FROM User_Tokens
+	  WHERE userID = ? AND date_expires > ?
+	  ORDER BY date_created DESC, tokenID DESC`, userID, schema.Now())
+}
+
+// Revokes one of the user's tokens (or ends one of their sessions).
+func RevokeToken(ctx context.Context, db database.Queryer, userID, tokenID uint64) error {
+	result, err := db.ExecContext(ctx,
+		`DELETE FROM User_Tokens WHERE userID = ? AND tokenID = ?`, userID, tokenID)
+	if err != nil {
+		return err
+	}
+	if count, err := result.RowsAffected(); err != nil {
+		return err
+	} else if count == 0 {
+		return fmt.Errorf("token %d: %w", tokenID, database.ErrNotFound)
+	}
+	return nil
+}
+
+// Revokes the token with the secret, e.g. to log out of a session.  Unknown
+// secrets are ignored.
+func RevokeSecret(ctx context.Context, db database.Queryer, secret string) error {
+	_, err := db.ExecContext(ctx, `DELETE FROM User_Tokens WHERE token_hash = ?`, hash_token(secret))
+	return err
+}
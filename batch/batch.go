@@ -0,0 +1,389 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/batch/batch.go
+
+// Package batch runs a list of changes to a user's collection (adding copies,
+// creating crates, moving, tagging, grading and listing copies) as one
+// transaction, so that a multi-step change either happens entirely or not at
+// all.  Later operations may refer to the copies and crates which earlier
+// operations created, by the name given to the earlier operation.
+package batch
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/kevindamm/cratedigdb/collection"
+	"github.com/kevindamm/cratedigdb/ledger"
+	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
+)
+
+// The kinds of operation in a batch.
+type OpKind string
+
+const (
+	// Adds the Vinyl to the collection (into the crate, if one is given).
+	OpAdd OpKind = "add"
+	// Creates the Crate (within the crate, if one is given).
+	OpCrate OpKind = "crate"
+	// Moves the Items into the crate, or out of any crate for crateID 0.
+	OpMove OpKind = "move"
+	// Adds and removes tags (by name) on the Items.
+	OpTag OpKind = "tag"
+	// Changes the media and/or sleeve grade of the Items.
+	OpGrade OpKind = "grade"
+	// Opens a listing for each of the Items, with the same prices.
+	OpList OpKind = "list"
+)
+
+// The most operations in a batch.
+const MaxOperations = 500
+
+// An operation of a batch.  Which of the fields are used depends on the Op.
+type Operation struct {
+	Op OpKind `json:"op"`
+	// Names the copy or crate which an add or crate operation creates, so that
+	// later operations may refer to it (as the ref of an item or crate_ref).
+	Name string `json:"name,omitempty"`
+
+	// The copy to add (its user is the batch's user).
+	Vinyl *schema.Vinyl `json:"vinyl,omitempty"`
+	// The crate to create.
+	Crate *schema.Crate `json:"crate,omitempty"`
+
+	// The crate of an add, move or crate operation (the parent of a created
+	// crate), either its crateID or the name of the operation which created it.
+	CrateID  uint64 `json:"crateID,omitempty"`
+	CrateRef string `json:"crate_ref,omitempty"`
+
+	// The copies to move, tag, grade or list.
+	Items []ItemRef `json:"items,omitempty"`
+
+	Add    []string `json:"add,omitempty"`
+	Remove []string `json:"remove,omitempty"`
+
+	MediaGrade  *schema.GradingEnum `json:"media_grade,omitempty"`
+	SleeveGrade *schema.GradingEnum `json:"sleeve_grade,omitempty"`
+
+	PriceLow    *schema.Money `json:"price_low,omitempty"`
+	PriceHigh   *schema.Money `json:"price_high,omitempty"`
+	AllowOffers bool          `json:"allow_offers,omitempty"`
+}
+
+// One of the user's copies, by its versionID and item number or by the name
+// of the add operation which created it.
+type ItemRef struct {
+	VersionID uint64 `json:"versionID,omitempty"`
+	Item      uint   `json:"item,omitempty"`
+	Ref       string `json:"ref,omitempty"`
+}
+
+// What became of an operation.
+type Status string
+
+const (
+	StatusDone   Status = "done"
+	StatusFailed Status = "failed"
+	// The operation succeeded, but a later one failed and its changes were
+	// rolled back with the rest of the batch.
+	StatusRolledBack Status = "rolled_back"
+	// The operation was not run because an earlier one failed.
+	StatusSkipped Status = "skipped"
+)
+
+type Result struct {
+	Index  int    `json:"index"`
+	Op     OpKind `json:"op"`
+	Name   string `json:"name,omitempty"`
+	Status Status `json:"status"`
+
+	// The added or graded copies, the created crate and the opened listings.
+	Vinyl    []schema.Vinyl   `json:"vinyl,omitempty"`
+	Crate    *schema.Crate    `json:"crate,omitempty"`
+	Listings []schema.Listing `json:"listings,omitempty"`
+
+	// Why the operation failed.
+	Err error `json:"-"`
+}
+
+// The outcome of a batch, with a result for each of its operations in order.
+type Report struct {
+	// Whether the changes were kept, false if any operation failed.
+	Committed bool     `json:"committed"`
+	Results   []Result `json:"results"`
+}
+
+// The failed operation, or nil if every operation succeeded.
+func (report Report) Failed() *Result {
+	for i, result := range report.Results {
+		if result.Status == StatusFailed {
+			return &report.Results[i]
+		}
+	}
+	return nil
+}
+
+var errOperationFailed = errors.New("a batch operation failed")
+
+// Runs the operations in order, in a single transaction (adding copies with
+// the next item numbers from the allocator).  The first operation to fail
+// stops the batch and rolls back all of its changes; its result has the error
+// and the operations after it are skipped.  The returned error is only for a
+// batch of more than MaxOperations or a failure to begin or commit the
+// transaction.
+func Run(ctx context.Context, db *sql.DB, items *collection.ItemAllocator, userID uint64, ops []Operation) (Report, error) {
+	if len(ops) > MaxOperations {
+		return Report{}, invalid("operations", "has %d operations, more than the %d allowed",
+			len(ops), MaxOperations)
+	}
+	report := Report{Results: make([]Result, len(ops))}
+	for i, op := range ops {
+		report.Results[i] = Result{Index: i, Op: op.Op, Name: op.Name, Status: StatusSkipped}
+	}
+
+	err := database.InTx(ctx, db, func(tx *sql.Tx) error {
+		run := runner{tx: tx, items: items, userID: userID,
+			crates: make(map[string]uint64), vinyl: make(map[string]schema.VinylKey)}
+		for i, op := range ops {
+			result := &report.Results[i]
+			if err := run.operation(ctx, op, result); err != nil {
+				result.Status, result.Err = StatusFailed, err
+				return errOperationFailed
+			}
+			result.Status = StatusDone
+		}
+		return nil
+	})
+	if errors.Is(err, errOperationFailed) {
+		for i, result := range report.Results {
+			if result.Status == StatusDone {
+				report.Results[i] = Result{Index: i, Op: result.Op, Name: result.Name,
+					Status: StatusRolledBack}
+			}
+		}
+		return report, nil
+	}
+	if err != nil {
+		return Report{}, err
+	}
+	report.Committed = true
+	return report, nil
+}
+
+// The state of a running batch: its transaction and the named results so far.
+type runner struct {
+	tx     *sql.Tx
+	items  *collection.ItemAllocator
+	userID uint64
+
+	crates map[string]uint64
+	vinyl  map[string]schema.VinylKey
+}
+
+func (run *runner) operation(ctx context.Context, op Operation, result *Result) error {
+	if op.Name != "" {
+		_, crate := run.crates[op.Name]
+		_, vinyl := run.vinyl[op.Name]
+		if crate || vinyl {
+			return invalid("name", "%q names an earlier operation", op.Name)
+		}
+	}
+
+	switch op.Op {
+	case OpAdd:
+		return run.add(ctx, op, result)
+	case OpCrate:
+		return run.crate(ctx, op, result)
+	case OpMove:
+		crateID, err := run.crate_id(op)
+		if err != nil {
+			return err
+		}
+		keys, err := run.keys(op)
+		if err != nil {
+			return err
+		}
+		return collection.MoveVinyl(ctx, run.tx, run.userID, crateID, keys)
+	case OpTag:
+		keys, err := run.keys(op)
+		if err != nil {
+			return err
+		}
+		if len(op.Add) == 0 && len(op.Remove) == 0 {
+			return invalid("add", "is required when there is nothing to remove")
+		}
+		return collection.TagVinyl(ctx, run.tx, run.userID, keys, op.Add, op.Remove)
+	case OpGrade:
+		return run.grade(ctx, op, result)
+	case OpList:
+		return run.list(ctx, op, result)
+	case "":
+		return invalid("op", "is required")
+	}
+	return invalid("op", "must be add, crate, move, tag, grade or list, not %q", op.Op)
+}
+
+func (run *runner) add(ctx context.Context, op Operation, result *Result) error {
+	if op.Vinyl == nil {
+		return invalid("vinyl", "is required")
+	}
+	vinyl := *op.Vinyl
+	vinyl.UserID = run.userID
+	vinyl.DateSold, vinyl.DateTraded, vinyl.DateArchived = nil, nil, nil
+	if op.CrateID != 0 || op.CrateRef != "" {
+		var err error
+		if vinyl.CrateID, err = run.crate_id(op); err != nil {
+			return err
+		}
+	}
+	if vinyl.CrateID != 0 {
+		if _, err := collection.GetCrate(ctx, run.tx, run.userID, vinyl.CrateID); err != nil {
+			return err
+		}
+	}
+
+	key, err := run.items.Add(ctx, run.tx, vinyl)
+	if err != nil {
+		return err
+	}
+	if len(vinyl.Tags) > 0 {
+		err = collection.TagVinyl(ctx, run.tx, run.userID, []schema.VinylKey{key}, vinyl.Tags, nil)
+		if err != nil {
+			return err
+		}
+	}
+	added, err := collection.GetVinyl(ctx, run.tx, key)
+	if err != nil {
+		return err
+	}
+	if op.Name != "" {
+		run.vinyl[op.Name] = key
+	}
+	result.Vinyl = []schema.Vinyl{added}
+	return nil
+}
+
+func (run *runner) crate(ctx context.Context, op Operation, result *Result) error {
+	if op.Crate == nil {
+		return invalid("crate", "is required")
+	}
+	crate := *op.Crate
+	crate.UserID = run.userID
+	if op.CrateID != 0 || op.CrateRef != "" {
+		var err error
+		if crate.ParentID, err = run.crate_id(op); err != nil {
+			return err
+		}
+	}
+
+	created, err := collection.CreateCrate(ctx, run.tx, crate)
+	if err != nil {
+		return err
+	}
+	if op.Name != "" {
+		run.crates[op.Name] = created.ID
+	}
+	result.Crate = &created
+	return nil
+}
+
+func (run *runner) grade(ctx context.Context, op Operation, result *Result) error {
+	keys, err := run.keys(op)
+	if err != nil {
+		return err
+	}
+	if op.MediaGrade == nil && op.SleeveGrade == nil {
+		return invalid("media_grade", "is required when there is no sleeve_grade")
+	}
+	update := collection.VinylUpdate{MediaGrade: op.MediaGrade, SleeveGrade: op.SleeveGrade}
+	for _, key := range keys {
+		vinyl, err := collection.UpdateVinyl(ctx, run.tx, key, update)
+		if err != nil {
+			return err
+		}
+		result.Vinyl = append(result.Vinyl, vinyl)
+	}
+	return nil
+}
+
+func (run *runner) list(ctx context.Context, op Operation, result *Result) error {
+	keys, err := run.keys(op)
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		listing, err := ledger.OpenListing(ctx, run.tx, schema.Listing{
+			UserID: key.UserID, VersionID: key.VersionID, Item: key.Item,
+			PriceLow: op.PriceLow, PriceHigh: op.PriceHigh, AllowOffers: op.AllowOffers,
+		})
+		if err != nil {
+			return err
+		}
+		result.Listings = append(result.Listings, listing)
+	}
+	return nil
+}
+
+// The operation's crate, by its crateID or the name of the crate operation
+// which created it.
+func (run *runner) crate_id(op Operation) (uint64, error) {
+	if op.CrateRef == "" {
+		return op.CrateID, nil
+	}
+	if op.CrateID != 0 {
+		return 0, invalid("crate_ref", "cannot be given with a crateID")
+	}
+	crateID, found := run.crates[op.CrateRef]
+	if !found {
+		return 0, invalid("crate_ref", "%q is not the name of an earlier crate operation", op.CrateRef)
+	}
+	return crateID, nil
+}
+
+// The keys of the operation's items, which must not be empty.
+func (run *runner) keys(op Operation) ([]schema.VinylKey, error) {
+	if len(op.Items) == 0 {
+		return nil, invalid("items", "is required")
+	}
+	keys := make([]schema.VinylKey, len(op.Items))
+	for i, item := range op.Items {
+		if item.Ref == "" {
+			keys[i] = schema.VinylKey{UserID: run.userID, VersionID: item.VersionID, Item: item.Item}
+			continue
+		}
+		key, found := run.vinyl[item.Ref]
+		if !found {
+			return nil, invalid(fmt.Sprintf("items[%d].ref", i),
+				"%q is not the name of an earlier add operation", item.Ref)
+		}
+		keys[i] = key
+	}
+	return keys, nil
+}
+
+// An error in the operation itself (rather than what it changes).
+func invalid(field string, message string, args ...any) error {
+	return &schema.ValidationError{Typename: "operation", Fields: []schema.FieldError{
+		{Field: field, Message: fmt.Sprintf(message, args...)}}}
+}
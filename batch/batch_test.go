@@ -0,0 +1,165 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/batch/batch_test.go
+
+package batch
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/kevindamm/cratedigdb/collection"
+	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Opens a database with a user "kevin" (userID 42) and a version of a release.
+func open_test_db(t *testing.T) *sql.DB {
+	ctx := context.Background()
+	db, err := database.Open(ctx, filepath.Join(t.TempDir(), "cratedig.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	for _, statement := range []string{
+		`INSERT INTO UserAccounts (userID, username) VALUES (42, "kevin"), (43, "dana")`,
+		`INSERT INTO Releases (releaseID, title, main_version) VALUES (100, "Blue Lines", 1178)`,
+		`INSERT INTO ReleaseVersions (versionID, releaseID, title) VALUES (1178, 100, "Blue Lines")`,
+	} {
+		_, err := tx.Exec(statement)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tx.Commit())
+	return db
+}
+
+func TestRunRefersToEarlierResults(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	var items collection.ItemAllocator
+	existing, err := items.Add(ctx, db, schema.Vinyl{UserID: 42, VersionID: 1178})
+	require.NoError(t, err)
+
+	fair, near_mint := schema.GradeFair, schema.GradeNearMint
+	price := schema.NewMoney(2500, schema.CurrencyUSD)
+	report, err := Run(ctx, db, &items, 42, []Operation{
+		{Op: OpCrate, Name: "sale", Crate: &schema.Crate{Name: "For Sale"}},
+		{Op: OpAdd, Name: "copy", CrateRef: "sale",
+			Vinyl: &schema.Vinyl{VersionID: 1178, MediaGrade: schema.GradeVeryGood, Tags: []string{"dupe"}}},
+		{Op: OpMove, CrateRef: "sale", Items: []ItemRef{{VersionID: 1178, Item: existing.Item}}},
+		{Op: OpTag, Items: []ItemRef{{Ref: "copy"}, {VersionID: 1178, Item: existing.Item}}, Add: []string{"sell"}},
+		{Op: OpGrade, Items: []ItemRef{{Ref: "copy"}}, MediaGrade: &near_mint, SleeveGrade: &fair},
+		{Op: OpList, Items: []ItemRef{{Ref: "copy"}}, PriceLow: &price},
+	})
+	require.NoError(t, err)
+	require.Nil(t, report.Failed())
+	assert.True(t, report.Committed)
+	require.Len(t, report.Results, 6)
+	for i, result := range report.Results {
+		assert.Equal(t, StatusDone, result.Status, "operation %d", i)
+	}
+
+	crate := report.Results[0].Crate
+	require.NotNil(t, crate)
+	assert.Equal(t, "for-sale", crate.Path)
+	added := report.Results[1].Vinyl
+	require.Len(t, added, 1)
+	assert.Equal(t, crate.ID, added[0].CrateID)
+	assert.Equal(t, schema.GradeNearMint, report.Results[4].Vinyl[0].MediaGrade)
+	assert.Equal(t, added[0].Item, report.Results[5].Listings[0].Item)
+
+	vinyl, err := collection.GetVinyl(ctx, db, added[0].VinylKey())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"dupe", "sell"}, vinyl.Tags)
+	vinyl, err = collection.GetVinyl(ctx, db, existing)
+	require.NoError(t, err)
+	assert.Equal(t, crate.ID, vinyl.CrateID)
+	assert.ElementsMatch(t, []string{"dupe", "sell"}, vinyl.Tags, "tags apply to every copy of the version")
+}
+
+func TestRunRollsBackOnFailure(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	var items collection.ItemAllocator
+
+	report, err := Run(ctx, db, &items, 42, []Operation{
+		{Op: OpCrate, Name: "new", Crate: &schema.Crate{Name: "New Arrivals"}},
+		{Op: OpAdd, Name: "copy", Vinyl: &schema.Vinyl{VersionID: 1178}},
+		{Op: OpMove, CrateRef: "new", Items: []ItemRef{{Ref: "copy"}, {Ref: "missing"}}},
+		{Op: OpTag, Items: []ItemRef{{Ref: "copy"}}, Add: []string{"new"}},
+	})
+	require.NoError(t, err)
+	assert.False(t, report.Committed)
+	assert.Equal(t, []Status{StatusRolledBack, StatusRolledBack, StatusFailed, StatusSkipped},
+		[]Status{report.Results[0].Status, report.Results[1].Status,
+			report.Results[2].Status, report.Results[3].Status})
+	assert.Nil(t, report.Results[0].Crate, "the rolled back crate is not reported")
+
+	failed := report.Failed()
+	require.NotNil(t, failed)
+	assert.Equal(t, 2, failed.Index)
+	var invalid *schema.ValidationError
+	require.ErrorAs(t, failed.Err, &invalid)
+	assert.Equal(t, "items[1].ref", invalid.Fields[0].Field)
+
+	var vinyl, crates int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM VinylItems`).Scan(&vinyl))
+	assert.Zero(t, vinyl, "nothing was added")
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM Crates WHERE userID = 42`).Scan(&crates))
+	assert.Zero(t, crates, "nothing was created")
+}
+
+func TestRunInvalidOperations(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	var items collection.ItemAllocator
+	for _, test := range []struct {
+		ops   []Operation
+		field string
+	}{
+		{[]Operation{{Op: "sell"}}, "op"},
+		{[]Operation{{Op: OpAdd}}, "vinyl"},
+		{[]Operation{{Op: OpTag, Items: []ItemRef{{VersionID: 1178, Item: 1}}}}, "add"},
+		{[]Operation{{Op: OpGrade, Items: []ItemRef{{VersionID: 1178, Item: 1}}}}, "media_grade"},
+		{[]Operation{{Op: OpMove, CrateRef: "none", Items: []ItemRef{{Ref: "x"}}}}, "crate_ref"},
+		{[]Operation{
+			{Op: OpCrate, Name: "a", Crate: &schema.Crate{Name: "A"}},
+			{Op: OpCrate, Name: "a", Crate: &schema.Crate{Name: "B"}},
+		}, "name"},
+	} {
+		report, err := Run(ctx, db, &items, 42, test.ops)
+		require.NoError(t, err)
+		failed := report.Failed()
+		require.NotNil(t, failed, test.field)
+		var invalid *schema.ValidationError
+		require.ErrorAs(t, failed.Err, &invalid, test.field)
+		assert.Equal(t, test.field, invalid.Fields[0].Field)
+	}
+
+	_, err := Run(ctx, db, &items, 42, make([]Operation, MaxOperations+1))
+	assert.Error(t, err)
+}
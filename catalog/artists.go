@@ -0,0 +1,280 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/catalog/artists.go
+
+// Package catalog holds the business logic for the Discogs-derived catalog
+// (artists, labels, releases and their versions) on top of the sqlite
+// database in package sql.
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kevindamm/cratedigdb/pagination"
+	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
+)
+
+// Looks up an artist with its name variations, aliases, group memberships and
+// URLs embedded.  Uses one query for each of these, regardless of how many
+// rows each has.  Returns an error wrapping database.ErrNotFound if missing.
+func GetArtist(ctx context.Context, db database.Queryer, artistID uint64) (schema.Artist, error) {
+	artist := schema.Artist{ID: artistID}
+	var realname sql.NullString
+	err := db.QueryRowContext(ctx, `
+	  SELECT name, realname, profile, data_quality
+	  FROM Artists WHERE artistID = ?`, artistID,
+	).Scan(&artist.Name, &realname, &artist.Profile, &artist.DataQuality)
+	if err != nil {
+		return artist, fmt.Errorf("artist %d: %w", artistID, database.Classify(err))
+	}
+	artist.RealName = realname.String
+
+	artist.NameVariations, err = database.QueryList(ctx, db, scan_string,
+		`SELECT name FROM Artist_Names WHERE artistID = ? ORDER BY rowid`, artistID)
+	if err != nil {
+		return artist, fmt.Errorf("artist %d names: %w", artistID, err)
+	}
+
+	artist.Aliases, err = database.QueryList(ctx, db, func(rows *sql.Rows) (schema.ArtistAlias, error) {
+		var alias schema.ArtistAlias
+		var aliasID sql.NullInt64
+		err := rows.Scan(&alias.Name, &aliasID)
+		alias.ArtistID = uint64(aliasID.Int64)
+		return alias, err
+	}, `SELECT alias_name, alias_artist_id FROM Artist_Alias
+	    WHERE artistID = ? ORDER BY rowid`, artistID)
+	if err != nil {
+		return artist, fmt.Errorf("artist %d aliases: %w", artistID, err)
+	}
+
+	scan_member := func(rows *sql.Rows) (schema.ArtistMember, error) {
+		var member schema.ArtistMember
+		return member, rows.Scan(&member.ArtistID, &member.Name)
+	}
+	artist.Members, err = database.QueryList(ctx, db, scan_member, `
+	  SELECT member_artistID, member_name FROM Artist_GroupMembers
+	  WHERE group_artistID = ? ORDER BY member_name`, artistID)
+	if err != nil {
+		return artist, fmt.Errorf("artist %d members: %w", artistID, err)
+	}
+	artist.Groups, err = database.QueryList(ctx, db, scan_member, `
+	  SELECT group_artistID, Artists.name FROM Artist_GroupMembers
+	    JOIN Artists ON Artists.artistID = group_artistID
+	  WHERE member_artistID = ? ORDER BY Artists.name`, artistID)
+	if err != nil {
+		return artist, fmt.Errorf("artist %d groups: %w", artistID, err)
+	}
+
+	artist.URLs, err = database.QueryList(ctx, db, scan_string,
+		`SELECT url FROM Artist_URLs WHERE artistID = ? ORDER BY rowid`, artistID)
+	if err != nil {
+		return artist, fmt.Errorf("artist %d urls: %w", artistID, err)
+	}
+	return artist, nil
+}
+
+// Selects a page of artists (without their embedded lists).  The placeholder
+// for unknown artists (artistID 0) is never included.
+type ArtistQuery struct {
+	// Case-insensitive (for ASCII letters) prefix of the artist's name.
+	NamePrefix string
+	// In one of the ArtistSorts orders.
+	Paging pagination.Request
+}
+
+// Artists are listed by name (the default) or by artistID.
+var ArtistSorts = pagination.Sorts{
+	{Name: "name", Columns: []pagination.Column{{Expr: "name COLLATE NOCASE"}, {Expr: "artistID"}}},
+	{Name: "id", Columns: []pagination.Column{{Expr: "artistID"}}},
+}
+
+// Returns the artists on the requested page, described along with the total
+// number of artists matching the query (on all pages).
+func ListArtists(ctx context.Context, db database.Queryer, query ArtistQuery) ([]schema.Artist, pagination.Page, error) {
+	pattern := like_escaper.Replace(query.NamePrefix) + "%"
+
+	var total int
+	err := db.QueryRowContext(ctx, `
+	  SELECT COUNT(*) FROM Artists
+	  WHERE artistID <> 0 AND name LIKE ? ESCAPE '\'`, pattern,
+	).Scan(&total)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+
+	after, args := query.Paging.Where()
+	artists, err := database.QueryList(ctx, db, func(rows *sql.Rows) (schema.Artist, error) {
+		var artist schema.Artist
+		var realname sql.NullString
+		err := rows.Scan(&artist.ID, &artist.Name, &realname, &artist.Profile, &artist.DataQuality)
+		artist.RealName = realname.String
+		return artist, err
+	}, `
+	  SELECT artistID, name, realname, profile, data_quality
+	  FROM Artists
+	  WHERE artistID <> 0 AND name LIKE ? ESCAPE '\' AND `+after+`
+	  ORDER BY `+query.Paging.OrderBy()+`
+	  LIMIT ?`,
+		append(append([]any{pattern}, args...), query.Paging.Fetch())...)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+	artists, page := pagination.Paginate(query.Paging, artists, total, artist_key(query.Paging.Sort))
+	return artists, page, nil
+}
+
+// The sort key of an artist in one of the ArtistSorts orders.
+func artist_key(sort pagination.Sort) func(schema.Artist) []any {
+	if strings.TrimPrefix(sort.Name, "-") == "id" {
+		return func(artist schema.Artist) []any { return []any{artist.ID} }
+	}
+	return func(artist schema.Artist) []any { return []any{artist.Name, artist.ID} }
+}
+
+var like_escaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Inserts the artist or replaces an existing one with the same ID, including
+// its name variations, aliases, members, groups and URLs (which replace the
+// existing ones entirely).  Returns true if the artist did not exist before.
+//
+// Members, groups and aliases with an artistID must refer to known artists,
+// otherwise the error wraps database.ErrReferenced.
+func UpsertArtist(ctx context.Context, db *sql.DB, artist schema.Artist) (bool, error) {
+	if err := artist.Validate(); err != nil {
+		return false, err
+	}
+	var created bool
+	err := database.InTx(ctx, db, func(tx *sql.Tx) error {
+		var count int
+		if err := tx.QueryRowContext(ctx,
+			`SELECT COUNT(*) FROM Artists WHERE artistID = ?`, artist.ID,
+		).Scan(&count); err != nil {
+			return err
+		}
+		created = count == 0
+
+		var realname any
+		if artist.RealName != "" {
+			realname = artist.RealName
+		}
+		if _, err := tx.ExecContext(ctx, `
+		  INSERT INTO Artists (artistID, name, realname, profile, data_quality)
+		  VALUES (?, ?, ?, ?, ?)
+		  ON CONFLICT (artistID) DO UPDATE SET
+		    name = excluded.name,
+		    realname = excluded.realname,
+		    profile = excluded.profile,
+		    data_quality = excluded.data_quality`,
+			artist.ID, artist.Name, realname, artist.Profile, artist.DataQuality,
+		); err != nil {
+			return err
+		}
+
+		for _, stmt := range []string{
+			`DELETE FROM Artist_Names WHERE artistID = ?1`,
+			`DELETE FROM Artist_Alias WHERE artistID = ?1`,
+			`DELETE FROM Artist_GroupMembers WHERE group_artistID = ?1 OR member_artistID = ?1`,
+			`DELETE FROM Artist_URLs WHERE artistID = ?1`,
+		} {
+			if _, err := tx.ExecContext(ctx, stmt, artist.ID); err != nil {
+				return err
+			}
+		}
+
+		for _, name := range artist.NameVariations {
+			if _, err := tx.ExecContext(ctx,
+				`INSERT INTO Artist_Names (artistID, name) VALUES (?, ?)`,
+				artist.ID, name); err != nil {
+				return err
+			}
+		}
+		for _, alias := range artist.Aliases {
+			var aliasID any
+			if alias.ArtistID != 0 {
+				aliasID = alias.ArtistID
+			}
+			if _, err := tx.ExecContext(ctx, `
+			  INSERT INTO Artist_Alias (artistID, alias_name, alias_artist_id)
+			  VALUES (?, ?, ?)`, artist.ID, alias.Name, aliasID); err != nil {
+				return err
+			}
+		}
+		for _, member := range artist.Members {
+			if _, err := tx.ExecContext(ctx, `
+			  INSERT INTO Artist_GroupMembers (group_artistID, member_artistID, member_name)
+			  VALUES (?, ?, ?)`, artist.ID, member.ArtistID, member.Name); err != nil {
+				return err
+			}
+		}
+		for _, group := range artist.Groups {
+			if _, err := tx.ExecContext(ctx, `
+			  INSERT INTO Artist_GroupMembers (group_artistID, member_artistID, member_name)
+			  VALUES (?, ?, ?)`, group.ArtistID, artist.ID, artist.Name); err != nil {
+				return err
+			}
+		}
+		for _, url := range artist.URLs {
+			if _, err := tx.ExecContext(ctx,
+				`INSERT INTO Artist_URLs (artistID, url) VALUES (?, ?)`,
+				artist.ID, url); err != nil {
+				return err
+			}
+		}
+		_, err := RecordGeneration(ctx, tx, "api")
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("upserting artist %d: %w", artist.ID, err)
+	}
+	return created, nil
+}
+
+// Deletes the artist along with its names, aliases, memberships and URLs.
+// The placeholder for unknown artists (artistID 0) cannot be deleted.
+// Fails with an error wrapping database.ErrReferenced while releases, tracks
+// or other artists' aliases still refer to the artist.  The new catalog
+// generation is recorded in the same transaction, so the two happen together.
+func DeleteArtist(ctx context.Context, db database.Queryer, artistID uint64) error {
+	err := database.InTx(ctx, db, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `DELETE FROM Artists WHERE artistID = ? AND artistID <> 0`, artistID)
+		if err != nil {
+			return err
+		}
+		if count, err := result.RowsAffected(); err != nil {
+			return err
+		} else if count == 0 {
+			return database.ErrNotFound
+		}
+		_, err = RecordGeneration(ctx, tx, "api")
+		return err
+	})
+	if errors.Is(err, database.ErrNotFound) {
+		return fmt.Errorf("artist %d: %w", artistID, err)
+	} else if err != nil {
+		return fmt.Errorf("deleting artist %d: %w", artistID, err)
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/catalog/artists_test.go
+
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/kevindamm/cratedigdb/pagination"
+	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Opens an in-memory database with a small catalog: the group Massive Attack
+// (artistID 10) and its member 3D (artistID 11), on the release Blue Lines.
+func open_test_db(t *testing.T) *sql.DB {
+	ctx := context.Background()
+	db, err := database.Open(ctx, ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	for _, statement := range []string{
+		`INSERT INTO Artists (artistID, name, realname) VALUES
+		   (10, "Massive Attack", NULL), (11, "3D", "Robert Del Naja")`,
+		`INSERT INTO Artist_GroupMembers (group_artistID, member_artistID, member_name)
+		   VALUES (10, 11, "3D")`,
+		`INSERT INTO Artist_Names (artistID, name) VALUES (10, "Massive"), (10, "Massive Attak")`,
+		`INSERT INTO Artist_Alias (artistID, alias_name) VALUES (11, "Delge")`,
+		`INSERT INTO Artist_URLs (artistID, url) VALUES (10, "https://www.massiveattack.co.uk")`,
+		`INSERT INTO Releases (releaseID, title, main_version) VALUES (100, "Blue Lines", 1178)`,
+		`INSERT INTO ReleaseVersions (versionID, releaseID, title) VALUES (1178, 100, "Blue Lines")`,
+		`INSERT INTO Release_Artists (releaseID, artistID) VALUES (100, 10)`,
+	} {
+		_, err := tx.Exec(statement)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tx.Commit())
+	return db
+}
+
+func TestGetArtist(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+
+	artist, err := GetArtist(ctx, db, 10)
+	require.NoError(t, err)
+	assert.Equal(t, schema.Artist{
+		ID:             10,
+		Name:           "Massive Attack",
+		NameVariations: []string{"Massive", "Massive Attak"},
+		Members:        []schema.ArtistMember{{ArtistID: 11, Name: "3D"}},
+		URLs:           []string{"https://www.massiveattack.co.uk"},
+	}, artist)
+
+	artist, err = GetArtist(ctx, db, 11)
+	require.NoError(t, err)
+	assert.Equal(t, "Robert Del Naja", artist.RealName)
+	assert.Equal(t, []schema.ArtistAlias{{Name: "Delge"}}, artist.Aliases)
+	assert.Equal(t, []schema.ArtistMember{{ArtistID: 10, Name: "Massive Attack"}}, artist.Groups)
+
+	_, err = GetArtist(ctx, db, 12)
+	assert.True(t, errors.Is(err, database.ErrNotFound), err)
+}
+
+func TestListArtists(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	for i := range 5 {
+		_, err := db.ExecContext(ctx, `INSERT INTO Artists (artistID, name) VALUES (?, ?)`,
+			20+i, fmt.Sprintf("Mad_%d", i))
+		require.NoError(t, err)
+	}
+
+	by_name := pagination.Request{Sort: ArtistSorts[0], Limit: 3}
+	artists, page, err := ListArtists(ctx, db, ArtistQuery{Paging: by_name})
+	require.NoError(t, err)
+	assert.Equal(t, 7, page.Total)
+	require.Len(t, artists, 3)
+	assert.Equal(t, "3D", artists[0].Name)
+
+	by_name.Limit = 4
+	artists, page, err = ListArtists(ctx, db, ArtistQuery{NamePrefix: "ma", Paging: by_name})
+	require.NoError(t, err)
+	require.Len(t, artists, 4)
+	next, err := pagination.ParseCursor(page.Next)
+	require.NoError(t, err)
+	by_name.Cursor = &next
+	artists, page, err = ListArtists(ctx, db, ArtistQuery{NamePrefix: "ma", Paging: by_name})
+	require.NoError(t, err)
+	assert.Equal(t, 6, page.Total)
+	require.Len(t, artists, 2)
+	assert.Equal(t, "Mad_4", artists[0].Name)
+	assert.Equal(t, "Massive Attack", artists[1].Name)
+	assert.Empty(t, page.Next)
+	assert.NotEmpty(t, page.Prev)
+
+	by_id := pagination.First(ArtistSorts[1].Reverse())
+	artists, _, err = ListArtists(ctx, db, ArtistQuery{Paging: by_id})
+	require.NoError(t, err)
+	require.Len(t, artists, 7)
+	assert.Equal(t, "Mad_4", artists[0].Name)
+
+	// Wildcards in the prefix match literally.
+	_, page, err = ListArtists(ctx, db, ArtistQuery{NamePrefix: "Mad_", Paging: by_name})
+	require.NoError(t, err)
+	assert.Equal(t, 5, page.Total)
+	_, page, err = ListArtists(ctx, db, ArtistQuery{NamePrefix: "M%", Paging: by_name})
+	require.NoError(t, err)
+	assert.Equal(t, 0, page.Total)
+}
+
+func TestUpsertArtist(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+
+	created, err := UpsertArtist(ctx, db, schema.Artist{
+		ID:             12,
+		Name:           "Tricky",
+		RealName:       "Adrian Thaws",
+		NameVariations: []string{"Tricky Kid"},
+		Aliases:        []schema.ArtistAlias{{Name: "Tricky Kid"}},
+		Groups:         []schema.ArtistMember{{ArtistID: 10, Name: "Massive Attack"}},
+	})
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	group, err := GetArtist(ctx, db, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []schema.ArtistMember{
+		{ArtistID: 11, Name: "3D"}, {ArtistID: 12, Name: "Tricky"}}, group.Members)
+
+	// Replacing the artist replaces all of its embedded lists.
+	created, err = UpsertArtist(ctx, db, schema.Artist{
+		ID: 12, Name: "Tricky", Profile: "Left the group in 1995.",
+		URLs: []string{"https://trickysite.com"},
+	})
+	require.NoError(t, err)
+	assert.False(t, created)
+	artist, err := GetArtist(ctx, db, 12)
+	require.NoError(t, err)
+	assert.Equal(t, schema.Artist{
+		ID: 12, Name: "Tricky", Profile: "Left the group in 1995.",
+		URLs: []string{"https://trickysite.com"},
+	}, artist)
+	group, err = GetArtist(ctx, db, 10)
+	require.NoError(t, err)
+	assert.Len(t, group.Members, 1)
+
+	_, err = UpsertArtist(ctx, db, schema.Artist{ID: 13})
+	var invalid *schema.ValidationError
+	assert.True(t, errors.As(err, &invalid), err)
+
+	_, err = UpsertArtist(ctx, db, schema.Artist{
+		ID: 13, Name: "Daddy G", Groups: []schema.ArtistMember{{ArtistID: 99}}})
+	assert.True(t, errors.Is(err, database.ErrReferenced), err)
+	_, err = GetArtist(ctx, db, 13)
+	assert.True(t, errors.Is(err, database.ErrNotFound), "rolled back: %v", err)
+}
+
+func TestDeleteArtist(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+
+	require.NoError(t, DeleteArtist(ctx, db, 11))
+	group, err := GetArtist(ctx, db, 10)
+	require.NoError(t, err)
+	assert.Empty(t, group.Members)
+
+	err = DeleteArtist(ctx, db, 11)
+	assert.True(t, errors.Is(err, database.ErrNotFound), err)
+
+	// Still credited on a release.
+	err = DeleteArtist(ctx, db, 10)
+	assert.True(t, errors.Is(err, database.ErrReferenced), err)
+
+	// Nor is the artist deleted if its generation can't be recorded.
+	_, err = db.Exec(`INSERT INTO Artists (artistID, name) VALUES (12, "Tricky")`)
+	require.NoError(t, err)
+	_, err = db.Exec(`
+	  CREATE TRIGGER no_generations BEFORE INSERT ON CatalogGenerations
+	  BEGIN SELECT RAISE(ABORT, 'no generations'); END`)
+	require.NoError(t, err)
+	require.Error(t, DeleteArtist(ctx, db, 12))
+	_, err = GetArtist(ctx, db, 12)
+	assert.NoError(t, err, "rolled back")
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/catalog/generations.go
+
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
+)
+
+// A change to the catalog, either an import of a Discogs dump or an edit made
+// through the API.  Catalog resources are unmodified since the latest one.
+type Generation struct {
+	ID      uint64           `json:"generation"`
+	Changed schema.Timestamp `json:"date_changed"`
+	Source  string           `json:"source"`
+}
+
+// The most recent generation, or the zero Generation if the catalog has never
+// been changed since the tables were created.
+func LatestGeneration(ctx context.Context, db database.Queryer) (Generation, error) {
+	var generation Generation
+	err := db.QueryRowContext(ctx, `
+	  SELECT generation, date_changed, source
+	  FROM CatalogGenerations ORDER BY generation DESC LIMIT 1`,
+	).Scan(&generation.ID, &generation.Changed, &generation.Source)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Generation{}, nil
+	}
+	if err != nil {
+		return generation, fmt.Errorf("latest catalog generation: %w", err)
+	}
+	return generation, nil
+}
+
+// Begins a new generation for a change from the source (e.g. "api"), within
+// the same transaction as the change when db is a *sql.Tx.
+func RecordGeneration(ctx context.Context, db database.Queryer, source string) (Generation, error) {
+	generation := Generation{Changed: schema.Now(), Source: source}
+	err := db.QueryRowContext(ctx, `
+	  INSERT INTO CatalogGenerations (date_changed, source) VALUES (?, ?)
+	  RETURNING generation`, generation.Changed, source,
+	).Scan(&generation.ID)
+	if err != nil {
+		return generation, fmt.Errorf("recording catalog generation: %w", err)
+	}
+	return generation, nil
+}
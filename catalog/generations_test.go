@@ -0,0 +1,65 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/catalog/generations_test.go
+
+package catalog
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kevindamm/cratedigdb/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerations(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+
+	latest, err := LatestGeneration(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, Generation{}, latest)
+
+	imported, err := RecordGeneration(ctx, db, "discogs_20250101")
+	require.NoError(t, err)
+	latest, err = LatestGeneration(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, imported, latest)
+	assert.False(t, latest.Changed.IsZero())
+
+	// Changes through the API each begin a generation, failed ones do not.
+	_, err = UpsertArtist(ctx, db, schema.Artist{ID: 12, Name: "Tricky"})
+	require.NoError(t, err)
+	_, err = UpsertArtist(ctx, db, schema.Artist{
+		ID: 13, Name: "Daddy G", Groups: []schema.ArtistMember{{ArtistID: 99}}})
+	require.Error(t, err)
+	latest, err = LatestGeneration(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, imported.ID+1, latest.ID)
+	assert.Equal(t, "api", latest.Source)
+
+	require.NoError(t, DeleteArtist(ctx, db, 12))
+	require.Error(t, DeleteArtist(ctx, db, 12))
+	latest, err = LatestGeneration(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, imported.ID+2, latest.ID)
+}
@@ -0,0 +1,342 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/catalog/releases.go
+
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/kevindamm/cratedigdb/pagination"
+	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
+)
+
+// Looks up the release (the master) with its artists, genres, styles, videos
+// and summaries of its versions.  Uses one query for each of these, regardless
+// of how many rows each has.  Returns an error wrapping database.ErrNotFound
+// if missing.
+func GetRelease(ctx context.Context, db database.Queryer, releaseID uint64) (schema.Release, error) {
+	release := schema.Release{ID: releaseID}
+	var year sql.NullInt64
+	err := db.QueryRowContext(ctx, `
+	  SELECT title, year, main_version, data_quality
+	  FROM Releases WHERE releaseID = ?`, releaseID,
+	).Scan(&release.Title, &year, &release.MainVersion, &release.DataQuality)
+	if err != nil {
+		return release, fmt.Errorf("release %d: %w", releaseID, database.Classify(err))
+	}
+	release.Year = int(year.Int64)
+
+	release.Artists, err = database.QueryList(ctx, db, scan_credit, `
+	  SELECT artistID, COALESCE(NULLIF(artist_name, ""), Artists.name),
+	    COALESCE(role, ""), ""
+	  FROM Release_Artists JOIN Artists USING (artistID)
+	  WHERE releaseID = ?
+	  ORDER BY ordering, artistID`, releaseID)
+	if err != nil {
+		return release, fmt.Errorf("release %d artists: %w", releaseID, err)
+	}
+
+	release.Genres, err = database.QueryList(ctx, db, scan_string, `
+	  SELECT genre FROM Release_Genres JOIN GenreEnum USING (genreID)
+	  WHERE releaseID = ? ORDER BY genreID`, releaseID)
+	if err != nil {
+		return release, fmt.Errorf("release %d genres: %w", releaseID, err)
+	}
+	release.Styles, err = database.QueryList(ctx, db, scan_string, `
+	  SELECT style FROM Release_Styles JOIN StyleEnum USING (styleID)
+	  WHERE releaseID = ? ORDER BY styleID`, releaseID)
+	if err != nil {
+		return release, fmt.Errorf("release %d styles: %w", releaseID, err)
+	}
+
+	release.Videos, err = database.QueryList(ctx, db, func(rows *sql.Rows) (schema.Video, error) {
+		var video schema.Video
+		return video, rows.Scan(&video.URL, &video.Title, &video.Description, &video.Duration)
+	}, `
+	  SELECT url, COALESCE(title, ""), COALESCE(description, ""), COALESCE(duration_s, 0)
+	  FROM Release_Videos WHERE releaseID = ? ORDER BY rowid`, releaseID)
+	if err != nil {
+		return release, fmt.Errorf("release %d videos: %w", releaseID, err)
+	}
+
+	release.Versions, err = database.QueryList(ctx, db, scan_version, `
+	  SELECT versionID, releaseID, title, year_released, country, notes, data_quality
+	  FROM ReleaseVersions WHERE releaseID = ?
+	  ORDER BY year_released, versionID`, releaseID)
+	if err != nil {
+		return release, fmt.Errorf("release %d versions: %w", releaseID, err)
+	}
+	return release, nil
+}
+
+// Selects a page of releases (without their embedded lists).  The placeholder
+// for unknown releases (releaseID 0) is never included.
+type ReleaseQuery struct {
+	// Case-insensitive (for ASCII letters) prefix of the release's title.
+	TitlePrefix string
+	// If nonzero, only releases crediting this artist.
+	ArtistID uint64
+	// In one of the ReleaseSorts orders.
+	Paging pagination.Request
+}
+
+// Releases are listed by title (the default), year or releaseID.  Releases
+// without a year sort as year 0.
+var ReleaseSorts = pagination.Sorts{
+	{Name: "title", Columns: []pagination.Column{{Expr: "title COLLATE NOCASE"}, {Expr: "releaseID"}}},
+	{Name: "year", Columns: []pagination.Column{{Expr: "COALESCE(year, 0)"}, {Expr: "releaseID"}}},
+	{Name: "id", Columns: []pagination.Column{{Expr: "releaseID"}}},
+}
+
+// Returns the releases on the requested page, described along with the total
+// number of releases matching the query (on all pages).
+func ListReleases(ctx context.Context, db database.Queryer, query ReleaseQuery) ([]schema.Release, pagination.Page, error) {
+	filter := `releaseID <> 0 AND title LIKE ? ESCAPE '\'`
+	args := []any{like_escaper.Replace(query.TitlePrefix) + "%"}
+	if query.ArtistID != 0 {
+		filter += ` AND releaseID IN (
+		  SELECT releaseID FROM Release_Artists WHERE artistID = ?)`
+		args = append(args, query.ArtistID)
+	}
+
+	var total int
+	err := db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM Releases WHERE `+filter, args...,
+	).Scan(&total)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+
+	after, after_args := query.Paging.Where()
+	releases, err := database.QueryList(ctx, db, func(rows *sql.Rows) (schema.Release, error) {
+		var release schema.Release
+		var year sql.NullInt64
+		err := rows.Scan(&release.ID, &release.Title, &year, &release.MainVersion, &release.DataQuality)
+		release.Year = int(year.Int64)
+		return release, err
+	}, `
+	  SELECT releaseID, title, year, main_version, data_quality
+	  FROM Releases
+	  WHERE `+filter+` AND `+after+`
+	  ORDER BY `+query.Paging.OrderBy()+`
+	  LIMIT ?`,
+		append(append(args, after_args...), query.Paging.Fetch())...)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+	releases, page := pagination.Paginate(query.Paging, releases, total, release_key(query.Paging.Sort))
+	return releases, page, nil
+}
+
+// The sort key of a release in one of the ReleaseSorts orders.
+func release_key(sort pagination.Sort) func(schema.Release) []any {
+	switch strings.TrimPrefix(sort.Name, "-") {
+	case "id":
+		return func(release schema.Release) []any { return []any{release.ID} }
+	case "year":
+		return func(release schema.Release) []any { return []any{release.Year, release.ID} }
+	}
+	return func(release schema.Release) []any { return []any{release.Title, release.ID} }
+}
+
+// Looks up the release version with its credits, labels (and catalog numbers),
+// identifiers (barcodes, etc.), formats, genres, styles, tracklist (with
+// per-track credits) and cover art.  Uses one query for each of these,
+// regardless of how many tracks or credits there are.  Returns an error
+// wrapping database.ErrNotFound if missing.
+//
+// Versions do not have their own styles; these are the styles of the release.
+func GetVersion(ctx context.Context, db database.Queryer, versionID uint64) (schema.ReleaseVersion, error) {
+	version, err := scan_version(db.QueryRowContext(ctx, `
+	  SELECT versionID, releaseID, title, year_released, country, notes, data_quality
+	  FROM ReleaseVersions WHERE versionID = ?`, versionID))
+	if err != nil {
+		return version, fmt.Errorf("version %d: %w", versionID, database.Classify(err))
+	}
+
+	credits, err := database.QueryList(ctx, db, func(rows *sql.Rows) (extra_credit, error) {
+		var credit extra_credit
+		return credit, rows.Scan(&credit.ArtistID, &credit.Name, &credit.Role, &credit.Tracks,
+			&credit.is_extra)
+	}, `
+	  SELECT artistID, COALESCE(NULLIF(artist_name, ""), Artists.name),
+	    COALESCE(role, ""), COALESCE(tracks, ""), is_extra
+	  FROM ReleaseVersion_Artists JOIN Artists USING (artistID)
+	  WHERE versionID = ?
+	  ORDER BY ordering, ReleaseVersion_Artists.rowid`, versionID)
+	if err != nil {
+		return version, fmt.Errorf("version %d artists: %w", versionID, err)
+	}
+	for _, credit := range credits {
+		if credit.is_extra {
+			version.ExtraArtists = append(version.ExtraArtists, credit.ArtistCredit)
+		} else {
+			version.Artists = append(version.Artists, credit.ArtistCredit)
+		}
+	}
+
+	version.Labels, err = database.QueryList(ctx, db, func(rows *sql.Rows) (schema.VersionLabel, error) {
+		var label schema.VersionLabel
+		return label, rows.Scan(&label.LabelID, &label.Name, &label.CatalogID)
+	}, `
+	  SELECT labelID, label_name, COALESCE(catalog_id, "")
+	  FROM ReleaseVersion_Labels WHERE versionID = ? ORDER BY rowid`, versionID)
+	if err != nil {
+		return version, fmt.Errorf("version %d labels: %w", versionID, err)
+	}
+
+	version.Identifiers, err = database.QueryList(ctx, db, func(rows *sql.Rows) (schema.VersionIdentifier, error) {
+		var identifier schema.VersionIdentifier
+		return identifier, rows.Scan(&identifier.Type, &identifier.Value, &identifier.Description)
+	}, `
+	  SELECT type, value, COALESCE(description, "")
+	  FROM ReleaseVersion_Identifiers WHERE versionID = ? ORDER BY rowid`, versionID)
+	if err != nil {
+		return version, fmt.Errorf("version %d identifiers: %w", versionID, err)
+	}
+
+	version.Formats, err = database.QueryList(ctx, db, func(rows *sql.Rows) (schema.MediaFormat, error) {
+		var format schema.MediaFormat
+		return format, rows.Scan(&format.Format, &format.Quantity, &format.Description, &format.Notes)
+	}, `
+	  SELECT formatID, COALESCE(quantity, 0), COALESCE(description, ""), COALESCE(notes, "")
+	  FROM ReleaseVersion_Formats WHERE versionID = ? ORDER BY formatID`, versionID)
+	if err != nil {
+		return version, fmt.Errorf("version %d formats: %w", versionID, err)
+	}
+
+	version.Genres, err = database.QueryList(ctx, db, scan_string, `
+	  SELECT genre FROM ReleaseVersion_Genres JOIN GenreEnum USING (genreID)
+	  WHERE versionID = ? ORDER BY genreID`, versionID)
+	if err != nil {
+		return version, fmt.Errorf("version %d genres: %w", versionID, err)
+	}
+	version.Styles, err = database.QueryList(ctx, db, scan_string, `
+	  SELECT style FROM Release_Styles JOIN StyleEnum USING (styleID)
+	  WHERE releaseID = ? ORDER BY styleID`, version.ReleaseID)
+	if err != nil {
+		return version, fmt.Errorf("version %d styles: %w", versionID, err)
+	}
+
+	version.Tracklist, err = database.QueryList(ctx, db, func(rows *sql.Rows) (schema.Track, error) {
+		track := schema.Track{VersionID: versionID}
+		return track, rows.Scan(&track.ID, &track.Number, &track.Title, &track.Duration)
+	}, `
+	  SELECT trackID, track_number, title, COALESCE(duration, "")
+	  FROM Tracks WHERE versionID = ? ORDER BY track_number`, versionID)
+	if err != nil {
+		return version, fmt.Errorf("version %d tracks: %w", versionID, err)
+	}
+	track_credits, err := database.QueryList(ctx, db, func(rows *sql.Rows) (track_credit, error) {
+		var credit track_credit
+		return credit, rows.Scan(&credit.trackID, &credit.ArtistID, &credit.Name, &credit.Role,
+			&credit.is_extra)
+	}, `
+	  SELECT trackID, artistID, COALESCE(NULLIF(Track_Artists.name, ""), Artists.name),
+	    COALESCE(role, ""), is_extra
+	  FROM Track_Artists
+	    JOIN Tracks USING (trackID)
+	    JOIN Artists USING (artistID)
+	  WHERE Tracks.versionID = ?
+	  ORDER BY trackID, is_extra, artistID`, versionID)
+	if err != nil {
+		return version, fmt.Errorf("version %d track credits: %w", versionID, err)
+	}
+	track_index := make(map[uint64]int, len(version.Tracklist))
+	for i, track := range version.Tracklist {
+		track_index[track.ID] = i
+	}
+	for _, credit := range track_credits {
+		track := &version.Tracklist[track_index[credit.trackID]]
+		if credit.is_extra {
+			track.ExtraArtists = append(track.ExtraArtists, credit.ArtistCredit)
+		} else {
+			track.Artists = append(track.Artists, credit.ArtistCredit)
+		}
+	}
+
+	// The front sleeve is the primary image and the back sleeve (if any) is
+	// secondary, matching the Discogs image types.
+	version.Images, err = database.QueryList(ctx, db, func(rows *sql.Rows) (schema.Image, error) {
+		var image schema.Image
+		return image, rows.Scan(&image.ID, &image.Type, &image.Path, &image.Filetype,
+			&image.Width, &image.Height)
+	}, `
+	  SELECT imageID, type, obj_path, COALESCE(filetype, ""),
+	    COALESCE(width, 0), COALESCE(height, 0)
+	  FROM (
+	    SELECT front_sleeve AS imageID, "primary" AS type, versionID
+	    FROM ReleaseVersion_CoverArt
+	    UNION ALL
+	    SELECT back_sleeve, "secondary", versionID
+	    FROM ReleaseVersion_CoverArt WHERE back_sleeve <> 0
+	  ) JOIN ImageData USING (imageID)
+	  WHERE versionID = ?
+	  ORDER BY type, imageID`, versionID)
+	if err != nil {
+		return version, fmt.Errorf("version %d cover art: %w", versionID, err)
+	}
+	return version, nil
+}
+
+// A version or track credit, before it is sorted into artists and extra artists.
+type extra_credit struct {
+	schema.ArtistCredit
+	is_extra bool
+}
+
+type track_credit struct {
+	extra_credit
+	trackID uint64
+}
+
+type row_scanner interface {
+	Scan(dest ...any) error
+}
+
+// Scans the version's own columns (versionID, releaseID, title, year_released,
+// country, notes, data_quality) from either a single row or one of many rows.
+func scan_version[R row_scanner](row R) (schema.ReleaseVersion, error) {
+	var version schema.ReleaseVersion
+	var year sql.NullInt64
+	var country, notes sql.NullString
+	err := row.Scan(&version.ID, &version.ReleaseID, &version.Title, &year, &country, &notes,
+		&version.DataQuality)
+	version.Year = int(year.Int64)
+	version.Country = country.String
+	version.Notes = notes.String
+	return version, err
+}
+
+func scan_credit(rows *sql.Rows) (schema.ArtistCredit, error) {
+	var credit schema.ArtistCredit
+	return credit, rows.Scan(&credit.ArtistID, &credit.Name, &credit.Role, &credit.Tracks)
+}
+
+func scan_string(rows *sql.Rows) (string, error) {
+	var value string
+	return value, rows.Scan(&value)
+}
@@ -0,0 +1,242 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/catalog/releases_test.go
+
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/kevindamm/cratedigdb/pagination"
+	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Describes release 100 (Blue Lines) in the test catalog and adds a second
+// version.  The first version (1178) is fully described and has the given
+// number of tracks.
+func add_test_release(t *testing.T, db *sql.DB, tracks int) {
+	tx, err := db.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	for _, statement := range []string{
+		`INSERT INTO GenreEnum (genreID, genre) VALUES (1, "Electronic"), (2, "Hip Hop")`,
+		`INSERT INTO StyleEnum (styleID, style) VALUES (1, "Trip Hop"), (2, "Downtempo")`,
+		`INSERT INTO Labels (labelID, name) VALUES (5, "Wild Bunch Records"), (6, "Circa")`,
+		`INSERT INTO ImageData (imageID, obj_path, filetype, width, height)
+		   VALUES (7, "covers/1178-front.jpg", "jpeg", 600, 600), (8, "covers/1178-back.jpg", "jpeg", 600, 600)`,
+		`UPDATE Release_Artists SET ordering = 1 WHERE releaseID = 100`,
+		`INSERT INTO Release_Genres (releaseID, genreID) VALUES (100, 2), (100, 1)`,
+		`INSERT INTO Release_Styles (releaseID, styleID) VALUES (100, 1)`,
+		`INSERT INTO Release_Videos (releaseID, url, title, duration_s)
+		   VALUES (100, "https://www.youtube.com/watch?v=ZWmrfgj0MZI", "Unfinished Sympathy", 308)`,
+		`UPDATE ReleaseVersions SET year_released = 1991, country = "UK" WHERE versionID = 1178`,
+		`INSERT INTO ReleaseVersions (versionID, releaseID, title, year_released)
+		   VALUES (1179, 100, "Blue Lines (Remastered)", 2012)`,
+		`INSERT INTO ReleaseVersion_Artists (versionID, artistID, is_extra, ordering, artist_name, role, tracks)
+		   VALUES (1178, 10, FALSE, 1, NULL, NULL, NULL),
+		          (1178, 11, TRUE, 2, "Robert Del Naja", "Artwork", NULL),
+		          (1178, 11, TRUE, 3, NULL, "Vocals", "A1, B2")`,
+		`INSERT INTO ReleaseVersion_Labels (versionID, labelID, label_name, catalog_id)
+		   VALUES (1178, 5, "Wild Bunch Records", "WBRLP 1"), (1178, 6, "Circa", NULL)`,
+		`INSERT INTO ReleaseVersion_Formats (versionID, formatID, quantity, description)
+		   VALUES (1178, 1, 1, "LP, Album")`,
+		`INSERT INTO ReleaseVersion_Genres (versionID, genreID) VALUES (1178, 1)`,
+		`INSERT INTO ReleaseVersion_Identifiers (versionID, type, value, description)
+		   VALUES (1178, "Barcode", "5 012093 201129", "Text"), (1178, "Matrix / Runout", "WBRLP 1 A1", NULL)`,
+		`INSERT INTO ReleaseVersion_CoverArt (versionID, front_sleeve, back_sleeve) VALUES (1178, 7, 8)`,
+	} {
+		_, err := tx.Exec(statement)
+		require.NoError(t, err, statement)
+	}
+	for i := 1; i <= tracks; i++ {
+		_, err := tx.Exec(`INSERT INTO Tracks (trackID, versionID, track_number, title, duration)
+		  VALUES (?, 1178, ?, ?, "4:00")`, 5000+i, i, fmt.Sprintf("Track %d", i))
+		require.NoError(t, err)
+		_, err = tx.Exec(`INSERT INTO Track_Artists (trackID, artistID, is_extra, role)
+		  VALUES (?, 11, TRUE, "Written-By")`, 5000+i)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tx.Commit())
+}
+
+// Counts the queries made through it.
+type counting_db struct {
+	database.Queryer
+	queries int
+}
+
+func (db *counting_db) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	db.queries++
+	return db.Queryer.QueryContext(ctx, query, args...)
+}
+
+func (db *counting_db) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	db.queries++
+	return db.Queryer.QueryRowContext(ctx, query, args...)
+}
+
+func TestGetRelease(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	add_test_release(t, db, 2)
+
+	release, err := GetRelease(ctx, db, 100)
+	require.NoError(t, err)
+	assert.Equal(t, "Blue Lines", release.Title)
+	assert.Equal(t, uint64(1178), release.MainVersion)
+	assert.Equal(t, []schema.ArtistCredit{{ArtistID: 10, Name: "Massive Attack"}}, release.Artists)
+	assert.Equal(t, []string{"Electronic", "Hip Hop"}, release.Genres)
+	assert.Equal(t, []string{"Trip Hop"}, release.Styles)
+	assert.Equal(t, []schema.Video{{
+		URL:      "https://www.youtube.com/watch?v=ZWmrfgj0MZI",
+		Title:    "Unfinished Sympathy",
+		Duration: 308,
+	}}, release.Videos)
+	require.Len(t, release.Versions, 2)
+	assert.Equal(t, schema.ReleaseVersion{
+		ID: 1178, ReleaseID: 100, Title: "Blue Lines", Year: 1991, Country: "UK",
+	}, release.Versions[0])
+	assert.Equal(t, uint64(1179), release.Versions[1].ID)
+
+	_, err = GetRelease(ctx, db, 101)
+	assert.True(t, errors.Is(err, database.ErrNotFound), err)
+}
+
+func TestListReleases(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	for _, statement := range []string{
+		`INSERT INTO Releases (releaseID, title, year, main_version)
+		   VALUES (101, "Mezzanine", 1998, 1200), (102, "blue monday", 1983, 1201)`,
+		`INSERT INTO ReleaseVersions (versionID, releaseID, title)
+		   VALUES (1200, 101, "Mezzanine"), (1201, 102, "Blue Monday")`,
+		`INSERT INTO Release_Artists (releaseID, artistID) VALUES (101, 10)`,
+	} {
+		_, err := tx.Exec(statement)
+		require.NoError(t, err, statement)
+	}
+	require.NoError(t, tx.Commit())
+
+	by_title := pagination.Request{Sort: ReleaseSorts[0], Limit: 2}
+	releases, page, err := ListReleases(ctx, db, ReleaseQuery{Paging: by_title})
+	require.NoError(t, err)
+	assert.Equal(t, 3, page.Total)
+	require.Len(t, releases, 2)
+	assert.Equal(t, "Blue Lines", releases[0].Title)
+	assert.Equal(t, "blue monday", releases[1].Title)
+	assert.Equal(t, 1983, releases[1].Year)
+
+	next, err := pagination.ParseCursor(page.Next)
+	require.NoError(t, err)
+	by_title.Cursor = &next
+	releases, page, err = ListReleases(ctx, db, ReleaseQuery{Paging: by_title})
+	require.NoError(t, err)
+	require.Len(t, releases, 1)
+	assert.Equal(t, "Mezzanine", releases[0].Title)
+	assert.Empty(t, page.Next)
+
+	releases, page, err = ListReleases(ctx, db, ReleaseQuery{
+		TitlePrefix: "BLUE", Paging: pagination.First(ReleaseSorts[1].Reverse())})
+	require.NoError(t, err)
+	assert.Equal(t, 2, page.Total)
+	assert.Equal(t, "-year", page.Sort)
+	require.Len(t, releases, 2)
+	assert.Equal(t, "blue monday", releases[0].Title)
+
+	releases, _, err = ListReleases(ctx, db, ReleaseQuery{
+		ArtistID: 10, Paging: pagination.First(ReleaseSorts[2])})
+	require.NoError(t, err)
+	require.Len(t, releases, 2)
+	assert.Equal(t, uint64(100), releases[0].ID)
+	assert.Equal(t, uint64(101), releases[1].ID)
+}
+
+func TestGetVersion(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	add_test_release(t, db, 2)
+
+	version, err := GetVersion(ctx, db, 1178)
+	require.NoError(t, err)
+	assert.Equal(t, "Blue Lines", version.Title)
+	assert.Equal(t, []schema.ArtistCredit{{ArtistID: 10, Name: "Massive Attack"}}, version.Artists)
+	assert.Equal(t, []schema.ArtistCredit{
+		{ArtistID: 11, Name: "Robert Del Naja", Role: "Artwork"},
+		{ArtistID: 11, Name: "3D", Role: "Vocals", Tracks: "A1, B2"},
+	}, version.ExtraArtists)
+	assert.Equal(t, []schema.VersionLabel{
+		{LabelID: 5, Name: "Wild Bunch Records", CatalogID: "WBRLP 1"},
+		{LabelID: 6, Name: "Circa"},
+	}, version.Labels)
+	assert.Equal(t, []schema.MediaFormat{
+		{Quantity: 1, Format: schema.FormatVinyl, Description: "LP, Album"}}, version.Formats)
+	assert.Equal(t, []string{"Electronic"}, version.Genres)
+	assert.Equal(t, []string{"Trip Hop"}, version.Styles)
+	assert.Equal(t, []schema.VersionIdentifier{
+		{Type: "Barcode", Value: "5 012093 201129", Description: "Text"},
+		{Type: "Matrix / Runout", Value: "WBRLP 1 A1"},
+	}, version.Identifiers)
+
+	require.Len(t, version.Tracklist, 2)
+	assert.Equal(t, schema.Track{
+		ID: 5001, VersionID: 1178, Number: 1, Title: "Track 1", Duration: "4:00",
+		ExtraArtists: []schema.ArtistCredit{{ArtistID: 11, Name: "3D", Role: "Written-By"}},
+	}, version.Tracklist[0])
+
+	require.Len(t, version.Images, 2)
+	assert.Equal(t, schema.Image{
+		ID: 7, Type: "primary", Path: "covers/1178-front.jpg", Filetype: "jpeg", Width: 600, Height: 600,
+	}, version.Images[0])
+	assert.Equal(t, "secondary", version.Images[1].Type)
+
+	// A version without any details.
+	version, err = GetVersion(ctx, db, 1179)
+	require.NoError(t, err)
+	assert.Equal(t, 2012, version.Year)
+	assert.Empty(t, version.Tracklist)
+	assert.Empty(t, version.Images)
+
+	_, err = GetVersion(ctx, db, 1180)
+	assert.True(t, errors.Is(err, database.ErrNotFound), err)
+}
+
+func TestGetVersionQueryCount(t *testing.T) {
+	ctx := context.Background()
+	count_queries := func(tracks int) int {
+		db := open_test_db(t)
+		add_test_release(t, db, tracks)
+		counter := &counting_db{Queryer: db}
+		version, err := GetVersion(ctx, counter, 1178)
+		require.NoError(t, err)
+		require.Len(t, version.Tracklist, tracks)
+		return counter.queries
+	}
+	assert.Equal(t, count_queries(2), count_queries(30))
+}
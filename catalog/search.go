@@ -0,0 +1,317 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/catalog/search.go
+
+package catalog
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/kevindamm/cratedigdb/pagination"
+	database "github.com/kevindamm/cratedigdb/sql"
+)
+
+// The kinds of catalog entries a search may return.  Following the worker,
+// "release" is what Discogs calls a master and "version" is a Discogs release.
+type SearchType string
+
+const (
+	SearchAny     SearchType = ""
+	SearchRelease SearchType = "release"
+	SearchVersion SearchType = "version"
+	SearchArtist  SearchType = "artist"
+	SearchLabel   SearchType = "label"
+)
+
+func ParseSearchType(text string) (SearchType, error) {
+	switch SearchType(text) {
+	case SearchAny, SearchRelease, SearchVersion, SearchArtist, SearchLabel:
+		return SearchType(text), nil
+	}
+	return "", fmt.Errorf("unknown search type %q, expected release, version, artist or label", text)
+}
+
+// The search parameters of the worker's /search (the Discogs database search).
+// Text parameters match case-insensitively, names and titles anywhere within
+// them; genre, style, country and format match exactly.  Empty (or zero)
+// parameters do not filter.
+type SearchQuery struct {
+	// Matches titles, artist and label names, catalog numbers and barcodes.
+	Q    string
+	Type SearchType
+
+	// The title of a release or version, also as "Artist - Title", or the
+	// name of an artist or label.
+	Title   string
+	Artist  string
+	Release string
+	Track   string
+	// An artist in the version's (or its tracks') additional credits.
+	Credit  string
+	Label   string
+	Genre   string
+	Style   string
+	Country string
+	Year    int
+	// The format (e.g. "Vinyl") or a word of its description (e.g. "LP").
+	Format string
+	// Compared without spaces or dashes.
+	Barcode string
+
+	// In one of the SearchSorts orders.
+	Paging pagination.Request
+}
+
+// A release, version, artist or label matching the search.
+type SearchResult struct {
+	Type    SearchType `json:"type"`
+	ID      uint64     `json:"id"`
+	Title   string     `json:"title"`
+	Year    int        `json:"year,omitempty"`
+	Country string     `json:"country,omitempty"`
+	// The release of a version (or the release itself).
+	ReleaseID uint64 `json:"releaseID,omitempty"`
+}
+
+// Results are listed by title (the default) or year (zero if unknown), with
+// ties in type then ID order.
+var SearchSorts = pagination.Sorts{
+	{Name: "title", Columns: []pagination.Column{
+		{Expr: "title COLLATE NOCASE"}, {Expr: "type"}, {Expr: "id"}}},
+	{Name: "year", Columns: []pagination.Column{{Expr: "year"}, {Expr: "type"}, {Expr: "id"}}},
+}
+
+// Searches the local catalog, returning the requested page of results,
+// described along with the total number of results (on all pages).  Artists
+// and labels are only included when searching by q, title, artist (for
+// artists) or label (for labels).
+func Search(ctx context.Context, db database.Queryer, query SearchQuery) ([]SearchResult, pagination.Page, error) {
+	var parts []string
+	var args []any
+	add := func(search_type SearchType, part string, part_args []any, ok bool) {
+		if ok && (query.Type == SearchAny || query.Type == search_type) {
+			parts = append(parts, part)
+			args = append(args, part_args...)
+		}
+	}
+
+	condition, condition_args := version_conditions(query)
+	add(SearchVersion, `
+	  SELECT 'version' AS type, v.versionID AS id, v.title AS title,
+	    COALESCE(v.year_released, r.year, 0) AS year, COALESCE(v.country, '') AS country,
+	    v.releaseID AS releaseID
+	  FROM ReleaseVersions v JOIN Releases r USING (releaseID)
+	  WHERE v.versionID <> 0 AND `+condition, condition_args, true)
+	add(SearchRelease, `
+	  SELECT 'release', r.releaseID, r.title, COALESCE(r.year, 0), '', r.releaseID
+	  FROM Releases r
+	  WHERE r.releaseID <> 0 AND EXISTS (
+	    SELECT 1 FROM ReleaseVersions v
+	    WHERE v.releaseID = r.releaseID AND `+condition+`)`, condition_args, true)
+
+	catalog_only := query.Release == "" && query.Track == "" && query.Credit == "" &&
+		query.Genre == "" && query.Style == "" && query.Country == "" && query.Year == 0 &&
+		query.Format == "" && query.Barcode == ""
+	condition, condition_args = name_conditions(`a.name`, `EXISTS (
+	  SELECT 1 FROM Artist_Names WHERE artistID = a.artistID AND name LIKE ? ESCAPE '\')`,
+		query.Q, query.Title, query.Artist)
+	add(SearchArtist, `
+	  SELECT 'artist', a.artistID, a.name, 0, '', 0
+	  FROM Artists a
+	  WHERE a.artistID <> 0 AND `+condition, condition_args, catalog_only && query.Label == "")
+	condition, condition_args = name_conditions(`l.name`, ``, query.Q, query.Title, query.Label)
+	add(SearchLabel, `
+	  SELECT 'label', l.labelID, l.name, 0, '', 0
+	  FROM Labels l
+	  WHERE l.labelID <> 0 AND `+condition, condition_args, catalog_only && query.Artist == "")
+
+	if len(parts) == 0 {
+		return nil, pagination.Page{Sort: query.Paging.Sort.Name, Limit: query.Paging.Limit}, nil
+	}
+	results_cte := `results (type, id, title, year, country, releaseID) AS (` +
+		strings.Join(parts, " UNION ALL ") + `)`
+
+	var total int
+	err := db.QueryRowContext(ctx,
+		`WITH `+results_cte+` SELECT COUNT(*) FROM results`, args...,
+	).Scan(&total)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+
+	after, after_args := query.Paging.Where()
+	results, err := database.QueryList(ctx, db, func(rows *sql.Rows) (SearchResult, error) {
+		var result SearchResult
+		return result, rows.Scan(&result.Type, &result.ID, &result.Title, &result.Year,
+			&result.Country, &result.ReleaseID)
+	}, `WITH `+results_cte+`
+	  SELECT type, id, title, year, country, releaseID FROM results
+	  WHERE `+after+`
+	  ORDER BY `+query.Paging.OrderBy()+`
+	  LIMIT ?`,
+		append(append(args, after_args...), query.Paging.Fetch())...)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+	results, page := pagination.Paginate(query.Paging, results, total, func(result SearchResult) []any {
+		if strings.TrimPrefix(query.Paging.Sort.Name, "-") == "year" {
+			return []any{result.Year, string(result.Type), result.ID}
+		}
+		return []any{result.Title, string(result.Type), result.ID}
+	})
+	return results, page, nil
+}
+
+// The LIKE pattern matching text anywhere within a value.
+func contains(text string) string {
+	return "%" + like_escaper.Replace(text) + "%"
+}
+
+// Conditions for matching each of the (non-empty) texts within the name
+// column or, if not empty, the alternative condition (with one parameter).
+func name_conditions(column, alternative string, texts ...string) (string, []any) {
+	conditions := []string{`TRUE`}
+	var args []any
+	for _, text := range texts {
+		if text == "" {
+			continue
+		}
+		if alternative == "" {
+			conditions = append(conditions, column+` LIKE ? ESCAPE '\'`)
+			args = append(args, contains(text))
+		} else {
+			conditions = append(conditions, `(`+column+` LIKE ? ESCAPE '\' OR `+alternative+`)`)
+			args = append(args, contains(text), contains(text))
+		}
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// The credited name of an artist in ReleaseVersion_Artists (as c).
+const credit_name = `COALESCE(NULLIF(c.artist_name, ''), (
+	SELECT name FROM Artists WHERE artistID = c.artistID))`
+
+// Conditions on a version (as v, of the release r) for the query's filters.
+func version_conditions(query SearchQuery) (string, []any) {
+	conditions := []string{`TRUE`}
+	var args []any
+	add := func(condition string, arg any, count int) {
+		conditions = append(conditions, condition)
+		for range count {
+			args = append(args, arg)
+		}
+	}
+
+	const artist_match = `(EXISTS (
+	    SELECT 1 FROM ReleaseVersion_Artists c
+	    WHERE c.versionID = v.versionID AND NOT c.is_extra
+	      AND ` + credit_name + ` LIKE ?1 ESCAPE '\')
+	  OR EXISTS (
+	    SELECT 1 FROM Release_Artists ra JOIN Artists USING (artistID)
+	    WHERE ra.releaseID = v.releaseID
+	      AND COALESCE(NULLIF(ra.artist_name, ''), Artists.name) LIKE ?1 ESCAPE '\'))`
+	const title_match = `(v.title LIKE ?1 ESCAPE '\' OR r.title LIKE ?1 ESCAPE '\')`
+	const label_match = `EXISTS (
+	    SELECT 1 FROM ReleaseVersion_Labels vl JOIN Labels USING (labelID)
+	    WHERE vl.versionID = v.versionID
+	      AND (vl.label_name LIKE ?1 ESCAPE '\' OR Labels.name LIKE ?1 ESCAPE '\'))`
+	const barcode_value = `REPLACE(REPLACE(value, ' ', ''), '-', '')`
+
+	// Each pattern is repeated for every ?1 in its condition, as plain
+	// parameters (the pagination cursor's parameters follow them).
+	numbered := func(condition string) (string, int) {
+		return strings.ReplaceAll(condition, "?1", "?"), strings.Count(condition, "?1")
+	}
+	add_match := func(condition string, text string) {
+		if text != "" {
+			condition, count := numbered(condition)
+			add(condition, contains(text), count)
+		}
+	}
+
+	if query.Q != "" {
+		add_match(`(`+title_match+` OR `+artist_match+` OR `+label_match+`
+		  OR EXISTS (
+		    SELECT 1 FROM ReleaseVersion_Labels
+		    WHERE versionID = v.versionID AND catalog_id LIKE ?1 ESCAPE '\')
+		  OR EXISTS (
+		    SELECT 1 FROM ReleaseVersion_Identifiers
+		    WHERE versionID = v.versionID AND value LIKE ?1 ESCAPE '\'))`, query.Q)
+	}
+	add_match(`(`+title_match+` OR EXISTS (
+	    SELECT 1 FROM ReleaseVersion_Artists c
+	    WHERE c.versionID = v.versionID AND NOT c.is_extra
+	      AND `+credit_name+` || ' - ' || v.title LIKE ?1 ESCAPE '\'))`, query.Title)
+	add_match(title_match, query.Release)
+	add_match(artist_match, query.Artist)
+	add_match(`EXISTS (
+	    SELECT 1 FROM Tracks WHERE versionID = v.versionID AND title LIKE ?1 ESCAPE '\')`,
+		query.Track)
+	add_match(`(EXISTS (
+	    SELECT 1 FROM ReleaseVersion_Artists c
+	    WHERE c.versionID = v.versionID AND c.is_extra
+	      AND `+credit_name+` LIKE ?1 ESCAPE '\')
+	  OR EXISTS (
+	    SELECT 1 FROM Track_Artists ta
+	      JOIN Tracks USING (trackID) JOIN Artists USING (artistID)
+	    WHERE Tracks.versionID = v.versionID
+	      AND COALESCE(NULLIF(ta.name, ''), Artists.name) LIKE ?1 ESCAPE '\'))`, query.Credit)
+	add_match(label_match, query.Label)
+
+	if query.Genre != "" {
+		add(`(EXISTS (
+		    SELECT 1 FROM ReleaseVersion_Genres JOIN GenreEnum USING (genreID)
+		    WHERE versionID = v.versionID AND genre = ? COLLATE NOCASE)
+		  OR EXISTS (
+		    SELECT 1 FROM Release_Genres JOIN GenreEnum USING (genreID)
+		    WHERE releaseID = v.releaseID AND genre = ? COLLATE NOCASE))`, query.Genre, 2)
+	}
+	if query.Style != "" {
+		add(`EXISTS (
+		    SELECT 1 FROM Release_Styles JOIN StyleEnum USING (styleID)
+		    WHERE releaseID = v.releaseID AND style = ? COLLATE NOCASE)`, query.Style, 1)
+	}
+	if query.Country != "" {
+		add(`v.country = ? COLLATE NOCASE`, query.Country, 1)
+	}
+	if query.Year != 0 {
+		add(`COALESCE(v.year_released, r.year) = ?`, query.Year, 1)
+	}
+	if query.Format != "" {
+		add(`EXISTS (
+		    SELECT 1 FROM ReleaseVersion_Formats JOIN MediaFormatEnum USING (formatID)
+		    WHERE versionID = v.versionID
+		      AND (format = ? COLLATE NOCASE OR format_abbr = ? COLLATE NOCASE
+		        OR ', ' || description || ',' LIKE ? ESCAPE '\'))`, query.Format, 2)
+		args = append(args, "%, "+like_escaper.Replace(query.Format)+",%")
+	}
+	if query.Barcode != "" {
+		barcode := strings.NewReplacer(" ", "", "-", "").Replace(query.Barcode)
+		add(`EXISTS (
+		    SELECT 1 FROM ReleaseVersion_Identifiers
+		    WHERE versionID = v.versionID AND type = 'Barcode'
+		      AND `+barcode_value+` = ?)`, barcode, 1)
+	}
+	return strings.Join(conditions, " AND "), args
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/catalog/search_test.go
+
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/kevindamm/cratedigdb/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearch(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	add_test_release(t, db, 2)
+
+	search := func(query SearchQuery) []string {
+		query.Paging = pagination.First(SearchSorts[0])
+		results, page, err := Search(ctx, db, query)
+		require.NoError(t, err)
+		assert.Equal(t, len(results), page.Total)
+		found := make([]string, len(results))
+		for i, result := range results {
+			found[i] = fmt.Sprintf("%s %d", result.Type, result.ID)
+		}
+		return found
+	}
+
+	for _, test := range []struct {
+		query    SearchQuery
+		expected []string
+	}{
+		{SearchQuery{Q: "massive"}, []string{"release 100", "version 1178", "version 1179", "artist 10"}},
+		{SearchQuery{Q: "massive", Type: SearchArtist}, []string{"artist 10"}},
+		{SearchQuery{Q: "wbrlp"}, []string{"release 100", "version 1178"}},
+		{SearchQuery{Q: "circa"}, []string{"release 100", "version 1178", "label 6"}},
+		{SearchQuery{Title: "Massive Attack - Blue"}, []string{"release 100", "version 1178"}},
+		{SearchQuery{Release: "remastered"}, []string{"release 100", "version 1179"}},
+		{SearchQuery{Artist: "3d"}, []string{"artist 11"}},
+		{SearchQuery{Credit: "del naja", Type: SearchVersion}, []string{"version 1178"}},
+		{SearchQuery{Track: "track 2", Type: SearchVersion}, []string{"version 1178"}},
+		{SearchQuery{Label: "wild bunch", Type: SearchRelease}, []string{"release 100"}},
+		{SearchQuery{Genre: "hip hop", Type: SearchVersion}, []string{"version 1178", "version 1179"}},
+		{SearchQuery{Genre: "jazz"}, []string{}},
+		{SearchQuery{Style: "Trip Hop", Type: SearchVersion}, []string{"version 1178", "version 1179"}},
+		{SearchQuery{Country: "uk"}, []string{"release 100", "version 1178"}},
+		{SearchQuery{Year: 2012}, []string{"release 100", "version 1179"}},
+		{SearchQuery{Format: "LP", Type: SearchVersion}, []string{"version 1178"}},
+		{SearchQuery{Format: "vinyl", Type: SearchVersion}, []string{"version 1178"}},
+		{SearchQuery{Format: "CD"}, []string{}},
+		{SearchQuery{Barcode: "5012093-201129"}, []string{"release 100", "version 1178"}},
+		{SearchQuery{Artist: "massive", Label: "circa"}, []string{"release 100", "version 1178"}},
+		{SearchQuery{Q: "100%"}, []string{}},
+	} {
+		assert.Equal(t, test.expected, search(test.query), "%+v", test.query)
+	}
+
+	results, page, err := Search(ctx, db, SearchQuery{
+		Q: "blue", Paging: pagination.Request{Sort: SearchSorts[1].Reverse(), Limit: 2}})
+	require.NoError(t, err)
+	assert.Equal(t, 3, page.Total)
+	require.Len(t, results, 2)
+	assert.Equal(t, SearchResult{Type: SearchVersion, ID: 1179, Title: "Blue Lines (Remastered)",
+		Year: 2012, ReleaseID: 100}, results[0])
+	assert.Equal(t, SearchVersion, results[1].Type)
+	next, err := pagination.ParseCursor(page.Next)
+	require.NoError(t, err)
+	results, _, err = Search(ctx, db, SearchQuery{
+		Q: "blue", Paging: pagination.Request{Sort: SearchSorts[1].Reverse(), Limit: 2, Cursor: &next}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, SearchResult{Type: SearchRelease, ID: 100, Title: "Blue Lines", ReleaseID: 100}, results[0])
+
+	_, err = ParseSearchType("master")
+	assert.Error(t, err)
+}
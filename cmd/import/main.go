@@ -0,0 +1,123 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/cmd/import/main.go
+
+// Imports copies into a user's collection from CSV or JSON lines files (see
+// collection.ReadImport for their columns), printing what became of each row.
+// By default a file is imported all or nothing; with -mode best-effort, the
+// rows which can be added are, and the others are reported.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kevindamm/cratedigdb/collection"
+	database "github.com/kevindamm/cratedigdb/sql"
+)
+
+func main() {
+	db_path := flag.String("db", "cratedig.db",
+		"path to the sqlite database (created if it does not exist)")
+	username := flag.String("user", "", "the username whose collection to import into")
+	mode_flag := flag.String("mode", "all", "all (or nothing) or best-effort")
+	format_flag := flag.String("format", "",
+		"csv or jsonl (by default, from the file's extension)")
+	flag.Parse()
+	if flag.NArg() == 0 || *username == "" {
+		log.Fatal("usage: import -user username [-db cratedig.db] [-mode best-effort] collection.csv ...")
+	}
+	mode, err := collection.ParseImportMode(*mode_flag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+	db, err := database.Open(ctx, *db_path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+	userID, err := collection.UserID(ctx, db, *username)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var items collection.ItemAllocator
+	failed := false
+	for _, path := range flag.Args() {
+		format := collection.ImportFormat(*format_flag)
+		if format == "" {
+			format = format_of(path)
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		rows, err := collection.ReadImport(file, format)
+		file.Close()
+		if err != nil {
+			log.Fatalf("%s: %v", path, err)
+		}
+
+		report, err := collection.ImportVinyl(ctx, db, &items, userID, rows, mode)
+		if err != nil {
+			log.Fatalf("%s: %v", path, err)
+		}
+		for _, row := range report.Rows {
+			switch {
+			case row.Vinyl != nil:
+				fmt.Printf("%s:%d: %s %s\n", path, row.Line, row.Status, row.Vinyl)
+			case len(row.Errors) > 0:
+				problems := make([]string, len(row.Errors))
+				for i, problem := range row.Errors {
+					problems[i] = strings.TrimPrefix(problem.Error(), ": ")
+				}
+				fmt.Printf("%s:%d: %s: %s\n", path, row.Line, row.Status, strings.Join(problems, "; "))
+			default:
+				fmt.Printf("%s:%d: %s\n", path, row.Line, row.Status)
+			}
+		}
+		if report.Committed {
+			fmt.Printf("imported %d copies from %s (%d rows failed)\n", report.Added, path, report.Failed)
+		} else {
+			fmt.Printf("imported nothing from %s, %d rows failed\n", path, report.Failed)
+		}
+		failed = failed || report.Failed > 0
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// JSON lines for .jsonl, .ndjson and .json files, otherwise CSV.
+func format_of(path string) collection.ImportFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson", ".json":
+		return collection.ImportJSONLines
+	}
+	return collection.ImportCSV
+}
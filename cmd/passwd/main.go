@@ -0,0 +1,91 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/cmd/passwd/main.go
+// github:kevindamm/cratedigdb/cmd/passwd/main.go
+
+// Sets the password of a user (read from the first line of stdin), with which
+// they may log in to the server.  With -create, the account is added first.
+// With -curator, the user is made a curator of the catalog (or, if false, no
+// longer one) and the password is left as it was.
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/kevindamm/cratedigdb/accounts"
+	"github.com/kevindamm/cratedigdb/collection"
+	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
+)
+
+func main() {
+	db_path := flag.String("db", "cratedig.db",
+		"path to the sqlite database (created if it does not exist)")
+	username := flag.String("user", "", "the username whose password to set")
+	create := flag.Bool("create", false, "create the account if it does not exist")
+	curator := flag.Bool("curator", false, "whether the user may edit the catalog")
+	flag.Parse()
+	if *username == "" {
+		log.Fatal("usage: passwd -user username [-db cratedig.db] [-create] [-curator=true|false] < password.txt")
+	}
+	set_curator := false
+	flag.Visit(func(set *flag.Flag) { set_curator = set_curator || set.Name == "curator" })
+
+	ctx := context.Background()
+	db, err := database.Open(ctx, *db_path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	userID, err := collection.UserID(ctx, db, *username)
+	if err != nil && *create {
+		var account schema.UserAccount
+		account, err = accounts.CreateAccount(ctx, db, schema.UserAccount{Username: *username})
+		userID = account.ID
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if set_curator {
+		if err := accounts.SetCurator(ctx, db, userID, *curator); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Fprintf(os.Stderr, "%s is a curator: %v\n", *username, *curator)
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "password for %s: ", *username)
+	password, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && password == "" {
+		log.Fatal(err)
+	}
+	if err := accounts.SetPassword(ctx, db, userID, strings.TrimRight(password, "\r\n")); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Fprintf(os.Stderr, "\nset the password for %s\n", *username)
+}
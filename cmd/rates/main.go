@@ -0,0 +1,60 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/cmd/rates/main.go
+
+// Imports the European Central Bank's euro reference rates from a local file
+// (eurofxref-hist.csv or eurofxref-hist.xml, as downloaded from the ECB) into
+// the ExchangeRates table, for converting ledger amounts between currencies.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"github.com/kevindamm/cratedigdb/ledger"
+	database "github.com/kevindamm/cratedigdb/sql"
+)
+
+func main() {
+	db_path := flag.String("db", "cratedig.db",
+		"path to the sqlite database (created if it does not exist)")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		log.Fatal("usage: rates [-db cratedig.db] eurofxref-hist.csv ...")
+	}
+
+	ctx := context.Background()
+	db, err := database.Open(ctx, *db_path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, path := range flag.Args() {
+		count, err := ledger.ImportRatesFile(ctx, db, path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("imported %d rates from %s\n", count, path)
+	}
+}
@@ -34,6 +34,7 @@ import (
 	"time"
 
 	service "github.com/kevindamm/cratedigdb/echo"
+	database "github.com/kevindamm/cratedigdb/sql"
 )
 
 func main() {
@@ -42,6 +43,8 @@ func main() {
 	cert_path := flag.String("cert_path", "",
 		"path where server.crt and server.key can be found (for this environment)")
 	debug := flag.Bool("debug", false, "enable debug mode and debug logging")
+	db_path := flag.String("db", "cratedig.db",
+		"path to the sqlite database (created if it does not exist)")
 	flag.Parse()
 
 	if *port == 0 {
@@ -51,7 +54,14 @@ func main() {
 		}
 	}
 
-	server := service.NewInMemoryHandler(*port, *debug)
+	// Opening the database also checks its base data against the schema enums.
+	db, err := database.Open(context.Background(), *db_path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	server := service.NewHandler(db, *port, *debug)
 	server.RegisterAPIRoutes()
 	// TODO other routes
 
@@ -60,7 +70,6 @@ func main() {
 	// Run graceful shutdown in a separate goroutine that exits after timeout.
 	go graceful_shutdown(server.Server, done, 5)
 
-	var err error
 	if len(*cert_path) > 0 {
 		crt_path := path.Join(*cert_path, "server.crt")
 		key_path := path.Join(*cert_path, "server.key")
@@ -0,0 +1,389 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/collection/crates.go
+
+package collection
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kevindamm/cratedigdb/pagination"
+	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
+)
+
+// Returned (wrapped) when moving a crate into itself or one of its subcrates.
+var ErrCrateCycle = errors.New("a crate cannot be inside itself")
+
+// Crates may be nested at most this deep; it also bounds the recursive queries.
+const MaxCrateDepth = 16
+
+// The user's crates with their slug paths, as a recursive CTE named
+// crate_paths with columns (crateID, path, depth, shown).  A crate is shown
+// (to users other than its owner) if it and all of its parents are visible.
+// The userID is parameter ?1.
+var crate_paths_cte = fmt.Sprintf(`
+  crate_paths (crateID, path, depth, shown) AS (
+    SELECT crateID, slug, 1, visible <> 0 FROM Crates
+    WHERE userID = ?1 AND parentID IS NULL
+    UNION ALL
+    SELECT Crates.crateID, crate_paths.path || '/' || Crates.slug, depth + 1,
+      crate_paths.shown AND Crates.visible <> 0
+    FROM Crates JOIN crate_paths ON Crates.parentID = crate_paths.crateID
+    WHERE depth < %d
+  )`, MaxCrateDepth)
+
+// The condition on crate_paths for whether hidden crates are included.
+func shown_condition(hidden bool) string {
+	if hidden {
+		return `TRUE`
+	}
+	return `crate_paths.shown`
+}
+
+// The crate (parameter ?2) and all crates nested within it, as a recursive CTE
+// named subcrates with the column crateID.
+const subcrates_cte = `
+  subcrates (crateID) AS (
+    SELECT ?2
+    UNION
+    SELECT Crates.crateID FROM Crates JOIN subcrates ON parentID = subcrates.crateID
+  )`
+
+const crate_columns = `Crates.crateID, Crates.userID, COALESCE(parentID, 0), name, slug,
+	crate_paths.path, username, visible, notes`
+
+func scan_crate(rows *sql.Rows) (schema.Crate, error) {
+	var crate schema.Crate
+	return crate, rows.Scan(&crate.ID, &crate.UserID, &crate.ParentID, &crate.Name, &crate.Slug,
+		&crate.Path, &crate.Username, &crate.Visible, &crate.Notes)
+}
+
+// Queries the user's crates, the condition may refer to the Crates columns and
+// crate_paths.path (arguments are numbered from ?2).
+func query_crates(ctx context.Context, db database.Queryer, userID uint64, condition string, args ...any) ([]schema.Crate, error) {
+	return database.QueryList(ctx, db, scan_crate, `WITH RECURSIVE `+crate_paths_cte+`
+	  SELECT `+crate_columns+`
+	  FROM Crates
+	    JOIN crate_paths USING (crateID)
+	    JOIN UserAccounts USING (userID)
+	  WHERE `+condition+`
+	  ORDER BY crate_paths.path`, append([]any{userID}, args...)...)
+}
+
+// Crates are listed by path (the default, so that each crate follows its
+// parent) or by crateID.
+var CrateSorts = pagination.Sorts{
+	{Name: "path", Columns: []pagination.Column{{Expr: "crate_paths.path"}}},
+	{Name: "id", Columns: []pagination.Column{{Expr: "Crates.crateID"}}},
+}
+
+// A page of the user's crates, described along with the total number of the
+// user's crates.  The special 'ALL' crate (crateID 0) is implicit and not
+// included.  Hidden crates (those which are not visible, and the crates within
+// them) are only included if hidden is true, i.e. for the user themselves.
+func ListCrates(ctx context.Context, db database.Queryer, userID uint64, hidden bool, paging pagination.Request) ([]schema.Crate, pagination.Page, error) {
+	var total int
+	err := db.QueryRowContext(ctx, `WITH RECURSIVE `+crate_paths_cte+`
+	  SELECT COUNT(*) FROM crate_paths WHERE `+shown_condition(hidden), userID,
+	).Scan(&total)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+
+	// The cursor's plain ? parameters are numbered after ?1 (the userID).
+	after, args := paging.Where()
+	crates, err := database.QueryList(ctx, db, scan_crate, `WITH RECURSIVE `+crate_paths_cte+`
+	  SELECT `+crate_columns+`
+	  FROM Crates
+	    JOIN crate_paths USING (crateID)
+	    JOIN UserAccounts USING (userID)
+	  WHERE `+shown_condition(hidden)+` AND `+after+`
+	  ORDER BY `+paging.OrderBy()+`
+	  LIMIT ?`, append(append([]any{userID}, args...), paging.Fetch())...)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+	crates, page := pagination.Paginate(paging, crates, total, func(crate schema.Crate) []any {
+		if strings.TrimPrefix(paging.Sort.Name, "-") == "id" {
+			return []any{crate.ID}
+		}
+		return []any{crate.Path}
+	})
+	return crates, page, nil
+}
+
+func GetCrate(ctx context.Context, db database.Queryer, userID, crateID uint64) (schema.Crate, error) {
+	crates, err := query_crates(ctx, db, userID, `crateID = ?2`, crateID)
+	if err != nil {
+		return schema.Crate{}, err
+	}
+	if len(crates) == 0 {
+		return schema.Crate{}, fmt.Errorf("crate %d: %w", crateID, database.ErrNotFound)
+	}
+	return crates[0], nil
+}
+
+// Finds the crate by its slash-separated slug path, e.g. "house/deep".  Hidden
+// crates are not found unless hidden is true (see ListCrates).
+func ResolveCratePath(ctx context.Context, db database.Queryer, userID uint64, path string, hidden bool) (schema.Crate, error) {
+	path = strings.Trim(path, "/")
+	crates, err := query_crates(ctx, db, userID,
+		`crate_paths.path = ?2 AND `+shown_condition(hidden), path)
+	if err != nil {
+		return schema.Crate{}, err
+	}
+	if len(crates) == 0 {
+		return schema.Crate{}, fmt.Errorf("crate %q: %w", path, database.ErrNotFound)
+	}
+	return crates[0], nil
+}
+
+// Creates the crate, returning it with its crateID and path.  The slug is
+// derived from the name if empty.  A zero ParentID creates a top-level crate.
+func CreateCrate(ctx context.Context, db database.Queryer, crate schema.Crate) (schema.Crate, error) {
+	crate.ID = 0
+	if crate.Slug == "" {
+		crate.Slug = schema.Slugify(crate.Name)
+	}
+	if err := validate_crate(crate); err != nil {
+		return schema.Crate{}, err
+	}
+
+	var created schema.Crate
+	err := database.InTx(ctx, db, func(tx *sql.Tx) error {
+		if err := check_parent(ctx, tx, crate); err != nil {
+			return err
+		}
+		err := tx.QueryRowContext(ctx, `
+		  INSERT INTO Crates (userID, parentID, name, slug, visible, notes)
+		  VALUES (?, ?, ?, ?, ?, ?)
+		  RETURNING crateID`,
+			crate.UserID, nullable_crate(crate.ParentID), crate.Name, crate.Slug,
+			crate.Visible, crate.Notes,
+		).Scan(&crate.ID)
+		if err != nil {
+			return err
+		}
+		if err := check_depth(ctx, tx, crate.UserID, crate.ID); err != nil {
+			return err
+		}
+		created, err = GetCrate(ctx, tx, crate.UserID, crate.ID)
+		return err
+	})
+	if err != nil {
+		return schema.Crate{}, fmt.Errorf("creating crate %q: %w", crate.Name, err)
+	}
+	return created, nil
+}
+
+// Changes to a crate; nil fields are left unchanged.  Renaming does not change
+// the slug unless it is also given.  A ParentID of zero moves the crate to the
+// top level.
+type CrateUpdate struct {
+	Name     *string `json:"name,omitempty"`
+	Slug     *string `json:"slug,omitempty"`
+	ParentID *uint64 `json:"parentID,omitempty"`
+	Visible  *bool   `json:"visible,omitempty"`
+	Notes    *string `json:"notes,omitempty"`
+}
+
+// Renames, moves or otherwise updates the crate, returning it as updated (with
+// its new path).  Moving a crate into itself or any of its subcrates fails with
+// an error wrapping ErrCrateCycle.
+func UpdateCrate(ctx context.Context, db *sql.DB, userID, crateID uint64, update CrateUpdate) (schema.Crate, error) {
+	var updated schema.Crate
+	err := database.InTx(ctx, db, func(tx *sql.Tx) error {
+		crate, err := GetCrate(ctx, tx, userID, crateID)
+		if err != nil {
+			return err
+		}
+		if update.Name != nil {
+			crate.Name = *update.Name
+		}
+		if update.Slug != nil {
+			crate.Slug = *update.Slug
+		}
+		if update.Visible != nil {
+			crate.Visible = *update.Visible
+		}
+		if update.Notes != nil {
+			crate.Notes = *update.Notes
+		}
+		if update.ParentID != nil {
+			crate.ParentID = *update.ParentID
+			var cycle bool
+			err := tx.QueryRowContext(ctx, `WITH RECURSIVE `+subcrates_cte+`
+			  SELECT EXISTS (SELECT 1 FROM subcrates WHERE crateID = ?3)`,
+				userID, crateID, crate.ParentID,
+			).Scan(&cycle)
+			if err != nil {
+				return err
+			}
+			if cycle {
+				return fmt.Errorf("moving crate %d into %d: %w", crateID, crate.ParentID, ErrCrateCycle)
+			}
+		}
+		if err := validate_crate(crate); err != nil {
+			return err
+		}
+		if err := check_parent(ctx, tx, crate); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+		  UPDATE Crates SET parentID = ?, name = ?, slug = ?, visible = ?, notes = ?
+		  WHERE crateID = ?`,
+			nullable_crate(crate.ParentID), crate.Name, crate.Slug, crate.Visible, crate.Notes,
+			crateID)
+		if err != nil {
+			return err
+		}
+		if err := check_depth(ctx, tx, userID, crateID); err != nil {
+			return err
+		}
+		updated, err = GetCrate(ctx, tx, userID, crateID)
+		return err
+	})
+	if err != nil {
+		return schema.Crate{}, fmt.Errorf("updating crate %d: %w", crateID, err)
+	}
+	return updated, nil
+}
+
+// Deletes the crate along with its subcrates.  The items in them are kept in
+// the collection, but become unsorted.
+func DeleteCrate(ctx context.Context, db database.Queryer, userID, crateID uint64) error {
+	result, err := db.ExecContext(ctx,
+		`DELETE FROM Crates WHERE userID = ? AND crateID = ?`, userID, crateID)
+	if err != nil {
+		return fmt.Errorf("deleting crate %d: %w", crateID, database.Classify(err))
+	}
+	if count, err := result.RowsAffected(); err != nil {
+		return err
+	} else if count == 0 {
+		return fmt.Errorf("crate %d: %w", crateID, database.ErrNotFound)
+	}
+	return nil
+}
+
+// Moves the user's copies into the crate, or out of any crate (unsorted) if the
+// crateID is zero.  Either all of them are moved or none are, e.g. if any is
+// not in the user's collection or has been archived.
+func MoveVinyl(ctx context.Context, db database.Queryer, userID, crateID uint64, keys []schema.VinylKey) error {
+	return database.InTx(ctx, db, func(tx *sql.Tx) error {
+		if crateID != 0 {
+			if _, err := GetCrate(ctx, tx, userID, crateID); err != nil {
+				return err
+			}
+		}
+		for _, key := range keys {
+			if key.UserID != userID {
+				return fmt.Errorf("vinyl %s: %w", key, database.ErrNotFound)
+			}
+			var archived bool
+			err := tx.QueryRowContext(ctx, `
+			  UPDATE VinylItems SET crateID = ?
+			  WHERE userID = ? AND versionID = ? AND item = ?
+			  RETURNING date_archived IS NOT NULL`,
+				nullable_crate(crateID), key.UserID, key.VersionID, key.Item,
+			).Scan(&archived)
+			if err != nil {
+				return fmt.Errorf("vinyl %s: %w", key, database.Classify(err))
+			}
+			if archived {
+				return fmt.Errorf("vinyl %s: %w", key, ErrArchived)
+			}
+		}
+		return nil
+	})
+}
+
+func validate_crate(crate schema.Crate) error {
+	if err := crate.Validate(); err != nil {
+		return err
+	}
+	if crate.Slug == "" {
+		return &schema.ValidationError{Typename: crate.Typename(), Fields: []schema.FieldError{
+			{Field: "slug", Message: "is required when the name has no letters or digits"}}}
+	}
+	return nil
+}
+
+// The parent must be one of the user's crates, and no sibling may have the
+// same name or slug (so that the crate's path is unique).
+func check_parent(ctx context.Context, tx *sql.Tx, crate schema.Crate) error {
+	if crate.ParentID != 0 {
+		var owner uint64
+		err := tx.QueryRowContext(ctx,
+			`SELECT userID FROM Crates WHERE crateID = ?`, crate.ParentID).Scan(&owner)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && owner != crate.UserID) {
+			return fmt.Errorf("parent crate %d: %w", crate.ParentID, database.ErrNotFound)
+		} else if err != nil {
+			return err
+		}
+	}
+
+	var siblings int
+	err := tx.QueryRowContext(ctx, `
+	  SELECT COUNT(*) FROM Crates
+	  WHERE userID = ? AND COALESCE(parentID, 0) = ? AND crateID <> ?
+	    AND (name = ? OR slug = ?)`,
+		crate.UserID, crate.ParentID, crate.ID, crate.Name, crate.Slug,
+	).Scan(&siblings)
+	if err != nil {
+		return err
+	}
+	if siblings > 0 {
+		return fmt.Errorf("crate %q (%s): %w", crate.Name, crate.Slug, database.ErrDuplicate)
+	}
+	return nil
+}
+
+// Fails if the crate or any of its subcrates is nested deeper than
+// MaxCrateDepth (i.e. no longer appears among the user's crate paths).
+func check_depth(ctx context.Context, tx *sql.Tx, userID, crateID uint64) error {
+	var unreachable bool
+	err := tx.QueryRowContext(ctx, `WITH RECURSIVE `+subcrates_cte+`, `+crate_paths_cte+`
+	  SELECT EXISTS (SELECT 1 FROM subcrates
+	                 WHERE crateID NOT IN (SELECT crateID FROM crate_paths))`,
+		userID, crateID).Scan(&unreachable)
+	if err != nil {
+		return err
+	}
+	if unreachable {
+		return &schema.ValidationError{Typename: "crate", Fields: []schema.FieldError{
+			{Field: "parentID", Message: fmt.Sprintf("crates may be nested at most %d deep", MaxCrateDepth)}}}
+	}
+	return nil
+}
+
+// Crate 0 (the special 'ALL' crate) is stored as NULL.
+func nullable_crate(crateID uint64) any {
+	if crateID == 0 {
+		return nil
+	}
+	return crateID
+}
@@ -0,0 +1,275 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/collection/crates_test.go
+
+package collection
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/kevindamm/cratedigdb/pagination"
+	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Creates the crates house, house/deep and techno for kevin, returning them.
+// Only house is visible.
+func add_test_crates(t *testing.T, db *sql.DB) (house, deep, techno schema.Crate) {
+	ctx := context.Background()
+	var err error
+	house, err = CreateCrate(ctx, db, schema.Crate{UserID: 42, Name: "House", Visible: true})
+	require.NoError(t, err)
+	deep, err = CreateCrate(ctx, db, schema.Crate{UserID: 42, ParentID: house.ID, Name: "Deep"})
+	require.NoError(t, err)
+	techno, err = CreateCrate(ctx, db, schema.Crate{UserID: 42, Name: "Techno", Slug: "techno"})
+	require.NoError(t, err)
+	return
+}
+
+func TestCreateCrate(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	house, deep, _ := add_test_crates(t, db)
+
+	assert.Equal(t, schema.Crate{
+		ID: house.ID, UserID: 42, Name: "House", Slug: "house", Path: "house",
+		Username: "kevin", Visible: true,
+	}, house)
+	assert.Equal(t, "house/deep", deep.Path)
+	assert.Equal(t, house.ID, deep.ParentID)
+
+	var invalid *schema.ValidationError
+	_, err := CreateCrate(ctx, db, schema.Crate{UserID: 42, Name: "???"})
+	assert.True(t, errors.As(err, &invalid), err)
+	_, err = CreateCrate(ctx, db, schema.Crate{UserID: 42, Name: "house"})
+	assert.True(t, errors.Is(err, database.ErrDuplicate), "same slug: %v", err)
+	_, err = CreateCrate(ctx, db, schema.Crate{UserID: 42, Name: "Deep", ParentID: house.ID})
+	assert.True(t, errors.Is(err, database.ErrDuplicate), err)
+	_, err = CreateCrate(ctx, db, schema.Crate{UserID: 43, Name: "Mine", ParentID: house.ID})
+	assert.True(t, errors.Is(err, database.ErrNotFound), "another user's parent: %v", err)
+
+	// The same names are fine in another parent, or for another user.
+	_, err = CreateCrate(ctx, db, schema.Crate{UserID: 42, Name: "Deep"})
+	assert.NoError(t, err)
+	_, err = CreateCrate(ctx, db, schema.Crate{UserID: 43, Name: "House"})
+	assert.NoError(t, err)
+
+	crate_paths := func(crates []schema.Crate) []string {
+		paths := make([]string, len(crates))
+		for i, crate := range crates {
+			paths[i] = crate.Path
+		}
+		return paths
+	}
+	crates, page, err := ListCrates(ctx, db, 42, true, pagination.First(CrateSorts[0]))
+	require.NoError(t, err)
+	assert.Equal(t, 4, page.Total)
+	assert.Equal(t, []string{"deep", "house", "house/deep", "techno"}, crate_paths(crates))
+
+	// The cursor's parameters follow the numbered userID parameter.
+	by_path := pagination.Request{Sort: CrateSorts[0].Reverse(), Limit: 3}
+	crates, page, err = ListCrates(ctx, db, 42, true, by_path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"techno", "house/deep", "house"}, crate_paths(crates))
+	next, err := pagination.ParseCursor(page.Next)
+	require.NoError(t, err)
+	by_path.Cursor = &next
+	crates, page, err = ListCrates(ctx, db, 42, true, by_path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"deep"}, crate_paths(crates))
+	assert.Empty(t, page.Next)
+}
+
+func TestResolveCratePath(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	_, deep, _ := add_test_crates(t, db)
+
+	crate, err := ResolveCratePath(ctx, db, 42, "/house/deep/", true)
+	require.NoError(t, err)
+	assert.Equal(t, deep, crate)
+
+	_, err = ResolveCratePath(ctx, db, 42, "deep", true)
+	assert.True(t, errors.Is(err, database.ErrNotFound), err)
+	_, err = ResolveCratePath(ctx, db, 43, "house/deep", true)
+	assert.True(t, errors.Is(err, database.ErrNotFound), err)
+}
+
+func TestHiddenCrates(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	house, _, _ := add_test_crates(t, db)
+	shown, err := CreateCrate(ctx, db, schema.Crate{UserID: 42, ParentID: house.ID, Name: "Disco", Visible: true})
+	require.NoError(t, err)
+	hidden, err := CreateCrate(ctx, db, schema.Crate{UserID: 42, Name: "Gifts"})
+	require.NoError(t, err)
+	_, err = CreateCrate(ctx, db, schema.Crate{UserID: 42, ParentID: hidden.ID, Name: "Shared", Visible: true})
+	require.NoError(t, err)
+
+	paths := func(hidden bool) []string {
+		crates, page, err := ListCrates(ctx, db, 42, hidden, pagination.First(CrateSorts[0]))
+		require.NoError(t, err)
+		paths := make([]string, len(crates))
+		for i, crate := range crates {
+			paths[i] = crate.Path
+		}
+		assert.Equal(t, len(crates), page.Total)
+		return paths
+	}
+	assert.Equal(t, []string{"gifts", "gifts/shared", "house", "house/deep", "house/disco", "techno"}, paths(true))
+	assert.Equal(t, []string{"house", "house/disco"}, paths(false),
+		"a visible crate inside a hidden one is hidden too")
+
+	crate, err := ResolveCratePath(ctx, db, 42, "house/disco", false)
+	require.NoError(t, err)
+	assert.Equal(t, shown, crate)
+	for _, path := range []string{"house/deep", "techno", "gifts/shared"} {
+		_, err = ResolveCratePath(ctx, db, 42, path, false)
+		assert.ErrorIs(t, err, database.ErrNotFound, path)
+		_, err = ResolveCratePath(ctx, db, 42, path, true)
+		assert.NoError(t, err, path)
+	}
+}
+
+func TestUpdateCrate(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	house, deep, techno := add_test_crates(t, db)
+
+	// Renaming keeps the slug unless it is also changed.
+	name := "House Music"
+	crate, err := UpdateCrate(ctx, db, 42, house.ID, CrateUpdate{Name: &name})
+	require.NoError(t, err)
+	assert.Equal(t, "House Music", crate.Name)
+	assert.Equal(t, "house", crate.Slug)
+	slug := "house-music"
+	_, err = UpdateCrate(ctx, db, 42, house.ID, CrateUpdate{Slug: &slug})
+	require.NoError(t, err)
+	crate, err = GetCrate(ctx, db, 42, deep.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "house-music/deep", crate.Path)
+
+	// Moving a crate moves its subcrates along with it.
+	crate, err = UpdateCrate(ctx, db, 42, house.ID, CrateUpdate{ParentID: &techno.ID})
+	require.NoError(t, err)
+	assert.Equal(t, "techno/house-music", crate.Path)
+	_, err = ResolveCratePath(ctx, db, 42, "techno/house-music/deep", true)
+	assert.NoError(t, err)
+
+	top := uint64(0)
+	crate, err = UpdateCrate(ctx, db, 42, house.ID, CrateUpdate{ParentID: &top})
+	require.NoError(t, err)
+	assert.Equal(t, "house-music", crate.Path)
+
+	_, err = UpdateCrate(ctx, db, 42, house.ID, CrateUpdate{ParentID: &house.ID})
+	assert.True(t, errors.Is(err, ErrCrateCycle), err)
+	_, err = UpdateCrate(ctx, db, 42, house.ID, CrateUpdate{ParentID: &deep.ID})
+	assert.True(t, errors.Is(err, ErrCrateCycle), err)
+
+	taken := "techno"
+	_, err = UpdateCrate(ctx, db, 42, house.ID, CrateUpdate{Slug: &taken})
+	assert.True(t, errors.Is(err, database.ErrDuplicate), err)
+	_, err = UpdateCrate(ctx, db, 43, house.ID, CrateUpdate{Name: &name})
+	assert.True(t, errors.Is(err, database.ErrNotFound), err)
+}
+
+func TestCrateDepth(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+
+	var parent schema.Crate
+	for depth := 1; depth <= MaxCrateDepth; depth++ {
+		crate, err := CreateCrate(ctx, db, schema.Crate{UserID: 42, ParentID: parent.ID, Name: "Nested"})
+		require.NoError(t, err, depth)
+		parent = crate
+	}
+	var invalid *schema.ValidationError
+	_, err := CreateCrate(ctx, db, schema.Crate{UserID: 42, ParentID: parent.ID, Name: "Nested"})
+	assert.True(t, errors.As(err, &invalid), err)
+
+	other, err := CreateCrate(ctx, db, schema.Crate{UserID: 42, Name: "Other"})
+	require.NoError(t, err)
+	_, err = UpdateCrate(ctx, db, 42, other.ID, CrateUpdate{ParentID: &parent.ID})
+	assert.True(t, errors.As(err, &invalid), err)
+}
+
+func TestDeleteCrateUnsortsItems(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	house, deep, techno := add_test_crates(t, db)
+	add_test_vinyl(t, db)
+
+	first := schema.VinylKey{UserID: 42, VersionID: 1178, Item: 1}
+	second := schema.VinylKey{UserID: 42, VersionID: 1178, Item: 2}
+	require.NoError(t, MoveVinyl(ctx, db, 42, deep.ID, []schema.VinylKey{first}))
+	require.NoError(t, MoveVinyl(ctx, db, 42, techno.ID, []schema.VinylKey{second}))
+
+	require.NoError(t, DeleteCrate(ctx, db, 42, house.ID))
+	_, err := GetCrate(ctx, db, 42, deep.ID)
+	assert.True(t, errors.Is(err, database.ErrNotFound), "subcrates are deleted: %v", err)
+	vinyl, err := GetVinyl(ctx, db, first)
+	require.NoError(t, err)
+	assert.Zero(t, vinyl.CrateID, "unsorted")
+	vinyl, err = GetVinyl(ctx, db, second)
+	require.NoError(t, err)
+	assert.Equal(t, techno.ID, vinyl.CrateID)
+
+	err = DeleteCrate(ctx, db, 42, house.ID)
+	assert.True(t, errors.Is(err, database.ErrNotFound), err)
+	err = DeleteCrate(ctx, db, 43, techno.ID)
+	assert.True(t, errors.Is(err, database.ErrNotFound), err)
+}
+
+func TestMoveVinyl(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	_, deep, _ := add_test_crates(t, db)
+	add_test_vinyl(t, db)
+
+	keys := []schema.VinylKey{
+		{UserID: 42, VersionID: 1178, Item: 1},
+		{UserID: 42, VersionID: 1179, Item: 1},
+	}
+	require.NoError(t, MoveVinyl(ctx, db, 42, deep.ID, keys))
+	_, page, err := ListVinyl(ctx, db, 42, VinylQuery{CrateID: deep.ID, Paging: first_page})
+	require.NoError(t, err)
+	assert.Equal(t, 2, page.Total)
+
+	// All or nothing.
+	err = MoveVinyl(ctx, db, 42, 0, append(keys, schema.VinylKey{UserID: 42, VersionID: 1178, Item: 9}))
+	assert.True(t, errors.Is(err, database.ErrNotFound), err)
+	_, page, err = ListVinyl(ctx, db, 42, VinylQuery{CrateID: deep.ID, Paging: first_page})
+	require.NoError(t, err)
+	assert.Equal(t, 2, page.Total)
+
+	require.NoError(t, MoveVinyl(ctx, db, 42, 0, keys))
+	_, page, err = ListVinyl(ctx, db, 42, VinylQuery{Unsorted: true, Paging: first_page})
+	require.NoError(t, err)
+	assert.Equal(t, 2, page.Total)
+
+	err = MoveVinyl(ctx, db, 43, deep.ID, nil)
+	assert.True(t, errors.Is(err, database.ErrNotFound), "another user's crate: %v", err)
+}
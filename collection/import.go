@@ -0,0 +1,474 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/collection/import.go
+
+package collection
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
+)
+
+// Whether an import adds only some of its rows when others fail.
+type ImportMode string
+
+const (
+	// Nothing is added unless every row can be.
+	ImportAllOrNothing ImportMode = "all"
+	// The rows which can be added are, and the others are reported.
+	ImportBestEffort ImportMode = "best-effort"
+)
+
+// Parses the mode of an import, all or nothing if empty.
+func ParseImportMode(mode string) (ImportMode, error) {
+	switch ImportMode(mode) {
+	case "", ImportAllOrNothing:
+		return ImportAllOrNothing, nil
+	case ImportBestEffort:
+		return ImportBestEffort, nil
+	}
+	return "", fmt.Errorf("unrecognized import mode %q, expected all or best-effort", mode)
+}
+
+// The formats which ReadImport reads.
+type ImportFormat string
+
+const (
+	ImportCSV       ImportFormat = "csv"
+	ImportJSONLines ImportFormat = "jsonl"
+)
+
+// The most rows that a single import may have.
+const MaxImportRows = 5000
+
+// A copy to add to the collection, identified by its versionID or else by the
+// catalog number or barcode of the version.
+type ImportRow struct {
+	// The line of the input that the row starts on.
+	Line int `json:"-"`
+
+	VersionID     uint64 `json:"versionID,omitempty"`
+	CatalogNumber string `json:"catno,omitempty"`
+	Barcode       string `json:"barcode,omitempty"`
+
+	MediaGrade  schema.GradingEnum `json:"media_grade,omitempty"`
+	SleeveGrade schema.GradingEnum `json:"sleeve_grade,omitempty"`
+	// The slug path of one of the user's crates, e.g. "house/deep".  The copy
+	// is unsorted if empty.
+	Crate         string        `json:"crate,omitempty"`
+	Tags          []string      `json:"tags,omitempty"`
+	Notes         string        `json:"notes,omitempty"`
+	PurchasePrice *schema.Money `json:"purchase_price,omitempty"`
+
+	// Problems found while reading the row, e.g. an unrecognized grade.
+	problems []schema.FieldError
+}
+
+// Reads the rows of an import.  CSV input starts with a header naming its
+// columns, in any order: versionID, catno, barcode, media_grade, sleeve_grade,
+// crate, tags (separated by semicolons), notes, purchase_price (e.g. "27.50"
+// or "27.50 GBP") and currency.  JSON lines input has an ImportRow object on
+// each line.  Blank lines are skipped.
+//
+// Rows which cannot be read are kept, with their problems, so that they are
+// reported along with the others; an error is returned only if the input as a
+// whole cannot be read (or has more than MaxImportRows).
+func ReadImport(input io.Reader, format ImportFormat) ([]ImportRow, error) {
+	switch format {
+	case ImportCSV:
+		return read_import_csv(input)
+	case ImportJSONLines:
+		return read_import_jsonl(input)
+	}
+	return nil, fmt.Errorf("unrecognized import format %q", format)
+}
+
+var import_columns = map[string]func(row *ImportRow, value string) error{
+	"versionid": func(row *ImportRow, value string) (err error) {
+		row.VersionID, err = strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return errors.New("must be a versionID")
+		}
+		return nil
+	},
+	"catno":   func(row *ImportRow, value string) error { row.CatalogNumber = value; return nil },
+	"barcode": func(row *ImportRow, value string) error { row.Barcode = value; return nil },
+	"media_grade": func(row *ImportRow, value string) (err error) {
+		row.MediaGrade, err = schema.ParseGrading(value)
+		return err
+	},
+	"sleeve_grade": func(row *ImportRow, value string) (err error) {
+		row.SleeveGrade, err = schema.ParseGrading(value)
+		return err
+	},
+	"crate": func(row *ImportRow, value string) error { row.Crate = value; return nil },
+	"tags": func(row *ImportRow, value string) error {
+		for _, tag := range strings.Split(value, ";") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				row.Tags = append(row.Tags, tag)
+			}
+		}
+		return nil
+	},
+	"notes": func(row *ImportRow, value string) error { row.Notes = value; return nil },
+	// The currency column's code is appended to the amount, see read_import_csv.
+	"purchase_price": func(row *ImportRow, value string) error {
+		amount, code, _ := strings.Cut(value, " ")
+		var currency schema.CurrencyEnum // USD by default
+		if code = strings.TrimSpace(code); code != "" {
+			var err error
+			if currency, err = schema.ParseCurrency(code); err != nil {
+				return err
+			}
+		}
+		price, err := schema.ParseMoney(amount, currency)
+		if err != nil {
+			return err
+		}
+		row.PurchasePrice = &price
+		return nil
+	},
+	"currency": func(row *ImportRow, value string) error {
+		_, err := schema.ParseCurrency(value)
+		return err
+	},
+}
+
+func read_import_csv(input io.Reader) ([]ImportRow, error) {
+	reader := csv.NewReader(input)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("reading the CSV header: %w", err)
+	}
+	columns := make([]string, len(header))
+	seen := make(map[string]bool)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, known := import_columns[name]; !known {
+			return nil, fmt.Errorf("unrecognized CSV column %q", header[i])
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("CSV column %q appears twice", header[i])
+		}
+		columns[i], seen[name] = name, true
+	}
+
+	var rows []ImportRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("reading the CSV: %w", err)
+		}
+		if len(rows) == MaxImportRows {
+			return nil, fmt.Errorf("an import may have at most %d rows", MaxImportRows)
+		}
+		line, _ := reader.FieldPos(0)
+		row := ImportRow{Line: line}
+		if len(record) != len(columns) {
+			row.problems = append(row.problems, schema.FieldError{
+				Message: fmt.Sprintf("has %d fields but the header has %d", len(record), len(columns))})
+		}
+		values := make(map[string]string, len(columns))
+		for i, value := range record[:min(len(record), len(columns))] {
+			values[columns[i]] = strings.TrimSpace(value)
+		}
+		if price, currency := values["purchase_price"], values["currency"]; price != "" &&
+			currency != "" && !strings.Contains(price, " ") {
+			values["purchase_price"] = price + " " + currency
+		}
+		for _, column := range columns {
+			if values[column] == "" {
+				continue
+			}
+			if err := import_columns[column](&row, values[column]); err != nil {
+				row.problems = append(row.problems, schema.FieldError{
+					Field: import_field(column), Message: err.Error()})
+			}
+		}
+		rows = append(rows, row)
+	}
+}
+
+// The JSON name of the CSV column, as the field of its problems.
+func import_field(column string) string {
+	switch column {
+	case "versionid":
+		return "versionID"
+	case "currency":
+		return "purchase_price.currency"
+	}
+	return column
+}
+
+func read_import_jsonl(input io.Reader) ([]ImportRow, error) {
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(nil, 1<<20)
+	var rows []ImportRow
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		if len(rows) == MaxImportRows {
+			return nil, fmt.Errorf("an import may have at most %d rows", MaxImportRows)
+		}
+		row := ImportRow{}
+		decoder := json.NewDecoder(bytes.NewReader(text))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&row); err != nil {
+			row = ImportRow{problems: []schema.FieldError{json_problem(err)}}
+		}
+		row.Line = line
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading the JSON lines: %w", err)
+	}
+	return rows, nil
+}
+
+func json_problem(err error) schema.FieldError {
+	var mistyped *json.UnmarshalTypeError
+	if errors.As(err, &mistyped) {
+		return schema.FieldError{Field: mistyped.Field,
+			Message: fmt.Sprintf("must be a %s, not %s", mistyped.Type, mistyped.Value)}
+	}
+	return schema.FieldError{Message: err.Error()}
+}
+
+// What became of a row of an import.
+type ImportStatus string
+
+const (
+	ImportAdded  ImportStatus = "added"
+	ImportFailed ImportStatus = "failed"
+	// The row could have been added, but another row of an all or nothing
+	// import failed.
+	ImportRolledBack ImportStatus = "rolled_back"
+)
+
+type ImportResult struct {
+	Line   int          `json:"line"`
+	Status ImportStatus `json:"status"`
+	// The copy which the row added.
+	Vinyl *schema.VinylKey `json:"vinyl,omitempty"`
+	// Why the row failed.
+	Errors []schema.FieldError `json:"errors,omitempty"`
+}
+
+// The outcome of an import, with a result for each of its rows in order.
+type ImportReport struct {
+	Mode ImportMode `json:"mode"`
+	// Whether the added rows were kept, false if an all or nothing import
+	// had a failed row.
+	Committed bool           `json:"committed"`
+	Added     int            `json:"added"`
+	Failed    int            `json:"failed"`
+	Rows      []ImportResult `json:"rows"`
+}
+
+var errImportFailed = errors.New("an import row failed")
+
+// Adds a copy to the user's collection for each row, in a single transaction
+// (and with the next item numbers from the allocator).  Every row is checked,
+// so the report has the problems of all failed rows; in ImportAllOrNothing
+// mode any of them rolls back the whole import.  The returned error is only
+// for failures of the database, not of the rows.
+func ImportVinyl(ctx context.Context, db *sql.DB, items *ItemAllocator, userID uint64, rows []ImportRow, mode ImportMode) (ImportReport, error) {
+	report := ImportReport{Mode: mode, Rows: make([]ImportResult, len(rows))}
+	err := database.InTx(ctx, db, func(tx *sql.Tx) error {
+		for i, row := range rows {
+			result, err := import_row(ctx, tx, items, userID, row)
+			if err != nil {
+				return fmt.Errorf("importing line %d: %w", row.Line, err)
+			}
+			report.Rows[i] = result
+			if result.Status == ImportAdded {
+				report.Added++
+			} else {
+				report.Failed++
+			}
+		}
+		if mode == ImportAllOrNothing && report.Failed > 0 {
+			return errImportFailed
+		}
+		return nil
+	})
+	if errors.Is(err, errImportFailed) {
+		for i, result := range report.Rows {
+			if result.Status == ImportAdded {
+				report.Rows[i] = ImportResult{Line: result.Line, Status: ImportRolledBack}
+			}
+		}
+		report.Added = 0
+		return report, nil
+	}
+	if err != nil {
+		return ImportReport{}, err
+	}
+	report.Committed = true
+	return report, nil
+}
+
+// Adds the row within a savepoint, so that a failed row leaves no changes.
+func import_row(ctx context.Context, tx *sql.Tx, items *ItemAllocator, userID uint64, row ImportRow) (ImportResult, error) {
+	result := ImportResult{Line: row.Line, Status: ImportFailed, Errors: row.problems}
+	if len(row.problems) > 0 {
+		return result, nil
+	}
+	if _, err := tx.ExecContext(ctx, `SAVEPOINT import_row`); err != nil {
+		return result, err
+	}
+	key, err := add_import_row(ctx, tx, items, userID, row)
+	if err != nil {
+		if _, rollback := tx.ExecContext(ctx, `ROLLBACK TO import_row`); rollback != nil {
+			return result, rollback
+		}
+	}
+	if _, release := tx.ExecContext(ctx, `RELEASE import_row`); release != nil {
+		return result, release
+	}
+	if err != nil {
+		if result.Errors = row_errors(err); result.Errors == nil {
+			return result, err
+		}
+		return result, nil
+	}
+	result.Status, result.Vinyl = ImportAdded, &key
+	return result, nil
+}
+
+func add_import_row(ctx context.Context, tx *sql.Tx, items *ItemAllocator, userID uint64, row ImportRow) (schema.VinylKey, error) {
+	vinyl := schema.Vinyl{
+		UserID:        userID,
+		MediaGrade:    row.MediaGrade,
+		SleeveGrade:   row.SleeveGrade,
+		Notes:         row.Notes,
+		PurchasePrice: row.PurchasePrice,
+	}
+	var err error
+	if vinyl.VersionID, err = resolve_version(ctx, tx, row); err != nil {
+		return schema.VinylKey{}, err
+	}
+	if row.Crate != "" {
+		crate, err := ResolveCratePath(ctx, tx, userID, row.Crate, true)
+		if errors.Is(err, database.ErrNotFound) {
+			return schema.VinylKey{}, schema.FieldError{Field: "crate", Message: "is not one of the user's crates"}
+		} else if err != nil {
+			return schema.VinylKey{}, err
+		}
+		vinyl.CrateID = crate.ID
+	}
+	key, err := items.Add(ctx, tx, vinyl)
+	if err != nil {
+		return key, err
+	}
+	if len(row.Tags) > 0 {
+		err = TagVinyl(ctx, tx, userID, []schema.VinylKey{key}, row.Tags, nil)
+	}
+	return key, err
+}
+
+// The problems of a failed row, or nil if the error is not about the row.
+func row_errors(err error) []schema.FieldError {
+	var invalid *schema.ValidationError
+	var field schema.FieldError
+	switch {
+	case errors.As(err, &invalid):
+		return invalid.Fields
+	case errors.As(err, &field):
+		return []schema.FieldError{field}
+	case errors.Is(err, database.ErrNotFound),
+		errors.Is(err, database.ErrReferenced),
+		errors.Is(err, database.ErrDuplicate),
+		errors.Is(err, database.ErrConstraint):
+		return []schema.FieldError{{Message: err.Error()}}
+	}
+	return nil
+}
+
+// The version which the row identifies: its versionID if given, otherwise the
+// only version with the catalog number or barcode (ignoring spaces, dashes and,
+// for catalog numbers, case).
+func resolve_version(ctx context.Context, db database.Queryer, row ImportRow) (uint64, error) {
+	var field, query string
+	var arg any
+	normalize := strings.NewReplacer(" ", "", "-", "")
+	switch {
+	case row.VersionID != 0:
+		field, arg = "versionID", row.VersionID
+		query = `SELECT versionID FROM ReleaseVersions WHERE versionID = ?`
+	case row.CatalogNumber != "":
+		field, arg = "catno", normalize.Replace(strings.ToUpper(row.CatalogNumber))
+		query = `SELECT DISTINCT versionID FROM ReleaseVersion_Labels
+		  WHERE REPLACE(REPLACE(UPPER(catalog_id), ' ', ''), '-', '') = ?
+		  ORDER BY versionID LIMIT 4`
+	case row.Barcode != "":
+		field, arg = "barcode", normalize.Replace(row.Barcode)
+		query = `SELECT DISTINCT versionID FROM ReleaseVersion_Identifiers
+		  WHERE REPLACE(REPLACE(value, ' ', ''), '-', '') = ? AND type = 'Barcode'
+		  ORDER BY versionID LIMIT 4`
+	default:
+		return 0, schema.FieldError{Field: "versionID", Message: "is required without a catno or barcode"}
+	}
+
+	versions, err := database.QueryList(ctx, db, func(rows *sql.Rows) (uint64, error) {
+		var versionID uint64
+		err := rows.Scan(&versionID)
+		return versionID, err
+	}, query, arg)
+	if err != nil {
+		return 0, err
+	}
+	switch len(versions) {
+	case 0:
+		return 0, schema.FieldError{Field: field, Message: "matches no release version"}
+	case 1:
+		return versions[0], nil
+	}
+	ids := make([]string, len(versions))
+	for i, versionID := range versions {
+		ids[i] = strconv.FormatUint(versionID, 10)
+	}
+	if len(ids) == 4 {
+		ids[3] = "more"
+	}
+	return 0, schema.FieldError{Field: field, Message: fmt.Sprintf(
+		"matches versions %s; give the versionID instead", strings.Join(ids, ", "))}
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/collection/import_test.go
+
+package collection
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+
+	"github.com/kevindamm/cratedigdb/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Adds catalog numbers and barcodes to the test versions, and a crate.
+func add_import_catalog(t *testing.T, db *sql.DB) schema.Crate {
+	for _, statement := range []string{
+		`INSERT INTO Labels (labelID, name) VALUES (7, "Wild Bunch")`,
+		`INSERT INTO ReleaseVersion_Labels (versionID, labelID, label_name, catalog_id)
+		   VALUES (1178, 7, "Wild Bunch", "WBRLP 1"), (1179, 7, "Wild Bunch", "WBRX-1"),
+		          (1178, 7, "Wild Bunch", "WB 2"), (1179, 7, "Wild Bunch", "wb2")`,
+		`INSERT INTO ReleaseVersion_Identifiers (versionID, type, value)
+		   VALUES (1178, "Barcode", "5 012093 100127"), (1179, "Matrix / Runout", "5012093100134"),
+		          (1179, "Barcode", "5012093-100134")`,
+	} {
+		_, err := db.Exec(statement)
+		require.NoError(t, err)
+	}
+	crate, err := CreateCrate(context.Background(), db, schema.Crate{UserID: 42, Name: "Trip Hop"})
+	require.NoError(t, err)
+	return crate
+}
+
+func TestReadImportCSV(t *testing.T) {
+	rows, err := ReadImport(strings.NewReader(
+		"versionID,catno,Media_Grade,sleeve_grade,crate,tags,notes,purchase_price,currency\n"+
+			"1178,,VG+,VG,trip-hop,warmup; ambient ,\"first press, shrink\",18.00,\n"+
+			",WBRX 1,Near Mint (NM or M-),,,,,2500,JPY\n"+
+			"first,,Great,,,,,12.345,\n"+
+			"1179,,M\n"), ImportCSV)
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+
+	usd := schema.NewMoney(1800, schema.CurrencyUSD)
+	assert.Equal(t, ImportRow{Line: 2, VersionID: 1178,
+		MediaGrade: schema.GradeVeryGoodPlus, SleeveGrade: schema.GradeVeryGood,
+		Crate: "trip-hop", Tags: []string{"warmup", "ambient"}, Notes: "first press, shrink",
+		PurchasePrice: &usd}, rows[0])
+	yen := schema.NewMoney(2500, schema.CurrencyJPY)
+	assert.Equal(t, ImportRow{Line: 3, CatalogNumber: "WBRX 1",
+		MediaGrade: schema.GradeNearMint, PurchasePrice: &yen}, rows[1])
+	assert.Equal(t, []schema.FieldError{
+		{Field: "versionID", Message: "must be a versionID"},
+		{Field: "media_grade", Message: `unrecognized grade "Great"`},
+		{Field: "purchase_price", Message: `amount "12.345" has more than 2 decimal places for USD`},
+	}, rows[2].problems)
+	assert.Equal(t, []schema.FieldError{{Message: "has 3 fields but the header has 9"}}, rows[3].problems)
+
+	_, err = ReadImport(strings.NewReader("versionID,grade\n1178,VG\n"), ImportCSV)
+	assert.ErrorContains(t, err, `unrecognized CSV column "grade"`)
+	rows, err = ReadImport(strings.NewReader(""), ImportCSV)
+	assert.NoError(t, err)
+	assert.Empty(t, rows)
+}
+
+func TestReadImportJSONLines(t *testing.T) {
+	rows, err := ReadImport(strings.NewReader(
+		`{"versionID": 1178, "media_grade": "VG+", "tags": ["warmup"], "purchase_price": {"amount": "18.00"}}`+"\n"+
+			"\n"+
+			`{"barcode": "5012093100127", "sleeve_grade": "Generic"}`+"\n"+
+			`{"versionID": "1178"}`+"\n"+
+			`{"version": 1178}`+"\n"+
+			`{"versionID": 1178`+"\n"), ImportJSONLines)
+	require.NoError(t, err)
+	require.Len(t, rows, 5)
+
+	usd := schema.NewMoney(1800, schema.CurrencyUSD)
+	assert.Equal(t, ImportRow{Line: 1, VersionID: 1178, MediaGrade: schema.GradeVeryGoodPlus,
+		Tags: []string{"warmup"}, PurchasePrice: &usd}, rows[0])
+	assert.Equal(t, ImportRow{Line: 3, Barcode: "5012093100127", SleeveGrade: schema.GradeGeneric}, rows[1])
+	assert.Equal(t, []schema.FieldError{{Field: "versionID", Message: "must be a uint64, not string"}},
+		rows[2].problems)
+	assert.Equal(t, 5, rows[3].Line)
+	assert.Equal(t, []schema.FieldError{{Message: `json: unknown field "version"`}}, rows[3].problems)
+	assert.Equal(t, 6, rows[4].Line)
+	assert.Len(t, rows[4].problems, 1)
+
+	_, err = ReadImport(strings.NewReader(strings.Repeat("{}\n", MaxImportRows+1)), ImportJSONLines)
+	assert.ErrorContains(t, err, "at most")
+}
+
+func TestImportVinyl(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	crate := add_import_catalog(t, db)
+	var items ItemAllocator
+
+	rows, err := ReadImport(strings.NewReader(
+		"versionID,catno,barcode,media_grade,crate,tags,purchase_price\n"+
+			"1178,,,VG+,trip-hop,warmup,18.00\n"+
+			",wbrx1,,NM,,,\n"+
+			",,5012093100127,VG,,,\n"+
+			",WB 2,,VG,,,\n"+
+			",,,VG,,,\n"+
+			"1178,,,VG,disco,,\n"+
+			"9999,,,VG,,,\n"+
+			"1179,,,No Cover,,,\n"), ImportCSV)
+	require.NoError(t, err)
+
+	report, err := ImportVinyl(ctx, db, &items, 42, rows, ImportAllOrNothing)
+	require.NoError(t, err)
+	assert.False(t, report.Committed)
+	assert.Equal(t, 0, report.Added)
+	assert.Equal(t, 5, report.Failed)
+	assert.Equal(t, ImportResult{Line: 2, Status: ImportRolledBack}, report.Rows[0])
+	assert.Equal(t, ImportResult{Line: 5, Status: ImportFailed, Errors: []schema.FieldError{
+		{Field: "catno", Message: "matches versions 1178, 1179; give the versionID instead"}}},
+		report.Rows[3])
+	assert.Equal(t, []schema.FieldError{
+		{Field: "versionID", Message: "is required without a catno or barcode"}}, report.Rows[4].Errors)
+	assert.Equal(t, []schema.FieldError{
+		{Field: "crate", Message: "is not one of the user's crates"}}, report.Rows[5].Errors)
+	assert.Equal(t, []schema.FieldError{
+		{Field: "versionID", Message: "matches no release version"}}, report.Rows[6].Errors)
+	assert.Equal(t, []schema.FieldError{
+		{Field: "media_grade", Message: "is not a valid media grade"}}, report.Rows[7].Errors)
+	_, page, err := ListVinyl(ctx, db, 42, VinylQuery{Paging: first_page})
+	require.NoError(t, err)
+	assert.Equal(t, 0, page.Total, "nothing was added")
+
+	report, err = ImportVinyl(ctx, db, &items, 42, rows, ImportBestEffort)
+	require.NoError(t, err)
+	assert.True(t, report.Committed)
+	assert.Equal(t, 3, report.Added)
+	assert.Equal(t, 5, report.Failed)
+	added := []schema.VinylKey{
+		{UserID: 42, VersionID: 1178, Item: 1},
+		{UserID: 42, VersionID: 1179, Item: 1},
+		{UserID: 42, VersionID: 1178, Item: 2},
+	}
+	for i, key := range added {
+		assert.Equal(t, ImportAdded, report.Rows[i].Status)
+		assert.Equal(t, &key, report.Rows[i].Vinyl)
+	}
+
+	vinyl, err := GetVinyl(ctx, db, added[0])
+	require.NoError(t, err)
+	assert.Equal(t, crate.ID, vinyl.CrateID)
+	assert.Equal(t, []string{"warmup"}, vinyl.Tags)
+	require.NotNil(t, vinyl.PurchasePrice)
+	assert.Equal(t, "18.00 USD", vinyl.PurchasePrice.String())
+	_, page, err = ListVinyl(ctx, db, 42, VinylQuery{Paging: first_page})
+	require.NoError(t, err)
+	assert.Equal(t, 3, page.Total, "only the rows which could be added")
+
+	// Without failed rows, all or nothing adds everything.
+	report, err = ImportVinyl(ctx, db, &items, 42, rows[:3], ImportAllOrNothing)
+	require.NoError(t, err)
+	assert.True(t, report.Committed)
+	assert.Equal(t, 3, report.Added)
+	assert.Equal(t, uint(4), report.Rows[2].Vinyl.Item, "after items 1, 2 and 3 (from row 0)")
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/collection/items.go
+
+// Package collection holds the business logic for a user's vinyl collection
+// (their copies, crates and tags) on top of the sqlite database in package sql.
+package collection
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
+)
+
+// Assigns item numbers to new copies in VinylItems.  The first copy of a
+// version is item 1 and each additional copy gets the next number after the
+// highest existing one, so numbers of sold or archived copies (which may be
+// referred to from the ledger) are never reused.
+//
+// The number is chosen and the row inserted in a single statement, which is
+// atomic in sqlite (under its write lock) even across processes and within a
+// caller's transaction.  Nothing is held across the statement, so Add may be
+// called concurrently inside and outside of transactions.
+type ItemAllocator struct{}
+
+// Inserts the copy into VinylItems with the next free item number (ignoring
+// vinyl.Item), returning the key of the new copy.  If the vinyl's ReleaseID
+// is zero it is taken from the version.  The Tags are not written.
+func (allocator *ItemAllocator) Add(ctx context.Context, db database.Queryer, vinyl schema.Vinyl) (schema.VinylKey, error) {
+	vinyl.Item = 1 // a placeholder so that validation passes
+	if err := vinyl.Validate(); err != nil {
+		return schema.VinylKey{}, err
+	}
+	date_added := schema.Today()
+	if vinyl.DateAdded != nil {
+		date_added = *vinyl.DateAdded
+	}
+	var crateID any
+	if vinyl.CrateID != 0 {
+		crateID = vinyl.CrateID
+	}
+	price, currency := purchase_price(vinyl)
+
+	var item uint
+	err := db.QueryRowContext(ctx, `
+	  INSERT INTO VinylItems (userID, releaseID, versionID, item, crateID,
+	    date_added, date_graded, media_grade, sleeve_grade, notes,
+	    purchase_price, purchase_currency)
+	  SELECT ?1,
+	    CASE WHEN ?2 <> 0 THEN ?2
+	         ELSE (SELECT releaseID FROM ReleaseVersions WHERE versionID = ?3) END,
+	    ?3,
+	    COALESCE((SELECT MAX(item) FROM VinylItems WHERE userID = ?1 AND versionID = ?3), 0) + 1,
+	    ?4, ?5, ?6, ?7, ?8, ?9, ?10, ?11
+	  RETURNING item`,
+		vinyl.UserID, vinyl.ReleaseID, vinyl.VersionID, crateID,
+		date_added, vinyl.DateGraded, vinyl.MediaGrade, vinyl.SleeveGrade, vinyl.Notes,
+		price, currency,
+	).Scan(&item)
+	if err != nil {
+		return schema.VinylKey{}, fmt.Errorf("adding version %d for user %d: %w",
+			vinyl.VersionID, vinyl.UserID, database.Classify(err))
+	}
+	return schema.VinylKey{UserID: vinyl.UserID, VersionID: vinyl.VersionID, Item: item}, nil
+}
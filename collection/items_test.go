@@ -0,0 +1,174 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/collection/items_test.go
+
+package collection
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Opens a database (in a temporary file, so that there may be concurrent
+// connections) with a user "kevin" (userID 42) and two versions of a release.
+func open_test_db(t *testing.T) *sql.DB {
+	return open_test_db_at(t, filepath.Join(t.TempDir(), "cratedig.db"))
+}
+
+// Opens the database at path (which may be ":memory:") with the test data of
+// open_test_db.
+func open_test_db_at(t *testing.T, path string) *sql.DB {
+	ctx := context.Background()
+	db, err := database.Open(ctx, path)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	for _, statement := range []string{
+		`INSERT INTO UserAccounts (userID, username) VALUES (42, "kevin"), (43, "dana")`,
+		`INSERT INTO Releases (releaseID, title, main_version) VALUES (100, "Blue Lines", 1178)`,
+		`INSERT INTO ReleaseVersions (versionID, releaseID, title)
+		   VALUES (1178, 100, "Blue Lines"), (1179, 100, "Blue Lines (Remastered)")`,
+	} {
+		_, err := tx.Exec(statement)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tx.Commit())
+	return db
+}
+
+func TestAddAllocatesItems(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	var allocator ItemAllocator
+
+	vinyl := schema.Vinyl{UserID: 42, VersionID: 1178, MediaGrade: schema.GradeVeryGoodPlus}
+	key, err := allocator.Add(ctx, db, vinyl)
+	require.NoError(t, err)
+	assert.Equal(t, schema.VinylKey{UserID: 42, VersionID: 1178, Item: 1}, key)
+
+	key, err = allocator.Add(ctx, db, vinyl)
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), key.Item)
+
+	// Numbering is per user and per version.
+	key, err = allocator.Add(ctx, db, schema.Vinyl{UserID: 43, VersionID: 1178})
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), key.Item)
+	key, err = allocator.Add(ctx, db, schema.Vinyl{UserID: 42, VersionID: 1179})
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), key.Item)
+
+	var releaseID uint64
+	var added schema.Date
+	require.NoError(t, db.QueryRow(`
+	  SELECT releaseID, date_added FROM VinylItems
+	  WHERE userID = 42 AND versionID = 1179 AND item = 1`).Scan(&releaseID, &added))
+	assert.Equal(t, uint64(100), releaseID)
+	assert.Equal(t, schema.Today(), added)
+
+	_, err = allocator.Add(ctx, db, schema.Vinyl{UserID: 42, VersionID: 9999})
+	assert.Error(t, err, "unknown version")
+	_, err = allocator.Add(ctx, db, schema.Vinyl{VersionID: 1178})
+	assert.Error(t, err, "missing user")
+}
+
+func TestAddIsConcurrencySafe(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	var allocator ItemAllocator
+
+	const copies = 20
+	items := make([]int, copies)
+	var group sync.WaitGroup
+	for i := range copies {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			key, err := allocator.Add(ctx, db, schema.Vinyl{UserID: 42, VersionID: 1178})
+			assert.NoError(t, err)
+			items[i] = int(key.Item)
+		}()
+	}
+	group.Wait()
+
+	sort.Ints(items)
+	for i, item := range items {
+		assert.Equal(t, i+1, item)
+	}
+}
+
+// Adding outside of a transaction waits for a transaction which is adding
+// copies (e.g. a batch or an import) to finish, rather than blocking it.
+func TestAddAlongsideTransaction(t *testing.T) {
+	for _, path := range []string{":memory:", filepath.Join(t.TempDir(), "cratedig.db")} {
+		ctx := context.Background()
+		db := open_test_db_at(t, path)
+		var allocator ItemAllocator
+		vinyl := schema.Vinyl{UserID: 42, VersionID: 1178}
+
+		tx, err := db.BeginTx(ctx, nil)
+		require.NoError(t, err)
+		_, err = allocator.Add(ctx, tx, vinyl)
+		require.NoError(t, err)
+
+		const copies = 5
+		added := make(chan schema.VinylKey, copies)
+		var started, group sync.WaitGroup
+		started.Add(copies)
+		for range copies {
+			group.Add(1)
+			go func() {
+				defer group.Done()
+				started.Done()
+				key, err := allocator.Add(ctx, db, vinyl)
+				assert.NoError(t, err, path)
+				added <- key
+			}()
+		}
+		// However the others interleave with the transaction, they can only add
+		// their copies once it commits, so they are numbered after its copies.
+		started.Wait()
+		_, err = allocator.Add(ctx, tx, vinyl)
+		require.NoError(t, err, path)
+		require.NoError(t, tx.Commit())
+		group.Wait()
+		close(added)
+
+		items := []int{}
+		for key := range added {
+			items = append(items, int(key.Item))
+		}
+		sort.Ints(items)
+		assert.Equal(t, []int{3, 4, 5, 6, 7}, items, path)
+	}
+}
@@ -0,0 +1,242 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/collection/tagexpr.go
+
+package collection
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// A boolean expression over tag names, such as
+//
+//	warmup AND (disco OR funk) AND NOT lent
+//
+// AND binds tighter than OR, and NOT tighter than both; the operators are not
+// case sensitive.  A tag name containing spaces, parentheses or an operator's
+// name must be double-quoted, e.g. "late night" OR "not yet".
+type TagExpr interface {
+	fmt.Stringer
+
+	// A condition on VinylItems (in the user's collection) and its arguments.
+	condition() (string, []any)
+}
+
+// The most tag names that an expression may refer to.
+const MaxTagTerms = 32
+
+type tag_term string
+type tag_not struct{ expr TagExpr }
+type tag_binary struct {
+	op          string
+	left, right TagExpr
+}
+
+func (term tag_term) String() string {
+	name := string(term)
+	if strings.ContainsFunc(name, is_tag_delimiter) || is_tag_operator(name) {
+		return `"` + name + `"`
+	}
+	return name
+}
+
+func (not tag_not) String() string { return "NOT " + not.expr.String() }
+
+func (expr tag_binary) String() string {
+	return "(" + expr.left.String() + " " + expr.op + " " + expr.right.String() + ")"
+}
+
+func (term tag_term) condition() (string, []any) {
+	return `EXISTS (
+	  SELECT 1 FROM VinylTagging JOIN TagNames USING (tagID)
+	  WHERE VinylTagging.userID = VinylItems.userID
+	    AND VinylTagging.versionID = VinylItems.versionID
+	    AND TagNames.name = ?)`, []any{string(term)}
+}
+
+func (not tag_not) condition() (string, []any) {
+	condition, args := not.expr.condition()
+	return "NOT " + condition, args
+}
+
+func (expr tag_binary) condition() (string, []any) {
+	left, args := expr.left.condition()
+	right, right_args := expr.right.condition()
+	return "(" + left + " " + expr.op + " " + right + ")", append(args, right_args...)
+}
+
+// Parses a tag expression, returning an error that describes where it is
+// malformed.
+func ParseTagExpr(expression string) (TagExpr, error) {
+	tokens, err := tokenize_tags(expression)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty tag expression")
+	}
+	if len(tokens) > 8*MaxTagTerms {
+		return nil, fmt.Errorf("tag expression is too long")
+	}
+	parser := tag_parser{tokens: tokens}
+	expr, err := parser.parse_or()
+	if err != nil {
+		return nil, err
+	}
+	if parser.pos < len(tokens) {
+		return nil, fmt.Errorf("unexpected %s in tag expression", tokens[parser.pos])
+	}
+	if parser.terms > MaxTagTerms {
+		return nil, fmt.Errorf("tag expression has more than %d tags", MaxTagTerms)
+	}
+	return expr, nil
+}
+
+type tag_token struct {
+	text   string
+	quoted bool
+}
+
+func (token tag_token) String() string {
+	if token.quoted {
+		return `"` + token.text + `"`
+	}
+	return fmt.Sprintf("%q", token.text)
+}
+
+func (token tag_token) is(operator string) bool {
+	return !token.quoted && strings.EqualFold(token.text, operator)
+}
+
+func is_tag_delimiter(r rune) bool {
+	return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"'
+}
+
+func is_tag_operator(name string) bool {
+	return strings.EqualFold(name, "AND") || strings.EqualFold(name, "OR") ||
+		strings.EqualFold(name, "NOT")
+}
+
+func tokenize_tags(expression string) ([]tag_token, error) {
+	var tokens []tag_token
+	for rest := expression; ; {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		if rest == "" {
+			return tokens, nil
+		}
+		switch rest[0] {
+		case '(', ')':
+			tokens = append(tokens, tag_token{text: rest[:1]})
+			rest = rest[1:]
+		case '"':
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quote in tag expression")
+			}
+			tokens = append(tokens, tag_token{text: rest[1 : end+1], quoted: true})
+			rest = rest[end+2:]
+		default:
+			end := strings.IndexFunc(rest, is_tag_delimiter)
+			if end < 0 {
+				end = len(rest)
+			}
+			tokens = append(tokens, tag_token{text: rest[:end]})
+			rest = rest[end:]
+		}
+	}
+}
+
+// Recursive descent over the grammar
+//
+//	or   = and { OR and }
+//	and  = not { AND not }
+//	not  = NOT not | "(" or ")" | name
+type tag_parser struct {
+	tokens []tag_token
+	pos    int
+	terms  int
+}
+
+func (parser *tag_parser) next_is(operator string) bool {
+	if parser.pos < len(parser.tokens) && parser.tokens[parser.pos].is(operator) {
+		parser.pos++
+		return true
+	}
+	return false
+}
+
+func (parser *tag_parser) parse_or() (TagExpr, error) {
+	left, err := parser.parse_and()
+	for err == nil && parser.next_is("OR") {
+		var right TagExpr
+		if right, err = parser.parse_and(); err == nil {
+			left = tag_binary{"OR", left, right}
+		}
+	}
+	return left, err
+}
+
+func (parser *tag_parser) parse_and() (TagExpr, error) {
+	left, err := parser.parse_not()
+	for err == nil && parser.next_is("AND") {
+		var right TagExpr
+		if right, err = parser.parse_not(); err == nil {
+			left = tag_binary{"AND", left, right}
+		}
+	}
+	return left, err
+}
+
+func (parser *tag_parser) parse_not() (TagExpr, error) {
+	if parser.pos >= len(parser.tokens) {
+		return nil, fmt.Errorf("tag expression ends where a tag was expected")
+	}
+	if parser.next_is("NOT") {
+		expr, err := parser.parse_not()
+		if err != nil {
+			return nil, err
+		}
+		return tag_not{expr}, nil
+	}
+	if parser.next_is("(") {
+		expr, err := parser.parse_or()
+		if err != nil {
+			return nil, err
+		}
+		if !parser.next_is(")") {
+			return nil, fmt.Errorf("unbalanced parentheses in tag expression")
+		}
+		return expr, nil
+	}
+
+	token := parser.tokens[parser.pos]
+	if !token.quoted && (token.text == ")" || is_tag_operator(token.text)) {
+		return nil, fmt.Errorf("unexpected %s in tag expression, expected a tag", token)
+	}
+	if strings.TrimSpace(token.text) == "" {
+		return nil, fmt.Errorf("empty tag name in tag expression")
+	}
+	parser.pos++
+	parser.terms++
+	return tag_term(token.text), nil
+}
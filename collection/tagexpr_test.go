@@ -0,0 +1,66 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/collection/tagexpr_test.go
+
+package collection
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTagExpr(t *testing.T) {
+	for expression, expected := range map[string]string{
+		`warmup`: `warmup`,
+		`warmup AND (disco OR funk) AND NOT lent`: `((warmup AND (disco OR funk)) AND NOT lent)`,
+		`a or b and c`:          `(a OR (b AND c))`,
+		`not not a`:             `NOT NOT a`,
+		`"late night" OR "not"`: `("late night" OR "not")`,
+		`(((italo)))`:           `italo`,
+	} {
+		expr, err := ParseTagExpr(expression)
+		require.NoError(t, err, expression)
+		assert.Equal(t, expected, expr.String(), expression)
+	}
+
+	for expression, message := range map[string]string{
+		``:                 "empty tag expression",
+		`warmup AND`:       "tag expression ends where a tag was expected",
+		`(disco OR funk`:   "unbalanced parentheses in tag expression",
+		`disco funk`:       `unexpected "funk" in tag expression`,
+		`disco OR OR funk`: `unexpected "OR" in tag expression, expected a tag`,
+		`"late night`:      "unterminated quote in tag expression",
+		`() OR disco`:      `unexpected ")" in tag expression, expected a tag`,
+		`"" OR disco`:      "empty tag name in tag expression",
+	} {
+		_, err := ParseTagExpr(expression)
+		assert.EqualError(t, err, message, expression)
+	}
+}
+
+func TestTagExprArguments(t *testing.T) {
+	expr, err := ParseTagExpr(`warmup AND (disco OR funk) AND NOT lent`)
+	require.NoError(t, err)
+	_, args := expr.condition()
+	assert.Equal(t, []any{"warmup", "disco", "funk", "lent"}, args)
+}
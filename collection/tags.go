@@ -0,0 +1,219 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/collection/tags.go
+
+package collection
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
+)
+
+func scan_tag(rows *sql.Rows) (schema.Tag, error) {
+	var tag schema.Tag
+	return tag, rows.Scan(&tag.ID, &tag.UserID, &tag.Name)
+}
+
+// All of the user's tags, ordered by name.
+func ListTags(ctx context.Context, db database.Queryer, userID uint64) ([]schema.Tag, error) {
+	return database.QueryList(ctx, db, scan_tag, `
+	  SELECT tagID, userID, name FROM TagNames
+	  WHERE userID = ?
+	  ORDER BY name, tagID`, userID)
+}
+
+func GetTag(ctx context.Context, db database.Queryer, userID, tagID uint64) (schema.Tag, error) {
+	tag := schema.Tag{ID: tagID, UserID: userID}
+	err := db.QueryRowContext(ctx, `
+	  SELECT name FROM TagNames WHERE userID = ? AND tagID = ?`, userID, tagID,
+	).Scan(&tag.Name)
+	if err != nil {
+		return schema.Tag{}, fmt.Errorf("tag %d: %w", tagID, database.Classify(err))
+	}
+	return tag, nil
+}
+
+// Creates the tag, returning it with its tagID.  Tag names are unique for each
+// user (ignoring case).
+func CreateTag(ctx context.Context, db database.Queryer, tag schema.Tag) (schema.Tag, error) {
+	tag.Name = strings.TrimSpace(tag.Name)
+	if err := validate_tag(tag); err != nil {
+		return schema.Tag{}, err
+	}
+	err := db.QueryRowContext(ctx, `
+	  INSERT INTO TagNames (userID, name) VALUES (?, ?)
+	  RETURNING tagID`, tag.UserID, tag.Name,
+	).Scan(&tag.ID)
+	if err != nil {
+		return schema.Tag{}, fmt.Errorf("tag %q: %w", tag.Name, database.Classify(err))
+	}
+	return tag, nil
+}
+
+// Renames the tag; if another of the user's tags already has the name, the
+// error wraps database.ErrDuplicate (and the tags may be merged instead).
+func RenameTag(ctx context.Context, db database.Queryer, userID, tagID uint64, name string) (schema.Tag, error) {
+	tag := schema.Tag{ID: tagID, UserID: userID, Name: strings.TrimSpace(name)}
+	if err := validate_tag(tag); err != nil {
+		return schema.Tag{}, err
+	}
+	result, err := db.ExecContext(ctx, `
+	  UPDATE TagNames SET name = ? WHERE userID = ? AND tagID = ?`,
+		tag.Name, userID, tagID)
+	if err != nil {
+		return schema.Tag{}, fmt.Errorf("tag %q: %w", tag.Name, database.Classify(err))
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return schema.Tag{}, err
+	} else if affected == 0 {
+		return schema.Tag{}, fmt.Errorf("tag %d: %w", tagID, database.ErrNotFound)
+	}
+	return tag, nil
+}
+
+// Deletes the tag, untagging everything it was applied to.
+func DeleteTag(ctx context.Context, db database.Queryer, userID, tagID uint64) error {
+	result, err := db.ExecContext(ctx,
+		`DELETE FROM TagNames WHERE userID = ? AND tagID = ?`, userID, tagID)
+	if err != nil {
+		return fmt.Errorf("tag %d: %w", tagID, database.Classify(err))
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return fmt.Errorf("tag %d: %w", tagID, database.ErrNotFound)
+	}
+	return nil
+}
+
+// Merges the other tags into the tag with tagID: whatever they were applied to
+// is tagged with it instead, and the other tags are deleted.
+func MergeTags(ctx context.Context, db *sql.DB, userID, tagID uint64, others []uint64) (schema.Tag, error) {
+	var merged schema.Tag
+	err := database.InTx(ctx, db, func(tx *sql.Tx) error {
+		var err error
+		if merged, err = GetTag(ctx, tx, userID, tagID); err != nil {
+			return err
+		}
+		for _, other := range others {
+			if other == tagID {
+				continue
+			}
+			if _, err := GetTag(ctx, tx, userID, other); err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, `
+			  INSERT OR IGNORE INTO VinylTagging (userID, versionID, tagID)
+			  SELECT userID, versionID, ? FROM VinylTagging WHERE tagID = ?`,
+				tagID, other); err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx,
+				`DELETE FROM TagNames WHERE tagID = ?`, other); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return merged, err
+}
+
+// Adds and removes tags (by name) on each of the copies, all or nothing.  Tags
+// apply to every copy of a version.  Added tags are created if the user does
+// not have them yet; removing a tag the user does not have is an error.
+func TagVinyl(ctx context.Context, db database.Queryer, userID uint64, keys []schema.VinylKey, add, remove []string) error {
+	return database.InTx(ctx, db, func(tx *sql.Tx) error {
+		versions := make([]uint64, len(keys))
+		for i, key := range keys {
+			vinyl, err := GetVinyl(ctx, tx, key)
+			if err == nil && key.UserID != userID {
+				err = fmt.Errorf("vinyl %s: %w", key, database.ErrNotFound)
+			}
+			if err != nil {
+				return err
+			}
+			if vinyl.DateArchived != nil {
+				return fmt.Errorf("vinyl %s: %w", key, ErrArchived)
+			}
+			versions[i] = key.VersionID
+		}
+
+		for _, name := range add {
+			tag := schema.Tag{UserID: userID, Name: strings.TrimSpace(name)}
+			if err := validate_tag(tag); err != nil {
+				return err
+			}
+			err := tx.QueryRowContext(ctx, `
+			  INSERT INTO TagNames (userID, name) VALUES (?1, ?2)
+			  ON CONFLICT DO UPDATE SET name = name
+			  RETURNING tagID`, userID, tag.Name,
+			).Scan(&tag.ID)
+			if err != nil {
+				return fmt.Errorf("tag %q: %w", tag.Name, database.Classify(err))
+			}
+			for _, versionID := range versions {
+				if _, err := tx.ExecContext(ctx, `
+				  INSERT OR IGNORE INTO VinylTagging (userID, versionID, tagID)
+				  VALUES (?, ?, ?)`, userID, versionID, tag.ID); err != nil {
+					return database.Classify(err)
+				}
+			}
+		}
+
+		for _, name := range remove {
+			name = strings.TrimSpace(name)
+			var tagID uint64
+			err := tx.QueryRowContext(ctx, `
+			  SELECT tagID FROM TagNames WHERE userID = ? AND name = ?`, userID, name,
+			).Scan(&tagID)
+			if err != nil {
+				return fmt.Errorf("tag %q: %w", name, database.Classify(err))
+			}
+			for _, versionID := range versions {
+				if _, err := tx.ExecContext(ctx, `
+				  DELETE FROM VinylTagging
+				  WHERE userID = ? AND versionID = ? AND tagID = ?`,
+					userID, versionID, tagID); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Tag names may not contain a double quote, which would make them impossible
+// to refer to in a TagExpr.
+func validate_tag(tag schema.Tag) error {
+	if err := tag.Validate(); err != nil {
+		return err
+	}
+	if strings.ContainsRune(tag.Name, '"') {
+		return &schema.ValidationError{Typename: tag.Typename(), Fields: []schema.FieldError{
+			{Field: "name", Message: `may not contain '"'`}}}
+	}
+	return nil
+}
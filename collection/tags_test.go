@@ -0,0 +1,140 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/collection/tags_test.go
+
+package collection
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateAndRenameTags(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+
+	disco, err := CreateTag(ctx, db, schema.Tag{UserID: 42, Name: " disco "})
+	require.NoError(t, err)
+	assert.Equal(t, "disco", disco.Name)
+	_, err = CreateTag(ctx, db, schema.Tag{UserID: 42, Name: "Disco"})
+	assert.ErrorIs(t, err, database.ErrDuplicate, "names are unique ignoring case")
+	_, err = CreateTag(ctx, db, schema.Tag{UserID: 43, Name: "disco"})
+	assert.NoError(t, err, "each user has their own tags")
+	_, err = CreateTag(ctx, db, schema.Tag{UserID: 42, Name: `say "hi"`})
+	assert.ErrorContains(t, err, "name: may not contain")
+
+	funk, err := CreateTag(ctx, db, schema.Tag{UserID: 42, Name: "funk"})
+	require.NoError(t, err)
+	_, err = RenameTag(ctx, db, 42, funk.ID, "DISCO")
+	assert.ErrorIs(t, err, database.ErrDuplicate)
+	renamed, err := RenameTag(ctx, db, 42, funk.ID, "Funk & Soul")
+	require.NoError(t, err)
+	assert.Equal(t, schema.Tag{ID: funk.ID, UserID: 42, Name: "Funk & Soul"}, renamed)
+	_, err = RenameTag(ctx, db, 43, funk.ID, "mine")
+	assert.ErrorIs(t, err, database.ErrNotFound, "only the owner may rename a tag")
+
+	tags, err := ListTags(ctx, db, 42)
+	require.NoError(t, err)
+	assert.Equal(t, []schema.Tag{disco, renamed}, tags)
+
+	require.NoError(t, DeleteTag(ctx, db, 42, disco.ID))
+	assert.ErrorIs(t, DeleteTag(ctx, db, 42, disco.ID), database.ErrNotFound)
+}
+
+func TestTagVinyl(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	add_test_vinyl(t, db)
+	first := schema.VinylKey{UserID: 42, VersionID: 1178, Item: 1}
+	other := schema.VinylKey{UserID: 42, VersionID: 1179, Item: 1}
+
+	require.NoError(t, TagVinyl(ctx, db, 42, []schema.VinylKey{first, other},
+		[]string{"disco", "Warmup"}, nil))
+	vinyl, err := GetVinyl(ctx, db, schema.VinylKey{UserID: 42, VersionID: 1178, Item: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"disco", "warmup"}, vinyl.Tags, "tags apply to every copy of the version")
+
+	require.NoError(t, TagVinyl(ctx, db, 42, []schema.VinylKey{other}, nil, []string{"warmup"}))
+	vinyl, err = GetVinyl(ctx, db, other)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"disco"}, vinyl.Tags)
+
+	err = TagVinyl(ctx, db, 42, []schema.VinylKey{first}, []string{"funk"}, []string{"lent"})
+	assert.ErrorIs(t, err, database.ErrNotFound)
+	tags, err := ListTags(ctx, db, 42)
+	require.NoError(t, err)
+	assert.Len(t, tags, 2, "a failed update creates no tags")
+	err = TagVinyl(ctx, db, 42, []schema.VinylKey{{UserID: 42, VersionID: 1178, Item: 9}}, []string{"funk"}, nil)
+	assert.ErrorIs(t, err, database.ErrNotFound)
+}
+
+func TestMergeTags(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	add_test_vinyl(t, db)
+	key := schema.VinylKey{UserID: 42, VersionID: 1178, Item: 1}
+	require.NoError(t, TagVinyl(ctx, db, 42, []schema.VinylKey{key}, []string{"opener"}, nil))
+	tags, err := ListTags(ctx, db, 42)
+	require.NoError(t, err)
+	require.Len(t, tags, 2)
+	opener, warmup := tags[0], tags[1]
+
+	merged, err := MergeTags(ctx, db, 42, warmup.ID, []uint64{opener.ID})
+	require.NoError(t, err)
+	assert.Equal(t, warmup, merged)
+	vinyl, err := GetVinyl(ctx, db, key)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"warmup"}, vinyl.Tags)
+	_, err = GetTag(ctx, db, 42, opener.ID)
+	assert.ErrorIs(t, err, database.ErrNotFound)
+
+	_, err = MergeTags(ctx, db, 43, warmup.ID, nil)
+	assert.ErrorIs(t, err, database.ErrNotFound)
+}
+
+func TestListVinylByTags(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	add_test_vinyl(t, db)
+	first := schema.VinylKey{UserID: 42, VersionID: 1178, Item: 1}
+	other := schema.VinylKey{UserID: 42, VersionID: 1179, Item: 1}
+	require.NoError(t, TagVinyl(ctx, db, 42, []schema.VinylKey{first}, []string{"disco"}, nil))
+	require.NoError(t, TagVinyl(ctx, db, 42, []schema.VinylKey{other}, []string{"funk", "lent"}, nil))
+
+	for expression, expected := range map[string]int{
+		`disco`:                      2,
+		`warmup AND (disco OR funk)`: 1,
+		`warmup AND (disco OR funk) AND NOT lent`: 0,
+		`NOT lent`:      2,
+		`disco OR lent`: 3,
+	} {
+		tags, err := ParseTagExpr(expression)
+		require.NoError(t, err)
+		_, page, err := ListVinyl(ctx, db, 42, VinylQuery{Tags: tags, Paging: first_page})
+		require.NoError(t, err)
+		assert.Equal(t, expected, page.Total, expression)
+	}
+}
@@ -0,0 +1,44 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/collection/users.go
+
+package collection
+
+import (
+	"context"
+	"fmt"
+
+	database "github.com/kevindamm/cratedigdb/sql"
+)
+
+// Looks up the userID of an active (not banned) account by its username.
+// Returns an error wrapping database.ErrNotFound for unknown or banned users.
+func UserID(ctx context.Context, db database.Queryer, username string) (uint64, error) {
+	var userID uint64
+	err := db.QueryRowContext(ctx, `
+	  SELECT userID FROM UserAccounts
+	  WHERE username = ? AND date_banned IS NULL`, username,
+	).Scan(&userID)
+	if err != nil {
+		return 0, fmt.Errorf("user %q: %w", username, database.Classify(err))
+	}
+	return userID, nil
+}
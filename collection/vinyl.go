@@ -0,0 +1,413 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/collection/vinyl.go
+
+package collection
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kevindamm/cratedigdb/pagination"
+	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
+)
+
+// Returned (wrapped) when modifying a copy that was removed from the collection
+// but kept for its ledger history.
+var ErrArchived = errors.New("copy has been removed from the collection")
+
+// Which copies to list, by whether they are still in the collection.
+type VinylStatus string
+
+const (
+	// Copies which have not been sold, traded or removed (the default).
+	StatusOwned    VinylStatus = "owned"
+	StatusSold     VinylStatus = "sold"
+	StatusTraded   VinylStatus = "traded"
+	StatusArchived VinylStatus = "archived"
+	StatusAll      VinylStatus = "all"
+)
+
+var status_conditions = map[VinylStatus]string{
+	StatusOwned:    `date_sold IS NULL AND date_traded IS NULL AND date_archived IS NULL`,
+	StatusSold:     `date_sold IS NOT NULL`,
+	StatusTraded:   `date_traded IS NOT NULL`,
+	StatusArchived: `date_archived IS NOT NULL`,
+	StatusAll:      `TRUE`,
+}
+
+func ParseVinylStatus(status string) (VinylStatus, error) {
+	if status == "" {
+		return StatusOwned, nil
+	}
+	if _, found := status_conditions[VinylStatus(status)]; !found {
+		return "", fmt.Errorf("unknown vinyl status %q", status)
+	}
+	return VinylStatus(status), nil
+}
+
+// Selects a page of a user's copies.  Zero values do not filter.
+type VinylQuery struct {
+	ReleaseID uint64
+	VersionID uint64
+	// Only the items directly in this crate (crate 0 is all of them).
+	CrateID uint64
+	// Only the items not in any crate, cannot be combined with CrateID.
+	Unsorted bool
+	Grades   []schema.GradeFilter
+	Status   VinylStatus
+	// Only the copies whose version's tags satisfy the expression (if not nil).
+	Tags TagExpr
+
+	// In one of the VinylSorts orders.
+	Paging pagination.Request
+}
+
+// Copies are listed by when they were added (the default, most recent first)
+// or by version and item number.
+var VinylSorts = pagination.Sorts{
+	{Name: "added", Columns: []pagination.Column{
+		{Expr: "date_added", Desc: true}, {Expr: "versionID"}, {Expr: "item"}}},
+	{Name: "version", Columns: []pagination.Column{{Expr: "versionID"}, {Expr: "item"}}},
+}
+
+// The sort key of a copy in one of the VinylSorts orders.
+func vinyl_key(sort pagination.Sort) func(schema.Vinyl) []any {
+	if strings.TrimPrefix(sort.Name, "-") == "version" {
+		return func(vinyl schema.Vinyl) []any { return []any{vinyl.VersionID, vinyl.Item} }
+	}
+	return func(vinyl schema.Vinyl) []any {
+		return []any{vinyl.DateAdded.String(), vinyl.VersionID, vinyl.Item}
+	}
+}
+
+const vinyl_columns = `userID, versionID, item, releaseID, crateID,
+	date_added, date_graded, date_sold, date_traded, date_archived,
+	media_grade, sleeve_grade, notes, purchase_price, purchase_currency`
+
+func scan_vinyl(rows *sql.Rows) (schema.Vinyl, error) {
+	var vinyl schema.Vinyl
+	var crateID sql.NullInt64
+	var price schema.NullMoney
+	var currency schema.CurrencyEnum
+	err := rows.Scan(&vinyl.UserID, &vinyl.VersionID, &vinyl.Item, &vinyl.ReleaseID, &crateID,
+		&vinyl.DateAdded, &vinyl.DateGraded, &vinyl.DateSold, &vinyl.DateTraded, &vinyl.DateArchived,
+		&vinyl.MediaGrade, &vinyl.SleeveGrade, &vinyl.Notes, &price, &currency)
+	vinyl.CrateID = uint64(crateID.Int64)
+	vinyl.PurchasePrice = price.In(currency)
+	return vinyl, err
+}
+
+// The nullable SQL values of the copy's purchase price and its currency.
+func purchase_price(vinyl schema.Vinyl) (price schema.NullMoney, currency any) {
+	if vinyl.PurchasePrice == nil {
+		return price, nil
+	}
+	return schema.NullMoneyOf(vinyl.PurchasePrice), vinyl.PurchasePrice.Currency
+}
+
+// Returns the copies on the requested page, with their tags, described along
+// with the total number of the user's copies which match the query (on all
+// pages).
+func ListVinyl(ctx context.Context, db database.Queryer, userID uint64, query VinylQuery) ([]schema.Vinyl, pagination.Page, error) {
+	if query.Status == "" {
+		query.Status = StatusOwned
+	}
+	status, found := status_conditions[query.Status]
+	if !found {
+		return nil, pagination.Page{}, fmt.Errorf("unknown vinyl status %q", query.Status)
+	}
+
+	conditions := []string{`userID = ?`, status}
+	args := []any{userID}
+	if query.ReleaseID != 0 {
+		conditions = append(conditions, `releaseID = ?`)
+		args = append(args, query.ReleaseID)
+	}
+	if query.VersionID != 0 {
+		conditions = append(conditions, `versionID = ?`)
+		args = append(args, query.VersionID)
+	}
+	if query.Unsorted {
+		conditions = append(conditions, `crateID IS NULL`)
+	} else if query.CrateID != 0 {
+		conditions = append(conditions, `crateID = ?`)
+		args = append(args, query.CrateID)
+	}
+	for _, filter := range query.Grades {
+		if filter.Field != "media" && filter.Field != "sleeve" {
+			return nil, pagination.Page{}, fmt.Errorf("cannot filter on %s grade", filter.Field)
+		}
+		grades := filter.Grades()
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(grades)), ", ")
+		if len(grades) == 0 {
+			placeholders = "NULL"
+		}
+		conditions = append(conditions, fmt.Sprintf(`%s IN (%s)`, filter.Column(), placeholders))
+		for _, grade := range grades {
+			args = append(args, grade)
+		}
+	}
+	if query.Tags != nil {
+		condition, tag_args := query.Tags.condition()
+		conditions = append(conditions, condition)
+		args = append(args, tag_args...)
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM VinylItems WHERE `+where, args...).Scan(&total)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+
+	after, after_args := query.Paging.Where()
+	vinyl, err := database.QueryList(ctx, db, scan_vinyl, `
+	  SELECT `+vinyl_columns+` FROM VinylItems WHERE `+where+` AND `+after+`
+	  ORDER BY `+query.Paging.OrderBy()+`
+	  LIMIT ?`,
+		append(append(args, after_args...), query.Paging.Fetch())...)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+	vinyl, page := pagination.Paginate(query.Paging, vinyl, total, vinyl_key(query.Paging.Sort))
+	if err := add_tags(ctx, db, userID, vinyl); err != nil {
+		return nil, pagination.Page{}, err
+	}
+	return vinyl, page, nil
+}
+
+// Looks up a single copy (which may have been archived) with its tags.
+func GetVinyl(ctx context.Context, db database.Queryer, key schema.VinylKey) (schema.Vinyl, error) {
+	vinyl, err := database.QueryList(ctx, db, scan_vinyl, `
+	  SELECT `+vinyl_columns+` FROM VinylItems
+	  WHERE userID = ? AND versionID = ? AND item = ?`,
+		key.UserID, key.VersionID, key.Item)
+	if err != nil {
+		return schema.Vinyl{}, fmt.Errorf("vinyl %s: %w", key, err)
+	}
+	if len(vinyl) == 0 {
+		return schema.Vinyl{}, fmt.Errorf("vinyl %s: %w", key, database.ErrNotFound)
+	}
+	if err := add_tags(ctx, db, key.UserID, vinyl); err != nil {
+		return schema.Vinyl{}, err
+	}
+	return vinyl[0], nil
+}
+
+// Fills in the Tags of each copy (tags apply to every copy of a version) with
+// a single query.
+func add_tags(ctx context.Context, db database.Queryer, userID uint64, vinyl []schema.Vinyl) error {
+	if len(vinyl) == 0 {
+		return nil
+	}
+	versions := make([]uint64, len(vinyl))
+	for i := range vinyl {
+		versions[i] = vinyl[i].VersionID
+	}
+	versions_json, err := json.Marshal(versions)
+	if err != nil {
+		return err
+	}
+
+	type version_tag struct {
+		versionID uint64
+		name      string
+	}
+	tags, err := database.QueryList(ctx, db, func(rows *sql.Rows) (version_tag, error) {
+		var tag version_tag
+		return tag, rows.Scan(&tag.versionID, &tag.name)
+	}, `
+	  SELECT DISTINCT versionID, name
+	  FROM VinylTagging JOIN TagNames USING (tagID)
+	  WHERE VinylTagging.userID = ?
+	    AND versionID IN (SELECT value FROM json_each(?))
+	  ORDER BY versionID, name`, userID, string(versions_json))
+	if err != nil {
+		return fmt.Errorf("tags: %w", err)
+	}
+	by_version := make(map[uint64][]string)
+	for _, tag := range tags {
+		by_version[tag.versionID] = append(by_version[tag.versionID], tag.name)
+	}
+	for i := range vinyl {
+		vinyl[i].Tags = by_version[vinyl[i].VersionID]
+	}
+	return nil
+}
+
+// Changes to a copy's grading, notes, purchase price and whether it was sold
+// or traded.  Nil fields are left unchanged.
+type VinylUpdate struct {
+	MediaGrade  *schema.GradingEnum `json:"media_grade,omitempty"`
+	SleeveGrade *schema.GradingEnum `json:"sleeve_grade,omitempty"`
+	// Defaults to today when either grade is changed.
+	DateGraded *schema.Date `json:"date_graded,omitempty"`
+	Notes      *string      `json:"notes,omitempty"`
+
+	PurchasePrice *schema.Money `json:"purchase_price,omitempty"`
+
+	DateSold   *schema.Date `json:"date_sold,omitempty"`
+	DateTraded *schema.Date `json:"date_traded,omitempty"`
+
+	// If set, checks the copy as it is before the update (e.g. against the
+	// ETag of an If-Match header) within the same transaction; an error from
+	// it is returned as is and nothing is changed.
+	Precondition func(schema.Vinyl) error `json:"-"`
+}
+
+// Applies the update to the copy and returns it as updated.  Copies which have
+// been archived cannot be updated (the error wraps ErrArchived).
+func UpdateVinyl(ctx context.Context, db database.Queryer, key schema.VinylKey, update VinylUpdate) (schema.Vinyl, error) {
+	var updated schema.Vinyl
+	err := database.InTx(ctx, db, func(tx *sql.Tx) error {
+		vinyl, err := GetVinyl(ctx, tx, key)
+		if err != nil {
+			return err
+		}
+		if vinyl.DateArchived != nil {
+			return fmt.Errorf("vinyl %s: %w", key, ErrArchived)
+		}
+		if update.Precondition != nil {
+			if err := update.Precondition(vinyl); err != nil {
+				return err
+			}
+		}
+
+		graded := false
+		if update.MediaGrade != nil {
+			vinyl.MediaGrade, graded = *update.MediaGrade, true
+		}
+		if update.SleeveGrade != nil {
+			vinyl.SleeveGrade, graded = *update.SleeveGrade, true
+		}
+		if update.DateGraded != nil {
+			vinyl.DateGraded = update.DateGraded
+		} else if graded {
+			today := schema.Today()
+			vinyl.DateGraded = &today
+		}
+		if update.Notes != nil {
+			vinyl.Notes = *update.Notes
+		}
+		if update.PurchasePrice != nil {
+			vinyl.PurchasePrice = update.PurchasePrice
+		}
+		if update.DateSold != nil {
+			vinyl.DateSold = update.DateSold
+		}
+		if update.DateTraded != nil {
+			vinyl.DateTraded = update.DateTraded
+		}
+		if err := validate_dates(vinyl); err != nil {
+			return err
+		}
+		if err := vinyl.Validate(); err != nil {
+			return err
+		}
+
+		price, currency := purchase_price(vinyl)
+		_, err = tx.ExecContext(ctx, `
+		  UPDATE VinylItems SET
+		    media_grade = ?, sleeve_grade = ?, date_graded = ?, notes = ?,
+		    date_sold = ?, date_traded = ?, purchase_price = ?, purchase_currency = ?
+		  WHERE userID = ? AND versionID = ? AND item = ?`,
+			vinyl.MediaGrade, vinyl.SleeveGrade, vinyl.DateGraded, vinyl.Notes,
+			vinyl.DateSold, vinyl.DateTraded, price, currency,
+			key.UserID, key.VersionID, key.Item)
+		updated = vinyl
+		return err
+	})
+	if err != nil {
+		return schema.Vinyl{}, err
+	}
+	return updated, nil
+}
+
+// Grading, sale and trade dates cannot precede the date the copy was added.
+func validate_dates(vinyl schema.Vinyl) error {
+	invalid := &schema.ValidationError{Typename: vinyl.Typename()}
+	for _, date := range []struct {
+		field string
+		value *schema.Date
+	}{
+		{"date_graded", vinyl.DateGraded},
+		{"date_sold", vinyl.DateSold},
+		{"date_traded", vinyl.DateTraded},
+	} {
+		if date.value != nil && vinyl.DateAdded != nil && date.value.Before(*vinyl.DateAdded) {
+			invalid.Fields = append(invalid.Fields, schema.FieldError{
+				Field: date.field, Message: "cannot be before date_added"})
+		}
+	}
+	if len(invalid.Fields) > 0 {
+		return invalid
+	}
+	return nil
+}
+
+// Removes the copy from the collection.  Copies which were sold, traded, listed
+// or are part of an order are archived instead of deleted, so that the ledger
+// keeps referring to them; returns true in that case.
+func RemoveVinyl(ctx context.Context, db *sql.DB, key schema.VinylKey) (bool, error) {
+	var archived bool
+	err := database.InTx(ctx, db, func(tx *sql.Tx) error {
+		var has_history bool
+		err := tx.QueryRowContext(ctx, `
+		  SELECT date_archived IS NOT NULL
+		    OR date_sold IS NOT NULL OR date_traded IS NOT NULL
+		    OR EXISTS (SELECT 1 FROM Listings
+		               WHERE userID = ?1 AND versionID = ?2 AND item = ?3)
+		    OR EXISTS (SELECT 1 FROM OrderPurchases
+		               WHERE sellerID = ?1 AND versionID = ?2 AND item = ?3)
+		    OR EXISTS (SELECT 1 FROM OrderTrades
+		               WHERE buyerID = ?1 AND versionID = ?2 AND item = ?3)
+		  FROM VinylItems
+		  WHERE userID = ?1 AND versionID = ?2 AND item = ?3`,
+			key.UserID, key.VersionID, key.Item,
+		).Scan(&has_history)
+		if err != nil {
+			return err
+		}
+
+		if !has_history {
+			_, err = tx.ExecContext(ctx, `
+			  DELETE FROM VinylItems WHERE userID = ? AND versionID = ? AND item = ?`,
+				key.UserID, key.VersionID, key.Item)
+			return err
+		}
+		archived = true
+		_, err = tx.ExecContext(ctx, `
+		  UPDATE VinylItems SET date_archived = COALESCE(date_archived, ?)
+		  WHERE userID = ? AND versionID = ? AND item = ?`,
+			schema.Today(), key.UserID, key.VersionID, key.Item)
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("removing vinyl %s: %w", key, err)
+	}
+	return archived, nil
+}
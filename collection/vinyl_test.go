@@ -0,0 +1,244 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/collection/vinyl_test.go
+
+package collection
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/kevindamm/cratedigdb/pagination"
+	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The first page of copies (at most 50) in the default order.
+var first_page = pagination.First(VinylSorts[0])
+
+// Adds three copies for kevin: two of version 1178 (the second is in crate 30
+// and graded NM) and one of 1179 which is tagged "warmup".
+func add_test_vinyl(t *testing.T, db *sql.DB) {
+	ctx := context.Background()
+	_, err := db.Exec(`INSERT INTO Crates (crateID, userID, name, slug) VALUES (30, 42, "Records", "records")`)
+	require.NoError(t, err)
+
+	var allocator ItemAllocator
+	added := schema.NewDate(2025, 1, 2)
+	for _, vinyl := range []schema.Vinyl{
+		{UserID: 42, VersionID: 1178, MediaGrade: schema.GradeVeryGoodPlus, DateAdded: &added},
+		{UserID: 42, VersionID: 1178, MediaGrade: schema.GradeNearMint, CrateID: 30, DateAdded: &added},
+		{UserID: 42, VersionID: 1179, MediaGrade: schema.GradeGood, Notes: "warped"},
+	} {
+		_, err := allocator.Add(ctx, db, vinyl)
+		require.NoError(t, err)
+	}
+
+	_, err = db.Exec(`INSERT INTO TagNames (tagID, userID, name) VALUES (1, 42, "warmup")`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO VinylTagging (userID, versionID, tagID) VALUES (42, 1179, 1)`)
+	require.NoError(t, err)
+}
+
+func TestListVinyl(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	add_test_vinyl(t, db)
+
+	vinyl, page, err := ListVinyl(ctx, db, 42, VinylQuery{Paging: first_page})
+	require.NoError(t, err)
+	assert.Equal(t, 3, page.Total)
+	require.Len(t, vinyl, 3)
+	assert.Equal(t, uint64(1179), vinyl[0].VersionID, "most recently added first")
+	assert.Equal(t, []string{"warmup"}, vinyl[0].Tags)
+	assert.Equal(t, "warped", vinyl[0].Notes)
+	assert.Equal(t, schema.VinylKey{UserID: 42, VersionID: 1178, Item: 1}, vinyl[1].VinylKey())
+	assert.Empty(t, vinyl[1].Tags)
+
+	by_version := pagination.Request{Sort: VinylSorts[1], Limit: 1}
+	vinyl, page, err = ListVinyl(ctx, db, 42, VinylQuery{VersionID: 1178, Paging: by_version})
+	require.NoError(t, err)
+	require.Len(t, vinyl, 1)
+	assert.Equal(t, uint(1), vinyl[0].Item)
+	next, err := pagination.ParseCursor(page.Next)
+	require.NoError(t, err)
+	by_version.Cursor = &next
+	vinyl, page, err = ListVinyl(ctx, db, 42, VinylQuery{VersionID: 1178, Paging: by_version})
+	require.NoError(t, err)
+	assert.Equal(t, 2, page.Total)
+	require.Len(t, vinyl, 1)
+	assert.Equal(t, uint(2), vinyl[0].Item)
+	assert.Empty(t, page.Next)
+
+	vinyl, _, err = ListVinyl(ctx, db, 42, VinylQuery{CrateID: 30, Paging: first_page})
+	require.NoError(t, err)
+	require.Len(t, vinyl, 1)
+	assert.Equal(t, uint64(30), vinyl[0].CrateID)
+	_, page, err = ListVinyl(ctx, db, 42, VinylQuery{Unsorted: true, Paging: first_page})
+	require.NoError(t, err)
+	assert.Equal(t, 2, page.Total)
+
+	filter, err := schema.ParseGradeFilter("media>=VG+")
+	require.NoError(t, err)
+	vinyl, page, err = ListVinyl(ctx, db, 42, VinylQuery{
+		Grades: []schema.GradeFilter{filter}, Paging: first_page})
+	require.NoError(t, err)
+	assert.Equal(t, 2, page.Total)
+	for _, copy := range vinyl {
+		assert.Equal(t, uint64(1178), copy.VersionID)
+	}
+
+	_, page, err = ListVinyl(ctx, db, 43, VinylQuery{Paging: first_page})
+	require.NoError(t, err)
+	assert.Equal(t, 0, page.Total, "only the user's own copies")
+
+	_, _, err = ListVinyl(ctx, db, 42, VinylQuery{Status: "lost", Paging: first_page})
+	assert.Error(t, err)
+	_, _, err = ListVinyl(ctx, db, 42, VinylQuery{
+		Grades: []schema.GradeFilter{{Field: "label", Op: "=", Grade: schema.GradeMint}},
+		Paging: first_page})
+	assert.Error(t, err)
+}
+
+func TestUpdateVinyl(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	add_test_vinyl(t, db)
+	key := schema.VinylKey{UserID: 42, VersionID: 1178, Item: 1}
+
+	grade, notes := schema.GradeVeryGood, "ring wear"
+	vinyl, err := UpdateVinyl(ctx, db, key, VinylUpdate{SleeveGrade: &grade, Notes: &notes})
+	require.NoError(t, err)
+	assert.Equal(t, schema.GradeVeryGoodPlus, vinyl.MediaGrade, "unchanged")
+	assert.Equal(t, schema.GradeVeryGood, vinyl.SleeveGrade)
+	assert.Equal(t, "ring wear", vinyl.Notes)
+	require.NotNil(t, vinyl.DateGraded)
+	assert.Equal(t, schema.Today(), *vinyl.DateGraded)
+
+	stored, err := GetVinyl(ctx, db, key)
+	require.NoError(t, err)
+	assert.Equal(t, vinyl, stored)
+
+	price := schema.NewMoney(2200, schema.CurrencyEUR)
+	vinyl, err = UpdateVinyl(ctx, db, key, VinylUpdate{PurchasePrice: &price})
+	require.NoError(t, err)
+	stored, err = GetVinyl(ctx, db, key)
+	require.NoError(t, err)
+	assert.Equal(t, &price, stored.PurchasePrice)
+	assert.Equal(t, "ring wear", stored.Notes, "unchanged")
+
+	sold := schema.NewDate(2025, 3, 1)
+	_, err = UpdateVinyl(ctx, db, key, VinylUpdate{DateSold: &sold})
+	require.NoError(t, err)
+	_, page, err := ListVinyl(ctx, db, 42, VinylQuery{Status: StatusSold, Paging: first_page})
+	require.NoError(t, err)
+	assert.Equal(t, 1, page.Total)
+	_, page, err = ListVinyl(ctx, db, 42, VinylQuery{Paging: first_page})
+	require.NoError(t, err)
+	assert.Equal(t, 2, page.Total, "sold copies are no longer owned")
+
+	var invalid *schema.ValidationError
+	early := schema.NewDate(2024, 12, 31)
+	_, err = UpdateVinyl(ctx, db, key, VinylUpdate{DateTraded: &early})
+	assert.True(t, errors.As(err, &invalid), err)
+	cover := schema.GradeNoCover
+	_, err = UpdateVinyl(ctx, db, key, VinylUpdate{MediaGrade: &cover})
+	assert.True(t, errors.As(err, &invalid), err)
+
+	_, err = UpdateVinyl(ctx, db, schema.VinylKey{UserID: 42, VersionID: 1178, Item: 9},
+		VinylUpdate{Notes: &notes})
+	assert.True(t, errors.Is(err, database.ErrNotFound), err)
+
+	// A failed precondition sees the copy as stored and leaves it unchanged.
+	stale := errors.New("stale")
+	other := "edited in another tab"
+	_, err = UpdateVinyl(ctx, db, key, VinylUpdate{Notes: &other,
+		Precondition: func(current schema.Vinyl) error {
+			assert.Equal(t, "ring wear", current.Notes)
+			return stale
+		}})
+	assert.ErrorIs(t, err, stale)
+	stored, err = GetVinyl(ctx, db, key)
+	require.NoError(t, err)
+	assert.Equal(t, "ring wear", stored.Notes)
+}
+
+func TestRemoveVinyl(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	add_test_vinyl(t, db)
+
+	// Without any history, the copy is deleted.
+	key := schema.VinylKey{UserID: 42, VersionID: 1179, Item: 1}
+	archived, err := RemoveVinyl(ctx, db, key)
+	require.NoError(t, err)
+	assert.False(t, archived)
+	_, err = GetVinyl(ctx, db, key)
+	assert.True(t, errors.Is(err, database.ErrNotFound), err)
+	_, err = RemoveVinyl(ctx, db, key)
+	assert.True(t, errors.Is(err, database.ErrNotFound), err)
+
+	// A copy that was listed is archived.
+	key = schema.VinylKey{UserID: 42, VersionID: 1178, Item: 2}
+	_, err = db.Exec(`INSERT INTO Listings (userID, versionID, item) VALUES (42, 1178, 2)`)
+	require.NoError(t, err)
+	archived, err = RemoveVinyl(ctx, db, key)
+	require.NoError(t, err)
+	assert.True(t, archived)
+	vinyl, err := GetVinyl(ctx, db, key)
+	require.NoError(t, err)
+	require.NotNil(t, vinyl.DateArchived)
+	assert.Equal(t, schema.Today(), *vinyl.DateArchived)
+
+	_, page, err := ListVinyl(ctx, db, 42, VinylQuery{Paging: first_page})
+	require.NoError(t, err)
+	assert.Equal(t, 1, page.Total)
+	_, page, err = ListVinyl(ctx, db, 42, VinylQuery{Status: StatusArchived, Paging: first_page})
+	require.NoError(t, err)
+	assert.Equal(t, 1, page.Total)
+
+	notes := "found it"
+	_, err = UpdateVinyl(ctx, db, key, VinylUpdate{Notes: &notes})
+	assert.True(t, errors.Is(err, ErrArchived), err)
+
+	// Removing again keeps the original archive date.
+	archived, err = RemoveVinyl(ctx, db, key)
+	require.NoError(t, err)
+	assert.True(t, archived)
+}
+
+func TestUserID(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+
+	userID, err := UserID(ctx, db, "kevin")
+	require.NoError(t, err)
+	assert.Equal(t, uint64(42), userID)
+
+	_, err = UserID(ctx, db, "nobody")
+	assert.True(t, errors.Is(err, database.ErrNotFound), err)
+	_, err = UserID(ctx, db, "unknown")
+	assert.True(t, errors.Is(err, database.ErrNotFound), "banned: %v", err)
+}
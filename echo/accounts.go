@@ -0,0 +1,231 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/echo/accounts.go
+
+package echo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kevindamm/cratedigdb/accounts"
+	"github.com/kevindamm/cratedigdb/schema"
+	"github.com/labstack/echo"
+)
+
+// The cookie which holds the secret of a login session.
+const session_cookie = "cratedig_session"
+
+// Authenticates the request by its bearer token (an Authorization header) or
+// else its session cookie, keeping the account and token in the context for
+// authorize.  Requests without either are anonymous, which is enough to read.
+// A bad bearer token is rejected, but a stale session cookie is only cleared
+// (so that a browser is not locked out of public pages).
+func (server *server) authenticate(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		request := ctx.Request()
+		if header := request.Header.Get(echo.HeaderAuthorization); header != "" {
+			scheme, secret, _ := strings.Cut(header, " ")
+			if !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(secret) == "" {
+				return echo.NewHTTPError(http.StatusUnauthorized,
+					"the Authorization header must be a Bearer token")
+			}
+			account, token, err := accounts.Authenticate(request.Context(), server.db, strings.TrimSpace(secret))
+			if err != nil {
+				return http_error(err)
+			}
+			ctx.Set("account", account)
+			ctx.Set("token", token)
+		} else if cookie, err := ctx.Cookie(session_cookie); err == nil && cookie.Value != "" {
+			account, token, err := accounts.Authenticate(request.Context(), server.db, cookie.Value)
+			if err != nil {
+				clear_session(ctx)
+				if !errors.Is(err, accounts.ErrInvalidToken) {
+					return http_error(err)
+				}
+			} else {
+				ctx.Set("account", account)
+				ctx.Set("token", token)
+			}
+		}
+		return next(ctx)
+	}
+}
+
+// The account and token which authenticated the request, if any.
+func authenticated(ctx echo.Context) (schema.UserAccount, accounts.Token, bool) {
+	account, ok := ctx.Get("account").(schema.UserAccount)
+	token, _ := ctx.Get("token").(accounts.Token)
+	return account, token, ok
+}
+
+// Whether the request is authenticated as the user, who may see their own
+// hidden crates and tags.
+func is_owner(ctx echo.Context, userID uint64) bool {
+	account, _, ok := authenticated(ctx)
+	return ok && account.ID == userID
+}
+
+// Requires an authenticated user whose token has the scope and, for routes
+// with a username, that the user is its owner: only they may change their own
+// collection, listings, orders and tokens.
+func (server *server) authorize(scope accounts.Scope) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			account, token, ok := authenticated(ctx)
+			if !ok {
+				return echo.NewHTTPError(http.StatusUnauthorized, "log in or give a bearer token")
+			}
+			if !token.Allows(scope) {
+				return echo.NewHTTPError(http.StatusForbidden,
+					fmt.Sprintf("the token does not have the %s scope", scope))
+			}
+			if ctx.Param("username") != "" {
+				ownerID, err := server.path_user(ctx)
+				if err != nil {
+					return err
+				}
+				if ownerID != account.ID {
+					return not_owner(ctx.Param("username"))
+				}
+			}
+			return next(ctx)
+		}
+	}
+}
+
+func not_owner(username string) error {
+	return echo.NewHTTPError(http.StatusForbidden, fmt.Sprintf("only %s may make this change", username))
+}
+
+// A username and password, as JSON or a form.
+type credentials struct {
+	Username string `json:"username" form:"username"`
+	Password string `json:"password" form:"password"`
+}
+
+// Logs in with the username and password in the body, starting a session
+// whose secret is set as a secure, HTTP-only cookie.  The cookie is SameSite
+// Lax, so that other sites cannot make changes with it.
+func (server *server) login(ctx echo.Context) error {
+	body := new(credentials)
+	if err := ctx.Bind(body); err != nil {
+		return err
+	}
+	request := ctx.Request().Context()
+	account, err := accounts.Login(request, server.db, body.Username, body.Password)
+	if err != nil {
+		return http_error(err)
+	}
+	secret, token, err := accounts.StartSession(request, server.db, account.ID)
+	if err != nil {
+		return http_error(err)
+	}
+	ctx.SetCookie(&http.Cookie{
+		Name:     session_cookie,
+		Value:    secret,
+		Path:     "/",
+		Expires:  token.Expires.Time,
+		MaxAge:   int(time.Until(token.Expires.Time).Seconds()),
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return respond(ctx, http.StatusOK, "account", account)
+}
+
+// Ends the session of the cookie, if any, and clears the cookie.
+func (server *server) logout(ctx echo.Context) error {
+	if cookie, err := ctx.Cookie(session_cookie); err == nil && cookie.Value != "" {
+		if err := accounts.RevokeSecret(ctx.Request().Context(), server.db, cookie.Value); err != nil {
+			return http_error(err)
+		}
+	}
+	clear_session(ctx)
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func clear_session(ctx echo.Context) {
+	ctx.SetCookie(&http.Cookie{Name: session_cookie, Path: "/", MaxAge: -1,
+		Secure: true, HttpOnly: true, SameSite: http.SameSiteLaxMode})
+}
+
+type token_list struct {
+	Tokens []accounts.Token `json:"tokens"`
+}
+
+// A newly issued token, with the secret which is only shown this once.
+type issued_token struct {
+	Secret string `json:"token"`
+	accounts.Token
+}
+
+// The user's sessions and API tokens (without their secrets).
+func (server *server) listTokens(ctx echo.Context) error {
+	userID, err := server.path_user(ctx)
+	if err != nil {
+		return err
+	}
+	tokens, err := accounts.ListTokens(ctx.Request().Context(), server.db, userID)
+	if err != nil {
+		return http_error(err)
+	}
+	if tokens == nil {
+		tokens = []accounts.Token{}
+	}
+	return respond(ctx, http.StatusOK, "tokens", token_list{tokens})
+}
+
+// Issues an API token with the label, scopes and days until it expires in the
+// body (an accounts.TokenRequest).
+func (server *server) issueToken(ctx echo.Context) error {
+	userID, err := server.path_user(ctx)
+	if err != nil {
+		return err
+	}
+	body := new(accounts.TokenRequest)
+	if err := ctx.Bind(body); err != nil {
+		return err
+	}
+	secret, token, err := accounts.IssueToken(ctx.Request().Context(), server.db, userID, *body)
+	if err != nil {
+		return http_error(err)
+	}
+	return respond(ctx, http.StatusCreated, "issued_token", issued_token{secret, token})
+}
+
+func (server *server) revokeToken(ctx echo.Context) error {
+	userID, err := server.path_user(ctx)
+	if err != nil {
+		return err
+	}
+	tokenID, err := path_id(ctx, "tokenID")
+	if err != nil {
+		return err
+	}
+	if err := accounts.RevokeToken(ctx.Request().Context(), server.db, userID, tokenID); err != nil {
+		return http_error(err)
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
@@ -26,28 +26,83 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/kevindamm/cratedigdb/catalog"
 	"github.com/kevindamm/cratedigdb/schema"
 	"github.com/labstack/echo"
 )
 
+// A page of artists by name (or sorted by "id"), optionally filtered to those
+// whose name starts with the name query parameter.
+func (server *server) listArtists(ctx echo.Context) error {
+	paging, err := page_request(ctx, catalog.ArtistSorts)
+	if err != nil {
+		return err
+	}
+	artists, page, err := catalog.ListArtists(ctx.Request().Context(), server.db,
+		catalog.ArtistQuery{
+			NamePrefix: ctx.QueryParam("name"),
+			Paging:     paging,
+		})
+	if err != nil {
+		return http_error(err)
+	}
+	if err := server.catalog_modified(ctx); err != nil {
+		return err
+	}
+	return page_response(ctx, "artists", "artist", artists, page)
+}
+
 func (server *server) getArtist(ctx echo.Context) error {
-	artist_id := ctx.Param("artist_id")
-	artist, found := server.artists_table[artist_id]
-	if !found {
-		return echo.NewHTTPError(http.StatusNotFound,
-			fmt.Sprintf("artist %s not found", artist_id))
+	artistID, err := path_id(ctx, "artistID")
+	if err != nil {
+		return err
+	}
+	artist, err := catalog.GetArtist(ctx.Request().Context(), server.db, artistID)
+	if err != nil {
+		return http_error(err)
+	}
+	if err := server.catalog_modified(ctx); err != nil {
+		return err
 	}
-	return ctx.JSON(http.StatusOK, artist)
+	return respond(ctx, http.StatusOK, "artist", artist)
 }
 
-func (server *server) addArtist(ctx echo.Context) error {
+// Creates the artist (201 Created) or replaces it (200 OK).  The artistID in
+// the body may be omitted but, if present, must match the path.
+func (server *server) upsertArtist(ctx echo.Context) error {
+	artistID, err := path_id(ctx, "artistID")
+	if err != nil {
+		return err
+	}
 	artist := new(schema.Artist)
 	if err := ctx.Bind(artist); err != nil {
 		return err
 	}
+	if artist.ID == 0 {
+		artist.ID = artistID
+	} else if artist.ID != artistID {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			fmt.Sprintf("artistID %d does not match the path", artist.ID))
+	}
 
-	// TODO pre-check whether artist has already been added.
-	server.artists_table[artist.ID] = artist
+	created, err := catalog.UpsertArtist(ctx.Request().Context(), server.db, *artist)
+	if err != nil {
+		return http_error(err)
+	}
+	if created {
+		return respond(ctx, http.StatusCreated, "artist", artist)
+	}
+	return respond(ctx, http.StatusOK, "artist", artist)
+}
 
-	return ctx.JSON(http.StatusCreated, artist)
+func (server *server) deleteArtist(ctx echo.Context) error {
+	artistID, err := path_id(ctx, "artistID")
+	if err != nil {
+		return err
+	}
+	err = catalog.DeleteArtist(ctx.Request().Context(), server.db, artistID)
+	if err != nil {
+		return http_error(err)
+	}
+	return ctx.NoContent(http.StatusNoContent)
 }
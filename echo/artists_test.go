@@ -18,52 +18,156 @@
 // OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
 // SOFTWARE.
 //
-// github:kevindamm/cratedig/service/artists_test.go
+// github:kevindamm/cratedigdb/echo/artists_test.go
 
-package echo_test
+package echo
 
 import (
+	"encoding/json"
 	"net/http"
-	"net/http/httptest"
 	"strings"
 	"testing"
 
-	"github.com/labstack/echo"
+	"github.com/kevindamm/cratedigdb/pagination"
+	"github.com/kevindamm/cratedigdb/schema"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetArtist(t *testing.T) {
-	// Setup
-	echos := echo.New()
-	recorder := httptest.NewRecorder()
-	request := httptest.NewRequest(http.MethodGet, "/", nil)
-	ctx := echos.NewContext(request, recorder)
-	ctx.SetPath("/artist/:artist_id")
-	ctx.SetParamNames("artist_id")
-	ctx.SetParamValues("1234")
-
-	// Assert
-	handler := TestHandler()
-	if assert.NoError(t, handler.getArtist(ctx)) {
-		assert.Equal(t, http.StatusOK, recorder.Code)
-		assert.Equal(t, ahhMayZing+"\n", recorder.Body.String())
+	server := newTestServer(t)
+
+	response := server.serve(http.MethodGet, "/artist/1234", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, ahhMayZing, response.Body.String())
+
+	response = server.serve(http.MethodGet, "/artist/999", nil)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	response = server.serve(http.MethodGet, "/artist/ahhMayZing", nil)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+func TestListArtists(t *testing.T) {
+	server := newTestServer(t)
+
+	var page struct {
+		Pagination pagination.Page `json:"pagination"`
+		Artists    []struct {
+			ID   uint64 `json:"artistID"`
+			Name string `json:"name"`
+		} `json:"artists"`
+	}
+	response := server.serve(http.MethodGet, "/artist?limit=2", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &page))
+	assert.Equal(t, 3, page.Pagination.Total)
+	assert.Equal(t, "name", page.Pagination.Sort)
+	assert.Empty(t, page.Pagination.Prev)
+	require.NotEmpty(t, page.Pagination.Next)
+	require.Len(t, page.Artists, 2)
+	assert.Equal(t, "ahhMayZing", page.Artists[0].Name)
+	assert.Equal(t, "DJ Kev", page.Artists[1].Name)
+	assert.Equal(t, `<http://example.com/artist?limit=2>; rel="first", `+
+		`<http://example.com/artist?cursor=`+page.Pagination.Next+`&limit=2>; rel="next"`,
+		response.Header().Get("Link"))
+
+	next := page.Pagination.Next
+	page.Pagination = pagination.Page{}
+	response = server.serve(http.MethodGet, "/artist?limit=2&cursor="+next, nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &page))
+	require.Len(t, page.Artists, 1)
+	assert.Equal(t, "Massive Attack", page.Artists[0].Name)
+	assert.Empty(t, page.Pagination.Next)
+	assert.Contains(t, response.Header().Get("Link"), `rel="prev"`)
+
+	response = server.serve(http.MethodGet, "/artist?name=mass&sort=-id", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &page))
+	assert.Equal(t, 1, page.Pagination.Total)
+	require.Len(t, page.Artists, 1)
+	assert.Equal(t, uint64(1300), page.Artists[0].ID)
+
+	for _, query := range []string{"?limit=1000", "?sort=rank", "?cursor=bogus",
+		"?sort=id&cursor=" + next} {
+		response = server.serve(http.MethodGet, "/artist"+query, nil)
+		assert.Equal(t, http.StatusBadRequest, response.Code, query)
 	}
 }
 
-func TestAddArtist(t *testing.T) {
-	// Setup
-	echos := echo.New()
-	recorder := httptest.NewRecorder()
-	request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(ahhMayZing))
-	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
-	ctx := echos.NewContext(request, recorder)
-
-	// Assert
-	handler := TestHandler()
-	if assert.NoError(t, handler.addArtist(ctx)) {
-		assert.Equal(t, http.StatusCreated, recorder.Code)
-		assert.Equal(t, ahhMayZing+"\n", recorder.Body.String())
+func TestListReleases(t *testing.T) {
+	server := newTestServer(t)
+
+	var page struct {
+		Pagination pagination.Page  `json:"pagination"`
+		Releases   []schema.Release `json:"releases"`
 	}
+	response := server.serve(http.MethodGet, "/release?artist=1300", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &page))
+	assert.Equal(t, 1, page.Pagination.Total)
+	require.Len(t, page.Releases, 1)
+	assert.Equal(t, uint64(100), page.Releases[0].ID)
+
+	response = server.serve(http.MethodGet, "/release?title=zzz", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `"releases":[]`)
+
+	response = server.serve(http.MethodGet, "/release?artist=massive", nil)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+func TestUpsertArtist(t *testing.T) {
+	server := newTestServer(t)
+
+	tricky := `{"name":"Tricky","realname":"Adrian Thaws","data_quality":"Needs Vote",` +
+		`"groups":[{"artistID":1300,"name":"Massive Attack"}]}`
+	response := server.serve(http.MethodPost, "/artist/1400", strings.NewReader(tricky))
+	assert.Equal(t, http.StatusCreated, response.Code)
+
+	response = server.serve(http.MethodGet, "/artist/1300", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `"members":[{"artistID":1400,"name":"Tricky"}]`)
+
+	response = server.serve(http.MethodPost, "/artist/1400", strings.NewReader(tricky))
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	response = server.serve(http.MethodPost, "/artist/1401", strings.NewReader(tricky[:20]+"}"))
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	response = server.serve(http.MethodPost, "/artist/1401",
+		strings.NewReader(`{"artistID":1400,"name":"Tricky"}`))
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	response = server.serve(http.MethodPost, "/artist/1401", strings.NewReader(`{"name":""}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	response = server.serve(http.MethodPost, "/artist/1401",
+		strings.NewReader(`{"name":"Daddy G","groups":[{"artistID":99,"name":"?"}]}`))
+	assert.Equal(t, http.StatusConflict, response.Code)
+}
+
+func TestDeleteArtist(t *testing.T) {
+	server := newTestServer(t)
+
+	// Still referred to by ahhMayZing's alias.
+	response := server.serve(http.MethodDelete, "/artist/1235", nil)
+	assert.Equal(t, http.StatusConflict, response.Code)
+
+	response = server.serve(http.MethodDelete, "/artist/1234", nil)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	response = server.serve(http.MethodGet, "/artist/1234", nil)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	response = server.serve(http.MethodDelete, "/artist/1234", nil)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = server.serve(http.MethodDelete, "/artist/1235", nil)
+	assert.Equal(t, http.StatusNoContent, response.Code)
 }
 
-var ahhMayZing = `{"id":"1234","name":"ahhMayZing","profile":"aspiring DJ, sharing my journey with anyone willing to listen 💙"}`
+var ahhMayZing = `{
+  "artistID": 1234,
+  "name": "ahhMayZing",
+  "profile": "aspiring DJ, sharing my journey with anyone willing to listen 💙",
+  "data_quality": "Needs Vote",
+  "namevariations": ["ahhMayZin'"],
+  "aliases": [{"name": "DJ Kev", "artistID": 1235}],
+  "urls": ["https://soundcloud.com/ahhmayzing"]
+}`
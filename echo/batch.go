@@ -0,0 +1,95 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/echo/batch.go
+
+package echo
+
+import (
+	"net/http"
+
+	"github.com/kevindamm/cratedigdb/accounts"
+	"github.com/kevindamm/cratedigdb/batch"
+	"github.com/labstack/echo"
+)
+
+// The body of a batch request, its operations in the order to run them.
+type batch_request struct {
+	Operations []batch.Operation `json:"operations"`
+}
+
+// The outcome of a batch, as batch.Report with the problem of the failed
+// operation (if any).
+type batch_response struct {
+	Committed bool           `json:"committed"`
+	Results   []batch_result `json:"results"`
+}
+
+type batch_result struct {
+	batch.Result
+	Error *problem `json:"error,omitempty"`
+}
+
+// Runs the operations in the body on the user's collection as one
+// transaction (see batch.Run), responding with the result of each.  A failed
+// operation does not fail the request; the response is not committed and has
+// the problem of the operation that failed.
+func (server *server) runBatch(ctx echo.Context) error {
+	userID, err := server.path_user(ctx)
+	if err != nil {
+		return err
+	}
+	body := new(batch_request)
+	if err := ctx.Bind(body); err != nil {
+		return err
+	}
+	if len(body.Operations) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "operations are required")
+	}
+	// The route requires the collection scope; listing copies also needs ledger.
+	if _, token, _ := authenticated(ctx); !token.Allows(accounts.ScopeLedger) {
+		for _, op := range body.Operations {
+			if op.Op == batch.OpList {
+				return echo.NewHTTPError(http.StatusForbidden,
+					"the token does not have the ledger scope, for list operations")
+			}
+		}
+	}
+
+	report, err := batch.Run(ctx.Request().Context(), server.db, &server.items, userID, body.Operations)
+	if err != nil {
+		return http_error(err)
+	}
+	response := batch_response{Committed: report.Committed,
+		Results: make([]batch_result, len(report.Results))}
+	for i, result := range report.Results {
+		response.Results[i] = batch_result{Result: result}
+	}
+	if failed := report.Failed(); failed != nil {
+		// Errors which are not about the operation (e.g. of the database) fail
+		// the whole request.
+		if _, ok := http_error(failed.Err).(*echo.HTTPError); !ok {
+			return failed.Err
+		}
+		details := server.problem_for(failed.Err, ctx)
+		response.Results[failed.Index].Error = &details
+	}
+	return respond(ctx, http.StatusOK, "batch_report", response)
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/echo/batch_test.go
+
+package echo
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kevindamm/cratedigdb/batch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBatch(t *testing.T) {
+	server := newTestServer(t)
+
+	response := server.serve(http.MethodPost, "/batch/kevin", strings.NewReader(`{"operations": [
+	  {"op": "crate", "name": "sale", "crate": {"name": "For Sale"}},
+	  {"op": "add", "name": "copy", "crate_ref": "sale", "vinyl": {"versionID": 1178, "media_grade": "VG"}},
+	  {"op": "tag", "items": [{"ref": "copy"}], "add": ["sell"]},
+	  {"op": "list", "items": [{"ref": "copy"}], "price_low": {"amount": "20.00", "currency": "USD"}}
+	]}`))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	var report batch_response
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &report))
+	assert.True(t, report.Committed)
+	require.Len(t, report.Results, 4)
+	assert.Equal(t, batch.StatusDone, report.Results[3].Status)
+	assert.Equal(t, "for-sale", report.Results[0].Crate.Path)
+	response = server.serve(http.MethodGet, "/listing/kevin/1178/1", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	// A failed operation rolls back the earlier ones, with its problem.
+	response = server.serve(http.MethodPost, "/batch/kevin", strings.NewReader(`{"operations": [
+	  {"op": "add", "name": "copy", "vinyl": {"versionID": 1178}},
+	  {"op": "list", "items": [{"ref": "copy"}, {"versionID": 1178, "item": 1}],
+	   "price_low": {"amount": "20.00", "currency": "USD"}},
+	  {"op": "grade", "items": [{"ref": "copy"}], "media_grade": "NM"}
+	]}`))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	report = batch_response{}
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &report))
+	assert.False(t, report.Committed)
+	assert.Equal(t, batch.StatusRolledBack, report.Results[0].Status)
+	assert.Equal(t, batch.StatusFailed, report.Results[1].Status)
+	assert.Equal(t, batch.StatusSkipped, report.Results[2].Status)
+	require.NotNil(t, report.Results[1].Error)
+	assert.Equal(t, http.StatusConflict, report.Results[1].Error.Status, "item 1 is already listed")
+	response = server.serve(http.MethodGet, "/vinyl/kevin/1178/2", nil)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = server.serve_headers(http.MethodPost, "/batch/kevin",
+		strings.NewReader(`{"operations": [{"op": "move", "crateID": 0, "items": [{"ref": "none"}]}]}`),
+		"Content-Type", "application/json", "HX-Request", "true")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `<section class="batch-report" data-committed="false">`)
+	assert.Contains(t, response.Body.String(), `<code>items[0].ref</code>`)
+
+	response = server.serve(http.MethodPost, "/batch/kevin", strings.NewReader(`{"operations": []}`))
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	response = server.serve(http.MethodPost, "/batch/nobody", strings.NewReader(`{"operations": [{"op": "tag"}]}`))
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/echo/conditional.go
+
+package echo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/kevindamm/cratedigdb/catalog"
+	"github.com/labstack/echo"
+)
+
+// A strong entity tag for the representation, from a hash of its content.
+// The JSON and HTML representations of a resource have different tags.
+func etag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:12]) + `"`
+}
+
+// Sends the body with its ETag or, for a GET (or HEAD) of an unmodified
+// resource (see not_modified), responds 304 Not Modified without it.
+func send(ctx echo.Context, code int, content_type string, body []byte) error {
+	ctx.Response().Header().Set("ETag", etag(body))
+	method := ctx.Request().Method
+	if code == http.StatusOK &&
+		(method == http.MethodGet || method == http.MethodHead) &&
+		not_modified(ctx) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+	return ctx.Blob(code, content_type, body)
+}
+
+// Whether the client's copy is current, by the If-None-Match header (matching
+// the response's ETag) or, only without it, the If-Modified-Since header (not
+// before the response's Last-Modified time, if the handler has set one).
+func not_modified(ctx echo.Context) bool {
+	request, response := ctx.Request().Header, ctx.Response().Header()
+	if tags := request.Get("If-None-Match"); tags != "" {
+		return etag_matches(tags, response.Get("ETag"), false)
+	}
+	since, err := http.ParseTime(request.Get(echo.HeaderIfModifiedSince))
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(response.Get(echo.HeaderLastModified))
+	if err != nil {
+		return false
+	}
+	return !modified.After(since)
+}
+
+// Whether the list of entity tags in an If-Match or If-None-Match header (or
+// "*") includes the tag.  The strong comparison (for If-Match) never matches
+// weak tags; the weak comparison ignores the W/ prefix on either.
+func etag_matches(tags string, tag string, strong bool) bool {
+	if strings.TrimSpace(tags) == "*" {
+		return tag != ""
+	}
+	if !strong {
+		tag = strings.TrimPrefix(tag, "W/")
+	}
+	for _, candidate := range strings.Split(tags, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if !strong {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		} else if strings.HasPrefix(candidate, "W/") {
+			continue
+		}
+		if candidate != "" && candidate == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Checks the If-Match header, if any, against the ETag of the resource's
+// current representation (as negotiated for this request), failing with 412
+// Precondition Failed when the client would overwrite someone else's change.
+func if_match(ctx echo.Context, fragment string, current any) error {
+	tags := ctx.Request().Header.Get("If-Match")
+	if tags == "" {
+		return nil
+	}
+	body, _, err := represent(ctx, fragment, current)
+	if err != nil {
+		return err
+	}
+	if !etag_matches(tags, etag(body), true) {
+		return echo.NewHTTPError(http.StatusPreconditionFailed,
+			"the resource has changed since it was retrieved")
+	}
+	return nil
+}
+
+// Sets the Last-Modified header to the time of the catalog's latest
+// generation, so that clients may revalidate with If-Modified-Since.
+func (server *server) catalog_modified(ctx echo.Context) error {
+	generation, err := catalog.LatestGeneration(ctx.Request().Context(), server.db)
+	if err != nil {
+		return http_error(err)
+	}
+	if !generation.Changed.IsZero() {
+		ctx.Response().Header().Set(echo.HeaderLastModified,
+			generation.Changed.UTC().Format(http.TimeFormat))
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/echo/conditional_test.go
+
+package echo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kevindamm/cratedigdb/catalog"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Serves the request with additional headers, as name-value pairs (which may
+// replace the Authorization as "kevin", or remove it with an empty value).
+func (server *server) serve_headers(method, target string, body io.Reader, headers ...string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, target, body)
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	request.Header.Set("Authorization", "Bearer "+test_token("kevin"))
+	for i := 0; i+1 < len(headers); i += 2 {
+		request.Header.Set(headers[i], headers[i+1])
+	}
+	recorder := httptest.NewRecorder()
+	server.echos.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestETagMatches(t *testing.T) {
+	for _, test := range []struct {
+		tags, tag    string
+		weak, strong bool
+	}{
+		{`"abc"`, `"abc"`, true, true},
+		{`"xyz", "abc"`, `"abc"`, true, true},
+		{`W/"abc"`, `"abc"`, true, false},
+		{`"abc"`, `W/"abc"`, true, false},
+		{`"abc"`, `"abd"`, false, false},
+		{`*`, `"abc"`, true, true},
+		{`*`, ``, false, false},
+		{`""`, ``, false, false},
+	} {
+		assert.Equal(t, test.weak, etag_matches(test.tags, test.tag, false), "%s ~ %s", test.tags, test.tag)
+		assert.Equal(t, test.strong, etag_matches(test.tags, test.tag, true), "%s = %s", test.tags, test.tag)
+	}
+}
+
+func TestIfNoneMatch(t *testing.T) {
+	server := newTestServer(t)
+
+	response := server.serve(http.MethodGet, "/artist/1234", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	tag := response.Header().Get("ETag")
+	assert.Regexp(t, `^"[0-9a-f]{24}"$`, tag)
+	assert.Equal(t, tag, server.serve(http.MethodGet, "/artist/1234", nil).Header().Get("ETag"),
+		"the same content has the same tag")
+
+	response = server.serve_headers(http.MethodGet, "/artist/1234", nil, "If-None-Match", tag)
+	assert.Equal(t, http.StatusNotModified, response.Code)
+	assert.Empty(t, response.Body.String())
+	assert.Equal(t, tag, response.Header().Get("ETag"))
+	response = server.serve_headers(http.MethodGet, "/artist/1234", nil, "If-None-Match", `W/`+tag)
+	assert.Equal(t, http.StatusNotModified, response.Code, "weak comparison")
+	response = server.serve_headers(http.MethodGet, "/artist/1234", nil, "If-None-Match", `"stale"`)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	// The HTML fragment is another representation, with its own tag.
+	response = server.serve_headers(http.MethodGet, "/artist/1234", nil, "HX-Request", "true")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.NotEqual(t, tag, response.Header().Get("ETag"))
+	response = server.serve_headers(http.MethodGet, "/artist/1234", nil,
+		"HX-Request", "true", "If-None-Match", tag)
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	// Pages of lists are tagged too, and the tag changes with the content.
+	response = server.serve(http.MethodGet, "/artist?limit=2", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	page := response.Header().Get("ETag")
+	require.NotEmpty(t, page)
+	response = server.serve_headers(http.MethodGet, "/artist?limit=2", nil, "If-None-Match", page)
+	assert.Equal(t, http.StatusNotModified, response.Code)
+
+	response = server.serve(http.MethodPost, "/artist/1234",
+		strings.NewReader(`{"name": "ahhMayZing", "profile": "touring"}`))
+	require.Equal(t, http.StatusOK, response.Code)
+	response = server.serve_headers(http.MethodGet, "/artist/1234", nil, "If-None-Match", tag)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.NotEqual(t, tag, response.Header().Get("ETag"))
+}
+
+func TestIfModifiedSince(t *testing.T) {
+	server := newTestServer(t)
+
+	// Without any catalog generation, there is no Last-Modified time.
+	response := server.serve(http.MethodGet, "/release/100", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Empty(t, response.Header().Get(echo.HeaderLastModified))
+	response = server.serve_headers(http.MethodGet, "/release/100", nil,
+		echo.HeaderIfModifiedSince, time.Now().UTC().Format(http.TimeFormat))
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	generation, err := catalog.RecordGeneration(context.Background(), server.db, "discogs_20250101")
+	require.NoError(t, err)
+	modified := generation.Changed.UTC().Format(http.TimeFormat)
+	for _, target := range []string{"/release/100", "/record/1178", "/artist/1300"} {
+		response = server.serve(http.MethodGet, target, nil)
+		require.Equal(t, http.StatusOK, response.Code, target)
+		assert.Equal(t, modified, response.Header().Get(echo.HeaderLastModified), target)
+
+		response = server.serve_headers(http.MethodGet, target, nil,
+			echo.HeaderIfModifiedSince, modified)
+		assert.Equal(t, http.StatusNotModified, response.Code, target)
+		response = server.serve_headers(http.MethodGet, target, nil, echo.HeaderIfModifiedSince,
+			generation.Changed.Add(-time.Hour).UTC().Format(http.TimeFormat))
+		assert.Equal(t, http.StatusOK, response.Code, target)
+	}
+
+	// If-None-Match takes precedence over If-Modified-Since.
+	response = server.serve_headers(http.MethodGet, "/release/100", nil,
+		echo.HeaderIfModifiedSince, modified, "If-None-Match", `"stale"`)
+	assert.Equal(t, http.StatusOK, response.Code)
+}
+
+// Two tabs edit the same copy; the second one's update must not overwrite the
+// first one's.
+func TestIfMatch(t *testing.T) {
+	server := newTestServer(t)
+	response := server.serve(http.MethodPost, "/vinyl/kevin/1178", nil)
+	require.Equal(t, http.StatusCreated, response.Code)
+
+	response = server.serve(http.MethodGet, "/vinyl/kevin/1178/1", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	tag := response.Header().Get("ETag")
+
+	response = server.serve_headers(http.MethodPost, "/vinyl/kevin/1178/1",
+		strings.NewReader(`{"notes": "first tab"}`), "If-Match", tag)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `"notes":"first tab"`)
+	updated := response.Header().Get("ETag")
+	assert.NotEqual(t, tag, updated)
+
+	response = server.serve_headers(http.MethodPost, "/vinyl/kevin/1178/1",
+		strings.NewReader(`{"notes": "second tab"}`), "If-Match", tag)
+	assert.Equal(t, http.StatusPreconditionFailed, response.Code)
+	assert.Equal(t, MIMEApplicationProblemJSON, response.Header().Get(echo.HeaderContentType))
+	assert.Contains(t, response.Body.String(), `"type":"/problems/precondition-failed"`)
+
+	response = server.serve(http.MethodGet, "/vinyl/kevin/1178/1", nil)
+	assert.Contains(t, response.Body.String(), `"notes":"first tab"`)
+	assert.Equal(t, updated, response.Header().Get("ETag"), "the update's tag is current")
+
+	response = server.serve_headers(http.MethodPost, "/vinyl/kevin/1178/1",
+		strings.NewReader(`{"notes": "second tab"}`), "If-Match", `W/`+updated)
+	assert.Equal(t, http.StatusPreconditionFailed, response.Code, "strong comparison")
+	response = server.serve_headers(http.MethodPost, "/vinyl/kevin/1178/1",
+		strings.NewReader(`{"notes": "second tab"}`), "If-Match", "*")
+	assert.Equal(t, http.StatusOK, response.Code)
+}
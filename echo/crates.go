@@ -0,0 +1,164 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/echo/crates.go
+
+package echo
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/kevindamm/cratedigdb/collection"
+	"github.com/kevindamm/cratedigdb/schema"
+	"github.com/labstack/echo"
+)
+
+// A page of the user's crates, each following its parent (or sorted by "id",
+// see collection.CrateSorts).  Hidden crates are only listed for their owner.
+func (server *server) listCrates(ctx echo.Context) error {
+	userID, err := server.path_user(ctx)
+	if err != nil {
+		return err
+	}
+	paging, err := page_request(ctx, collection.CrateSorts)
+	if err != nil {
+		return err
+	}
+	crates, page, err := collection.ListCrates(ctx.Request().Context(), server.db, userID,
+		is_owner(ctx, userID), paging)
+	if err != nil {
+		return http_error(err)
+	}
+	return page_response(ctx, "crates", "crate", crates, page)
+}
+
+// Resolves the crate by its slug path, e.g. /crates/kevin/house/deep.  Hidden
+// crates are not found, except by their owner.
+func (server *server) getCrateByPath(ctx echo.Context) error {
+	userID, err := server.path_user(ctx)
+	if err != nil {
+		return err
+	}
+	crate, err := collection.ResolveCratePath(ctx.Request().Context(), server.db, userID,
+		ctx.Param("*"), is_owner(ctx, userID))
+	if err != nil {
+		return http_error(err)
+	}
+	return respond(ctx, http.StatusOK, "crate", crate)
+}
+
+// Creates a crate from the body's name (and optionally slug, parentID, visible
+// and notes).  Crates are visible unless the body says otherwise.
+func (server *server) createCrate(ctx echo.Context) error {
+	userID, err := server.path_user(ctx)
+	if err != nil {
+		return err
+	}
+	crate := &schema.Crate{Visible: true}
+	if err := ctx.Bind(crate); err != nil {
+		return err
+	}
+	crate.UserID = userID
+
+	created, err := collection.CreateCrate(ctx.Request().Context(), server.db, *crate)
+	if err != nil {
+		return http_error(err)
+	}
+	ctx.Response().Header().Set(echo.HeaderLocation,
+		"/crates/"+ctx.Param("username")+"/"+created.Path)
+	return respond(ctx, http.StatusCreated, "crate", created)
+}
+
+// Renames, moves (with parentID, zero for top-level) or otherwise updates the
+// crate; the body is a collection.CrateUpdate.
+func (server *server) updateCrate(ctx echo.Context) error {
+	userID, err := server.path_user(ctx)
+	if err != nil {
+		return err
+	}
+	crateID, err := path_id(ctx, "crateID")
+	if err != nil {
+		return err
+	}
+	update := new(collection.CrateUpdate)
+	if err := ctx.Bind(update); err != nil {
+		return err
+	}
+	crate, err := collection.UpdateCrate(ctx.Request().Context(), server.db, userID, crateID, *update)
+	if err != nil {
+		return http_error(err)
+	}
+	return respond(ctx, http.StatusOK, "crate", crate)
+}
+
+// Deletes the crate and its subcrates; their items become unsorted.
+func (server *server) deleteCrate(ctx echo.Context) error {
+	userID, err := server.path_user(ctx)
+	if err != nil {
+		return err
+	}
+	crateID, err := path_id(ctx, "crateID")
+	if err != nil {
+		return err
+	}
+	if err := collection.DeleteCrate(ctx.Request().Context(), server.db, userID, crateID); err != nil {
+		return http_error(err)
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// Moves the items listed in the body into the crate, or out of any crate when
+// the crateID is 0.  The body is {"items": [{"versionID": 1178, "item": 2}]}.
+func (server *server) moveVinyl(ctx echo.Context) error {
+	userID, err := server.path_user(ctx)
+	if err != nil {
+		return err
+	}
+	crateID, err := strconv.ParseUint(ctx.Param("crateID"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "crateID must be an integer")
+	}
+	body := new(vinyl_items)
+	if err := ctx.Bind(body); err != nil {
+		return err
+	}
+	err = collection.MoveVinyl(ctx.Request().Context(), server.db, userID, crateID, body.keys(userID))
+	if err != nil {
+		return http_error(err)
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// A list of the user's copies in a request body (the user is in the path).
+type vinyl_items struct {
+	Items []struct {
+		VersionID uint64 `json:"versionID"`
+		Item      uint   `json:"item"`
+	} `json:"items"`
+}
+
+func (body vinyl_items) keys(userID uint64) []schema.VinylKey {
+	keys := make([]schema.VinylKey, len(body.Items))
+	for i, item := range body.Items {
+		keys[i] = schema.VinylKey{UserID: userID, VersionID: item.VersionID, Item: item.Item}
+	}
+	return keys
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/echo/crates_test.go
+
+package echo
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kevindamm/cratedigdb/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (server *server) create_crate(t *testing.T, body string) schema.Crate {
+	t.Helper()
+	response := server.serve(http.MethodPost, "/crate/kevin", strings.NewReader(body))
+	require.Equal(t, http.StatusCreated, response.Code, response.Body.String())
+	var crate schema.Crate
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &crate))
+	return crate
+}
+
+func TestCreateCrate(t *testing.T) {
+	server := newTestServer(t)
+
+	house := server.create_crate(t, `{"name":"Deep House"}`)
+	assert.Equal(t, "deep-house", house.Slug)
+	assert.Equal(t, "deep-house", house.Path)
+	assert.True(t, house.Visible)
+	sub := server.create_crate(t, `{"name":"Late Night","parentID":`+house.Key()+`,"visible":false}`)
+	assert.Equal(t, "deep-house/late-night", sub.Path)
+	assert.False(t, sub.Visible)
+
+	response := server.serve(http.MethodPost, "/crate/kevin", strings.NewReader(`{"name":"deep house"}`))
+	assert.Equal(t, http.StatusConflict, response.Code, "sibling with the same slug")
+	response = server.serve(http.MethodPost, "/crate/kevin", strings.NewReader(`{"name":""}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	response = server.serve(http.MethodPost, "/crate/nobody", strings.NewReader(`{"name":"Techno"}`))
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestGetCrateByPath(t *testing.T) {
+	server := newTestServer(t)
+	house := server.create_crate(t, `{"name":"House"}`)
+	server.create_crate(t, `{"name":"Deep","parentID":`+house.Key()+`}`)
+
+	response := server.serve(http.MethodGet, "/crates/kevin/house/deep", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	var crate schema.Crate
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &crate))
+	assert.Equal(t, "Deep", crate.Name)
+	assert.Equal(t, house.ID, crate.ParentID)
+
+	response = server.serve(http.MethodGet, "/crates/kevin/deep", nil)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	var list struct {
+		Crates []schema.Crate `json:"crates"`
+	}
+	response = server.serve(http.MethodGet, "/crates/kevin", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &list))
+	require.Len(t, list.Crates, 2)
+	assert.Equal(t, "house", list.Crates[0].Path)
+	assert.Equal(t, "house/deep", list.Crates[1].Path)
+}
+
+func TestHiddenCrates(t *testing.T) {
+	server := newTestServer(t)
+	house := server.create_crate(t, `{"name":"House"}`)
+	server.create_crate(t, `{"name":"Late Night","parentID":`+house.Key()+`,"visible":false}`)
+
+	crate_paths := func(authorization string) []string {
+		var list struct {
+			Crates []schema.Crate `json:"crates"`
+		}
+		response := server.serve_headers(http.MethodGet, "/crates/kevin", nil, "Authorization", authorization)
+		require.Equal(t, http.StatusOK, response.Code)
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &list))
+		paths := []string{}
+		for _, crate := range list.Crates {
+			paths = append(paths, crate.Path)
+		}
+		return paths
+	}
+	kevin, dana := "Bearer "+test_token("kevin"), "Bearer "+test_token("dana")
+	assert.Equal(t, []string{"house", "house/late-night"}, crate_paths(kevin))
+	assert.Equal(t, []string{"house"}, crate_paths(dana))
+	assert.Equal(t, []string{"house"}, crate_paths(""))
+
+	for authorization, code := range map[string]int{kevin: http.StatusOK, dana: http.StatusNotFound, "": http.StatusNotFound} {
+		response := server.serve_headers(http.MethodGet, "/crates/kevin/house/late-night", nil,
+			"Authorization", authorization)
+		assert.Equal(t, code, response.Code, authorization)
+	}
+}
+
+func TestUpdateCrate(t *testing.T) {
+	server := newTestServer(t)
+	house := server.create_crate(t, `{"name":"House"}`)
+	deep := server.create_crate(t, `{"name":"Deep","parentID":`+house.Key()+`}`)
+
+	response := server.serve(http.MethodPost, "/crate/kevin/"+deep.Key(),
+		strings.NewReader(`{"parentID":0,"name":"Deep House","slug":"deep-house"}`))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	var crate schema.Crate
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &crate))
+	assert.Equal(t, "deep-house", crate.Path)
+
+	// Moving a crate beneath itself would make a cycle.
+	response = server.serve(http.MethodPost, "/crate/kevin/"+house.Key(),
+		strings.NewReader(`{"parentID":`+deep.Key()+`}`))
+	require.Equal(t, http.StatusOK, response.Code)
+	response = server.serve(http.MethodPost, "/crate/kevin/"+deep.Key(),
+		strings.NewReader(`{"parentID":`+house.Key()+`}`))
+	assert.Equal(t, http.StatusConflict, response.Code)
+}
+
+func TestMoveAndDeleteCrate(t *testing.T) {
+	server := newTestServer(t)
+	house := server.create_crate(t, `{"name":"House"}`)
+	response := server.serve(http.MethodPost, "/vinyl/kevin/1178", nil)
+	require.Equal(t, http.StatusCreated, response.Code)
+
+	response = server.serve(http.MethodPost, "/crate/kevin/"+house.Key()+"/vinyl",
+		strings.NewReader(`{"items":[{"versionID":1178,"item":1}]}`))
+	require.Equal(t, http.StatusNoContent, response.Code, response.Body.String())
+	var vinyl schema.Vinyl
+	response = server.serve(http.MethodGet, "/vinyl/kevin/1178/1", nil)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &vinyl))
+	assert.Equal(t, house.ID, vinyl.CrateID)
+
+	response = server.serve(http.MethodPost, "/crate/kevin/"+house.Key()+"/vinyl",
+		strings.NewReader(`{"items":[{"versionID":1178,"item":9}]}`))
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = server.serve(http.MethodDelete, "/crate/kevin/"+house.Key(), nil)
+	require.Equal(t, http.StatusNoContent, response.Code)
+	response = server.serve(http.MethodGet, "/vinyl/kevin/1178/1", nil)
+	vinyl = schema.Vinyl{}
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &vinyl))
+	assert.Zero(t, vinyl.CrateID, "items of a deleted crate become unsorted")
+	response = server.serve(http.MethodDelete, "/crate/kevin/"+house.Key(), nil)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
@@ -0,0 +1,231 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/echo/errors.go
+
+package echo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/kevindamm/cratedigdb/accounts"
+	"github.com/kevindamm/cratedigdb/collection"
+	"github.com/kevindamm/cratedigdb/ledger"
+	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
+	"github.com/labstack/echo"
+)
+
+// Converts errors from the accounts, catalog, collection and ledger packages
+// into an HTTP error with the appropriate status code, keeping the error as
+// its Internal cause (e.g. for the fields of a *schema.ValidationError).
+func http_error(err error) error {
+	var invalid *schema.ValidationError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &invalid):
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, invalid.Error()).SetInternal(err)
+	case errors.Is(err, database.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error()).SetInternal(err)
+	case errors.Is(err, accounts.ErrBadCredentials),
+		errors.Is(err, accounts.ErrInvalidToken):
+		return echo.NewHTTPError(http.StatusUnauthorized, err.Error()).SetInternal(err)
+	case errors.Is(err, accounts.ErrBanned),
+		errors.Is(err, ledger.ErrNotPermitted):
+		return echo.NewHTTPError(http.StatusForbidden, err.Error()).SetInternal(err)
+	case errors.Is(err, database.ErrDuplicate),
+		errors.Is(err, database.ErrReferenced),
+		errors.Is(err, collection.ErrArchived),
+		errors.Is(err, collection.ErrCrateCycle),
+		errors.Is(err, ledger.ErrListed),
+		errors.Is(err, ledger.ErrSold),
+		errors.Is(err, ledger.ErrListingClosed),
+		errors.Is(err, ledger.ErrReserved),
+		errors.Is(err, ledger.ErrInvalidTransition),
+		errors.Is(err, ledger.ErrOrderClosed):
+		return echo.NewHTTPError(http.StatusConflict, err.Error()).SetInternal(err)
+	case errors.Is(err, database.ErrConstraint),
+		errors.Is(err, ledger.ErrNoRate):
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error()).SetInternal(err)
+	}
+	return err
+}
+
+const MIMEApplicationProblemJSON = "application/problem+json"
+
+// The RFC 7807 problem details of a failed request, which is the body of
+// every JSON error response (and the data of the HTML "error" fragment).
+type problem struct {
+	// A URI reference identifying the kind of problem, see problem_types.
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	// The request's path.
+	Instance string `json:"instance,omitempty"`
+	// The X-Request-ID of the request, for finding it in the server's logs.
+	RequestID string `json:"requestID,omitempty"`
+	// Each invalid field of the request body, by its path of JSON names.
+	Errors []schema.FieldError `json:"errors,omitempty"`
+}
+
+// The kinds of problems, by status code.  Other statuses are about:blank,
+// which means the problem is just what the status says.
+var problem_types = map[int]string{
+	http.StatusBadRequest:          "/problems/bad-request",
+	http.StatusUnauthorized:        "/problems/unauthorized",
+	http.StatusForbidden:           "/problems/forbidden",
+	http.StatusNotFound:            "/problems/not-found",
+	http.StatusConflict:            "/problems/conflict",
+	http.StatusPreconditionFailed:  "/problems/precondition-failed",
+	http.StatusUnprocessableEntity: "/problems/validation",
+	http.StatusInternalServerError: "/problems/internal",
+}
+
+// Describes the error as a problem.  Errors which are not HTTP errors (nor
+// mapped to one by http_error) are internal errors, whose details are only
+// shown in debug mode.
+func (server *server) problem_for(err error, ctx echo.Context) problem {
+	he, ok := http_error(err).(*echo.HTTPError)
+	if !ok {
+		he = echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+		if server.debug {
+			he.Message = err.Error()
+		}
+	}
+	details := problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(he.Code),
+		Status:    he.Code,
+		Instance:  ctx.Request().URL.Path,
+		RequestID: ctx.Response().Header().Get(echo.HeaderXRequestID),
+	}
+	if kind, found := problem_types[he.Code]; found {
+		details.Type = kind
+	}
+	if message := fmt.Sprint(he.Message); message != details.Title {
+		details.Detail = message
+	}
+
+	var invalid *schema.ValidationError
+	var mistyped *json.UnmarshalTypeError
+	switch {
+	case errors.As(he.Internal, &invalid):
+		details.Errors = invalid.Fields
+	case errors.As(he.Internal, &mistyped) && mistyped.Field != "":
+		details.Type = problem_types[http.StatusUnprocessableEntity]
+		details.Detail = "the request body has a value of the wrong type"
+		details.Errors = []schema.FieldError{{
+			Field:   field_path(mistyped.Field),
+			Message: fmt.Sprintf("expected %s, got %s", json_type(mistyped.Type), mistyped.Value),
+		}}
+	}
+	return details
+}
+
+// Converts encoding/json's path to a field (e.g. "items.0.versionID") to the
+// form of schema.FieldError paths ("items[0].versionID").
+func field_path(path string) string {
+	var field strings.Builder
+	for i, name := range strings.Split(path, ".") {
+		if _, err := strconv.Atoi(name); err == nil {
+			field.WriteString("[" + name + "]")
+			continue
+		}
+		if i > 0 {
+			field.WriteString(".")
+		}
+		field.WriteString(name)
+	}
+	return field.String()
+}
+
+// The JSON type which decodes into the Go type.
+func json_type(goType reflect.Type) string {
+	for goType.Kind() == reflect.Pointer {
+		goType = goType.Elem()
+	}
+	switch goType.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	}
+	return "object"
+}
+
+// The server's HTTPErrorHandler, responding to every error with its problem
+// details: as application/problem+json or, for requests which prefer HTML
+// (see wants_html), as the "error" fragment.  Internal errors are logged with
+// the request ID, and never stop the server.
+func (server *server) handle_error(err error, ctx echo.Context) {
+	details := server.problem_for(err, ctx)
+	if details.Status >= http.StatusInternalServerError {
+		server.echos.Logger.Errorf("request %s to %s failed: %v",
+			details.RequestID, details.Instance, err)
+	}
+	if ctx.Response().Committed {
+		return
+	}
+
+	ctx.Response().Header().Set("Vary", "Accept, HX-Request")
+	if details.Status == http.StatusUnauthorized {
+		ctx.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="cratedigdb"`)
+	}
+	switch {
+	case ctx.Request().Method == http.MethodHead:
+		err = ctx.NoContent(details.Status)
+	case wants_html(ctx):
+		err = ctx.Render(details.Status, "error", details)
+	default:
+		var body []byte
+		if body, err = json.Marshal(details); err == nil {
+			err = ctx.Blob(details.Status, MIMEApplicationProblemJSON, body)
+		}
+	}
+	if err != nil {
+		server.echos.Logger.Error(err)
+	}
+}
+
+// Parses a numeric ID from the path, responding with 400 Bad Request if the
+// parameter is not a positive integer.
+func path_id(ctx echo.Context, name string) (uint64, error) {
+	id, err := strconv.ParseUint(ctx.Param(name), 10, 64)
+	if err != nil || id == 0 {
+		return 0, echo.NewHTTPError(http.StatusBadRequest,
+			name+" must be a positive integer")
+	}
+	return id, nil
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/echo/errors_test.go
+
+package echo
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/kevindamm/cratedigdb/schema"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Serves the request, expecting a problem+json response with the status.
+func (server *server) serve_problem(t *testing.T, method, target, body string, status int) problem {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	response := server.serve(method, target, reader)
+	require.Equal(t, status, response.Code, response.Body.String())
+	assert.Equal(t, MIMEApplicationProblemJSON, response.Header().Get("Content-Type"))
+
+	var details problem
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &details))
+	assert.Equal(t, status, details.Status)
+	assert.Equal(t, http.StatusText(status), details.Title)
+	assert.Equal(t, strings.SplitN(target, "?", 2)[0], details.Instance)
+	assert.Equal(t, response.Header().Get(echo.HeaderXRequestID), details.RequestID)
+	assert.NotEmpty(t, details.RequestID)
+	return details
+}
+
+func TestProblemNotFound(t *testing.T) {
+	server := newTestServer(t)
+
+	details := server.serve_problem(t, http.MethodGet, "/release/9999", "", http.StatusNotFound)
+	assert.Equal(t, "/problems/not-found", details.Type)
+	assert.Contains(t, details.Detail, "release 9999")
+
+	details = server.serve_problem(t, http.MethodGet, "/nowhere", "", http.StatusNotFound)
+	assert.Equal(t, "/problems/not-found", details.Type)
+	assert.Empty(t, details.Detail)
+
+	// A malformed ID is a bad request, not a crash.
+	details = server.serve_problem(t, http.MethodGet, "/record/oops", "", http.StatusBadRequest)
+	assert.Equal(t, "/problems/bad-request", details.Type)
+	assert.Equal(t, "versionID must be a positive integer", details.Detail)
+
+	details = server.serve_problem(t, http.MethodPut, "/release/100", "", http.StatusMethodNotAllowed)
+	assert.Equal(t, "about:blank", details.Type)
+}
+
+func TestProblemValidation(t *testing.T) {
+	server := newTestServer(t)
+
+	details := server.serve_problem(t, http.MethodPost, "/tag/kevin", `{"name": " "}`,
+		http.StatusUnprocessableEntity)
+	assert.Equal(t, "/problems/validation", details.Type)
+	assert.Equal(t, []schema.FieldError{{Field: "name", Message: "is required"}}, details.Errors)
+
+	// Values of the wrong type are reported by their field path.
+	details = server.serve_problem(t, http.MethodPost, "/crate/kevin/0/vinyl",
+		`{"items": [{"versionID": "1178", "item": 1}]}`, http.StatusBadRequest)
+	assert.Equal(t, "/problems/validation", details.Type)
+	assert.Equal(t, []schema.FieldError{
+		{Field: "items[0].versionID", Message: "expected integer, got string"},
+	}, details.Errors)
+
+	details = server.serve_problem(t, http.MethodPost, "/tag/kevin", `{"name": `, http.StatusBadRequest)
+	assert.Equal(t, "/problems/bad-request", details.Type)
+	assert.Empty(t, details.Errors)
+}
+
+func TestProblemConflict(t *testing.T) {
+	server := newTestServer(t)
+
+	response := server.serve(http.MethodPost, "/tag/kevin", strings.NewReader(`{"name": "warmup"}`))
+	require.Equal(t, http.StatusCreated, response.Code)
+	details := server.serve_problem(t, http.MethodPost, "/tag/kevin", `{"name": "warmup"}`,
+		http.StatusConflict)
+	assert.Equal(t, "/problems/conflict", details.Type)
+	assert.Contains(t, details.Detail, "already exists")
+}
+
+func TestProblemInternal(t *testing.T) {
+	server := newTestServer(t)
+	server.echos.GET("/failing", func(ctx echo.Context) error {
+		return errors.New("the disk is on fire")
+	})
+	server.echos.GET("/panicking", func(ctx echo.Context) error {
+		panic("the disk is on fire")
+	})
+
+	for _, target := range []string{"/failing", "/panicking"} {
+		details := server.serve_problem(t, http.MethodGet, target, "", http.StatusInternalServerError)
+		assert.Equal(t, "/problems/internal", details.Type)
+		assert.Empty(t, details.Detail, "internal errors are not shown outside debug mode")
+	}
+
+	// The request's own ID is kept.
+	request := httptest.NewRequest(http.MethodGet, "/failing", nil)
+	request.Header.Set(echo.HeaderXRequestID, "req-1234")
+	response := httptest.NewRecorder()
+	server.echos.ServeHTTP(response, request)
+	assert.Contains(t, response.Body.String(), `"requestID":"req-1234"`)
+
+	server.debug = true
+	details := server.serve_problem(t, http.MethodGet, "/failing", "", http.StatusInternalServerError)
+	assert.Equal(t, "the disk is on fire", details.Detail)
+}
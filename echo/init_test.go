@@ -18,29 +18,81 @@
 // OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
 // SOFTWARE.
 //
-// github:kevindamm/cratedig/service/testutil.go
+// github:kevindamm/cratedigdb/echo/init_test.go
 
-package echo_test
+package echo
 
 import (
-	"github.com/kevindamm/cratedigdb/echo"
-	"github.com/kevindamm/cratedigdb/schema"
+	"context"
+	"crypto/sha256"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
-func TestHandler() *echo.Server {
-	// this will be updated as we incrementally upgrade the backing store.
-	server := echo.NewInMemoryHandler(0, true)
+// A server with its API routes, backed by an in-memory database holding a few
+// artists, a release and the users "kevin" (userID 42, a curator) and "dana"
+// (userID 43), each with a session (see test_token).  This will be updated as
+// the API covers more of the backing store.
+func newTestServer(t *testing.T) *server {
+	server, err := NewInMemoryHandler(0, false)
+	require.NoError(t, err)
+	t.Cleanup(func() { server.db.Close() })
 	server.RegisterAPIRoutes()
 
-	server.artists_table = map[uint64]*schema.Artist{
-		1234: {
-			ID:      1234,
-			Name:    "ahhMayZing",
-			Profile: "aspiring DJ, sharing my journey with anyone willing to listen 💙",
-		},
+	tx, err := server.db.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	for _, statement := range []string{
+		`INSERT INTO UserAccounts (userID, username, is_curator)
+		   VALUES (42, "kevin", TRUE), (43, "dana", FALSE)`,
+		`INSERT INTO Artists (artistID, name, profile) VALUES
+		   (1234, "ahhMayZing", "aspiring DJ, sharing my journey with anyone willing to listen 💙"),
+		   (1235, "DJ Kev", ""),
+		   (1300, "Massive Attack", "")`,
+		`INSERT INTO Artist_Names (artistID, name) VALUES (1234, "ahhMayZin'")`,
+		`INSERT INTO Artist_Alias (artistID, alias_name, alias_artist_id)
+		   VALUES (1234, "DJ Kev", 1235)`,
+		`INSERT INTO Artist_URLs (artistID, url) VALUES (1234, "https://soundcloud.com/ahhmayzing")`,
+		`INSERT INTO Releases (releaseID, title, main_version) VALUES (100, "Blue Lines", 1178)`,
+		`INSERT INTO ReleaseVersions (versionID, releaseID, title) VALUES (1178, 100, "Blue Lines")`,
+		`INSERT INTO Release_Artists (releaseID, artistID) VALUES (100, 1300)`,
+		`INSERT INTO Tracks (trackID, versionID, track_number, title, duration)
+		   VALUES (5001, 1178, 1, "Safe From Harm", "5:18")`,
+		`INSERT INTO Track_Artists (trackID, artistID, is_extra, role)
+		   VALUES (5001, 1234, TRUE, "Remix")`,
+	} {
+		_, err := tx.Exec(statement)
+		require.NoError(t, err)
 	}
-	server.releases_table = make(map[uint64]*schema.Release)
-	server.versions_table = make(map[uint64]*schema.ReleaseVersion)
-
+	for userID, username := range map[uint64]string{42: "kevin", 43: "dana"} {
+		hash := sha256.Sum256([]byte(test_token(username)))
+		_, err := tx.Exec(`
+		  INSERT INTO User_Tokens (userID, token_hash, kind, scopes, date_expires)
+		  VALUES (?, ?, "session", "catalog collection ledger account", "2100-01-01 00:00:00")`,
+			userID, hash[:])
+		require.NoError(t, err)
+	}
+	require.NoError(t, tx.Commit())
 	return server
 }
+
+// The secret of the test user's session, which newTestServer adds.
+func test_token(username string) string {
+	return "test-session-" + username
+}
+
+// Routes the request through the server, authenticated as "kevin", returning
+// the recorded response.
+func (server *server) serve(method, target string, body io.Reader) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, target, body)
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	request.Header.Set("Authorization", "Bearer "+test_token("kevin"))
+	recorder := httptest.NewRecorder()
+	server.echos.ServeHTTP(recorder, request)
+	return recorder
+}
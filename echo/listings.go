@@ -21,3 +21,140 @@
 // github:kevindamm/cratedig/echo/listings.go
 
 package echo
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/kevindamm/cratedigdb/ledger"
+	"github.com/kevindamm/cratedigdb/schema"
+	"github.com/labstack/echo"
+)
+
+// A page of the seller's listings, most recently opened first (or sorted by
+// "version", see ledger.ListingSorts).  The query may filter by status (open,
+// closed or all), release, version and currency.
+func (server *server) listListings(ctx echo.Context) error {
+	userID, err := server.path_user(ctx)
+	if err != nil {
+		return err
+	}
+	paging, err := page_request(ctx, ledger.ListingSorts)
+	if err != nil {
+		return err
+	}
+	query := ledger.ListingQuery{Paging: paging}
+
+	bad_request := func(message string) error {
+		return echo.NewHTTPError(http.StatusBadRequest, message)
+	}
+	if query.Status, err = ledger.ParseListingStatus(ctx.QueryParam("status")); err != nil {
+		return bad_request(err.Error())
+	}
+	if value := ctx.QueryParam("release"); value != "" {
+		if query.ReleaseID, err = strconv.ParseUint(value, 10, 64); err != nil {
+			return bad_request("release must be a releaseID")
+		}
+	}
+	if value := ctx.QueryParam("version"); value != "" {
+		if query.VersionID, err = strconv.ParseUint(value, 10, 64); err != nil {
+			return bad_request("version must be a versionID")
+		}
+	}
+	if value := ctx.QueryParam("currency"); value != "" {
+		if query.Currency, err = schema.ParseCurrency(value); err != nil {
+			return bad_request(err.Error())
+		}
+	}
+
+	listings, page, err := ledger.ListListings(ctx.Request().Context(), server.db, userID, query)
+	if err != nil {
+		return http_error(err)
+	}
+	return page_response(ctx, "listings", "listing", listings, page)
+}
+
+func (server *server) getListing(ctx echo.Context) error {
+	key, err := server.path_vinyl_key(ctx)
+	if err != nil {
+		return err
+	}
+	listing, err := ledger.GetListing(ctx.Request().Context(), server.db, key)
+	if err != nil {
+		return http_error(err)
+	}
+	return respond(ctx, http.StatusOK, "listing", listing)
+}
+
+// The copy's earlier listings, closed before it was listed again (404 Not
+// Found if it has never been listed).
+func (server *server) getListingHistory(ctx echo.Context) error {
+	key, err := server.path_vinyl_key(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := ledger.GetListing(ctx.Request().Context(), server.db, key); err != nil {
+		return http_error(err)
+	}
+	listings, err := ledger.ListingHistory(ctx.Request().Context(), server.db, key)
+	if err != nil {
+		return http_error(err)
+	}
+	return respond(ctx, http.StatusOK, "listing_history", listing_history{listings})
+}
+
+type listing_history struct {
+	Listings []schema.Listing `json:"listings"`
+}
+
+// Lists the copy in the path for sale; the body has the price_low and/or
+// price_high (e.g. {"amount": "25.00", "currency": "USD"}) and allow_offers.
+func (server *server) openListing(ctx echo.Context) error {
+	key, err := server.path_vinyl_key(ctx)
+	if err != nil {
+		return err
+	}
+	listing := new(schema.Listing)
+	if err := ctx.Bind(listing); err != nil {
+		return err
+	}
+	listing.UserID, listing.VersionID, listing.Item = key.UserID, key.VersionID, key.Item
+
+	opened, err := ledger.OpenListing(ctx.Request().Context(), server.db, *listing)
+	if err != nil {
+		return http_error(err)
+	}
+	return respond(ctx, http.StatusCreated, "listing", opened)
+}
+
+// Updates the prices or allow_offers of the open listing; the body is a
+// ledger.ListingUpdate where omitted fields are unchanged.
+func (server *server) updateListing(ctx echo.Context) error {
+	key, err := server.path_vinyl_key(ctx)
+	if err != nil {
+		return err
+	}
+	update := new(ledger.ListingUpdate)
+	if err := ctx.Bind(update); err != nil {
+		return err
+	}
+	listing, err := ledger.UpdateListing(ctx.Request().Context(), server.db, key, *update)
+	if err != nil {
+		return http_error(err)
+	}
+	return respond(ctx, http.StatusOK, "listing", listing)
+}
+
+// Closes the listing, responding with the closed listing (which is kept for
+// the seller's history).
+func (server *server) closeListing(ctx echo.Context) error {
+	key, err := server.path_vinyl_key(ctx)
+	if err != nil {
+		return err
+	}
+	listing, err := ledger.CloseListing(ctx.Request().Context(), server.db, key)
+	if err != nil {
+		return http_error(err)
+	}
+	return respond(ctx, http.StatusOK, "listing", listing)
+}
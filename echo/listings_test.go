@@ -0,0 +1,108 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/echo/listings_test.go
+
+package echo
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kevindamm/cratedigdb/pagination"
+	"github.com/kevindamm/cratedigdb/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListings(t *testing.T) {
+	server := newTestServer(t)
+	for range 2 {
+		response := server.serve(http.MethodPost, "/vinyl/kevin/1178", nil)
+		require.Equal(t, http.StatusCreated, response.Code)
+	}
+
+	response := server.serve(http.MethodPost, "/listing/kevin/1178/1", strings.NewReader(
+		`{"price_low":{"amount":"20.00","currency":"USD"},"price_high":{"amount":"25.00","currency":"USD"},"allow_offers":true}`))
+	require.Equal(t, http.StatusCreated, response.Code, response.Body.String())
+	var listing schema.Listing
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &listing))
+	assert.Equal(t, schema.NewMoney(2500, schema.CurrencyUSD), *listing.PriceHigh)
+	assert.True(t, listing.AllowOffers)
+
+	response = server.serve(http.MethodPost, "/listing/kevin/1178/1",
+		strings.NewReader(`{"price_low":{"amount":"1.00","currency":"USD"}}`))
+	assert.Equal(t, http.StatusConflict, response.Code, "already listed")
+	response = server.serve(http.MethodPost, "/listing/kevin/1178/2", strings.NewReader(`{}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code, "no price")
+	response = server.serve(http.MethodPost, "/listing/kevin/1178/3",
+		strings.NewReader(`{"price_low":{"amount":"1.00","currency":"USD"}}`))
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = server.serve(http.MethodPatch, "/listing/kevin/1178/1",
+		strings.NewReader(`{"allow_offers":false}`))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	listing = schema.Listing{}
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &listing))
+	assert.False(t, listing.AllowOffers)
+	assert.Equal(t, schema.NewMoney(2000, schema.CurrencyUSD), *listing.PriceLow)
+
+	var page struct {
+		Pagination pagination.Page  `json:"pagination"`
+		Listings   []schema.Listing `json:"listings"`
+	}
+	response = server.serve(http.MethodGet, "/listings/kevin", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &page))
+	assert.Equal(t, 1, page.Pagination.Total)
+
+	response = server.serve(http.MethodDelete, "/listing/kevin/1178/1", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	response = server.serve(http.MethodDelete, "/listing/kevin/1178/1", nil)
+	assert.Equal(t, http.StatusConflict, response.Code, "already closed")
+	response = server.serve(http.MethodPatch, "/listing/kevin/1178/1",
+		strings.NewReader(`{"allow_offers":true}`))
+	assert.Equal(t, http.StatusConflict, response.Code)
+
+	response = server.serve(http.MethodGet, "/listings/kevin?status=closed", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &page))
+	require.Len(t, page.Listings, 1)
+	assert.NotNil(t, page.Listings[0].DateClosed)
+	response = server.serve(http.MethodGet, "/listings/kevin?status=pending", nil)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	// Listed again, the closed listing is kept in the copy's history.
+	response = server.serve(http.MethodPost, "/listing/kevin/1178/1",
+		strings.NewReader(`{"price_low":{"amount":"18.00","currency":"USD"}}`))
+	require.Equal(t, http.StatusCreated, response.Code, response.Body.String())
+	var history struct {
+		Listings []schema.Listing `json:"listings"`
+	}
+	response = server.serve(http.MethodGet, "/listing/kevin/1178/1/history", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &history))
+	require.Len(t, history.Listings, 1)
+	assert.Equal(t, schema.NewMoney(2000, schema.CurrencyUSD), *history.Listings[0].PriceLow)
+	assert.NotNil(t, history.Listings[0].DateClosed)
+	response = server.serve(http.MethodGet, "/listing/kevin/1178/2/history", nil)
+	assert.Equal(t, http.StatusNotFound, response.Code, "never listed")
+}
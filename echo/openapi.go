@@ -0,0 +1,390 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/echo/openapi.go
+
+package echo
+
+import (
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/kevindamm/cratedigdb/accounts"
+	"github.com/kevindamm/cratedigdb/pagination"
+	"github.com/kevindamm/cratedigdb/schema"
+	"github.com/labstack/echo"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+// Describes a route for the OpenAPI document.  The request and response
+// bodies are given by a value of their Go type, whose schema is generated
+// from its json fields (see schema.SchemaGenerator).
+type operation struct {
+	Summary string
+	// The operationId, which defaults to the name of the route's handler.
+	ID string
+	// Groups the operation in the docs, by default the first segment of the
+	// path (without a plural "s").
+	Tag string
+
+	// Query parameters.  Lists with Sorts also take the sort, limit and
+	// cursor parameters (see pagination.Parse).
+	Query []query_param
+	Sorts pagination.Sorts
+
+	Request         any
+	RequestOptional bool
+	// The body of a successful response, a paged list, an html_page, a
+	// json_document or a static_file, or nil for 204 No Content.
+	Response any
+	// The status of a successful response, when it is not 200 OK (or 204 No
+	// Content without a Response).  Another status may also succeed, with the
+	// same body (or without one, if it is 204 No Content).
+	Status    int
+	Alternate int
+	// Whether the request may have an If-Match header (see if_match).
+	IfMatch bool
+	// If set, the request must be authenticated with a session or token which
+	// has the scope, as the owner of the path's username (see authorize).
+	Scope accounts.Scope
+}
+
+type query_param struct {
+	Name        string
+	Description string
+	// A JSON Schema type for the value, "string" if empty.
+	Type     string
+	Repeated bool
+}
+
+// A page of a list, as written by page_response: the items under the name,
+// each with the same type as the Item value.
+type paged struct {
+	Name string
+	Item any
+}
+
+// A whole HTML page (rather than JSON or a fragment of the resource).
+type html_page struct{}
+
+// A JSON object which is not a resource (and has no HTML fragment).
+type json_document struct{}
+
+// A static file, such as a script or stylesheet of the docs page.
+type static_file struct{}
+
+// A request body of rows, as CSV (with a header naming the columns) or as JSON
+// lines, each row with the fields of the Row value.
+type import_body struct {
+	Row any
+}
+
+// Adds the route, along with its description for the OpenAPI document.
+func (server *server) route(method, path string, handler echo.HandlerFunc, op operation) {
+	var middleware []echo.MiddlewareFunc
+	if op.Scope != "" {
+		middleware = append(middleware, server.authorize(op.Scope))
+	}
+	route := server.echos.Add(method, path, handler, middleware...)
+	if op.ID == "" {
+		// Handler names are e.g. "github.com/.../echo.(*server).getArtist-fm".
+		name := route.Name[strings.LastIndex(route.Name, ".")+1:]
+		op.ID = strings.TrimSuffix(name, "-fm")
+	}
+	if op.Tag == "" {
+		segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+		op.Tag = strings.TrimSuffix(segment, "s")
+	}
+	server.operations[method+" "+path] = op
+}
+
+// The OpenAPI 3.1 document describing every route.  It is an error if any
+// route was added without its operation (i.e. not with server.route).
+func (server *server) openapi() (map[string]any, error) {
+	generator := schema.NewSchemaGenerator("#/components/schemas/")
+	paths := make(map[string]map[string]any)
+	var undocumented []string
+	for _, route := range server.echos.Routes() {
+		op, found := server.operations[route.Method+" "+route.Path]
+		if !found {
+			undocumented = append(undocumented, route.Method+" "+route.Path)
+			continue
+		}
+		path, parameters := openapi_path(route.Path)
+		for _, param := range op.query_params() {
+			parameters = append(parameters, param.openapi())
+		}
+		if op.IfMatch {
+			parameters = append(parameters, map[string]any{
+				"name": "If-Match", "in": "header", "schema": schema.JSONSchema{"type": "string"},
+				"description": "the ETag of the resource when it was retrieved, " +
+					"so that the request fails rather than overwrite a later change",
+			})
+		}
+		document := map[string]any{
+			"operationId": op.ID,
+			"tags":        []string{op.Tag},
+			"responses":   op.responses(route.Method, generator),
+		}
+		if op.Summary != "" {
+			document["summary"] = op.Summary
+		}
+		if op.Scope != "" {
+			document["security"] = []map[string][]string{
+				{"bearer": {string(op.Scope)}}, {"session": {}}}
+		}
+		if len(parameters) > 0 {
+			document["parameters"] = parameters
+		}
+		if rows, ok := op.Request.(import_body); ok {
+			document["requestBody"] = map[string]any{
+				"required": true,
+				"content": map[string]any{
+					"text/csv":          map[string]any{"schema": schema.JSONSchema{"type": "string"}},
+					"application/jsonl": map[string]any{"schema": generator.Of(rows.Row)},
+				},
+			}
+		} else if op.Request != nil {
+			document["requestBody"] = map[string]any{
+				"required": !op.RequestOptional,
+				"content": map[string]any{
+					echo.MIMEApplicationJSON: map[string]any{"schema": generator.Of(op.Request)},
+				},
+			}
+		}
+		if paths[path] == nil {
+			paths[path] = make(map[string]any)
+		}
+		paths[path][strings.ToLower(route.Method)] = document
+	}
+	if len(undocumented) > 0 {
+		slices.Sort(undocumented)
+		return nil, fmt.Errorf("routes without an OpenAPI operation: %s",
+			strings.Join(undocumented, ", "))
+	}
+
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":   "cratedigdb",
+			"version": "0.1.0",
+			"description": "The catalog of releases, users' vinyl collections and their " +
+				"marketplace ledger.  Every resource is served as JSON or, for HTMX " +
+				"requests (or when the Accept header prefers it), as an HTML fragment.",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": generator.Definitions,
+			"securitySchemes": map[string]any{
+				"bearer": map[string]any{"type": "http", "scheme": "bearer",
+					"description": "an API token, which has the scopes it was issued with"},
+				"session": map[string]any{"type": "apiKey", "in": "cookie", "name": session_cookie,
+					"description": "a login session (see /session), which has every scope (catalog only for curators)"},
+			},
+			"responses": map[string]any{
+				"Error": map[string]any{
+					"description": "The request failed, see its RFC 7807 problem details.",
+					"content": map[string]any{
+						MIMEApplicationProblemJSON: map[string]any{"schema": generator.Of(problem{})},
+						echo.MIMETextHTML:          html_fragment,
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+var html_fragment = map[string]any{"schema": schema.JSONSchema{"type": "string"}}
+
+// Converts the echo path's parameters (:name, or * for the rest of the path)
+// to OpenAPI's {name}, and describes them.  IDs and item numbers are integers.
+func openapi_path(path string) (string, []map[string]any) {
+	segments := strings.Split(path, "/")
+	var parameters []map[string]any
+	for i, segment := range segments {
+		var name string
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			name = segment[1:]
+		case segment == "*":
+			name = "path"
+		default:
+			continue
+		}
+		segments[i] = "{" + name + "}"
+		value := schema.JSONSchema{"type": "string"}
+		if strings.HasSuffix(name, "ID") || name == "item" {
+			value = schema.JSONSchema{"type": "integer", "minimum": 1}
+		}
+		parameters = append(parameters, map[string]any{
+			"name": name, "in": "path", "required": true, "schema": value,
+		})
+	}
+	return strings.Join(segments, "/"), parameters
+}
+
+func (op operation) query_params() []query_param {
+	params := op.Query
+	if len(op.Sorts) == 0 {
+		return params
+	}
+	names := make([]string, len(op.Sorts))
+	for i, sort := range op.Sorts {
+		names[i] = sort.Name
+	}
+	return append(slices.Clone(params),
+		query_param{Name: "sort", Description: fmt.Sprintf(
+			"one of %s (default %s), prefixed with - to reverse it",
+			strings.Join(names, ", "), names[0])},
+		query_param{Name: "limit", Type: "integer", Description: fmt.Sprintf(
+			"the page size, at most %d (default %d)", pagination.MaxLimit, pagination.DefaultLimit)},
+		query_param{Name: "cursor", Description: "the next or prev cursor of a page"})
+}
+
+func (param query_param) openapi() map[string]any {
+	value := schema.JSONSchema{"type": param.Type}
+	if param.Type == "" {
+		value["type"] = "string"
+	}
+	if param.Repeated {
+		value = schema.JSONSchema{"type": "array", "items": value}
+	}
+	document := map[string]any{"name": param.Name, "in": "query", "schema": value}
+	if param.Description != "" {
+		document["description"] = param.Description
+	}
+	return document
+}
+
+func (op operation) responses(method string, generator *schema.SchemaGenerator) map[string]any {
+	responses := map[string]any{
+		"default": map[string]any{"$ref": "#/components/responses/Error"},
+	}
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+		if op.Response == nil {
+			status = http.StatusNoContent
+		}
+	}
+
+	response := map[string]any{"description": http.StatusText(status)}
+	etag := map[string]any{"ETag": map[string]any{
+		"description": "a strong entity tag of the representation, for If-None-Match and If-Match",
+		"schema":      schema.JSONSchema{"type": "string"},
+	}}
+	switch body := op.Response.(type) {
+	case nil:
+	case html_page:
+		response["content"] = map[string]any{echo.MIMETextHTML: html_fragment}
+	case json_document:
+		response["content"] = map[string]any{echo.MIMEApplicationJSON: map[string]any{
+			"schema": schema.JSONSchema{"type": "object"}}}
+	case static_file:
+		response["headers"] = etag
+		response["content"] = map[string]any{"*/*": map[string]any{
+			"schema": schema.JSONSchema{"type": "string"}}}
+	case paged:
+		response["headers"] = map[string]any{"ETag": etag["ETag"], "Link": map[string]any{
+			"description": "links to the first, prev and next pages (RFC 8288)",
+			"schema":      schema.JSONSchema{"type": "string"},
+		}}
+		response["content"] = map[string]any{
+			echo.MIMEApplicationJSON: map[string]any{"schema": schema.JSONSchema{
+				"type": "object",
+				"properties": map[string]any{
+					"pagination": generator.Of(pagination.Page{}),
+					body.Name:    schema.JSONSchema{"type": "array", "items": generator.Of(body.Item)},
+				},
+				"required": []string{"pagination", body.Name},
+			}},
+			echo.MIMETextHTML: html_fragment,
+		}
+	default:
+		response["headers"] = etag
+		response["content"] = map[string]any{
+			echo.MIMEApplicationJSON: map[string]any{"schema": generator.Of(body)},
+			echo.MIMETextHTML:        html_fragment,
+		}
+	}
+	responses[fmt.Sprint(status)] = response
+	if _, tagged := response["headers"]; tagged && method == http.MethodGet {
+		responses[fmt.Sprint(http.StatusNotModified)] = map[string]any{
+			"description": "Not Modified since the If-None-Match ETag (or If-Modified-Since)"}
+	}
+	if op.IfMatch {
+		responses[fmt.Sprint(http.StatusPreconditionFailed)] = map[string]any{
+			"$ref": "#/components/responses/Error"}
+	}
+
+	if op.Alternate == http.StatusNoContent {
+		responses[fmt.Sprint(op.Alternate)] = map[string]any{
+			"description": http.StatusText(op.Alternate)}
+	} else if op.Alternate != 0 {
+		alternate := make(map[string]any)
+		for key, value := range response {
+			alternate[key] = value
+		}
+		alternate["description"] = http.StatusText(op.Alternate)
+		responses[fmt.Sprint(op.Alternate)] = alternate
+	}
+	return responses
+}
+
+// The OpenAPI document, as JSON.
+func (server *server) getOpenAPI(ctx echo.Context) error {
+	document, err := server.openapi()
+	if err != nil {
+		return err
+	}
+	return ctx.JSON(http.StatusOK, document)
+}
+
+// Interactive docs for the OpenAPI document.
+func (server *server) getDocs(ctx echo.Context) error {
+	return ctx.Render(http.StatusOK, "docs", nil)
+}
+
+// The assets of the docs page which it loads from the server, rather than
+// from a CDN, so that the docs work offline and always at the same version.
+var docs_assets = map[string]bool{
+	"swagger-ui.css":       true,
+	"swagger-ui-bundle.js": true,
+	"favicon-32x32.png":    true,
+}
+
+// A script, stylesheet or image of the docs page, from the build of
+// swagger-ui-dist which github.com/swaggo/files embeds.
+func (server *server) getDocsAsset(ctx echo.Context) error {
+	name := ctx.Param("asset")
+	if !docs_assets[name] {
+		return echo.NewHTTPError(http.StatusNotFound, "no such asset: "+name)
+	}
+	body, err := fs.ReadFile(swaggerFiles.FS, name)
+	if err != nil {
+		return err
+	}
+	return send(ctx, http.StatusOK, mime.TypeByExtension(path.Ext(name)), body)
+}
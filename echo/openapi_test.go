@@ -0,0 +1,181 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/echo/openapi_test.go
+
+package echo
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Every route must be added with its operation, including the schemas of its
+// request and response bodies.
+func TestOpenAPIDocumentsEveryRoute(t *testing.T) {
+	server := newTestServer(t)
+
+	for _, route := range server.echos.Routes() {
+		key := route.Method + " " + route.Path
+		op, found := server.operations[key]
+		if !assert.True(t, found, "%s has no OpenAPI operation, add it with server.route", key) {
+			continue
+		}
+		assert.NotEmpty(t, op.Summary, key)
+		if op.Response == nil {
+			assert.Contains(t, []int{0, http.StatusNoContent}, op.Status,
+				"%s has no response schema", key)
+		}
+		switch route.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+			assert.NotNil(t, op.Request, "%s has no request schema", key)
+		}
+	}
+	_, err := server.openapi()
+	assert.NoError(t, err)
+
+	server.echos.GET("/undocumented", func(ctx echo.Context) error { return nil })
+	_, err = server.openapi()
+	assert.ErrorContains(t, err, "GET /undocumented")
+}
+
+func TestGetOpenAPI(t *testing.T) {
+	server := newTestServer(t)
+
+	response := server.serve(http.MethodGet, "/openapi.json", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	var document map[string]any
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &document))
+	assert.Equal(t, "3.1.0", document["openapi"])
+
+	paths := document["paths"].(map[string]any)
+	routed := make(map[string]any)
+	for _, route := range server.echos.Routes() {
+		path, _ := openapi_path(route.Path)
+		routed[path] = true
+	}
+	assert.ElementsMatch(t, keys(routed), keys(paths), "a path for each route")
+	artist := paths["/artist/{artistID}"].(map[string]any)
+	assert.ElementsMatch(t, []string{"get", "post", "delete"}, keys(artist))
+	assert.Contains(t, paths, "/crates/{username}/{path}")
+
+	upsert := artist["post"].(map[string]any)
+	assert.Equal(t, "upsertArtist", upsert["operationId"])
+	assert.Equal(t, []any{"artist"}, upsert["tags"])
+	assert.Equal(t, map[string]any{"$ref": "#/components/schemas/Artist"},
+		dig(upsert, "requestBody", "content", "application/json", "schema"))
+	assert.ElementsMatch(t, []string{"200", "201", "default"}, keys(upsert["responses"].(map[string]any)))
+	parameter := dig(upsert, "parameters").([]any)[0]
+	assert.Equal(t, map[string]any{
+		"name": "artistID", "in": "path", "required": true,
+		"schema": map[string]any{"type": "integer", "minimum": 1.0},
+	}, parameter)
+
+	list := dig(paths, "/artist", "get").(map[string]any)
+	var names []string
+	for _, parameter := range list["parameters"].([]any) {
+		names = append(names, parameter.(map[string]any)["name"].(string))
+	}
+	assert.Equal(t, []string{"name", "sort", "limit", "cursor"}, names)
+	page := dig(list, "responses", "200", "content", "application/json", "schema", "properties")
+	assert.Equal(t, map[string]any{"$ref": "#/components/schemas/Page"}, dig(page, "pagination"))
+	assert.Equal(t, map[string]any{"$ref": "#/components/schemas/Artist"}, dig(page, "artists", "items"))
+
+	// Operations are uniquely identified and every reference is defined.
+	operationIDs := make(map[string]bool)
+	for path, methods := range paths {
+		for method, op := range methods.(map[string]any) {
+			id := op.(map[string]any)["operationId"].(string)
+			assert.False(t, operationIDs[id], "%s %s repeats operationId %s", method, path, id)
+			operationIDs[id] = true
+		}
+	}
+	for _, ref := range refs(document) {
+		assert.NotNil(t, dig(document, strings.Split(strings.TrimPrefix(ref, "#/"), "/")...),
+			"%s is not defined", ref)
+	}
+}
+
+func TestGetDocs(t *testing.T) {
+	server := newTestServer(t)
+
+	response := server.serve(http.MethodGet, "/docs", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, response.Body.String(), `url: "/openapi.json"`)
+	assert.NotContains(t, response.Body.String(), "https://", "the docs load nothing from elsewhere")
+
+	// Its assets are served along with it.
+	response = server.serve(http.MethodGet, "/docs/swagger-ui-bundle.js", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Header().Get("Content-Type"), "javascript")
+	assert.NotEmpty(t, response.Header().Get("ETag"))
+	response = server.serve(http.MethodGet, "/docs/swagger-ui.css", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Header().Get("Content-Type"), "text/css")
+	response = server.serve(http.MethodGet, "/docs/index.html", nil)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func keys(object map[string]any) []string {
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	return names
+}
+
+// The value at the path of keys into nested objects, or nil if not found.
+func dig(value any, path ...string) any {
+	for _, key := range path {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
+
+// Every $ref within the document.
+func refs(value any) []string {
+	var found []string
+	switch value := value.(type) {
+	case map[string]any:
+		for key, field := range value {
+			if ref, ok := field.(string); ok && key == "$ref" {
+				found = append(found, ref)
+			} else {
+				found = append(found, refs(field)...)
+			}
+		}
+	case []any:
+		for _, item := range value {
+			found = append(found, refs(item)...)
+		}
+	}
+	return found
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/echo/orders.go
+
+package echo
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/kevindamm/cratedigdb/ledger"
+	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
+	"github.com/labstack/echo"
+)
+
+// A page of the user's orders, most recently active first (or sorted by
+// "opened" or "id", see ledger.OrderSorts).  The query may filter by role
+// (seller, buyer or any), status (e.g. "Shipped") and open (true for orders
+// which have not been completed, merged or cancelled).  Only the user may see
+// their orders (see authorize).
+func (server *server) listOrders(ctx echo.Context) error {
+	userID, err := server.path_user(ctx)
+	if err != nil {
+		return err
+	}
+	paging, err := page_request(ctx, ledger.OrderSorts)
+	if err != nil {
+		return err
+	}
+	query := ledger.OrderQuery{Paging: paging}
+
+	bad_request := func(message string) error {
+		return echo.NewHTTPError(http.StatusBadRequest, message)
+	}
+	if query.Role, err = ledger.ParseOrderRole(ctx.QueryParam("role")); err != nil {
+		return bad_request(err.Error())
+	}
+	if value := ctx.QueryParam("status"); value != "" {
+		status, err := schema.ParseOrderStatus(value)
+		if err != nil {
+			return bad_request(err.Error())
+		}
+		query.Status = &status
+	}
+	if value := ctx.QueryParam("open"); value != "" {
+		if query.Open, err = strconv.ParseBool(value); err != nil {
+			return bad_request("open must be true or false")
+		}
+	}
+
+	orders, page, err := ledger.ListOrders(ctx.Request().Context(), server.db, userID, query)
+	if err != nil {
+		return http_error(err)
+	}
+	return page_response(ctx, "orders", "order", orders, page)
+}
+
+// The totals of the user's completed sales and purchases, valued in the
+// query's currency (USD by default) at the exchange rates of the day each
+// order was closed.  Only the user may see them (see authorize).
+func (server *server) getReport(ctx echo.Context) error {
+	userID, err := server.path_user(ctx)
+	if err != nil {
+		return err
+	}
+	currency := schema.CurrencyUSD
+	if value := ctx.QueryParam("currency"); value != "" {
+		if currency, err = schema.ParseCurrency(value); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	}
+	totals, err := ledger.NewConverter(server.db).OrderTotals(ctx.Request().Context(), userID, currency)
+	if err != nil {
+		return http_error(err)
+	}
+	return respond(ctx, http.StatusOK, "order_totals", totals)
+}
+
+// Creates an order for the user in the path (the buyer); the body is a
+// ledger.NewOrder, e.g. {"purchases": ["42-1178-2"], "comment": "..."}.
+func (server *server) createOrder(ctx echo.Context) error {
+	buyerID, err := server.path_user(ctx)
+	if err != nil {
+		return err
+	}
+	request := new(ledger.NewOrder)
+	if err := ctx.Bind(request); err != nil {
+		return err
+	}
+	request.BuyerID = buyerID
+
+	order, err := ledger.CreateOrder(ctx.Request().Context(), server.db, *request)
+	if err != nil {
+		return http_error(err)
+	}
+	ctx.Response().Header().Set(echo.HeaderLocation, "/order/"+order.Key())
+	return respond(ctx, http.StatusCreated, "order", order)
+}
+
+// The order in the path, which only its buyer and seller may see; to anyone
+// else it is not found (rather than forbidden, which would say that it exists).
+func (server *server) party_order(ctx echo.Context) (schema.Order, error) {
+	orderID, err := path_id(ctx, "orderID")
+	if err != nil {
+		return schema.Order{}, err
+	}
+	order, err := ledger.GetOrder(ctx.Request().Context(), server.db, orderID)
+	if err != nil {
+		return schema.Order{}, http_error(err)
+	}
+	if account, _, _ := authenticated(ctx); account.ID != order.SellerID && account.ID != order.BuyerID {
+		return schema.Order{}, http_error(fmt.Errorf("order %d: %w", orderID, database.ErrNotFound))
+	}
+	return order, nil
+}
+
+func (server *server) getOrder(ctx echo.Context) error {
+	order, err := server.party_order(ctx)
+	if err != nil {
+		return err
+	}
+	return respond(ctx, http.StatusOK, "order", order)
+}
+
+// Moves the order to another status, changes its offer price or comments on
+// it; the body is a ledger.OrderChange.  Invalid transitions are a conflict,
+// and those which are the other party's to make are forbidden.  Only the buyer
+// and seller may change the order (see party_order).
+func (server *server) changeOrder(ctx echo.Context) error {
+	order, err := server.party_order(ctx)
+	if err != nil {
+		return err
+	}
+	change := new(ledger.OrderChange)
+	if err := ctx.Bind(change); err != nil {
+		return err
+	}
+	account, _, _ := authenticated(ctx)
+	change.UserID = account.ID
+	order, err = ledger.ChangeOrder(ctx.Request().Context(), server.db, order.ID, *change)
+	if err != nil {
+		return http_error(err)
+	}
+	return respond(ctx, http.StatusOK, "order", order)
+}
+
+// The order's timeline of updates, oldest first (see party_order).
+func (server *server) listOrderUpdates(ctx echo.Context) error {
+	order, err := server.party_order(ctx)
+	if err != nil {
+		return err
+	}
+	updates, err := ledger.ListOrderUpdates(ctx.Request().Context(), server.db, order.ID)
+	if err != nil {
+		return http_error(err)
+	}
+	return respond(ctx, http.StatusOK, "order_updates", order_updates{updates})
+}
+
+type order_updates struct {
+	Updates []schema.OrderUpdate `json:"updates"`
+}
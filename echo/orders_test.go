@@ -0,0 +1,184 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/echo/orders_test.go
+
+package echo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kevindamm/cratedigdb/accounts"
+	"github.com/kevindamm/cratedigdb/ledger"
+	"github.com/kevindamm/cratedigdb/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrders(t *testing.T) {
+	server := newTestServer(t)
+	response := server.serve(http.MethodPost, "/vinyl/kevin/1178", nil)
+	require.Equal(t, http.StatusCreated, response.Code)
+	response = server.serve(http.MethodPost, "/listing/kevin/1178/1",
+		strings.NewReader(`{"price_low":{"amount":"20.00","currency":"USD"}}`))
+	require.Equal(t, http.StatusCreated, response.Code)
+
+	dana := "Bearer " + test_token("dana")
+	response = server.serve_headers(http.MethodPost, "/orders/dana",
+		strings.NewReader(`{"purchases":["42-1178-1"],"comment":"is the sleeve OK?"}`),
+		"Authorization", dana)
+	require.Equal(t, http.StatusCreated, response.Code, response.Body.String())
+	var order schema.Order
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &order))
+	assert.Equal(t, "/order/"+order.Key(), response.Header().Get("Location"))
+	assert.Equal(t, schema.NewMoney(2000, schema.CurrencyUSD), order.OfferPrice)
+	assert.Equal(t, uint64(43), order.BuyerID)
+
+	response = server.serve_headers(http.MethodPost, "/orders/dana",
+		strings.NewReader(`{"purchases":["42-1178-1"]}`), "Authorization", dana)
+	assert.Equal(t, http.StatusConflict, response.Code, "already in an open order")
+	response = server.serve_headers(http.MethodPost, "/orders/dana",
+		strings.NewReader(`{"purchases":["1178-1"]}`), "Authorization", dana)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	path := "/order/" + order.Key()
+	response = server.serve(http.MethodPost, path, strings.NewReader(`{"status":"Shipped"}`))
+	assert.Equal(t, http.StatusConflict, response.Code, "not paid yet")
+	response = server.serve_headers(http.MethodPost, path,
+		strings.NewReader(`{"status":"Payment Received"}`), "Authorization", dana)
+	assert.Equal(t, http.StatusForbidden, response.Code, "only the seller receives payment")
+	for _, status := range []string{"Payment Received", "Shipped"} {
+		response = server.serve(http.MethodPost, path, strings.NewReader(`{"status":"`+status+`"}`))
+		require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	}
+	response = server.serve(http.MethodPost, path, strings.NewReader(`{"status":"Confirmed"}`))
+	assert.Equal(t, http.StatusForbidden, response.Code, "only the buyer confirms receipt")
+	response = server.serve_headers(http.MethodPost, path,
+		strings.NewReader(`{"status":"Confirmed"}`), "Authorization", dana)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	var vinyl schema.Vinyl
+	response = server.serve(http.MethodGet, "/vinyl/kevin/1178/1", nil)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &vinyl))
+	assert.NotNil(t, vinyl.DateSold)
+
+	var timeline struct {
+		Updates []schema.OrderUpdate `json:"updates"`
+	}
+	response = server.serve(http.MethodGet, path+"/updates", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &timeline))
+	require.Len(t, timeline.Updates, 4)
+	assert.Equal(t, "is the sleeve OK?", timeline.Updates[0].Comment)
+	assert.Equal(t, schema.StatusConfirmed, *timeline.Updates[3].Status)
+
+	var page struct {
+		Orders []schema.Order `json:"orders"`
+	}
+	response = server.serve(http.MethodGet, "/orders/kevin?role=seller&status=Confirmed", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &page))
+	assert.Len(t, page.Orders, 1)
+	response = server.serve(http.MethodGet, "/orders/kevin?open=true", nil)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &page))
+	assert.Empty(t, page.Orders)
+	response = server.serve(http.MethodGet, "/order/999", nil)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestOrdersArePrivate(t *testing.T) {
+	server := newTestServer(t)
+	response := server.serve(http.MethodPost, "/vinyl/kevin/1178", nil)
+	require.Equal(t, http.StatusCreated, response.Code)
+	response = server.serve(http.MethodPost, "/listing/kevin/1178/1",
+		strings.NewReader(`{"price_low":{"amount":"20.00","currency":"USD"}}`))
+	require.Equal(t, http.StatusCreated, response.Code)
+	dana := "Bearer " + test_token("dana")
+	response = server.serve_headers(http.MethodPost, "/orders/dana",
+		strings.NewReader(`{"purchases":["42-1178-1"]}`), "Authorization", dana)
+	require.Equal(t, http.StatusCreated, response.Code, response.Body.String())
+	var order schema.Order
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &order))
+	path := "/order/" + order.Key()
+
+	ctx := context.Background()
+	lee, err := accounts.CreateAccount(ctx, server.db, schema.UserAccount{Username: "lee"})
+	require.NoError(t, err)
+	secret, _, err := accounts.StartSession(ctx, server.db, lee.ID)
+	require.NoError(t, err)
+	other := "Bearer " + secret
+
+	for _, target := range []string{path, path + "/updates"} {
+		for _, party := range []string{dana, "Bearer " + test_token("kevin")} {
+			response = server.serve_headers(http.MethodGet, target, nil, "Authorization", party)
+			assert.Equal(t, http.StatusOK, response.Code, "%s by a party", target)
+		}
+		response = server.serve_headers(http.MethodGet, target, nil, "Authorization", "")
+		assert.Equal(t, http.StatusUnauthorized, response.Code, "%s anonymously", target)
+		response = server.serve_headers(http.MethodGet, target, nil, "Authorization", other)
+		assert.Equal(t, http.StatusNotFound, response.Code, "%s by someone else", target)
+	}
+	response = server.serve_headers(http.MethodPost, path,
+		strings.NewReader(`{"comment":"mine now"}`), "Authorization", other)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	response = server.serve_headers(http.MethodGet, "/orders/dana", nil, "Authorization", "")
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+	response = server.serve_headers(http.MethodGet, "/orders/dana", nil, "Authorization", other)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+	response = server.serve_headers(http.MethodGet, "/orders/dana", nil, "Authorization", dana)
+	assert.Equal(t, http.StatusOK, response.Code)
+}
+
+func TestReport(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+	_, err := ledger.ImportRatesFile(ctx, server.db, "../ledger/testdata/eurofxref-hist.csv")
+	require.NoError(t, err)
+	_, err = server.db.Exec(`
+	  INSERT INTO Orders
+	    (orderID, seller_userID, buyer_userID, offer_price, price_currency, date_opened, date_closed, status)
+	  VALUES
+	    (1, 42, 43, 2500, "EUR", "2025-04-16", "2025-04-17", ?1),
+	    (2, 43, 42, 1000, "EUR", "2025-04-21", "2025-04-22", ?1),
+	    (3, 42, 43, 9999, "EUR", "2025-04-22", "2025-04-22", ?2),
+	    (4, 42, 43, 2500, "EUR", "2025-04-16", "2025-04-17", ?3)`,
+		schema.StatusConfirmed, schema.StatusCancelled, schema.StatusMerged)
+	require.NoError(t, err)
+
+	// The cancelled order and the one merged (into order 1) are not counted.
+	var totals ledger.OrderTotals
+	response := server.serve(http.MethodGet, "/report/kevin?currency=EUR", nil)
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &totals))
+	assert.Equal(t, 2, totals.Orders)
+	assert.Equal(t, schema.NewMoney(2500, schema.CurrencyEUR), totals.Sales)
+	assert.Equal(t, schema.NewMoney(1000, schema.CurrencyEUR), totals.Purchases)
+
+	response = server.serve(http.MethodGet, "/report/kevin?currency=XYZ", nil)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	response = server.serve_headers(http.MethodGet, "/report/kevin", nil,
+		"Authorization", "Bearer "+test_token("dana"))
+	assert.Equal(t, http.StatusForbidden, response.Code, "only the user sees their totals")
+}
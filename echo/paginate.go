@@ -0,0 +1,93 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/echo/paginate.go
+
+package echo
+
+import (
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/kevindamm/cratedigdb/pagination"
+	"github.com/labstack/echo"
+)
+
+// Reads the sort, limit and cursor query parameters for a list in one of the
+// sort orders.  Responds with 400 Bad Request for invalid values, including a
+// cursor from a list in another order.
+func page_request(ctx echo.Context, sorts pagination.Sorts) (pagination.Request, error) {
+	request, err := pagination.Parse(ctx.QueryParams(), sorts)
+	if err != nil {
+		return request, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return request, nil
+}
+
+// The data of the "page" fragment: each item rendered as its own fragment,
+// with the URLs of the previous and next pages (if any).
+type page_fragment struct {
+	Name       string
+	Items      []template.HTML
+	Page       pagination.Page
+	Prev, Next string
+}
+
+// Responds with the page of items under the given name, alongside the page's
+// description (as "pagination"), and links to the first, previous and next
+// pages in the Link header.  A nil list of items is written as empty.  HTML
+// responses (see respond) render each item with the named fragment.
+func page_response[T any](ctx echo.Context, name, fragment string, items []T, page pagination.Page) error {
+	request := ctx.Request()
+	self := url.URL{
+		Scheme:   ctx.Scheme(),
+		Host:     request.Host,
+		Path:     request.URL.Path,
+		RawQuery: request.URL.RawQuery,
+	}
+	ctx.Response().Header().Set("Link", page.Links(&self))
+	if items == nil {
+		items = []T{}
+	}
+	if !wants_html(ctx) {
+		return respond(ctx, http.StatusOK, "", map[string]any{
+			"pagination": page,
+			name:         items,
+		})
+	}
+
+	data := page_fragment{Name: name, Items: make([]template.HTML, len(items)), Page: page}
+	renderer := ctx.Echo().Renderer.(TemplateRenderer)
+	for i, item := range items {
+		html, err := renderer.fragment(fragment, item)
+		if err != nil {
+			return err
+		}
+		data.Items[i] = html
+	}
+	if page.Prev != "" {
+		data.Prev = pagination.WithCursor(&self, page.Prev)
+	}
+	if page.Next != "" {
+		data.Next = pagination.WithCursor(&self, page.Next)
+	}
+	return respond(ctx, http.StatusOK, "page", data)
+}
@@ -23,12 +23,9 @@
 package echo
 
 import (
-	"fmt"
-	"log"
 	"net/http"
-	"strconv"
 
-	"github.com/kevindamm/cratedigdb/schema"
+	"github.com/kevindamm/cratedigdb/catalog"
 	"github.com/labstack/echo"
 )
 
@@ -36,25 +33,16 @@ import (
 // (in discogs it is either a release or a collection's item)
 
 func (server *server) getRecord(ctx echo.Context) error {
-	id, err := strconv.Atoi(ctx.Param("versionID"))
+	versionID, err := path_id(ctx, "versionID")
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	release_version, found := server.versions_table[uint64(id)]
-	if !found {
-		return echo.NewHTTPError(http.StatusNotFound,
-			fmt.Sprintf("record %s not found", id))
+	version, err := catalog.GetVersion(ctx.Request().Context(), server.db, versionID)
+	if err != nil {
+		return http_error(err)
 	}
-	return ctx.JSON(http.StatusOK, release_version)
-}
-
-func (server *server) addRecord(ctx echo.Context) error {
-	record := new(schema.Record)
-	if err := ctx.Bind(record); err != nil {
+	if err := server.catalog_modified(ctx); err != nil {
 		return err
 	}
-
-	// TODO pre-check whether record has already been added.
-	server.records_table[record.DiscogsID] = record
-	return ctx.JSON(http.StatusCreated, record)
+	return respond(ctx, http.StatusOK, "version", version)
 }
@@ -18,16 +18,36 @@
 // OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
 // SOFTWARE.
 //
-// github:kevindamm/cratedig/service/records_test.go
+// github:kevindamm/cratedigdb/echo/records_test.go
 
-package echo_test
+package echo
 
-import "testing"
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
 
 func TestGetRecord(t *testing.T) {
-	// Setup
+	server := newTestServer(t)
 
-	// Assert
+	response := server.serve(http.MethodGet, "/record/1178", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `{
+	  "versionID": 1178,
+	  "releaseID": 100,
+	  "title": "Blue Lines",
+	  "data_quality": "Needs Vote",
+	  "tracklist": [{
+	    "trackID": 5001, "versionID": 1178, "track_number": 1,
+	    "title": "Safe From Harm", "duration": "5:18",
+	    "featured": [{"artistID": 1234, "name": "ahhMayZing", "role": "Remix"}]
+	  }]
+	}`, response.Body.String())
+
+	response = server.serve(http.MethodGet, "/record/1180", nil)
+	assert.Equal(t, http.StatusNotFound, response.Code)
 }
 
 func TestAddRecord(t *testing.T) {
@@ -23,34 +23,48 @@
 package echo
 
 import (
-	"fmt"
-	"log"
 	"net/http"
 	"strconv"
 
-	"github.com/kevindamm/cratedigdb/schema"
+	"github.com/kevindamm/cratedigdb/catalog"
 	"github.com/labstack/echo"
 )
 
-func (server *server) getRelease(ctx echo.Context) error {
-	id, err := strconv.Atoi(ctx.Param("releaseID"))
+// A page of releases by title (or sorted by "year" or "id"), optionally
+// filtered to those whose title starts with the title query parameter or
+// which credit the artist query parameter (an artistID).
+func (server *server) listReleases(ctx echo.Context) error {
+	paging, err := page_request(ctx, catalog.ReleaseSorts)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	release, found := server.releases_table[uint64(id)]
-	if !found {
-		return echo.NewHTTPError(http.StatusNotFound,
-			fmt.Sprintf("release %s not found", id))
+	query := catalog.ReleaseQuery{TitlePrefix: ctx.QueryParam("title"), Paging: paging}
+	if value := ctx.QueryParam("artist"); value != "" {
+		if query.ArtistID, err = strconv.ParseUint(value, 10, 64); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "artist must be an artistID")
+		}
+	}
+	releases, page, err := catalog.ListReleases(ctx.Request().Context(), server.db, query)
+	if err != nil {
+		return http_error(err)
 	}
-	return ctx.JSON(http.StatusOK, release)
+	if err := server.catalog_modified(ctx); err != nil {
+		return err
+	}
+	return page_response(ctx, "releases", "release", releases, page)
 }
 
-func (server *server) addRelease(ctx echo.Context) error {
-	release := new(schema.Release)
-	if err := ctx.Bind(release); err != nil {
+func (server *server) getRelease(ctx echo.Context) error {
+	releaseID, err := path_id(ctx, "releaseID")
+	if err != nil {
 		return err
 	}
-
-	server.releases_table[release.ID] = release
-	return ctx.JSON(http.StatusCreated, release)
+	release, err := catalog.GetRelease(ctx.Request().Context(), server.db, releaseID)
+	if err != nil {
+		return http_error(err)
+	}
+	if err := server.catalog_modified(ctx); err != nil {
+		return err
+	}
+	return respond(ctx, http.StatusOK, "release", release)
 }
@@ -18,16 +18,37 @@
 // OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
 // SOFTWARE.
 //
-// github:kevindamm/cratedig/service/albums_test.go
+// github:kevindamm/cratedigdb/echo/releases_test.go
 
-package echo_test
+package echo
 
-import "testing"
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
 
 func TestGetRelease(t *testing.T) {
-	// Setup
+	server := newTestServer(t)
 
-	// Assert
+	response := server.serve(http.MethodGet, "/release/100", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `{
+	  "releaseID": 100,
+	  "title": "Blue Lines",
+	  "main_version": 1178,
+	  "data_quality": "Needs Vote",
+	  "artists": [{"artistID": 1300, "name": "Massive Attack"}],
+	  "versions": [
+	    {"versionID": 1178, "releaseID": 100, "title": "Blue Lines", "data_quality": "Needs Vote"}
+	  ]
+	}`, response.Body.String())
+
+	response = server.serve(http.MethodGet, "/release/101", nil)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	response = server.serve(http.MethodGet, "/release/blue-lines", nil)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
 }
 
 func TestAddRelease(t *testing.T) {
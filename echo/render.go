@@ -0,0 +1,150 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/echo/render.go
+
+package echo
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"html/template"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo"
+)
+
+// HTML fragments for every resource, named by the resource's typename, plus
+// "page" (a page of a list) and "error", and the "docs" page.
+//
+//go:embed fragments/*.html
+var fragment_files embed.FS
+
+func NewRenderer() echo.Renderer {
+	return TemplateRenderer{
+		templates: template.Must(template.ParseFS(fragment_files, "fragments/*.html")),
+	}
+}
+
+type TemplateRenderer struct {
+	templates *template.Template
+}
+
+func (site TemplateRenderer) Render(w io.Writer, name string, data interface{}, ctx echo.Context) error {
+	return site.templates.ExecuteTemplate(w, name, data)
+}
+
+// Renders the fragment for embedding within another (e.g. items of a page).
+func (site TemplateRenderer) fragment(name string, data any) (template.HTML, error) {
+	var html bytes.Buffer
+	if err := site.templates.ExecuteTemplate(&html, name, data); err != nil {
+		return "", err
+	}
+	return template.HTML(html.String()), nil
+}
+
+// Whether to respond with an HTML fragment rather than JSON: for HTMX requests
+// (with an HX-Request header) and when the Accept header prefers text/html to
+// application/json.  JSON is the default, also when both are equally accepted.
+func wants_html(ctx echo.Context) bool {
+	request := ctx.Request()
+	if request.Header.Get("HX-Request") == "true" {
+		return true
+	}
+	html, json := accept_quality(request.Header.Get(echo.HeaderAccept))
+	return html > json
+}
+
+// The quality values which the Accept header gives text/html and
+// application/json, from their most specific matching media ranges.  Both are
+// acceptable (with quality 1) if the header is empty.
+func accept_quality(accept string) (html float64, json float64) {
+	if strings.TrimSpace(accept) == "" {
+		return 1, 1
+	}
+	// How specifically a media range matches: 3 exactly, 2 the type/*, 1 */*.
+	html_match, json_match := 0, 0
+	for _, media_range := range strings.Split(accept, ",") {
+		media_type, params, err := mime.ParseMediaType(strings.TrimSpace(media_range))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if value, found := params["q"]; found {
+			if quality, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+		match := func(full string) int {
+			switch media_type {
+			case full:
+				return 3
+			case strings.Split(full, "/")[0] + "/*":
+				return 2
+			case "*/*":
+				return 1
+			}
+			return 0
+		}
+		if specific := match("text/html"); specific > html_match {
+			html, html_match = quality, specific
+		}
+		if specific := match("application/json"); specific > json_match {
+			json, json_match = quality, specific
+		}
+	}
+	return html, json
+}
+
+// Responds with the value as JSON or, if preferred (see wants_html), as the
+// named HTML fragment.  Either way, the response varies by these headers and
+// is sent with an ETag (see send).
+func respond(ctx echo.Context, code int, fragment string, value any) error {
+	ctx.Response().Header().Set("Vary", "Accept, HX-Request")
+	body, content_type, err := represent(ctx, fragment, value)
+	if err != nil {
+		return err
+	}
+	return send(ctx, code, content_type, body)
+}
+
+// The body and content type which respond would send for the value; the JSON
+// is encoded as by echo (indented in debug mode or with a pretty parameter).
+func represent(ctx echo.Context, fragment string, value any) ([]byte, string, error) {
+	var body bytes.Buffer
+	if wants_html(ctx) {
+		if err := ctx.Echo().Renderer.Render(&body, fragment, value, ctx); err != nil {
+			return nil, "", err
+		}
+		return body.Bytes(), echo.MIMETextHTMLCharsetUTF8, nil
+	}
+	encoder := json.NewEncoder(&body)
+	if _, pretty := ctx.QueryParams()["pretty"]; ctx.Echo().Debug || pretty {
+		encoder.SetIndent("", "  ")
+	}
+	if err := encoder.Encode(value); err != nil {
+		return nil, "", err
+	}
+	return body.Bytes(), echo.MIMEApplicationJSONCharsetUTF8, nil
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/echo/render_test.go
+
+package echo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kevindamm/cratedigdb/accounts"
+	"github.com/kevindamm/cratedigdb/catalog"
+	"github.com/kevindamm/cratedigdb/ledger"
+	"github.com/kevindamm/cratedigdb/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptQuality(t *testing.T) {
+	tests := []struct {
+		accept     string
+		html, json float64
+	}{
+		{"", 1, 1},
+		{"*/*", 1, 1},
+		{"text/html", 1, 0},
+		{"application/json", 0, 1},
+		{"text/html, application/json;q=0.9", 1, 0.9},
+		{"text/*;q=0.5, */*;q=0.1", 0.5, 0.1},
+		{"text/html;q=0.2, text/*, */*;q=0.8", 0.2, 0.8},
+		{"text/html;q=oops, application/json", 0, 1},
+	}
+	for _, test := range tests {
+		html, json := accept_quality(test.accept)
+		assert.Equal(t, test.html, html, "html quality for %q", test.accept)
+		assert.Equal(t, test.json, json, "json quality for %q", test.accept)
+	}
+}
+
+func TestWantsHTML(t *testing.T) {
+	server := newTestServer(t)
+	wants := func(headers map[string]string) bool {
+		request := httptest.NewRequest(http.MethodGet, "/", nil)
+		for name, value := range headers {
+			request.Header.Set(name, value)
+		}
+		return wants_html(server.echos.NewContext(request, httptest.NewRecorder()))
+	}
+	assert.False(t, wants(nil))
+	assert.False(t, wants(map[string]string{"Accept": "*/*"}))
+	assert.False(t, wants(map[string]string{"Accept": "text/html, application/json"}))
+	assert.True(t, wants(map[string]string{"Accept": "text/html,application/xhtml+xml,*/*;q=0.8"}))
+	assert.True(t, wants(map[string]string{"HX-Request": "true"}))
+	assert.True(t, wants(map[string]string{"HX-Request": "true", "Accept": "application/json"}))
+}
+
+// Every fragment renders the resource it is named for (as read from the schema
+// conformance fixtures, which fill in most fields).
+func TestFragments(t *testing.T) {
+	renderer := NewRenderer().(TemplateRenderer)
+	fixture := func(name string, value any) any {
+		data, err := os.ReadFile(filepath.Join("..", "schema", "testdata", "conformance", name+".json"))
+		require.NoError(t, err)
+		require.NoError(t, json.Unmarshal(data, value))
+		return value
+	}
+	var update schema.OrderUpdate
+	fixture("order_update", &update)
+	var tag schema.Tag
+	fixture("tag", &tag)
+	today := schema.Today()
+
+	fragments := map[string]any{
+		"artist":  fixture("artist", new(schema.Artist)),
+		"release": fixture("release", new(schema.Release)),
+		"version": fixture("version", new(schema.ReleaseVersion)),
+		"vinyl":   fixture("vinyl", new(schema.Vinyl)),
+		"crate":   fixture("crate", new(schema.Crate)),
+		"tag":     tag,
+		"tags":    struct{ Tags []schema.Tag }{[]schema.Tag{tag}},
+		"listing": fixture("listing", new(schema.Listing)),
+		"listing_history": listing_history{[]schema.Listing{{VersionID: 1178, Item: 1,
+			PriceLow:   &schema.Money{Amount: 1800, Currency: schema.CurrencyUSD},
+			DateOpened: &today, DateClosed: &today}}},
+		"order":        fixture("order", new(schema.Order)),
+		"order_update": update,
+		"order_updates": struct {
+			Updates []schema.OrderUpdate
+		}{[]schema.OrderUpdate{update}},
+		"order_totals": ledger.OrderTotals{Currency: schema.CurrencyEUR, Orders: 1,
+			Sales: schema.NewMoney(2500, schema.CurrencyEUR), Purchases: schema.NewMoney(0, schema.CurrencyEUR)},
+		"account": fixture("account", new(schema.UserAccount)),
+		"tokens": token_list{[]accounts.Token{{ID: 1, Kind: accounts.TokenAPI,
+			Scopes: []accounts.Scope{accounts.ScopeCollection}, Expires: schema.Now()}}},
+		"issued_token":  issued_token{"secret", accounts.Token{ID: 2, Kind: accounts.TokenSession}},
+		"search_result": catalog.SearchResult{Type: "release", ID: 100, Title: "Blue Lines", Year: 1991},
+		"page":          page_fragment{Name: "artists", Prev: "/artist?cursor=a", Next: "/artist?cursor=b"},
+		"error": problem{Status: 422, Title: "Unprocessable Entity",
+			Errors: []schema.FieldError{{Field: "name", Message: "is required"}}},
+	}
+	for name, data := range fragments {
+		html, err := renderer.fragment(name, data)
+		if assert.NoError(t, err, name) {
+			assert.NotEmpty(t, strings.TrimSpace(string(html)), name)
+		}
+	}
+}
+
+func TestRespondHTML(t *testing.T) {
+	server := newTestServer(t)
+
+	request := httptest.NewRequest(http.MethodGet, "/artist/1234", nil)
+	request.Header.Set("Accept", "text/html")
+	response := httptest.NewRecorder()
+	server.echos.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, response.Header().Get("Vary"), "HX-Request")
+	assert.Contains(t, response.Body.String(), `<article class="artist" id="artist-1234">`)
+	assert.Contains(t, response.Body.String(), "ahhMayZing")
+
+	// JSON remains the default.
+	response = server.serve(http.MethodGet, "/artist/1234", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Header().Get("Content-Type"), "application/json")
+	assert.Contains(t, response.Header().Get("Vary"), "Accept")
+}
+
+func TestRespondHTMLPage(t *testing.T) {
+	server := newTestServer(t)
+
+	request := httptest.NewRequest(http.MethodGet, "/artist?limit=1", nil)
+	request.Header.Set("HX-Request", "true")
+	response := httptest.NewRecorder()
+	server.echos.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Header().Get("Content-Type"), "text/html")
+	assert.NotEmpty(t, response.Header().Get("Link"))
+
+	body := response.Body.String()
+	assert.Contains(t, body, `<section class="page artists" data-total="3">`)
+	assert.Equal(t, 1, strings.Count(body, `<article class="artist"`))
+	assert.Contains(t, body, `rel="next"`)
+	assert.Contains(t, body, `hx-get="http://example.com/artist?cursor=`)
+	assert.NotContains(t, body, `rel="prev"`)
+}
+
+func TestRespondHTMLError(t *testing.T) {
+	server := newTestServer(t)
+
+	request := httptest.NewRequest(http.MethodGet, "/artist/9999", nil)
+	request.Header.Set("HX-Request", "true")
+	response := httptest.NewRecorder()
+	server.echos.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	assert.Contains(t, response.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, response.Body.String(), `<div class="error" role="alert" data-status="404" data-request-id=`)
+
+	request = httptest.NewRequest(http.MethodGet, "/artist/oops", nil)
+	request.Header.Set("Accept", "text/html")
+	response = httptest.NewRecorder()
+	server.echos.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Contains(t, response.Body.String(), `data-status="400"`)
+
+	// Routes which don't exist also render the fragment.
+	request = httptest.NewRequest(http.MethodGet, "/nowhere", nil)
+	request.Header.Set("HX-Request", "true")
+	response = httptest.NewRecorder()
+	server.echos.ServeHTTP(response, request)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	assert.Contains(t, response.Body.String(), `class="error"`)
+
+	// Without a preference for HTML, errors are problem details (see errors_test).
+	response = server.serve(http.MethodGet, "/artist/9999", nil)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	assert.Equal(t, MIMEApplicationProblemJSON, response.Header().Get("Content-Type"))
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/echo/search.go
+
+package echo
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/kevindamm/cratedigdb/catalog"
+	"github.com/labstack/echo"
+)
+
+// Searches the local catalog with the same query parameters as the worker's
+// /search: q, type, title, artist, release, track, credit, label, genre,
+// style, country, year, format and barcode (see catalog.SearchQuery).  The
+// results are sorted by title (or "year", see catalog.SearchSorts).
+func (server *server) search(ctx echo.Context) error {
+	paging, err := page_request(ctx, catalog.SearchSorts)
+	if err != nil {
+		return err
+	}
+	query := catalog.SearchQuery{
+		Q:       ctx.QueryParam("q"),
+		Title:   ctx.QueryParam("title"),
+		Artist:  ctx.QueryParam("artist"),
+		Release: ctx.QueryParam("release"),
+		Track:   ctx.QueryParam("track"),
+		Credit:  ctx.QueryParam("credit"),
+		Label:   ctx.QueryParam("label"),
+		Genre:   ctx.QueryParam("genre"),
+		Style:   ctx.QueryParam("style"),
+		Country: ctx.QueryParam("country"),
+		Format:  ctx.QueryParam("format"),
+		Barcode: ctx.QueryParam("barcode"),
+		Paging:  paging,
+	}
+	bad_request := func(message string) error {
+		return echo.NewHTTPError(http.StatusBadRequest, message)
+	}
+	if query.Type, err = catalog.ParseSearchType(ctx.QueryParam("type")); err != nil {
+		return bad_request(err.Error())
+	}
+	if value := ctx.QueryParam("year"); value != "" {
+		if query.Year, err = strconv.Atoi(value); err != nil || query.Year < 1 {
+			return bad_request("year must be a positive integer")
+		}
+	}
+
+	results, page, err := catalog.Search(ctx.Request().Context(), server.db, query)
+	if err != nil {
+		return http_error(err)
+	}
+	return page_response(ctx, "results", "search_result", results, page)
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/echo/search_test.go
+
+package echo
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/kevindamm/cratedigdb/catalog"
+	"github.com/kevindamm/cratedigdb/pagination"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearch(t *testing.T) {
+	server := newTestServer(t)
+
+	var page struct {
+		Pagination pagination.Page        `json:"pagination"`
+		Results    []catalog.SearchResult `json:"results"`
+	}
+	response := server.serve(http.MethodGet, "/search?q=massive", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &page))
+	assert.Equal(t, 3, page.Pagination.Total)
+	assert.Equal(t, []catalog.SearchResult{
+		{Type: catalog.SearchRelease, ID: 100, Title: "Blue Lines", ReleaseID: 100},
+		{Type: catalog.SearchVersion, ID: 1178, Title: "Blue Lines", ReleaseID: 100},
+		{Type: catalog.SearchArtist, ID: 1300, Title: "Massive Attack"},
+	}, page.Results)
+
+	response = server.serve(http.MethodGet, "/search?type=version&credit=ahhmayzin&track=harm", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &page))
+	require.Len(t, page.Results, 1)
+	assert.Equal(t, uint64(1178), page.Results[0].ID)
+
+	response = server.serve(http.MethodGet, "/search?type=label&artist=massive", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `"results":[]`)
+
+	for _, query := range []string{"?type=master", "?year=1991a", "?sort=relevance"} {
+		response = server.serve(http.MethodGet, "/search"+query, nil)
+		assert.Equal(t, http.StatusBadRequest, response.Code, query)
+	}
+}
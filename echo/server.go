@@ -23,14 +23,19 @@
 package echo
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
-	"html/template"
-	"io"
 	"log"
 	"net/http"
 	"time"
 
+	"github.com/kevindamm/cratedigdb/accounts"
+	"github.com/kevindamm/cratedigdb/catalog"
+	"github.com/kevindamm/cratedigdb/collection"
+	"github.com/kevindamm/cratedigdb/ledger"
 	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
 	"github.com/labstack/echo"
 	"github.com/labstack/echo/middleware"
 )
@@ -48,18 +53,19 @@ type server struct {
 	echos *echo.Echo
 	debug bool
 
-	// naive DB tables while routes, etc. defined
-	artists_table  map[uint64]*schema.Artist
-	labels_table   map[uint64]*schema.Label
-	releases_table map[uint64]*schema.Release
-	versions_table map[uint64]*schema.ReleaseVersion
-	vinyl_table    map[uint64]*schema.Vinyl
-	listings_table map[uint64]*schema.Listing
+	db    *sql.DB
+	items collection.ItemAllocator
+
+	// Describes each route (by method and path) for the OpenAPI document.
+	operations map[string]operation
 }
 
-func NewInMemoryHandler(port int, debug bool) *server {
+// Serves the API backed by an already opened database (see database.Open).
+func NewHandler(db *sql.DB, port int, debug bool) *server {
 	server := new(server)
 	server.port = port
+	server.db = db
+	server.operations = make(map[string]operation)
 	if debug {
 		log.Printf("Listening on port %d", port)
 		server.debug = true
@@ -73,20 +79,341 @@ func NewInMemoryHandler(port int, debug bool) *server {
 	}
 
 	server.echos = echo.New()
+	server.echos.Use(middleware.RequestID())
 	server.echos.Use(middleware.Logger())
 	server.echos.Use(middleware.Recover())
+	server.echos.Use(server.authenticate)
 	//qps.echo.Pre(middleware.HTTPSRedirect())
 	//qps.echo.Pre(middleware.RemoveTrailingSlash())
 
 	server.echos.Renderer = NewRenderer()
+	server.echos.HTTPErrorHandler = server.handle_error
 	server.Handler = server.echos
 
 	return server
 }
 
+// Serves the API from a new, empty in-memory database (e.g. for tests).
+// The database is closed along with the server.
+func NewInMemoryHandler(port int, debug bool) (*server, error) {
+	db, err := database.Open(context.Background(), ":memory:")
+	if err != nil {
+		return nil, err
+	}
+	server := NewHandler(db, port, debug)
+	server.RegisterOnShutdown(func() { db.Close() })
+	return server, nil
+}
+
 func (handler *server) RegisterAPIRoutes() {
-	handler.echos.GET("/artist/:artist_id", handler.getArtist)
-	handler.echos.POST("/artist/:artist_id", handler.addArtist)
+	handler.route(http.MethodGet, "/artist", handler.listArtists, operation{
+		Summary:  "Lists artists by name",
+		Query:    []query_param{{Name: "name", Description: "a prefix of the artists' names"}},
+		Sorts:    catalog.ArtistSorts,
+		Response: paged{"artists", schema.Artist{}},
+	})
+	handler.route(http.MethodGet, "/artist/:artistID", handler.getArtist, operation{
+		Summary:  "Gets an artist with their aliases, members and groups",
+		Response: schema.Artist{},
+	})
+	handler.route(http.MethodPost, "/artist/:artistID", handler.upsertArtist, operation{
+		Summary:   "Creates or replaces an artist",
+		Scope:     accounts.ScopeCatalog,
+		Request:   schema.Artist{},
+		Response:  schema.Artist{},
+		Alternate: http.StatusCreated,
+	})
+	handler.route(http.MethodDelete, "/artist/:artistID", handler.deleteArtist, operation{
+		Summary: "Deletes an artist",
+		Scope:   accounts.ScopeCatalog,
+	})
+
+	handler.route(http.MethodGet, "/release", handler.listReleases, operation{
+		Summary: "Lists releases by title",
+		Query: []query_param{
+			{Name: "title", Description: "a prefix of the releases' titles"},
+			{Name: "artist", Type: "integer", Description: "an artistID credited on the releases"},
+		},
+		Sorts:    catalog.ReleaseSorts,
+		Response: paged{"releases", schema.Release{}},
+	})
+	handler.route(http.MethodGet, "/release/:releaseID", handler.getRelease, operation{
+		Summary:  "Gets a release with its versions",
+		Response: schema.Release{},
+	})
+	handler.route(http.MethodGet, "/record/:versionID", handler.getRecord, operation{
+		Summary:  "Gets a release version with its tracklist",
+		Response: schema.ReleaseVersion{},
+	})
+	handler.route(http.MethodGet, "/search", handler.search, operation{
+		Summary: "Searches the catalog",
+		Query: []query_param{
+			{Name: "q", Description: "matches titles and names"},
+			{Name: "type", Description: "release, version, artist or label"},
+			{Name: "title"}, {Name: "artist"}, {Name: "release"}, {Name: "track"},
+			{Name: "credit"}, {Name: "label"}, {Name: "genre"}, {Name: "style"},
+			{Name: "country"}, {Name: "year", Type: "integer"}, {Name: "format"},
+			{Name: "barcode"},
+		},
+		Sorts:    catalog.SearchSorts,
+		Response: paged{"results", catalog.SearchResult{}},
+	})
+
+	vinyl_query := []query_param{
+		{Name: "release", Type: "integer", Description: "a releaseID"},
+		{Name: "version", Type: "integer", Description: "a versionID"},
+		{Name: "crate", Description: `a crateID or "unsorted"`},
+		{Name: "grade", Repeated: true, Description: "a grade filter, e.g. media>=VG+"},
+		{Name: "status", Description: "owned (the default), sold, traded, archived or all"},
+		{Name: "tags", Description: `a tag expression, e.g. "warmup AND NOT lent" (only for the user themselves)`},
+	}
+	handler.route(http.MethodGet, "/vinyl/:username", handler.listVinyl, operation{
+		Summary:  "Lists the user's copies",
+		Query:    vinyl_query,
+		Sorts:    collection.VinylSorts,
+		Response: paged{"vinyl", schema.Vinyl{}},
+	})
+	handler.route(http.MethodGet, "/vinyl/:username/:versionID", handler.listVinyl, operation{
+		Summary:  "Lists the user's copies of a version",
+		ID:       "listVinylOfVersion",
+		Query:    vinyl_query,
+		Sorts:    collection.VinylSorts,
+		Response: paged{"vinyl", schema.Vinyl{}},
+	})
+	handler.route(http.MethodPost, "/vinyl/:username/:versionID", handler.addVinyl, operation{
+		Summary:         "Adds a copy of the version to the user's collection",
+		Scope:           accounts.ScopeCollection,
+		Request:         schema.Vinyl{},
+		RequestOptional: true,
+		Response:        schema.Vinyl{},
+		Status:          http.StatusCreated,
+	})
+	handler.route(http.MethodPost, "/vinyl/:username/import", handler.importVinyl, operation{
+		Summary: "Adds many copies to the user's collection from CSV or JSON lines",
+		Scope:   accounts.ScopeCollection,
+		Query: []query_param{{Name: "mode",
+			Description: "all (the default, nothing is added if any row fails) or best-effort"}},
+		Request:  import_body{collection.ImportRow{}},
+		Response: collection.ImportReport{},
+	})
+	handler.route(http.MethodGet, schema.VinylItemRoute, handler.getVinyl, operation{
+		Summary:  "Gets a copy",
+		Response: schema.Vinyl{},
+	})
+	handler.route(http.MethodPost, schema.VinylItemRoute, handler.updateVinyl, operation{
+		Summary:  "Updates the grades, notes, or sold or traded dates of a copy",
+		Scope:    accounts.ScopeCollection,
+		Request:  collection.VinylUpdate{},
+		Response: schema.Vinyl{},
+		IfMatch:  true,
+	})
+	handler.route(http.MethodDelete, schema.VinylItemRoute, handler.removeVinyl, operation{
+		Summary:   "Removes a copy, or archives it if the ledger refers to it",
+		Scope:     accounts.ScopeCollection,
+		Response:  schema.Vinyl{},
+		Alternate: http.StatusNoContent,
+	})
+
+	handler.route(http.MethodGet, "/crates/:username", handler.listCrates, operation{
+		Summary:  "Lists the user's crates",
+		Sorts:    collection.CrateSorts,
+		Response: paged{"crates", schema.Crate{}},
+	})
+	handler.route(http.MethodGet, "/crates/:username/*", handler.getCrateByPath, operation{
+		Summary:  "Gets a crate by its path of slugs",
+		Response: schema.Crate{},
+	})
+	handler.route(http.MethodPost, "/crate/:username", handler.createCrate, operation{
+		Summary:  "Creates a crate",
+		Scope:    accounts.ScopeCollection,
+		Request:  schema.Crate{},
+		Response: schema.Crate{},
+		Status:   http.StatusCreated,
+	})
+	handler.route(http.MethodPost, "/crate/:username/:crateID", handler.updateCrate, operation{
+		Summary:  "Renames, moves or otherwise updates a crate",
+		Scope:    accounts.ScopeCollection,
+		Request:  collection.CrateUpdate{},
+		Response: schema.Crate{},
+	})
+	handler.route(http.MethodDelete, "/crate/:username/:crateID", handler.deleteCrate, operation{
+		Summary: "Deletes a crate and its subcrates, unsorting their items",
+		Scope:   accounts.ScopeCollection,
+	})
+	handler.route(http.MethodPost, "/crate/:username/:crateID/vinyl", handler.moveVinyl, operation{
+		Summary: "Moves copies into the crate (or out of any crate, for crateID 0)",
+		Scope:   accounts.ScopeCollection,
+		Request: vinyl_items{},
+	})
+
+	handler.route(http.MethodPost, "/batch/:username", handler.runBatch, operation{
+		Summary:  "Adds, moves, tags, grades and lists copies (and creates crates) in one transaction",
+		Scope:    accounts.ScopeCollection,
+		Request:  batch_request{},
+		Response: batch_response{},
+	})
+
+	handler.route(http.MethodGet, "/listings/:username", handler.listListings, operation{
+		Summary: "Lists the seller's listings",
+		Query: []query_param{
+			{Name: "status", Description: "open (the default), closed or all"},
+			{Name: "release", Type: "integer", Description: "a releaseID"},
+			{Name: "version", Type: "integer", Description: "a versionID"},
+			{Name: "currency", Description: "an ISO 4217 currency code"},
+		},
+		Sorts:    ledger.ListingSorts,
+		Response: paged{"listings", schema.Listing{}},
+	})
+	handler.route(http.MethodGet, "/listing/:username/:versionID/:item", handler.getListing, operation{
+		Summary:  "Gets the listing of a copy",
+		Response: schema.Listing{},
+	})
+	handler.route(http.MethodGet, "/listing/:username/:versionID/:item/history", handler.getListingHistory, operation{
+		Summary:  "Lists the copy's earlier listings, closed before it was listed again",
+		Response: listing_history{},
+	})
+	handler.route(http.MethodPost, "/listing/:username/:versionID/:item", handler.openListing, operation{
+		Summary:  "Lists a copy for sale",
+		Scope:    accounts.ScopeLedger,
+		Request:  schema.Listing{},
+		Response: schema.Listing{},
+		Status:   http.StatusCreated,
+	})
+	handler.route(http.MethodPatch, "/listing/:username/:versionID/:item", handler.updateListing, operation{
+		Summary:  "Updates the prices or allow_offers of an open listing",
+		Scope:    accounts.ScopeLedger,
+		Request:  ledger.ListingUpdate{},
+		Response: schema.Listing{},
+	})
+	handler.route(http.MethodDelete, "/listing/:username/:versionID/:item", handler.closeListing, operation{
+		Summary:  "Closes a listing",
+		Scope:    accounts.ScopeLedger,
+		Response: schema.Listing{},
+	})
+
+	handler.route(http.MethodGet, "/orders/:username", handler.listOrders, operation{
+		Summary: "Lists the user's orders",
+		Query: []query_param{
+			{Name: "role", Description: "seller, buyer or any (the default)"},
+			{Name: "status", Description: `an order status, e.g. "Shipped"`},
+			{Name: "open", Type: "boolean", Description: "whether the orders are still open"},
+		},
+		Sorts:    ledger.OrderSorts,
+		Scope:    accounts.ScopeLedger,
+		Response: paged{"orders", schema.Order{}},
+	})
+	handler.route(http.MethodGet, "/report/:username", handler.getReport, operation{
+		Summary: "Totals the user's completed sales and purchases in one currency",
+		Tag:     "order",
+		Query: []query_param{
+			{Name: "currency", Description: "an ISO 4217 currency code (default USD)"},
+		},
+		Scope:    accounts.ScopeLedger,
+		Response: ledger.OrderTotals{},
+	})
+	handler.route(http.MethodPost, "/orders/:username", handler.createOrder, operation{
+		Summary:  "Creates an order with the user as the buyer",
+		Scope:    accounts.ScopeLedger,
+		Request:  ledger.NewOrder{},
+		Response: schema.Order{},
+		Status:   http.StatusCreated,
+	})
+	handler.route(http.MethodGet, "/order/:orderID", handler.getOrder, operation{
+		Summary:  "Gets an order (only its buyer and seller may)",
+		Scope:    accounts.ScopeLedger,
+		Response: schema.Order{},
+	})
+	handler.route(http.MethodPost, "/order/:orderID", handler.changeOrder, operation{
+		Summary:  "Changes the status or offer price of an order, or comments on it",
+		Scope:    accounts.ScopeLedger,
+		Request:  ledger.OrderChange{},
+		Response: schema.Order{},
+	})
+	handler.route(http.MethodGet, "/order/:orderID/updates", handler.listOrderUpdates, operation{
+		Summary:  "Lists the order's timeline of updates",
+		Scope:    accounts.ScopeLedger,
+		Response: order_updates{},
+	})
+
+	handler.route(http.MethodGet, "/tags/:username", handler.listTags, operation{
+		Summary:  "Lists the user's tags (which only they may see)",
+		Scope:    accounts.ScopeCollection,
+		Response: tag_list{},
+	})
+	handler.route(http.MethodPost, "/tags/:username/vinyl", handler.tagVinyl, operation{
+		Summary: "Adds and removes tags on copies",
+		Scope:   accounts.ScopeCollection,
+		Request: tag_changes{},
+	})
+	handler.route(http.MethodPost, "/tag/:username", handler.createTag, operation{
+		Summary:  "Creates a tag",
+		Scope:    accounts.ScopeCollection,
+		Request:  schema.Tag{},
+		Response: schema.Tag{},
+		Status:   http.StatusCreated,
+	})
+	handler.route(http.MethodPost, "/tag/:username/:tagID", handler.renameTag, operation{
+		Summary:  "Renames a tag",
+		Scope:    accounts.ScopeCollection,
+		Request:  tag_name{},
+		Response: schema.Tag{},
+	})
+	handler.route(http.MethodDelete, "/tag/:username/:tagID", handler.deleteTag, operation{
+		Summary: "Deletes a tag",
+		Scope:   accounts.ScopeCollection,
+	})
+	handler.route(http.MethodPost, "/tag/:username/:tagID/merge", handler.mergeTags, operation{
+		Summary:  "Merges tags into this one",
+		Scope:    accounts.ScopeCollection,
+		Request:  tag_ids{},
+		Response: schema.Tag{},
+	})
+
+	handler.route(http.MethodPost, "/session", handler.login, operation{
+		Summary:  "Logs in, starting a session (kept in a secure cookie)",
+		Tag:      "account",
+		Request:  credentials{},
+		Response: schema.UserAccount{},
+	})
+	handler.route(http.MethodDelete, "/session", handler.logout, operation{
+		Summary: "Ends the session",
+		Tag:     "account",
+	})
+	handler.route(http.MethodGet, "/tokens/:username", handler.listTokens, operation{
+		Summary:  "Lists the user's sessions and API tokens",
+		Tag:      "account",
+		Scope:    accounts.ScopeAccount,
+		Response: token_list{},
+	})
+	handler.route(http.MethodPost, "/token/:username", handler.issueToken, operation{
+		Summary:  "Issues an API token (its secret is only in this response)",
+		Tag:      "account",
+		Scope:    accounts.ScopeAccount,
+		Request:  accounts.TokenRequest{},
+		Response: issued_token{},
+		Status:   http.StatusCreated,
+	})
+	handler.route(http.MethodDelete, "/token/:username/:tokenID", handler.revokeToken, operation{
+		Summary: "Revokes an API token or ends a session",
+		Tag:     "account",
+		Scope:   accounts.ScopeAccount,
+	})
+
+	handler.route(http.MethodGet, "/openapi.json", handler.getOpenAPI, operation{
+		Summary:  "This OpenAPI document",
+		Tag:      "openapi",
+		Response: json_document{},
+	})
+	handler.route(http.MethodGet, "/docs", handler.getDocs, operation{
+		Summary:  "Interactive docs for the OpenAPI document",
+		Tag:      "openapi",
+		Response: html_page{},
+	})
+	handler.route(http.MethodGet, "/docs/:asset", handler.getDocsAsset, operation{
+		Summary:  "A script, stylesheet or icon of the interactive docs",
+		Tag:      "openapi",
+		Response: static_file{},
+	})
 }
 
 func (server *server) ServeLocalhost(port int) error {
@@ -98,17 +425,3 @@ func (server *server) ServeTLS(crt_path, key_path string) error {
 	url := "cratedig3000.kevindamm.com:443"
 	return server.echos.StartTLS(url, crt_path, key_path)
 }
-
-func NewRenderer() echo.Renderer {
-	return TemplateRenderer{
-		//templates: template.Must(template.ParseGlob("templates/*.ht_")),
-	}
-}
-
-type TemplateRenderer struct {
-	templates *template.Template
-}
-
-func (site TemplateRenderer) Render(w io.Writer, name string, data interface{}, ctx echo.Context) error {
-	return site.templates.ExecuteTemplate(w, name, data)
-}
@@ -0,0 +1,164 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/echo/tags.go
+
+package echo
+
+import (
+	"net/http"
+
+	"github.com/kevindamm/cratedigdb/collection"
+	"github.com/kevindamm/cratedigdb/schema"
+	"github.com/labstack/echo"
+)
+
+// The user's tags, which only they may see (see authorize).
+func (server *server) listTags(ctx echo.Context) error {
+	userID, err := server.path_user(ctx)
+	if err != nil {
+		return err
+	}
+	tags, err := collection.ListTags(ctx.Request().Context(), server.db, userID)
+	if err != nil {
+		return http_error(err)
+	}
+	if tags == nil {
+		tags = []schema.Tag{}
+	}
+	return respond(ctx, http.StatusOK, "tags", tag_list{tags})
+}
+
+// Creates a tag from the body's name.
+func (server *server) createTag(ctx echo.Context) error {
+	userID, err := server.path_user(ctx)
+	if err != nil {
+		return err
+	}
+	tag := new(schema.Tag)
+	if err := ctx.Bind(tag); err != nil {
+		return err
+	}
+	tag.UserID = userID
+
+	created, err := collection.CreateTag(ctx.Request().Context(), server.db, *tag)
+	if err != nil {
+		return http_error(err)
+	}
+	return respond(ctx, http.StatusCreated, "tag", created)
+}
+
+// Renames the tag to the body's name.
+func (server *server) renameTag(ctx echo.Context) error {
+	userID, err := server.path_user(ctx)
+	if err != nil {
+		return err
+	}
+	tagID, err := path_id(ctx, "tagID")
+	if err != nil {
+		return err
+	}
+	body := new(tag_name)
+	if err := ctx.Bind(body); err != nil {
+		return err
+	}
+	tag, err := collection.RenameTag(ctx.Request().Context(), server.db, userID, tagID, body.Name)
+	if err != nil {
+		return http_error(err)
+	}
+	return respond(ctx, http.StatusOK, "tag", tag)
+}
+
+func (server *server) deleteTag(ctx echo.Context) error {
+	userID, err := server.path_user(ctx)
+	if err != nil {
+		return err
+	}
+	tagID, err := path_id(ctx, "tagID")
+	if err != nil {
+		return err
+	}
+	if err := collection.DeleteTag(ctx.Request().Context(), server.db, userID, tagID); err != nil {
+		return http_error(err)
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// Merges the tags in the body, e.g. {"tags": [7, 9]}, into the tag in the path
+// and deletes them.
+func (server *server) mergeTags(ctx echo.Context) error {
+	userID, err := server.path_user(ctx)
+	if err != nil {
+		return err
+	}
+	tagID, err := path_id(ctx, "tagID")
+	if err != nil {
+		return err
+	}
+	body := new(tag_ids)
+	if err := ctx.Bind(body); err != nil {
+		return err
+	}
+	tag, err := collection.MergeTags(ctx.Request().Context(), server.db, userID, tagID, body.Tags)
+	if err != nil {
+		return http_error(err)
+	}
+	return respond(ctx, http.StatusOK, "tag", tag)
+}
+
+// Adds and removes tags by name on each of the items in the body, e.g.
+//
+//	{"add": ["warmup"], "remove": ["lent"], "items": [{"versionID": 1178, "item": 2}]}
+//
+// Tags apply to every copy of an item's version.
+func (server *server) tagVinyl(ctx echo.Context) error {
+	userID, err := server.path_user(ctx)
+	if err != nil {
+		return err
+	}
+	body := new(tag_changes)
+	if err := ctx.Bind(body); err != nil {
+		return err
+	}
+	err = collection.TagVinyl(ctx.Request().Context(), server.db, userID,
+		body.keys(userID), body.Add, body.Remove)
+	if err != nil {
+		return http_error(err)
+	}
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+type tag_list struct {
+	Tags []schema.Tag `json:"tags"`
+}
+
+type tag_name struct {
+	Name string `json:"name"`
+}
+
+type tag_ids struct {
+	Tags []uint64 `json:"tags"`
+}
+
+type tag_changes struct {
+	Add    []string `json:"add,omitempty"`
+	Remove []string `json:"remove,omitempty"`
+	vinyl_items
+}
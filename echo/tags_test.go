@@ -0,0 +1,141 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/echo/tags_test.go
+
+package echo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/kevindamm/cratedigdb/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (server *server) create_tag(t *testing.T, name string) schema.Tag {
+	t.Helper()
+	response := server.serve(http.MethodPost, "/tag/kevin", strings.NewReader(`{"name":"`+name+`"}`))
+	require.Equal(t, http.StatusCreated, response.Code, response.Body.String())
+	var tag schema.Tag
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &tag))
+	return tag
+}
+
+func TestTags(t *testing.T) {
+	server := newTestServer(t)
+	disco := server.create_tag(t, "disco")
+	assert.Equal(t, uint64(42), disco.UserID)
+	funk := server.create_tag(t, "funk")
+
+	response := server.serve(http.MethodPost, "/tag/kevin", strings.NewReader(`{"name":"Disco"}`))
+	assert.Equal(t, http.StatusConflict, response.Code)
+	response = server.serve(http.MethodPost, "/tag/kevin", strings.NewReader(`{"name":" "}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+
+	response = server.serve(http.MethodPost, "/tag/kevin/"+funk.Key(), strings.NewReader(`{"name":"boogie"}`))
+	require.Equal(t, http.StatusOK, response.Code)
+	response = server.serve(http.MethodPost, "/tag/kevin/"+funk.Key()+"/merge",
+		strings.NewReader(`{"tags":[`+disco.Key()+`]}`))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	var list struct {
+		Tags []schema.Tag `json:"tags"`
+	}
+	response = server.serve(http.MethodGet, "/tags/kevin", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &list))
+	assert.Equal(t, []schema.Tag{{ID: funk.ID, UserID: 42, Name: "boogie"}}, list.Tags)
+
+	response = server.serve(http.MethodDelete, "/tag/kevin/"+funk.Key(), nil)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	response = server.serve(http.MethodDelete, "/tag/kevin/"+funk.Key(), nil)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestTagVinyl(t *testing.T) {
+	server := newTestServer(t)
+	for range 2 {
+		response := server.serve(http.MethodPost, "/vinyl/kevin/1178", nil)
+		require.Equal(t, http.StatusCreated, response.Code)
+	}
+
+	response := server.serve(http.MethodPost, "/tags/kevin/vinyl", strings.NewReader(
+		`{"add":["warmup","disco"],"items":[{"versionID":1178,"item":1}]}`))
+	require.Equal(t, http.StatusNoContent, response.Code, response.Body.String())
+	response = server.serve(http.MethodPost, "/tags/kevin/vinyl", strings.NewReader(
+		`{"remove":["lent"],"items":[{"versionID":1178,"item":1}]}`))
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	var page struct {
+		Vinyl []schema.Vinyl `json:"vinyl"`
+	}
+	query := url.Values{"tags": {"warmup AND (disco OR funk) AND NOT lent"}}
+	response = server.serve(http.MethodGet, "/vinyl/kevin?"+query.Encode(), nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &page))
+	require.Len(t, page.Vinyl, 2, "tags apply to every copy of the version")
+	assert.Equal(t, []string{"disco", "warmup"}, page.Vinyl[0].Tags)
+
+	query = url.Values{"tags": {"warmup AND (disco"}}
+	response = server.serve(http.MethodGet, "/vinyl/kevin?"+query.Encode(), nil)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+func TestTagsArePrivate(t *testing.T) {
+	server := newTestServer(t)
+	response := server.serve(http.MethodPost, "/vinyl/kevin/1178", nil)
+	require.Equal(t, http.StatusCreated, response.Code)
+	response = server.serve(http.MethodPost, "/tags/kevin/vinyl", strings.NewReader(
+		`{"add":["lent"],"items":[{"versionID":1178,"item":1}]}`))
+	require.Equal(t, http.StatusNoContent, response.Code, response.Body.String())
+
+	dana := "Bearer " + test_token("dana")
+	for _, authorization := range []string{dana, ""} {
+		var vinyl schema.Vinyl
+		response = server.serve_headers(http.MethodGet, "/vinyl/kevin/1178/1", nil, "Authorization", authorization)
+		require.Equal(t, http.StatusOK, response.Code)
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &vinyl))
+		assert.Empty(t, vinyl.Tags, authorization)
+		assert.NotContains(t, response.Body.String(), "lent")
+
+		response = server.serve_headers(http.MethodGet, "/vinyl/kevin", nil, "Authorization", authorization)
+		require.Equal(t, http.StatusOK, response.Code)
+		assert.NotContains(t, response.Body.String(), "lent")
+
+		query := url.Values{"tags": {"lent"}}
+		response = server.serve_headers(http.MethodGet, "/vinyl/kevin?"+query.Encode(), nil,
+			"Authorization", authorization)
+		assert.Equal(t, http.StatusForbidden, response.Code, authorization)
+	}
+	response = server.serve_headers(http.MethodGet, "/tags/kevin", nil, "Authorization", dana)
+	assert.Equal(t, http.StatusForbidden, response.Code)
+	response = server.serve_headers(http.MethodGet, "/tags/kevin", nil, "Authorization", "")
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+
+	var vinyl schema.Vinyl
+	response = server.serve(http.MethodGet, "/vinyl/kevin/1178/1", nil)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &vinyl))
+	assert.Equal(t, []string{"lent"}, vinyl.Tags, "the owner sees their tags")
+}
@@ -1 +1,273 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/echo/vinyl.go
+
 package echo
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/kevindamm/cratedigdb/collection"
+	"github.com/kevindamm/cratedigdb/schema"
+	"github.com/labstack/echo"
+)
+
+// Resolves the username in the path to the (active) user's ID.
+func (server *server) path_user(ctx echo.Context) (uint64, error) {
+	userID, err := collection.UserID(ctx.Request().Context(), server.db, ctx.Param("username"))
+	if err != nil {
+		return 0, http_error(err)
+	}
+	return userID, nil
+}
+
+func (server *server) path_vinyl_key(ctx echo.Context) (schema.VinylKey, error) {
+	userID, err := server.path_user(ctx)
+	if err != nil {
+		return schema.VinylKey{}, err
+	}
+	key, err := schema.VinylKeyFromPath(userID, ctx.Param("versionID"), ctx.Param("item"))
+	if err != nil {
+		return key, echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	return key, nil
+}
+
+// A page of the user's copies, most recently added first (or sorted by
+// "version", see collection.VinylSorts).  The query may filter by release,
+// version (also when in the path), crate (a crateID or "unsorted"), grade
+// (e.g. grade=media>=VG+, repeatable), status (owned, sold, traded, archived
+// or all) and, for the user themselves, tags (a boolean expression such as
+// "warmup AND (disco OR funk) AND NOT lent").
+func (server *server) listVinyl(ctx echo.Context) error {
+	userID, err := server.path_user(ctx)
+	if err != nil {
+		return err
+	}
+	paging, err := page_request(ctx, collection.VinylSorts)
+	if err != nil {
+		return err
+	}
+	query := collection.VinylQuery{Paging: paging}
+
+	bad_request := func(message string) error {
+		return echo.NewHTTPError(http.StatusBadRequest, message)
+	}
+	if value := ctx.QueryParam("release"); value != "" {
+		if query.ReleaseID, err = strconv.ParseUint(value, 10, 64); err != nil {
+			return bad_request("release must be a releaseID")
+		}
+	}
+	version := ctx.Param("versionID")
+	if version == "" {
+		version = ctx.QueryParam("version")
+	}
+	if version != "" {
+		if query.VersionID, err = strconv.ParseUint(version, 10, 64); err != nil {
+			return bad_request("version must be a versionID")
+		}
+	}
+	if value := ctx.QueryParam("crate"); value == "unsorted" {
+		query.Unsorted = true
+	} else if value != "" {
+		if query.CrateID, err = strconv.ParseUint(value, 10, 64); err != nil {
+			return bad_request(`crate must be a crateID or "unsorted"`)
+		}
+	}
+	for _, value := range ctx.QueryParams()["grade"] {
+		filter, err := schema.ParseGradeFilter(value)
+		if err != nil {
+			return bad_request(err.Error())
+		}
+		query.Grades = append(query.Grades, filter)
+	}
+	if query.Status, err = collection.ParseVinylStatus(ctx.QueryParam("status")); err != nil {
+		return bad_request(err.Error())
+	}
+	if value := ctx.QueryParam("tags"); value != "" {
+		if !is_owner(ctx, userID) {
+			return echo.NewHTTPError(http.StatusForbidden,
+				"only "+ctx.Param("username")+" may filter by their tags")
+		}
+		if query.Tags, err = collection.ParseTagExpr(value); err != nil {
+			return bad_request(err.Error())
+		}
+	}
+
+	vinyl, page, err := collection.ListVinyl(ctx.Request().Context(), server.db, userID, query)
+	if err != nil {
+		return http_error(err)
+	}
+	return page_response(ctx, "vinyl", "vinyl", private_tags(ctx, userID, vinyl), page)
+}
+
+// Adds a copy of the version in the path to the user's collection, with the
+// next item number.  The body (optional) may give the crateID, grades, notes
+// and date_added.
+func (server *server) addVinyl(ctx echo.Context) error {
+	userID, err := server.path_user(ctx)
+	if err != nil {
+		return err
+	}
+	versionID, err := path_id(ctx, "versionID")
+	if err != nil {
+		return err
+	}
+	vinyl := new(schema.Vinyl)
+	if ctx.Request().ContentLength != 0 {
+		if err := ctx.Bind(vinyl); err != nil {
+			return err
+		}
+	}
+	vinyl.UserID, vinyl.VersionID = userID, versionID
+	vinyl.DateSold, vinyl.DateTraded, vinyl.DateArchived = nil, nil, nil
+
+	request := ctx.Request().Context()
+	key, err := server.items.Add(request, server.db, *vinyl)
+	if err != nil {
+		return http_error(err)
+	}
+	added, err := collection.GetVinyl(request, server.db, key)
+	if err != nil {
+		return http_error(err)
+	}
+	ctx.Response().Header().Set(echo.HeaderLocation, key.Path(ctx.Param("username")))
+	return respond(ctx, http.StatusCreated, "vinyl", added)
+}
+
+// Adds many copies at once from a CSV or JSON lines body (see
+// collection.ReadImport), responding with a report of what became of each
+// row.  The mode query parameter is "all" (the default, so nothing is added
+// if any row fails) or "best-effort".
+func (server *server) importVinyl(ctx echo.Context) error {
+	userID, err := server.path_user(ctx)
+	if err != nil {
+		return err
+	}
+	mode, err := collection.ParseImportMode(ctx.QueryParam("mode"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	request := ctx.Request()
+	format, err := import_format(request.Header.Get(echo.HeaderContentType))
+	if err != nil {
+		return err
+	}
+	body := http.MaxBytesReader(ctx.Response(), request.Body, max_import_bytes)
+	rows, err := collection.ReadImport(body, format)
+	if too_large := new(http.MaxBytesError); errors.As(err, &too_large) {
+		return echo.NewHTTPError(http.StatusRequestEntityTooLarge, err.Error())
+	} else if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	report, err := collection.ImportVinyl(request.Context(), server.db, &server.items, userID, rows, mode)
+	if err != nil {
+		return http_error(err)
+	}
+	return respond(ctx, http.StatusOK, "import_report", report)
+}
+
+// Enough for collection.MaxImportRows rows with long notes.
+const max_import_bytes = 8 << 20
+
+func import_format(content_type string) (collection.ImportFormat, error) {
+	media_type, _, _ := mime.ParseMediaType(content_type)
+	switch media_type {
+	case "text/csv":
+		return collection.ImportCSV, nil
+	case "application/jsonl", "application/x-ndjson", echo.MIMEApplicationJSON:
+		return collection.ImportJSONLines, nil
+	}
+	return "", echo.NewHTTPError(http.StatusUnsupportedMediaType,
+		"the body must be text/csv or application/jsonl")
+}
+
+func (server *server) getVinyl(ctx echo.Context) error {
+	key, err := server.path_vinyl_key(ctx)
+	if err != nil {
+		return err
+	}
+	vinyl, err := collection.GetVinyl(ctx.Request().Context(), server.db, key)
+	if err != nil {
+		return http_error(err)
+	}
+	vinyl = private_tags(ctx, key.UserID, []schema.Vinyl{vinyl})[0]
+	return respond(ctx, http.StatusOK, "vinyl", vinyl)
+}
+
+// Tags are private to their owner (see VinylTagging), so they are left out of
+// the user's copies when shown to anyone else.
+func private_tags(ctx echo.Context, userID uint64, vinyl []schema.Vinyl) []schema.Vinyl {
+	if !is_owner(ctx, userID) {
+		for i := range vinyl {
+			vinyl[i].Tags = nil
+		}
+	}
+	return vinyl
+}
+
+// Updates the grades, notes, or sold or traded dates of the copy; the body is
+// a collection.VinylUpdate where omitted fields are unchanged.  With an
+// If-Match header, the update only applies if the copy still has that ETag.
+func (server *server) updateVinyl(ctx echo.Context) error {
+	key, err := server.path_vinyl_key(ctx)
+	if err != nil {
+		return err
+	}
+	update := new(collection.VinylUpdate)
+	if err := ctx.Bind(update); err != nil {
+		return err
+	}
+	update.Precondition = func(current schema.Vinyl) error {
+		return if_match(ctx, "vinyl", current)
+	}
+	vinyl, err := collection.UpdateVinyl(ctx.Request().Context(), server.db, key, *update)
+	if err != nil {
+		return http_error(err)
+	}
+	return respond(ctx, http.StatusOK, "vinyl", vinyl)
+}
+
+// Removes the copy (204 No Content) or, if the ledger refers to it, archives
+// it (200 OK with the archived copy).
+func (server *server) removeVinyl(ctx echo.Context) error {
+	key, err := server.path_vinyl_key(ctx)
+	if err != nil {
+		return err
+	}
+	request := ctx.Request().Context()
+	archived, err := collection.RemoveVinyl(request, server.db, key)
+	if err != nil {
+		return http_error(err)
+	}
+	if !archived {
+		return ctx.NoContent(http.StatusNoContent)
+	}
+	vinyl, err := collection.GetVinyl(request, server.db, key)
+	if err != nil {
+		return http_error(err)
+	}
+	return respond(ctx, http.StatusOK, "vinyl", vinyl)
+}
@@ -0,0 +1,196 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/echo/vinyl_test.go
+
+package echo
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/kevindamm/cratedigdb/collection"
+	"github.com/kevindamm/cratedigdb/pagination"
+	"github.com/kevindamm/cratedigdb/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddVinyl(t *testing.T) {
+	server := newTestServer(t)
+
+	response := server.serve(http.MethodPost, "/vinyl/kevin/1178",
+		strings.NewReader(`{"media_grade":"VG+","sleeve_grade":"VG","notes":"first pressing"}`))
+	require.Equal(t, http.StatusCreated, response.Code)
+	assert.Equal(t, "/vinyl/kevin/1178/1", response.Header().Get("Location"))
+	var vinyl schema.Vinyl
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &vinyl))
+	assert.Equal(t, schema.VinylKey{UserID: 42, VersionID: 1178, Item: 1}, vinyl.VinylKey())
+	assert.Equal(t, uint64(100), vinyl.ReleaseID)
+	assert.Equal(t, schema.GradeVeryGoodPlus, vinyl.MediaGrade)
+	assert.Equal(t, "first pressing", vinyl.Notes)
+
+	// The body is optional.
+	response = server.serve(http.MethodPost, "/vinyl/kevin/1178", nil)
+	require.Equal(t, http.StatusCreated, response.Code)
+	assert.Equal(t, "/vinyl/kevin/1178/2", response.Header().Get("Location"))
+
+	response = server.serve(http.MethodPost, "/vinyl/nobody/1178", nil)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	response = server.serve(http.MethodPost, "/vinyl/kevin/1180", nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code, "unknown version")
+	response = server.serve(http.MethodPost, "/vinyl/kevin/1178",
+		strings.NewReader(`{"media_grade":"No Cover"}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+}
+
+func TestListVinyl(t *testing.T) {
+	server := newTestServer(t)
+	for _, body := range []string{`{"media_grade":"NM"}`, `{"media_grade":"G"}`} {
+		response := server.serve(http.MethodPost, "/vinyl/kevin/1178", strings.NewReader(body))
+		require.Equal(t, http.StatusCreated, response.Code)
+	}
+
+	var page struct {
+		Pagination pagination.Page `json:"pagination"`
+		Vinyl      []schema.Vinyl  `json:"vinyl"`
+	}
+	response := server.serve(http.MethodGet, "/vinyl/kevin", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &page))
+	assert.Equal(t, 2, page.Pagination.Total)
+	assert.Len(t, page.Vinyl, 2)
+
+	response = server.serve(http.MethodGet, "/vinyl/kevin/1178?grade=media%3E%3DVG%2B", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &page))
+	require.Len(t, page.Vinyl, 1)
+	assert.Equal(t, schema.GradeNearMint, page.Vinyl[0].MediaGrade)
+
+	response = server.serve(http.MethodGet, "/vinyl/kevin?crate=unsorted&status=all", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &page))
+	assert.Equal(t, 2, page.Pagination.Total)
+
+	for _, query := range []string{"?grade=label=M", "?status=lost", "?crate=house", "?release=x"} {
+		response = server.serve(http.MethodGet, "/vinyl/kevin"+query, nil)
+		assert.Equal(t, http.StatusBadRequest, response.Code, query)
+	}
+}
+
+func TestUpdateVinyl(t *testing.T) {
+	server := newTestServer(t)
+	response := server.serve(http.MethodPost, "/vinyl/kevin/1178", nil)
+	require.Equal(t, http.StatusCreated, response.Code)
+
+	response = server.serve(http.MethodPost, "/vinyl/kevin/1178/1",
+		strings.NewReader(`{"media_grade":"VG","notes":"surface noise","date_sold":"2030-01-02"}`))
+	require.Equal(t, http.StatusOK, response.Code)
+	var vinyl schema.Vinyl
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &vinyl))
+	assert.Equal(t, schema.GradeVeryGood, vinyl.MediaGrade)
+	assert.Equal(t, "surface noise", vinyl.Notes)
+	require.NotNil(t, vinyl.DateSold)
+	assert.Equal(t, "2030-01-02", vinyl.DateSold.String())
+
+	response = server.serve(http.MethodGet, "/vinyl/kevin/1178/1", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `"date_sold":"2030-01-02"`)
+
+	response = server.serve(http.MethodPost, "/vinyl/kevin/1178/1",
+		strings.NewReader(`{"date_traded":"1999-01-01"}`))
+	assert.Equal(t, http.StatusUnprocessableEntity, response.Code)
+	response = server.serve(http.MethodPost, "/vinyl/kevin/1178/2", strings.NewReader(`{}`))
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	response = server.serve(http.MethodGet, "/vinyl/kevin/1178/first", nil)
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+}
+
+func TestRemoveVinyl(t *testing.T) {
+	server := newTestServer(t)
+	for range 2 {
+		response := server.serve(http.MethodPost, "/vinyl/kevin/1178", nil)
+		require.Equal(t, http.StatusCreated, response.Code)
+	}
+
+	response := server.serve(http.MethodDelete, "/vinyl/kevin/1178/1", nil)
+	assert.Equal(t, http.StatusNoContent, response.Code)
+	response = server.serve(http.MethodGet, "/vinyl/kevin/1178/1", nil)
+	assert.Equal(t, http.StatusNotFound, response.Code)
+
+	// A sold copy is archived rather than deleted.
+	response = server.serve(http.MethodPost, "/vinyl/kevin/1178/2",
+		strings.NewReader(`{"date_sold":"2030-01-02"}`))
+	require.Equal(t, http.StatusOK, response.Code)
+	response = server.serve(http.MethodDelete, "/vinyl/kevin/1178/2", nil)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `"date_archived"`)
+
+	response = server.serve(http.MethodPost, "/vinyl/kevin/1178/2", strings.NewReader(`{"notes":"?"}`))
+	assert.Equal(t, http.StatusConflict, response.Code)
+}
+
+func TestImportVinyl(t *testing.T) {
+	server := newTestServer(t)
+	csv := "versionID,media_grade,tags,purchase_price\n" +
+		"1178,VG+,warmup,18.00 GBP\n" +
+		"1178,Great,,\n"
+
+	response := server.serve_headers(http.MethodPost, "/vinyl/kevin/import",
+		strings.NewReader(csv), "Content-Type", "text/csv")
+	require.Equal(t, http.StatusOK, response.Code)
+	var report collection.ImportReport
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &report))
+	assert.False(t, report.Committed)
+	assert.Equal(t, collection.ImportRolledBack, report.Rows[0].Status)
+	assert.Equal(t, []schema.FieldError{{Field: "media_grade", Message: `unrecognized grade "Great"`}},
+		report.Rows[1].Errors)
+
+	response = server.serve_headers(http.MethodPost, "/vinyl/kevin/import?mode=best-effort",
+		strings.NewReader(csv), "Content-Type", "text/csv; charset=utf-8")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `"committed":true,"added":1,"failed":1`)
+	assert.Contains(t, response.Body.String(), `"vinyl":"42-1178-1"`)
+	response = server.serve(http.MethodGet, "/vinyl/kevin/1178/1", nil)
+	assert.Contains(t, response.Body.String(), `"tags":["warmup"]`)
+	assert.Contains(t, response.Body.String(), `"purchase_price":{"amount":"18.00","currency":"GBP"}`)
+
+	response = server.serve_headers(http.MethodPost, "/vinyl/kevin/import",
+		strings.NewReader(`{"versionID": 1178, "notes": "second copy"}`+"\n"),
+		"Content-Type", "application/jsonl", "HX-Request", "true")
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `<section class="import-report"`)
+	assert.Contains(t, response.Body.String(), "42-1178-2")
+
+	response = server.serve_headers(http.MethodPost, "/vinyl/kevin/import",
+		strings.NewReader("<rows/>"), "Content-Type", "application/xml")
+	assert.Equal(t, http.StatusUnsupportedMediaType, response.Code)
+	response = server.serve_headers(http.MethodPost, "/vinyl/kevin/import?mode=some",
+		strings.NewReader(csv), "Content-Type", "text/csv")
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	response = server.serve_headers(http.MethodPost, "/vinyl/kevin/import",
+		strings.NewReader("grade\nVG\n"), "Content-Type", "text/csv")
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	response = server.serve_headers(http.MethodPost, "/vinyl/nobody/import",
+		strings.NewReader(csv), "Content-Type", "text/csv")
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
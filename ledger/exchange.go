@@ -0,0 +1,306 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/ledger/exchange.go
+
+// Package ledger holds the business logic for the ledger tables (listings,
+// orders and their valuation) on top of the sqlite database in package sql.
+package ledger
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/kevindamm/cratedigdb/schema"
+)
+
+// A reference rate: the number of units of Currency that equal one Euro.
+type ExchangeRate struct {
+	Date     schema.Date
+	Currency schema.CurrencyEnum
+	Rate     string // decimal, as published
+}
+
+// When a transaction date has no published rate (the ECB does not publish on
+// weekends or TARGET holidays) the most recent earlier rate is used, as long
+// as it is no more than this many days older than the transaction.  Holiday
+// closures (e.g. Good Friday through Easter Monday) fit within this window.
+const MaxRateAge = 7
+
+var ErrNoRate = errors.New("no exchange rate available")
+
+// Reads rates in either of the ECB formats, detected by the first character:
+// the XML envelope (eurofxref-hist.xml) or the CSV table (eurofxref-hist.csv).
+// Currencies that are not in schema.CurrencyOptions() are skipped.
+func ReadECBRates(reader io.Reader) ([]ExchangeRate, error) {
+	buffered := bufio.NewReader(reader)
+	for {
+		next, err := buffered.Peek(1)
+		if err != nil {
+			return nil, fmt.Errorf("reading exchange rates: %w", err)
+		}
+		switch next[0] {
+		case ' ', '\t', '\r', '\n', 0xEF, 0xBB, 0xBF: // whitespace or BOM
+			buffered.ReadByte()
+			continue
+		case '<':
+			return ReadECBXML(buffered)
+		}
+		return ReadECBCSV(buffered)
+	}
+}
+
+// Reads the ECB CSV format, a header row "Date,USD,JPY,..." followed by a row
+// of rates per date, with "N/A" where a currency had no rate that day.  Both
+// the historical ("2025-01-31") and daily ("31 January 2025") dates are read.
+func ReadECBCSV(reader io.Reader) ([]ExchangeRate, error) {
+	records := csv.NewReader(reader)
+	records.FieldsPerRecord = -1
+	records.TrimLeadingSpace = true
+
+	header, err := records.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading exchange rate header: %w", err)
+	}
+	if len(header) == 0 || !strings.EqualFold(strings.TrimSpace(header[0]), "Date") {
+		return nil, errors.New("exchange rate CSV must begin with a Date column")
+	}
+
+	rates := make([]ExchangeRate, 0)
+	for {
+		record, err := records.Read()
+		if err == io.EOF {
+			return rates, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		date, err := parse_rate_date(record[0])
+		if err != nil {
+			return nil, err
+		}
+		for i := 1; i < len(record) && i < len(header); i++ {
+			rate, ok, err := new_rate(date, header[i], record[i])
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				rates = append(rates, rate)
+			}
+		}
+	}
+}
+
+type ecb_envelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// Reads the ECB XML format, where each <Cube time="..."> contains a
+// <Cube currency="USD" rate="1.0393"/> for each currency.
+func ReadECBXML(reader io.Reader) ([]ExchangeRate, error) {
+	var envelope ecb_envelope
+	if err := xml.NewDecoder(reader).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("reading exchange rate XML: %w", err)
+	}
+	rates := make([]ExchangeRate, 0)
+	for _, day := range envelope.Days {
+		date, err := parse_rate_date(day.Time)
+		if err != nil {
+			return nil, err
+		}
+		for _, published := range day.Rates {
+			rate, ok, err := new_rate(date, published.Currency, published.Rate)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				rates = append(rates, rate)
+			}
+		}
+	}
+	return rates, nil
+}
+
+func parse_rate_date(text string) (schema.Date, error) {
+	if date, err := schema.ParseDate(text); err == nil {
+		return date, nil
+	}
+	if instant, err := time.Parse("2 January 2006", strings.TrimSpace(text)); err == nil {
+		return schema.DateOf(instant), nil
+	}
+	return schema.Date{}, fmt.Errorf("unrecognized exchange rate date %q", text)
+}
+
+// Returns ok=false for rates which are not applicable (unknown currencies and
+// missing values) and an error for rates which cannot be parsed.
+func new_rate(date schema.Date, code string, text string) (ExchangeRate, bool, error) {
+	text = strings.TrimSpace(text)
+	currency, err := schema.ParseCurrency(code)
+	if err != nil || currency == schema.CurrencyEUR || text == "" || text == "N/A" {
+		return ExchangeRate{}, false, nil
+	}
+	if ratio, ok := new(big.Rat).SetString(text); !ok || ratio.Sign() <= 0 {
+		return ExchangeRate{}, false, fmt.Errorf("invalid %s rate %q on %s",
+			currency, text, date)
+	}
+	return ExchangeRate{date, currency, text}, true, nil
+}
+
+// Inserts the rates into the ExchangeRates table (in a single transaction),
+// replacing any existing rate for the same currency and date.
+func ImportRates(ctx context.Context, db *sql.DB, rates []ExchangeRate) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	insert, err := tx.PrepareContext(ctx,
+		`INSERT OR REPLACE INTO ExchangeRates (currency, date, rate) VALUES (?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+	for _, rate := range rates {
+		if _, err := insert.ExecContext(ctx, rate.Currency, rate.Date, rate.Rate); err != nil {
+			return fmt.Errorf("importing %s rate on %s: %w", rate.Currency, rate.Date, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Reads the ECB rates from the file at path and imports them, returning the
+// number of rates imported.
+func ImportRatesFile(ctx context.Context, db *sql.DB, path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	rates, err := ReadECBRates(file)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", path, err)
+	}
+	return len(rates), ImportRates(ctx, db, rates)
+}
+
+// Converts amounts between currencies using the imported reference rates.
+type Converter struct {
+	db *sql.DB
+}
+
+func NewConverter(db *sql.DB) *Converter {
+	return &Converter{db}
+}
+
+// Finds the rate (units per Euro) for the currency on the given date, falling
+// back to the most recent earlier rate within MaxRateAge days.  The Euro's
+// rate is always one.
+func (converter *Converter) Rate(ctx context.Context, currency schema.CurrencyEnum, date schema.Date) (*big.Rat, error) {
+	if currency == schema.CurrencyEUR {
+		return big.NewRat(1, 1), nil
+	}
+	code, _ := currency.Value()
+	earliest := date.AddDays(-MaxRateAge)
+
+	var text string
+	err := converter.db.QueryRowContext(ctx, `
+	  SELECT rate FROM ExchangeRates
+	  WHERE currency = ? AND date <= ? AND date >= ?
+	  ORDER BY date DESC LIMIT 1`, code, date, earliest).Scan(&text)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("%w for %s on %s", ErrNoRate, code, date)
+	}
+	if err != nil {
+		return nil, err
+	}
+	rate, ok := new(big.Rat).SetString(text)
+	if !ok || rate.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid %s rate %q in database", code, text)
+	}
+	return rate, nil
+}
+
+// Converts the amount into the target currency at the rates for the given
+// date (see Rate), rounding half away from zero to the target's minor unit.
+func (converter *Converter) Convert(ctx context.Context, amount schema.Money, to schema.CurrencyEnum, date schema.Date) (schema.Money, error) {
+	from, _ := amount.Currency.Value()
+	target, _ := to.Value()
+	if from == target {
+		return schema.NewMoney(amount.Amount, to), nil
+	}
+	from_rate, err := converter.Rate(ctx, amount.Currency, date)
+	if err != nil {
+		return schema.Money{}, err
+	}
+	to_rate, err := converter.Rate(ctx, to, date)
+	if err != nil {
+		return schema.Money{}, err
+	}
+
+	// minor_to = minor_from / 10^exp_from / from_rate * to_rate * 10^exp_to
+	value := new(big.Rat).SetInt64(amount.Amount)
+	value.Mul(value, to_rate)
+	value.Quo(value, from_rate)
+	value.Mul(value, pow10(to.Exponent()))
+	value.Quo(value, pow10(amount.Currency.Exponent()))
+
+	rounded, err := round_half_away(value)
+	if err != nil {
+		return schema.Money{}, err
+	}
+	return schema.NewMoney(rounded, to), nil
+}
+
+func pow10(exponent int) *big.Rat {
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil))
+}
+
+func round_half_away(value *big.Rat) (int64, error) {
+	numerator := new(big.Int).Abs(value.Num())
+	quotient, remainder := new(big.Int).QuoRem(numerator, value.Denom(), new(big.Int))
+	if remainder.Lsh(remainder, 1).Cmp(value.Denom()) >= 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+	if value.Sign() < 0 {
+		quotient.Neg(quotient)
+	}
+	if !quotient.IsInt64() {
+		return 0, schema.ErrMoneyOverflow
+	}
+	return quotient.Int64(), nil
+}
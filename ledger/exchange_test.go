@@ -0,0 +1,144 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/ledger/exchange_test.go
+
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func open_test_db(t *testing.T) *sql.DB {
+	db, err := database.Open(context.Background(), ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func day(text string) schema.Date {
+	date, err := schema.ParseDate(text)
+	if err != nil {
+		panic(err)
+	}
+	return date
+}
+
+func TestReadECBFormats(t *testing.T) {
+	file, err := os.Open("testdata/eurofxref-hist.csv")
+	require.NoError(t, err)
+	defer file.Close()
+	rates, err := ReadECBRates(file)
+	require.NoError(t, err)
+	// 11 of the listed currencies are in CurrencyOptions() (EUR is implied).
+	assert.Len(t, rates, 3*11)
+	assert.Equal(t, ExchangeRate{day("2025-04-22"), schema.CurrencyUSD, "1.1476"}, rates[0])
+
+	file, err = os.Open("testdata/eurofxref-hist.xml")
+	require.NoError(t, err)
+	defer file.Close()
+	rates, err = ReadECBRates(file)
+	require.NoError(t, err)
+	assert.Len(t, rates, 2*3)
+	assert.Equal(t, ExchangeRate{day("2025-04-17"), schema.CurrencyGBP, "0.85685"}, rates[5])
+
+	// The daily file uses a long-form date and spaces after each comma.
+	rates, err = ReadECBCSV(strings.NewReader("Date, USD, JPY, \n22 April 2025, 1.1476, 161.83, \n"))
+	require.NoError(t, err)
+	assert.Equal(t, []ExchangeRate{
+		{day("2025-04-22"), schema.CurrencyUSD, "1.1476"},
+		{day("2025-04-22"), schema.CurrencyJPY, "161.83"},
+	}, rates)
+
+	_, err = ReadECBCSV(strings.NewReader("Date,USD\n2025-04-22,-1\n"))
+	assert.Error(t, err)
+}
+
+func TestConvertWithHolidayFallback(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	count, err := ImportRatesFile(ctx, db, "testdata/eurofxref-hist.csv")
+	require.NoError(t, err)
+	assert.Equal(t, 33, count)
+	converter := NewConverter(db)
+
+	// Easter 2025: no rates were published from Good Friday to Easter Monday.
+	converted, err := converter.Convert(ctx,
+		schema.NewMoney(10000, schema.CurrencyUSD), schema.CurrencyGBP, day("2025-04-20"))
+	require.NoError(t, err)
+	assert.Equal(t, schema.NewMoney(7546, schema.CurrencyGBP), converted)
+
+	converted, err = converter.Convert(ctx,
+		schema.NewMoney(1000, schema.CurrencyUSD), schema.CurrencyJPY, day("2025-04-22"))
+	require.NoError(t, err)
+	assert.Equal(t, schema.NewMoney(1410, schema.CurrencyJPY), converted)
+
+	converted, err = converter.Convert(ctx,
+		schema.NewMoney(1410, schema.CurrencyJPY), schema.CurrencyEUR, day("2025-04-22"))
+	require.NoError(t, err)
+	assert.Equal(t, schema.NewMoney(871, schema.CurrencyEUR), converted)
+
+	_, err = converter.Convert(ctx,
+		schema.NewMoney(100, schema.CurrencyUSD), schema.CurrencyGBP, day("2025-04-30"))
+	assert.ErrorIs(t, err, ErrNoRate)
+	_, err = converter.Convert(ctx,
+		schema.NewMoney(100, schema.CurrencyUSD), schema.CurrencyGBP, day("2025-04-15"))
+	assert.ErrorIs(t, err, ErrNoRate)
+}
+
+func TestOrderTotals(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	_, err := ImportRatesFile(ctx, db, "testdata/eurofxref-hist.xml")
+	require.NoError(t, err)
+
+	_, err = db.Exec(`INSERT INTO UserAccounts (userID, username) VALUES (1, "kevin"), (2, "dana")`)
+	require.NoError(t, err)
+	_, err = db.Exec(`
+	  INSERT INTO Orders
+	    (orderID, seller_userID, buyer_userID, offer_price, price_currency, date_opened, date_closed, status)
+	  VALUES
+	    (1, 1, 2, 2500, "GBP", "2025/04/16", "2025/04/19", ?1),
+	    (2, 2, 1, 1000, NULL, "2025-04-21", "2025-04-22", ?1),
+	    (3, 1, 2, 9999, "USD", "2025-04-22", "2025-04-22", ?2),
+	    (4, 1, 2, 2500, "GBP", "2025-04-16", "2025-04-17", ?3),
+	    (5, 1, 2, 4000, "USD", "2025-04-22", NULL, ?4),
+	    (6, 2, 1, 3000, "USD", "2025-04-22", NULL, ?5)`,
+		schema.StatusConfirmed, schema.StatusCancelled, schema.StatusMerged,
+		schema.StatusShipped, schema.StatusPaymentPending)
+	require.NoError(t, err)
+
+	// Only the completed orders count: not those cancelled, merged (into order
+	// 1, say) or still open, whether paid for or not.
+	totals, err := NewConverter(db).OrderTotals(ctx, 1, schema.CurrencyEUR)
+	require.NoError(t, err)
+	assert.Equal(t, 2, totals.Orders)
+	assert.Equal(t, schema.NewMoney(2918, schema.CurrencyEUR), totals.Sales)
+	assert.Equal(t, schema.NewMoney(871, schema.CurrencyEUR), totals.Purchases)
+}
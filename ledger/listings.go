@@ -0,0 +1,354 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/ledger/listings.go
+
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kevindamm/cratedigdb/collection"
+	"github.com/kevindamm/cratedigdb/pagination"
+	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
+)
+
+// Returned (wrapped) when opening a listing for a copy that is already listed.
+var ErrListed = errors.New("copy is already listed")
+
+// Returned (wrapped) when listing a copy that has been sold or traded.
+var ErrSold = errors.New("copy has been sold or traded")
+
+// Returned (wrapped) when modifying a listing that has been closed.
+var ErrListingClosed = errors.New("listing is closed")
+
+// Which listings to list, by whether they are still available.
+type ListingStatus string
+
+const (
+	// Listings which have not been closed (the default).
+	ListingOpen   ListingStatus = "open"
+	ListingClosed ListingStatus = "closed"
+	ListingAll    ListingStatus = "all"
+)
+
+var listing_conditions = map[ListingStatus]string{
+	ListingOpen:   `Listings.date_closed IS NULL`,
+	ListingClosed: `Listings.date_closed IS NOT NULL`,
+	ListingAll:    `TRUE`,
+}
+
+func ParseListingStatus(status string) (ListingStatus, error) {
+	if status == "" {
+		return ListingOpen, nil
+	}
+	if _, found := listing_conditions[ListingStatus(status)]; !found {
+		return "", fmt.Errorf("unknown listing status %q", status)
+	}
+	return ListingStatus(status), nil
+}
+
+// Selects a page of a seller's listings.  Zero values do not filter.
+type ListingQuery struct {
+	ReleaseID uint64
+	VersionID uint64
+	Currency  schema.CurrencyEnum
+	Status    ListingStatus
+
+	// In one of the ListingSorts orders.
+	Paging pagination.Request
+}
+
+// Listings are listed by when they were opened (the default, most recent
+// first) or by version and item number.
+var ListingSorts = pagination.Sorts{
+	{Name: "opened", Columns: []pagination.Column{
+		{Expr: "date_opened", Desc: true}, {Expr: "Listings.versionID"}, {Expr: "Listings.item"}}},
+	{Name: "version", Columns: []pagination.Column{{Expr: "Listings.versionID"}, {Expr: "Listings.item"}}},
+}
+
+// The sort key of a listing in one of the ListingSorts orders.
+func listing_key(sort pagination.Sort) func(schema.Listing) []any {
+	if strings.TrimPrefix(sort.Name, "-") == "version" {
+		return func(listing schema.Listing) []any { return []any{listing.VersionID, listing.Item} }
+	}
+	return func(listing schema.Listing) []any {
+		return []any{listing.DateOpened.String(), listing.VersionID, listing.Item}
+	}
+}
+
+const listing_columns = `Listings.userID, Listings.versionID, Listings.item,
+	price_low, price_high, price_currency, allow_offers, date_opened, Listings.date_closed`
+
+func scan_listing(rows *sql.Rows) (schema.Listing, error) {
+	var listing schema.Listing
+	var low, high schema.NullMoney
+	var currency schema.CurrencyEnum
+	err := rows.Scan(&listing.UserID, &listing.VersionID, &listing.Item,
+		&low, &high, &currency, &listing.AllowOffers, &listing.DateOpened, &listing.DateClosed)
+	listing.PriceLow, listing.PriceHigh = low.In(currency), high.In(currency)
+	return listing, err
+}
+
+// Returns the listings on the requested page, described along with the total
+// number of the seller's listings which match the query (on all pages).
+func ListListings(ctx context.Context, db database.Queryer, userID uint64, query ListingQuery) ([]schema.Listing, pagination.Page, error) {
+	if query.Status == "" {
+		query.Status = ListingOpen
+	}
+	status, found := listing_conditions[query.Status]
+	if !found {
+		return nil, pagination.Page{}, fmt.Errorf("unknown listing status %q", query.Status)
+	}
+
+	conditions := []string{`Listings.userID = ?`, status}
+	args := []any{userID}
+	if query.ReleaseID != 0 {
+		conditions = append(conditions, `releaseID = ?`)
+		args = append(args, query.ReleaseID)
+	}
+	if query.VersionID != 0 {
+		conditions = append(conditions, `Listings.versionID = ?`)
+		args = append(args, query.VersionID)
+	}
+	if query.Currency != "" {
+		conditions = append(conditions, `COALESCE(price_currency, ?) = ?`)
+		args = append(args, schema.CurrencyUSD, query.Currency)
+	}
+	from := ` FROM Listings JOIN ReleaseVersions USING (versionID) WHERE ` +
+		strings.Join(conditions, " AND ")
+
+	var total int
+	err := db.QueryRowContext(ctx, `SELECT COUNT(*)`+from, args...).Scan(&total)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+	after, after_args := query.Paging.Where()
+	listings, err := database.QueryList(ctx, db, scan_listing, `
+	  SELECT `+listing_columns+from+` AND `+after+`
+	  ORDER BY `+query.Paging.OrderBy()+`
+	  LIMIT ?`,
+		append(append(args, after_args...), query.Paging.Fetch())...)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+	listings, page := pagination.Paginate(query.Paging, listings, total, listing_key(query.Paging.Sort))
+	return listings, page, nil
+}
+
+// Looks up the listing (open or closed) of a copy.
+func GetListing(ctx context.Context, db database.Queryer, key schema.VinylKey) (schema.Listing, error) {
+	listings, err := database.QueryList(ctx, db, scan_listing, `
+	  SELECT `+listing_columns+` FROM Listings
+	  WHERE userID = ? AND versionID = ? AND item = ?`,
+		key.UserID, key.VersionID, key.Item)
+	if err != nil {
+		return schema.Listing{}, fmt.Errorf("listing %s: %w", key, err)
+	}
+	if len(listings) == 0 {
+		return schema.Listing{}, fmt.Errorf("listing %s: %w", key, database.ErrNotFound)
+	}
+	return listings[0], nil
+}
+
+// The earlier listings of a copy, which were closed before it was listed
+// again, in the order they were opened.  Its current listing (see GetListing)
+// is not included.
+func ListingHistory(ctx context.Context, db database.Queryer, key schema.VinylKey) ([]schema.Listing, error) {
+	listings, err := database.QueryList(ctx, db, scan_listing, `
+	  SELECT userID, versionID, item,
+	    price_low, price_high, price_currency, allow_offers, date_opened, date_closed
+	  FROM ListingHistory
+	  WHERE userID = ? AND versionID = ? AND item = ?
+	  ORDER BY historyID`,
+		key.UserID, key.VersionID, key.Item)
+	if err != nil {
+		return nil, fmt.Errorf("listing history %s: %w", key, err)
+	}
+	return listings, nil
+}
+
+// Opens a listing for the copy, which must still be in the seller's collection
+// and not already listed.  A copy whose earlier listing was closed (without
+// selling it) is listed again, opening today with the new prices, and the
+// earlier listing is kept in its history (see ListingHistory).
+func OpenListing(ctx context.Context, db database.Queryer, listing schema.Listing) (schema.Listing, error) {
+	if err := validate_listing(listing); err != nil {
+		return schema.Listing{}, err
+	}
+	err := database.InTx(ctx, db, func(tx *sql.Tx) error {
+		var err error
+		listing, err = open_listing(ctx, tx, listing)
+		return err
+	})
+	if err != nil {
+		return schema.Listing{}, err
+	}
+	return listing, nil
+}
+
+func open_listing(ctx context.Context, tx *sql.Tx, listing schema.Listing) (schema.Listing, error) {
+	key := listing.VinylKey()
+	vinyl, err := collection.GetVinyl(ctx, tx, key)
+	if err != nil {
+		return schema.Listing{}, err
+	}
+	if vinyl.DateSold != nil || vinyl.DateTraded != nil {
+		return schema.Listing{}, fmt.Errorf("vinyl %s: %w", key, ErrSold)
+	}
+	if vinyl.DateArchived != nil {
+		return schema.Listing{}, fmt.Errorf("vinyl %s: %w", key, collection.ErrArchived)
+	}
+	existing, err := GetListing(ctx, tx, key)
+	if err == nil && existing.DateClosed == nil {
+		return schema.Listing{}, fmt.Errorf("vinyl %s: %w", key, ErrListed)
+	} else if err != nil && !errors.Is(err, database.ErrNotFound) {
+		return schema.Listing{}, err
+	}
+	if err == nil {
+		// The closed listing is replaced below, so it is kept in the history.
+		if _, err := tx.ExecContext(ctx, `
+		  INSERT INTO ListingHistory (userID, versionID, item,
+		    price_low, price_high, price_currency, allow_offers, date_opened, date_closed)
+		  SELECT userID, versionID, item,
+		    price_low, price_high, price_currency, allow_offers, date_opened, date_closed
+		  FROM Listings WHERE userID = ? AND versionID = ? AND item = ?`,
+			key.UserID, key.VersionID, key.Item); err != nil {
+			return schema.Listing{}, fmt.Errorf("listing %s: %w", key, database.Classify(err))
+		}
+	}
+
+	low, high, currency := listing_prices(listing)
+	_, err = tx.ExecContext(ctx, `
+	  INSERT INTO Listings (userID, versionID, item,
+	    price_low, price_high, price_currency, allow_offers, date_opened)
+	  VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	  ON CONFLICT DO UPDATE SET
+	    price_low = excluded.price_low, price_high = excluded.price_high,
+	    price_currency = excluded.price_currency, allow_offers = excluded.allow_offers,
+	    date_opened = excluded.date_opened, date_closed = NULL`,
+		key.UserID, key.VersionID, key.Item,
+		low, high, currency, listing.AllowOffers, schema.Today())
+	if err != nil {
+		return schema.Listing{}, fmt.Errorf("listing %s: %w", key, database.Classify(err))
+	}
+	return GetListing(ctx, tx, key)
+}
+
+// Changes to an open listing's prices and whether it allows offers.  Nil
+// fields are left unchanged.
+type ListingUpdate struct {
+	PriceLow    *schema.Money `json:"price_low,omitempty"`
+	PriceHigh   *schema.Money `json:"price_high,omitempty"`
+	AllowOffers *bool         `json:"allow_offers,omitempty"`
+}
+
+// Applies the update to the open listing, returning the updated listing.
+func UpdateListing(ctx context.Context, db *sql.DB, key schema.VinylKey, update ListingUpdate) (schema.Listing, error) {
+	var listing schema.Listing
+	err := database.InTx(ctx, db, func(tx *sql.Tx) error {
+		var err error
+		if listing, err = GetListing(ctx, tx, key); err != nil {
+			return err
+		}
+		if listing.DateClosed != nil {
+			return fmt.Errorf("listing %s: %w", key, ErrListingClosed)
+		}
+		if update.PriceLow != nil {
+			listing.PriceLow = update.PriceLow
+		}
+		if update.PriceHigh != nil {
+			listing.PriceHigh = update.PriceHigh
+		}
+		if update.AllowOffers != nil {
+			listing.AllowOffers = *update.AllowOffers
+		}
+		if err := validate_listing(listing); err != nil {
+			return err
+		}
+
+		low, high, currency := listing_prices(listing)
+		_, err = tx.ExecContext(ctx, `
+		  UPDATE Listings SET
+		    price_low = ?, price_high = ?, price_currency = ?, allow_offers = ?
+		  WHERE userID = ? AND versionID = ? AND item = ?`,
+			low, high, currency, listing.AllowOffers,
+			key.UserID, key.VersionID, key.Item)
+		if err != nil {
+			return fmt.Errorf("listing %s: %w", key, database.Classify(err))
+		}
+		listing, err = GetListing(ctx, tx, key)
+		return err
+	})
+	if err != nil {
+		return schema.Listing{}, err
+	}
+	return listing, nil
+}
+
+// Closes the open listing as of today, returning the closed listing.
+func CloseListing(ctx context.Context, db database.Queryer, key schema.VinylKey) (schema.Listing, error) {
+	result, err := db.ExecContext(ctx, `
+	  UPDATE Listings SET date_closed = ?
+	  WHERE userID = ? AND versionID = ? AND item = ? AND date_closed IS NULL`,
+		schema.Today(), key.UserID, key.VersionID, key.Item)
+	if err != nil {
+		return schema.Listing{}, fmt.Errorf("listing %s: %w", key, database.Classify(err))
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return schema.Listing{}, err
+	} else if affected == 0 {
+		if _, err := GetListing(ctx, db, key); err != nil {
+			return schema.Listing{}, err
+		}
+		return schema.Listing{}, fmt.Errorf("listing %s: %w", key, ErrListingClosed)
+	}
+	return GetListing(ctx, db, key)
+}
+
+// A listing needs at least one price; when it has both they must be in the
+// same currency, with price_low no more than price_high.
+func validate_listing(listing schema.Listing) error {
+	if err := listing.Validate(); err != nil {
+		return err
+	}
+	if listing.PriceLow == nil && listing.PriceHigh == nil {
+		return &schema.ValidationError{Typename: listing.Typename(), Fields: []schema.FieldError{
+			{Field: "price_low", Message: "is required when there is no price_high"}}}
+	}
+	return nil
+}
+
+// The nullable SQL values for the listing's prices and their currency.
+func listing_prices(listing schema.Listing) (low, high schema.NullMoney, currency schema.CurrencyEnum) {
+	low, high = schema.NullMoneyOf(listing.PriceLow), schema.NullMoneyOf(listing.PriceHigh)
+	if listing.PriceLow != nil {
+		currency = listing.PriceLow.Currency
+	}
+	if listing.PriceHigh != nil {
+		currency = listing.PriceHigh.Currency
+	}
+	return low, high, currency
+}
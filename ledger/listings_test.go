@@ -0,0 +1,241 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/ledger/listings_test.go
+
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/kevindamm/cratedigdb/collection"
+	"github.com/kevindamm/cratedigdb/pagination"
+	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Adds users kevin (1) and dana (2), release 100 with versions 1178 and 1179,
+// and three copies for kevin: 1178 items 1 and 2 and 1179 item 1.
+func add_test_collection(t *testing.T, db *sql.DB) {
+	ctx := context.Background()
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	defer tx.Rollback()
+	for _, statement := range []string{
+		`INSERT INTO UserAccounts (userID, username) VALUES (1, "kevin"), (2, "dana")`,
+		`INSERT INTO Releases (releaseID, title, main_version) VALUES (100, "Blue Lines", 1178)`,
+		`INSERT INTO ReleaseVersions (versionID, releaseID, title)
+		   VALUES (1178, 100, "Blue Lines"), (1179, 100, "Blue Lines (Remastered)")`,
+	} {
+		_, err := tx.Exec(statement)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tx.Commit())
+
+	var allocator collection.ItemAllocator
+	for _, versionID := range []uint64{1178, 1178, 1179} {
+		_, err := allocator.Add(ctx, db, schema.Vinyl{UserID: 1, VersionID: versionID})
+		require.NoError(t, err)
+	}
+}
+
+func usd(amount int64) *schema.Money {
+	money := schema.NewMoney(amount, schema.CurrencyUSD)
+	return &money
+}
+
+func TestOpenListing(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	add_test_collection(t, db)
+	key := schema.VinylKey{UserID: 1, VersionID: 1178, Item: 1}
+
+	listing, err := OpenListing(ctx, db, schema.Listing{
+		UserID: 1, VersionID: 1178, Item: 1,
+		PriceLow: usd(2000), PriceHigh: usd(2500), AllowOffers: true})
+	require.NoError(t, err)
+	assert.Equal(t, usd(2000), listing.PriceLow)
+	assert.True(t, listing.AllowOffers)
+	assert.Equal(t, schema.Today(), *listing.DateOpened)
+	assert.Nil(t, listing.DateClosed)
+
+	_, err = OpenListing(ctx, db, schema.Listing{UserID: 1, VersionID: 1178, Item: 1, PriceLow: usd(1)})
+	assert.ErrorIs(t, err, ErrListed)
+	_, err = OpenListing(ctx, db, schema.Listing{UserID: 1, VersionID: 1178, Item: 9, PriceLow: usd(1)})
+	assert.ErrorIs(t, err, database.ErrNotFound)
+	_, err = OpenListing(ctx, db, schema.Listing{UserID: 1, VersionID: 1178, Item: 2})
+	assert.ErrorContains(t, err, "price_low: is required")
+	gbp := schema.NewMoney(3000, schema.CurrencyGBP)
+	_, err = OpenListing(ctx, db, schema.Listing{UserID: 1, VersionID: 1178, Item: 2,
+		PriceLow: usd(2000), PriceHigh: &gbp})
+	assert.ErrorContains(t, err, "must be the same currency")
+
+	sold := schema.Today()
+	_, err = collection.UpdateVinyl(ctx, db, schema.VinylKey{UserID: 1, VersionID: 1179, Item: 1},
+		collection.VinylUpdate{DateSold: &sold})
+	require.NoError(t, err)
+	_, err = OpenListing(ctx, db, schema.Listing{UserID: 1, VersionID: 1179, Item: 1, PriceLow: usd(1)})
+	assert.ErrorIs(t, err, ErrSold)
+
+	// A closed listing may be opened again.
+	_, err = CloseListing(ctx, db, key)
+	require.NoError(t, err)
+	listing, err = OpenListing(ctx, db, schema.Listing{UserID: 1, VersionID: 1178, Item: 1, PriceHigh: usd(1800)})
+	require.NoError(t, err)
+	assert.Nil(t, listing.DateClosed)
+	assert.Nil(t, listing.PriceLow)
+	assert.Equal(t, usd(1800), listing.PriceHigh)
+	assert.False(t, listing.AllowOffers)
+}
+
+func TestRelistingKeepsHistory(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	add_test_listings(t, db)
+
+	// Withdrawn and listed again (twice), each earlier listing is kept.
+	for _, price := range []int64{1700, 1600} {
+		_, err := CloseListing(ctx, db, first)
+		require.NoError(t, err)
+		_, err = OpenListing(ctx, db, schema.Listing{
+			UserID: 1, VersionID: 1178, Item: 1, PriceLow: usd(price)})
+		require.NoError(t, err)
+	}
+	history, err := ListingHistory(ctx, db, first)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, usd(1800), history[0].PriceLow)
+	assert.Equal(t, usd(2000), history[0].PriceHigh)
+	assert.Equal(t, usd(1700), history[1].PriceLow)
+	for _, earlier := range history {
+		assert.Equal(t, first, earlier.VinylKey())
+		assert.Equal(t, schema.Today(), *earlier.DateClosed)
+	}
+	listing, err := GetListing(ctx, db, first)
+	require.NoError(t, err)
+	assert.Equal(t, usd(1600), listing.PriceLow)
+	assert.Nil(t, listing.DateClosed)
+
+	// Once sold, the copy's listing stays as it was when the order closed.
+	order, err := CreateOrder(ctx, db, NewOrder{BuyerID: 2, Purchases: []schema.VinylKey{second}})
+	require.NoError(t, err)
+	for _, change := range []OrderChange{
+		{UserID: 1, Status: status(schema.StatusPaymentReceived)},
+		{UserID: 1, Status: status(schema.StatusShipped)},
+		{UserID: 2, Status: status(schema.StatusConfirmed)},
+	} {
+		_, err = ChangeOrder(ctx, db, order.ID, change)
+		require.NoError(t, err)
+	}
+	_, err = OpenListing(ctx, db, schema.Listing{UserID: 1, VersionID: 1178, Item: 2, PriceLow: usd(1)})
+	assert.ErrorIs(t, err, ErrSold)
+	listing, err = GetListing(ctx, db, second)
+	require.NoError(t, err)
+	assert.Equal(t, usd(1500), listing.PriceLow)
+	assert.NotNil(t, listing.DateClosed)
+	history, err = ListingHistory(ctx, db, second)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func TestUpdateAndCloseListing(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	add_test_collection(t, db)
+	key := schema.VinylKey{UserID: 1, VersionID: 1178, Item: 1}
+	_, err := OpenListing(ctx, db, schema.Listing{UserID: 1, VersionID: 1178, Item: 1,
+		PriceLow: usd(2000), PriceHigh: usd(2500)})
+	require.NoError(t, err)
+
+	offers := true
+	listing, err := UpdateListing(ctx, db, key, ListingUpdate{PriceHigh: usd(3000), AllowOffers: &offers})
+	require.NoError(t, err)
+	assert.Equal(t, usd(2000), listing.PriceLow)
+	assert.Equal(t, usd(3000), listing.PriceHigh)
+	assert.True(t, listing.AllowOffers)
+	_, err = UpdateListing(ctx, db, key, ListingUpdate{PriceLow: usd(5000)})
+	assert.ErrorContains(t, err, "price_high: must not be less than price_low")
+
+	listing, err = CloseListing(ctx, db, key)
+	require.NoError(t, err)
+	assert.Equal(t, schema.Today(), *listing.DateClosed)
+	_, err = CloseListing(ctx, db, key)
+	assert.ErrorIs(t, err, ErrListingClosed)
+	_, err = UpdateListing(ctx, db, key, ListingUpdate{AllowOffers: &offers})
+	assert.ErrorIs(t, err, ErrListingClosed)
+	_, err = CloseListing(ctx, db, schema.VinylKey{UserID: 1, VersionID: 1178, Item: 2})
+	assert.ErrorIs(t, err, database.ErrNotFound)
+}
+
+func TestListListings(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	add_test_collection(t, db)
+	for _, listing := range []schema.Listing{
+		{UserID: 1, VersionID: 1178, Item: 1, PriceLow: usd(2000)},
+		{UserID: 1, VersionID: 1178, Item: 2, PriceLow: usd(1500)},
+		{UserID: 1, VersionID: 1179, Item: 1, PriceLow: &schema.Money{Amount: 900, Currency: schema.CurrencyEUR}},
+	} {
+		_, err := OpenListing(ctx, db, listing)
+		require.NoError(t, err)
+	}
+	_, err := CloseListing(ctx, db, schema.VinylKey{UserID: 1, VersionID: 1178, Item: 2})
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		query    ListingQuery
+		expected int
+	}{
+		{ListingQuery{}, 2},
+		{ListingQuery{Status: ListingClosed}, 1},
+		{ListingQuery{Status: ListingAll}, 3},
+		{ListingQuery{Status: ListingAll, VersionID: 1178}, 2},
+		{ListingQuery{ReleaseID: 100, Currency: schema.CurrencyEUR}, 1},
+	} {
+		test.query.Paging = pagination.First(ListingSorts[0])
+		listings, page, err := ListListings(ctx, db, 1, test.query)
+		require.NoError(t, err)
+		assert.Equal(t, test.expected, page.Total, "%+v", test.query)
+		assert.Len(t, listings, test.expected)
+	}
+
+	by_version := pagination.Request{Sort: ListingSorts[1], Limit: 2}
+	listings, page, err := ListListings(ctx, db, 1, ListingQuery{Status: ListingAll, Paging: by_version})
+	require.NoError(t, err)
+	require.Len(t, listings, 2)
+	assert.Equal(t, uint(2), listings[1].Item)
+	next, err := pagination.ParseCursor(page.Next)
+	require.NoError(t, err)
+	by_version.Cursor = &next
+	listings, page, err = ListListings(ctx, db, 1, ListingQuery{Status: ListingAll, Paging: by_version})
+	require.NoError(t, err)
+	require.Len(t, listings, 1)
+	assert.Equal(t, uint64(1179), listings[0].VersionID)
+	assert.Empty(t, page.Next)
+
+	listings, page, err = ListListings(ctx, db, 2, ListingQuery{Paging: pagination.First(ListingSorts[0])})
+	require.NoError(t, err)
+	assert.Zero(t, page.Total)
+	assert.Empty(t, listings)
+}
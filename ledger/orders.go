@@ -0,0 +1,634 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/ledger/orders.go
+
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kevindamm/cratedigdb/pagination"
+	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
+)
+
+// Returned (wrapped) when changing an order's status in a way that the order
+// lifecycle does not allow, e.g. shipping an order that has not been paid.
+var ErrInvalidTransition = errors.New("invalid order status transition")
+
+// Returned (wrapped) when changing an order that has been completed, merged or
+// cancelled.
+var ErrOrderClosed = errors.New("order is closed")
+
+// Returned (wrapped) when ordering a listed copy that is already part of
+// another open order.
+var ErrReserved = errors.New("copy is part of another open order")
+
+// Returned (wrapped) when a user changes an order they are not a party to, or
+// makes a status change which is the other party's to make (e.g. a buyer
+// marking their own payment as received).
+var ErrNotPermitted = errors.New("not permitted for this party to the order")
+
+// A status which an order may move into, and which party may move it there.
+type transition struct {
+	to schema.OrderStatusEnum
+	by OrderRole
+}
+
+// The statuses that an order may move into from each status.  An order is
+// completed when it is Confirmed, and closed without completing when it is
+// Merged or Cancelled; those have no further transitions.  The seller invoices,
+// receives payment and ships; the buyer starts paying and confirms receipt;
+// either may cancel before payment or ask for a refund after it.
+var order_transitions = map[schema.OrderStatusEnum][]transition{
+	schema.StatusInvoiceSent: {
+		{schema.StatusPaymentPending, RoleBuyer},
+		{schema.StatusPaymentReceived, RoleSeller},
+		{schema.StatusMerged, RoleSeller},
+		{schema.StatusCancelled, RoleAny}},
+	schema.StatusPaymentPending: {
+		{schema.StatusPaymentReceived, RoleSeller},
+		{schema.StatusInvoiceSent, RoleSeller},
+		{schema.StatusCancelled, RoleAny}},
+	schema.StatusPaymentReceived: {
+		{schema.StatusInProgress, RoleSeller},
+		{schema.StatusShipped, RoleSeller},
+		{schema.StatusRefundPending, RoleAny}},
+	schema.StatusInProgress: {
+		{schema.StatusShipped, RoleSeller},
+		{schema.StatusRefundPending, RoleAny}},
+	schema.StatusShipped: {
+		{schema.StatusConfirmed, RoleBuyer},
+		{schema.StatusRefundPending, RoleAny}},
+	schema.StatusRefundPending: {
+		{schema.StatusCancelled, RoleSeller}},
+}
+
+// Whether an order may move from one status into the other by the party in
+// the role (RoleSeller or RoleBuyer, or RoleAny for whether either may).
+func CanTransition(from, to schema.OrderStatusEnum, role OrderRole) bool {
+	for _, next := range order_transitions[from] {
+		if next.to == to && (role == RoleAny || next.by == RoleAny || next.by == role) {
+			return true
+		}
+	}
+	return false
+}
+
+func is_closing(status schema.OrderStatusEnum) bool {
+	return status == schema.StatusConfirmed ||
+		status == schema.StatusMerged ||
+		status == schema.StatusCancelled
+}
+
+// An item (of the buyer) offered in trade, valued at TradeValue or, if nil, at
+// the asking price of its listing.
+type TradeOffer struct {
+	Vinyl      schema.VinylKey `json:"vinyl"`
+	TradeValue *schema.Money   `json:"trade_value,omitempty"`
+}
+
+// The buyer's order for listed copies of a single seller, which may include
+// copies of the buyer's in trade.  Copies are in their compact key form (e.g.
+// "42-1178-2") when encoded as JSON.
+type NewOrder struct {
+	BuyerID   uint64            `json:"-"`
+	Purchases []schema.VinylKey `json:"purchases"`
+	Trades    []TradeOffer      `json:"trades,omitempty"`
+	// Defaults to the total of the purchases minus the value of the trades.
+	OfferPrice *schema.Money `json:"offer_price,omitempty"`
+	Comment    string        `json:"comment,omitempty"`
+}
+
+// The price a listing asks for: its price_high or, if that is not set, its
+// price_low.
+func asking_price(listing schema.Listing) *schema.Money {
+	if listing.PriceHigh != nil {
+		return listing.PriceHigh
+	}
+	return listing.PriceLow
+}
+
+// Creates an order (with the status Invoice Sent) for the purchases, which
+// must be open listings of the same seller and not part of another open order.
+// Traded copies which are not listed yet are recorded at their trade value,
+// without being offered for sale (see record_trade_listing).
+func CreateOrder(ctx context.Context, db *sql.DB, request NewOrder) (schema.Order, error) {
+	invalid := func(field, message string) error {
+		return &schema.ValidationError{Typename: "order", Fields: []schema.FieldError{
+			{Field: field, Message: message}}}
+	}
+	if len(request.Purchases) == 0 {
+		return schema.Order{}, invalid("purchases", "is required")
+	}
+	order := schema.Order{
+		SellerID: request.Purchases[0].UserID,
+		BuyerID:  request.BuyerID,
+		Status:   schema.StatusInvoiceSent,
+	}
+	if order.BuyerID == order.SellerID {
+		return schema.Order{}, invalid("buyerID", "cannot be the seller")
+	}
+	ordered := make(map[schema.VinylKey]bool)
+	for i, key := range request.Purchases {
+		if key.UserID != order.SellerID {
+			return schema.Order{}, invalid(fmt.Sprintf("purchases[%d]", i), "must be listed by the same seller")
+		}
+		if ordered[key] {
+			return schema.Order{}, invalid(fmt.Sprintf("purchases[%d]", i), "is already in the order")
+		}
+		ordered[key] = true
+	}
+	for i, trade := range request.Trades {
+		if trade.Vinyl.UserID != order.BuyerID {
+			return schema.Order{}, invalid(fmt.Sprintf("trades[%d].vinyl", i), "must be the buyer's copy")
+		}
+		if ordered[trade.Vinyl] {
+			return schema.Order{}, invalid(fmt.Sprintf("trades[%d].vinyl", i), "is already in the order")
+		}
+		ordered[trade.Vinyl] = true
+	}
+
+	err := database.InTx(ctx, db, func(tx *sql.Tx) error {
+		var total *schema.Money
+		for i, key := range request.Purchases {
+			listing, err := GetListing(ctx, tx, key)
+			if err != nil {
+				return err
+			}
+			if listing.DateClosed != nil {
+				return fmt.Errorf("listing %s: %w", key, ErrListingClosed)
+			}
+			if err := check_reserved(ctx, tx, key); err != nil {
+				return err
+			}
+			if asking_price(listing) == nil {
+				return invalid(fmt.Sprintf("purchases[%d]", i), "is listed without a price")
+			}
+			price := *asking_price(listing)
+			if total == nil {
+				total = &price
+			} else if *total, err = total.Add(price); err != nil {
+				return invalid("purchases", "must all be listed in the same currency")
+			}
+			order.Purchases = append(order.Purchases, schema.OrderPurchase{
+				SellerID: key.UserID, VersionID: key.VersionID, Item: key.Item, Price: price})
+		}
+
+		for i, trade := range request.Trades {
+			key := trade.Vinyl
+			listing, err := GetListing(ctx, tx, key)
+			if err != nil && !errors.Is(err, database.ErrNotFound) {
+				return err
+			}
+			value := trade.TradeValue
+			if value == nil && err == nil {
+				value = asking_price(listing)
+			}
+			if value == nil {
+				return invalid(fmt.Sprintf("trades[%d].trade_value", i), "is required for a copy that is not listed")
+			}
+			if err := check_reserved(ctx, tx, key); err != nil {
+				return err
+			}
+			if err != nil || listing.DateClosed != nil {
+				if err := record_trade_listing(ctx, tx, key, *value); err != nil {
+					return err
+				}
+			}
+			if *total, err = total.Sub(*value); err != nil {
+				return invalid(fmt.Sprintf("trades[%d].trade_value.currency", i), "must be the order's currency")
+			}
+			order.Trades = append(order.Trades, schema.OrderTrade{
+				BuyerID: key.UserID, VersionID: key.VersionID, Item: key.Item, Value: *value})
+		}
+
+		order.OfferPrice = *total
+		if request.OfferPrice != nil {
+			order.OfferPrice = *request.OfferPrice
+		}
+		if err := order.Validate(); err != nil {
+			return err
+		}
+
+		today, now := schema.Today(), schema.Now()
+		err := tx.QueryRowContext(ctx, `
+		  INSERT INTO Orders (seller_userID, buyer_userID, offer_price, price_currency,
+		    date_opened, last_activity, status)
+		  VALUES (?, ?, ?, ?, ?, ?, ?)
+		  RETURNING orderID`,
+			order.SellerID, order.BuyerID, order.OfferPrice, order.OfferPrice.Currency,
+			today, now, order.Status,
+		).Scan(&order.ID)
+		if err != nil {
+			return fmt.Errorf("creating order: %w", database.Classify(err))
+		}
+		for _, purchase := range order.Purchases {
+			if _, err := tx.ExecContext(ctx, `
+			  INSERT INTO OrderPurchases (orderID, sellerID, versionID, item, purchase_price)
+			  VALUES (?, ?, ?, ?, ?)`,
+				order.ID, purchase.SellerID, purchase.VersionID, purchase.Item,
+				purchase.Price); err != nil {
+				return fmt.Errorf("purchase %s: %w", purchase.VinylKey(), database.Classify(err))
+			}
+		}
+		for _, trade := range order.Trades {
+			if _, err := tx.ExecContext(ctx, `
+			  INSERT INTO OrderTrades (orderID, buyerID, versionID, item, trade_value)
+			  VALUES (?, ?, ?, ?, ?)`,
+				order.ID, trade.BuyerID, trade.VersionID, trade.Item,
+				trade.Value); err != nil {
+				return fmt.Errorf("trade %s: %w", trade.VinylKey(), database.Classify(err))
+			}
+		}
+		if err := log_update(ctx, tx, order.ID, &order.Status, request.Comment); err != nil {
+			return err
+		}
+		order, err = GetOrder(ctx, tx, order.ID)
+		return err
+	})
+	if err != nil {
+		return schema.Order{}, err
+	}
+	return order, nil
+}
+
+// Trades refer to a listing of the buyer's copy, so a copy which is not
+// listed is given one at its trade value.  The listing is closed as soon as it
+// is opened: it records the trade without offering the copy for sale, whether
+// or not the order goes through.
+func record_trade_listing(ctx context.Context, tx *sql.Tx, key schema.VinylKey, value schema.Money) error {
+	listing, err := open_listing(ctx, tx, schema.Listing{
+		UserID: key.UserID, VersionID: key.VersionID, Item: key.Item, PriceLow: &value})
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+	  UPDATE Listings SET date_closed = ?
+	  WHERE userID = ? AND versionID = ? AND item = ?`,
+		listing.DateOpened, key.UserID, key.VersionID, key.Item)
+	if err != nil {
+		return fmt.Errorf("listing %s: %w", key, database.Classify(err))
+	}
+	return nil
+}
+
+// A listed copy may only be in one open order at a time.
+func check_reserved(ctx context.Context, tx *sql.Tx, key schema.VinylKey) error {
+	var reserved bool
+	err := tx.QueryRowContext(ctx, `
+	  SELECT EXISTS (
+	    SELECT 1 FROM OrderPurchases JOIN Orders USING (orderID)
+	    WHERE sellerID = ?1 AND versionID = ?2 AND item = ?3 AND date_closed IS NULL
+	  ) OR EXISTS (
+	    SELECT 1 FROM OrderTrades JOIN Orders USING (orderID)
+	    WHERE buyerID = ?1 AND versionID = ?2 AND item = ?3 AND date_closed IS NULL
+	  )`, key.UserID, key.VersionID, key.Item,
+	).Scan(&reserved)
+	if err != nil {
+		return err
+	}
+	if reserved {
+		return fmt.Errorf("vinyl %s: %w", key, ErrReserved)
+	}
+	return nil
+}
+
+// Appends an update to the order's timeline; status is nil when the update
+// did not change the order's status.
+func log_update(ctx context.Context, tx *sql.Tx, orderID uint64, status *schema.OrderStatusEnum, comment string) error {
+	_, err := tx.ExecContext(ctx, `
+	  INSERT INTO OrderUpdates (orderID, update_time, comment, status)
+	  VALUES (?, ?, ?, ?)`, orderID, schema.Now(), comment, status)
+	if err != nil {
+		return fmt.Errorf("order %d update: %w", orderID, database.Classify(err))
+	}
+	return nil
+}
+
+const order_columns = `orderID, seller_userID, buyer_userID, offer_price, price_currency,
+	date_opened, date_closed, last_activity, status`
+
+func scan_order(rows *sql.Rows) (schema.Order, error) {
+	var order schema.Order
+	return order, rows.Scan(&order.ID, &order.SellerID, &order.BuyerID,
+		&order.OfferPrice, &order.OfferPrice.Currency,
+		&order.DateOpened, &order.DateClosed, &order.LastActivity, &order.Status)
+}
+
+// Looks up the order with its purchases and trades.
+func GetOrder(ctx context.Context, db database.Queryer, orderID uint64) (schema.Order, error) {
+	orders, err := database.QueryList(ctx, db, scan_order, `
+	  SELECT `+order_columns+` FROM Orders WHERE orderID = ?`, orderID)
+	if err != nil {
+		return schema.Order{}, fmt.Errorf("order %d: %w", orderID, err)
+	}
+	if len(orders) == 0 {
+		return schema.Order{}, fmt.Errorf("order %d: %w", orderID, database.ErrNotFound)
+	}
+	order := orders[0]
+	currency := order.OfferPrice.Currency
+
+	order.Purchases, err = database.QueryList(ctx, db, func(rows *sql.Rows) (schema.OrderPurchase, error) {
+		purchase := schema.OrderPurchase{Price: schema.NewMoney(0, currency)}
+		return purchase, rows.Scan(&purchase.ID, &purchase.SellerID,
+			&purchase.VersionID, &purchase.Item, &purchase.Price)
+	}, `
+	  SELECT purchaseID, sellerID, versionID, item, purchase_price
+	  FROM OrderPurchases WHERE orderID = ? ORDER BY purchaseID`, orderID)
+	if err != nil {
+		return schema.Order{}, fmt.Errorf("order %d purchases: %w", orderID, err)
+	}
+	order.Trades, err = database.QueryList(ctx, db, func(rows *sql.Rows) (schema.OrderTrade, error) {
+		trade := schema.OrderTrade{Value: schema.NewMoney(0, currency)}
+		return trade, rows.Scan(&trade.ID, &trade.BuyerID,
+			&trade.VersionID, &trade.Item, &trade.Value)
+	}, `
+	  SELECT tradeID, buyerID, versionID, item, trade_value
+	  FROM OrderTrades WHERE orderID = ? ORDER BY tradeID`, orderID)
+	if err != nil {
+		return schema.Order{}, fmt.Errorf("order %d trades: %w", orderID, err)
+	}
+	return order, nil
+}
+
+// Which of a user's orders to list, by their part in the order.
+type OrderRole string
+
+const (
+	// Orders where the user is the seller or the buyer (the default).
+	RoleAny    OrderRole = "any"
+	RoleSeller OrderRole = "seller"
+	RoleBuyer  OrderRole = "buyer"
+)
+
+var role_conditions = map[OrderRole]string{
+	RoleAny:    `(seller_userID = ?1 OR buyer_userID = ?1)`,
+	RoleSeller: `seller_userID = ?1`,
+	RoleBuyer:  `buyer_userID = ?1`,
+}
+
+func ParseOrderRole(role string) (OrderRole, error) {
+	if role == "" {
+		return RoleAny, nil
+	}
+	if _, found := role_conditions[OrderRole(role)]; !found {
+		return "", fmt.Errorf("unknown order role %q", role)
+	}
+	return OrderRole(role), nil
+}
+
+// Selects a page of a user's orders.
+type OrderQuery struct {
+	Role OrderRole
+	// Only the orders with this status, if not nil.
+	Status *schema.OrderStatusEnum
+	// Only the orders that are still open (not completed, merged or cancelled).
+	Open bool
+
+	// In one of the OrderSorts orders.
+	Paging pagination.Request
+}
+
+// Orders are listed by their last activity (the default, most recent first),
+// by when they were opened (also most recent first) or by orderID.
+var OrderSorts = pagination.Sorts{
+	{Name: "activity", Columns: []pagination.Column{
+		{Expr: "COALESCE(last_activity, '')", Desc: true}, {Expr: "orderID", Desc: true}}},
+	{Name: "opened", Columns: []pagination.Column{
+		{Expr: "COALESCE(date_opened, '')", Desc: true}, {Expr: "orderID", Desc: true}}},
+	{Name: "id", Columns: []pagination.Column{{Expr: "orderID"}}},
+}
+
+// The sort key of an order in one of the OrderSorts orders.
+func order_key(sort pagination.Sort) func(schema.Order) []any {
+	switch strings.TrimPrefix(sort.Name, "-") {
+	case "id":
+		return func(order schema.Order) []any { return []any{order.ID} }
+	case "opened":
+		return func(order schema.Order) []any {
+			var opened string
+			if order.DateOpened != nil {
+				opened = order.DateOpened.String()
+			}
+			return []any{opened, order.ID}
+		}
+	}
+	return func(order schema.Order) []any {
+		var activity string
+		if order.LastActivity != nil {
+			activity = order.LastActivity.String()
+		}
+		return []any{activity, order.ID}
+	}
+}
+
+// Returns the orders on the requested page (without their purchases and
+// trades, see GetOrder), described along with the total number of orders
+// matching the query.
+func ListOrders(ctx context.Context, db database.Queryer, userID uint64, query OrderQuery) ([]schema.Order, pagination.Page, error) {
+	if query.Role == "" {
+		query.Role = RoleAny
+	}
+	role, found := role_conditions[query.Role]
+	if !found {
+		return nil, pagination.Page{}, fmt.Errorf("unknown order role %q", query.Role)
+	}
+
+	conditions := []string{role}
+	args := []any{userID}
+	if query.Status != nil {
+		args = append(args, *query.Status)
+		conditions = append(conditions, fmt.Sprintf(`status = ?%d`, len(args)))
+	}
+	if query.Open {
+		conditions = append(conditions, `date_closed IS NULL`)
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int
+	err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM Orders WHERE `+where, args...).Scan(&total)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+	// The cursor's plain ? parameters are numbered after those of the filters.
+	after, after_args := query.Paging.Where()
+	orders, err := database.QueryList(ctx, db, scan_order, `
+	  SELECT `+order_columns+` FROM Orders WHERE `+where+` AND `+after+`
+	  ORDER BY `+query.Paging.OrderBy()+`
+	  LIMIT ?`,
+		append(append(args, after_args...), query.Paging.Fetch())...)
+	if err != nil {
+		return nil, pagination.Page{}, err
+	}
+	orders, page := pagination.Paginate(query.Paging, orders, total, order_key(query.Paging.Sort))
+	return orders, page, nil
+}
+
+// The order's timeline, oldest first.
+func ListOrderUpdates(ctx context.Context, db database.Queryer, orderID uint64) ([]schema.OrderUpdate, error) {
+	if _, err := GetOrder(ctx, db, orderID); err != nil {
+		return nil, err
+	}
+	return database.QueryList(ctx, db, func(rows *sql.Rows) (schema.OrderUpdate, error) {
+		var update schema.OrderUpdate
+		return update, rows.Scan(&update.ID, &update.OrderID, &update.UpdateTime,
+			&update.Comment, &update.Status)
+	}, `
+	  SELECT updateID, orderID, update_time, comment, status
+	  FROM OrderUpdates WHERE orderID = ?
+	  ORDER BY update_time, updateID`, orderID)
+}
+
+// A change to an open order: its status, its offer price (only until payment
+// is received) and/or a comment.  Nil fields are left unchanged.
+type OrderChange struct {
+	// The user making the change, who must be the order's buyer or seller.
+	UserID     uint64                  `json:"-"`
+	Status     *schema.OrderStatusEnum `json:"status,omitempty"`
+	OfferPrice *schema.Money           `json:"offer_price,omitempty"`
+	Comment    string                  `json:"comment,omitempty"`
+}
+
+// Applies the change to the order and logs it in the order's timeline.  Only
+// the buyer and seller may change the order, and each only moves it into the
+// statuses of their role (see order_transitions).  When the order is
+// Confirmed its listings are closed and its purchased copies marked sold (and
+// traded copies traded), all in the same transaction.
+func ChangeOrder(ctx context.Context, db *sql.DB, orderID uint64, change OrderChange) (schema.Order, error) {
+	invalid := func(field, message string) error {
+		return &schema.ValidationError{Typename: "order", Fields: []schema.FieldError{
+			{Field: field, Message: message}}}
+	}
+	if change.Status == nil && change.OfferPrice == nil && change.Comment == "" {
+		return schema.Order{}, invalid("status", "a status, offer_price or comment is required")
+	}
+
+	var order schema.Order
+	err := database.InTx(ctx, db, func(tx *sql.Tx) error {
+		var err error
+		if order, err = GetOrder(ctx, tx, orderID); err != nil {
+			return err
+		}
+		if order.DateClosed != nil {
+			return fmt.Errorf("order %d: %w", orderID, ErrOrderClosed)
+		}
+		var role OrderRole
+		switch {
+		case change.UserID == 0:
+			return fmt.Errorf("order %d: no user: %w", orderID, ErrNotPermitted)
+		case change.UserID == order.SellerID:
+			role = RoleSeller
+		case change.UserID == order.BuyerID:
+			role = RoleBuyer
+		default:
+			return fmt.Errorf("order %d: user %d: %w", orderID, change.UserID, ErrNotPermitted)
+		}
+
+		comment := change.Comment
+		if change.OfferPrice != nil {
+			if order.Status != schema.StatusInvoiceSent && order.Status != schema.StatusPaymentPending {
+				return invalid("offer_price", "cannot change once payment is received")
+			}
+			order.OfferPrice = *change.OfferPrice
+			if err := order.Validate(); err != nil {
+				return err
+			}
+			if comment == "" {
+				comment = "offer price changed to " + order.OfferPrice.String()
+			}
+		}
+		if change.Status != nil {
+			if !CanTransition(order.Status, *change.Status, RoleAny) {
+				return fmt.Errorf("order %d from %s to %s: %w",
+					orderID, order.Status, *change.Status, ErrInvalidTransition)
+			}
+			if !CanTransition(order.Status, *change.Status, role) {
+				return fmt.Errorf("order %d from %s to %s by the %s: %w",
+					orderID, order.Status, *change.Status, role, ErrNotPermitted)
+			}
+			order.Status = *change.Status
+		}
+
+		var closed *schema.Date
+		if is_closing(order.Status) {
+			today := schema.Today()
+			closed = &today
+		}
+		_, err = tx.ExecContext(ctx, `
+		  UPDATE Orders SET offer_price = ?, status = ?, date_closed = ?, last_activity = ?
+		  WHERE orderID = ?`,
+			order.OfferPrice, order.Status, closed, schema.Now(), orderID)
+		if err != nil {
+			return fmt.Errorf("order %d: %w", orderID, database.Classify(err))
+		}
+		if order.Status == schema.StatusConfirmed {
+			if err := complete_order(ctx, tx, order); err != nil {
+				return err
+			}
+		}
+		if err := log_update(ctx, tx, orderID, change.Status, comment); err != nil {
+			return err
+		}
+		order, err = GetOrder(ctx, tx, orderID)
+		return err
+	})
+	if err != nil {
+		return schema.Order{}, err
+	}
+	return order, nil
+}
+
+// Closes the listings of the order's purchases and trades, and marks the
+// purchased copies as sold and the traded copies as traded.
+func complete_order(ctx context.Context, tx *sql.Tx, order schema.Order) error {
+	today := schema.Today()
+	close_and_mark := func(key schema.VinylKey, column string) error {
+		if _, err := tx.ExecContext(ctx, `
+		  UPDATE Listings SET date_closed = ?
+		  WHERE userID = ? AND versionID = ? AND item = ? AND date_closed IS NULL`,
+			today, key.UserID, key.VersionID, key.Item); err != nil {
+			return fmt.Errorf("listing %s: %w", key, database.Classify(err))
+		}
+		if _, err := tx.ExecContext(ctx, `
+		  UPDATE VinylItems SET `+column+` = ?
+		  WHERE userID = ? AND versionID = ? AND item = ?`,
+			today, key.UserID, key.VersionID, key.Item); err != nil {
+			return fmt.Errorf("vinyl %s: %w", key, database.Classify(err))
+		}
+		return nil
+	}
+	for _, purchase := range order.Purchases {
+		if err := close_and_mark(purchase.VinylKey(), "date_sold"); err != nil {
+			return err
+		}
+	}
+	for _, trade := range order.Trades {
+		if err := close_and_mark(trade.VinylKey(), "date_traded"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/ledger/orders_test.go
+
+package ledger
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/kevindamm/cratedigdb/collection"
+	"github.com/kevindamm/cratedigdb/pagination"
+	"github.com/kevindamm/cratedigdb/schema"
+	database "github.com/kevindamm/cratedigdb/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Lists kevin's 1178 items 1 and 2 (at $20 and $15) and gives dana a copy of
+// 1179 to trade.
+func add_test_listings(t *testing.T, db *sql.DB) {
+	ctx := context.Background()
+	add_test_collection(t, db)
+	for _, listing := range []schema.Listing{
+		{UserID: 1, VersionID: 1178, Item: 1, PriceLow: usd(1800), PriceHigh: usd(2000)},
+		{UserID: 1, VersionID: 1178, Item: 2, PriceLow: usd(1500)},
+	} {
+		_, err := OpenListing(ctx, db, listing)
+		require.NoError(t, err)
+	}
+	var allocator collection.ItemAllocator
+	_, err := allocator.Add(ctx, db, schema.Vinyl{UserID: 2, VersionID: 1179})
+	require.NoError(t, err)
+}
+
+var (
+	first  = schema.VinylKey{UserID: 1, VersionID: 1178, Item: 1}
+	second = schema.VinylKey{UserID: 1, VersionID: 1178, Item: 2}
+	danas  = schema.VinylKey{UserID: 2, VersionID: 1179, Item: 1}
+)
+
+func status(status schema.OrderStatusEnum) *schema.OrderStatusEnum {
+	return &status
+}
+
+func TestCreateOrder(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	add_test_listings(t, db)
+
+	order, err := CreateOrder(ctx, db, NewOrder{
+		BuyerID:   2,
+		Purchases: []schema.VinylKey{first, second},
+		Trades:    []TradeOffer{{Vinyl: danas, TradeValue: usd(500)}},
+		Comment:   "will trade my remaster",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), order.SellerID)
+	assert.Equal(t, uint64(2), order.BuyerID)
+	assert.Equal(t, schema.StatusInvoiceSent, order.Status)
+	assert.Equal(t, *usd(3000), order.OfferPrice, "asking prices minus the trade value")
+	require.Len(t, order.Purchases, 2)
+	assert.Equal(t, *usd(2000), order.Purchases[0].Price, "the asking price is price_high")
+	require.Len(t, order.Trades, 1)
+
+	listing, err := GetListing(ctx, db, danas)
+	require.NoError(t, err)
+	assert.Equal(t, usd(500), listing.PriceLow, "the traded copy is recorded at its trade value")
+	assert.NotNil(t, listing.DateClosed, "but not offered for sale")
+
+	updates, err := ListOrderUpdates(ctx, db, order.ID)
+	require.NoError(t, err)
+	require.Len(t, updates, 1)
+	assert.Equal(t, "will trade my remaster", updates[0].Comment)
+	assert.Equal(t, status(schema.StatusInvoiceSent), updates[0].Status)
+
+	_, err = CreateOrder(ctx, db, NewOrder{BuyerID: 2, Purchases: []schema.VinylKey{second}})
+	assert.ErrorIs(t, err, ErrReserved)
+	_, err = CreateOrder(ctx, db, NewOrder{BuyerID: 2})
+	assert.ErrorContains(t, err, "purchases: is required")
+	_, err = CreateOrder(ctx, db, NewOrder{BuyerID: 1, Purchases: []schema.VinylKey{second}})
+	assert.ErrorContains(t, err, "buyerID: cannot be the seller")
+	_, err = CreateOrder(ctx, db, NewOrder{BuyerID: 2,
+		Purchases: []schema.VinylKey{{UserID: 1, VersionID: 1179, Item: 1}}})
+	assert.ErrorIs(t, err, database.ErrNotFound, "not listed")
+}
+
+func TestOrderTransitions(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	add_test_listings(t, db)
+	order, err := CreateOrder(ctx, db, NewOrder{BuyerID: 2, Purchases: []schema.VinylKey{first}})
+	require.NoError(t, err)
+
+	_, err = ChangeOrder(ctx, db, order.ID, OrderChange{UserID: 1, Status: status(schema.StatusShipped)})
+	assert.ErrorIs(t, err, ErrInvalidTransition, "cannot ship before payment")
+
+	order, err = ChangeOrder(ctx, db, order.ID, OrderChange{UserID: 2, OfferPrice: usd(1900)})
+	require.NoError(t, err)
+	assert.Equal(t, *usd(1900), order.OfferPrice)
+	for _, next := range []schema.OrderStatusEnum{
+		schema.StatusPaymentReceived, schema.StatusShipped,
+	} {
+		order, err = ChangeOrder(ctx, db, order.ID, OrderChange{UserID: 1, Status: &next})
+		require.NoError(t, err)
+		assert.Equal(t, next, order.Status)
+	}
+	_, err = ChangeOrder(ctx, db, order.ID, OrderChange{UserID: 2, OfferPrice: usd(1000)})
+	assert.ErrorContains(t, err, "offer_price: cannot change once payment is received")
+	assert.Nil(t, order.DateClosed)
+
+	order, err = ChangeOrder(ctx, db, order.ID, OrderChange{UserID: 2,
+		Status: status(schema.StatusConfirmed), Comment: "arrived safely"})
+	require.NoError(t, err)
+	assert.Equal(t, schema.Today(), *order.DateClosed)
+	_, err = ChangeOrder(ctx, db, order.ID, OrderChange{UserID: 1, Comment: "thanks!"})
+	assert.ErrorIs(t, err, ErrOrderClosed)
+
+	updates, err := ListOrderUpdates(ctx, db, order.ID)
+	require.NoError(t, err)
+	require.Len(t, updates, 5)
+	assert.Nil(t, updates[1].Status, "the price change did not change the status")
+	assert.Equal(t, "offer price changed to 19.00 USD", updates[1].Comment)
+	assert.Equal(t, "arrived safely", updates[4].Comment)
+}
+
+func TestOrderTransitionsByRole(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	add_test_listings(t, db)
+	order, err := CreateOrder(ctx, db, NewOrder{BuyerID: 2, Purchases: []schema.VinylKey{first}})
+	require.NoError(t, err)
+
+	for _, change := range []OrderChange{
+		{UserID: 2, Status: status(schema.StatusPaymentReceived)},
+		{UserID: 1, Status: status(schema.StatusPaymentPending)},
+		{UserID: 3, Comment: "not my order"},
+		{Comment: "nobody"},
+	} {
+		_, err = ChangeOrder(ctx, db, order.ID, change)
+		assert.ErrorIs(t, err, ErrNotPermitted, "%+v", change)
+	}
+	order, err = ChangeOrder(ctx, db, order.ID, OrderChange{UserID: 2, Status: status(schema.StatusPaymentPending)})
+	require.NoError(t, err)
+	order, err = ChangeOrder(ctx, db, order.ID, OrderChange{UserID: 1, Status: status(schema.StatusPaymentReceived)})
+	require.NoError(t, err)
+	_, err = ChangeOrder(ctx, db, order.ID, OrderChange{UserID: 2, Status: status(schema.StatusShipped)})
+	assert.ErrorIs(t, err, ErrNotPermitted, "only the seller ships")
+	order, err = ChangeOrder(ctx, db, order.ID, OrderChange{UserID: 1, Status: status(schema.StatusShipped)})
+	require.NoError(t, err)
+	_, err = ChangeOrder(ctx, db, order.ID, OrderChange{UserID: 1, Status: status(schema.StatusConfirmed)})
+	assert.ErrorIs(t, err, ErrNotPermitted, "only the buyer confirms receipt")
+	order, err = ChangeOrder(ctx, db, order.ID, OrderChange{UserID: 2, Status: status(schema.StatusRefundPending)})
+	require.NoError(t, err, "either may ask for a refund")
+	assert.Equal(t, schema.StatusRefundPending, order.Status)
+
+	assert.True(t, CanTransition(schema.StatusShipped, schema.StatusConfirmed, RoleAny))
+	assert.False(t, CanTransition(schema.StatusShipped, schema.StatusConfirmed, RoleSeller))
+	assert.False(t, CanTransition(schema.StatusShipped, schema.StatusInvoiceSent, RoleAny))
+}
+
+func TestCompleteOrder(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	add_test_listings(t, db)
+	order, err := CreateOrder(ctx, db, NewOrder{
+		BuyerID:   2,
+		Purchases: []schema.VinylKey{first},
+		Trades:    []TradeOffer{{Vinyl: danas, TradeValue: usd(500)}},
+	})
+	require.NoError(t, err)
+	for _, next := range []schema.OrderStatusEnum{
+		schema.StatusPaymentReceived, schema.StatusInProgress, schema.StatusShipped,
+	} {
+		_, err = ChangeOrder(ctx, db, order.ID, OrderChange{UserID: 1, Status: &next})
+		require.NoError(t, err)
+	}
+
+	// Closing the order fails (and rolls back) if a copy cannot be marked sold.
+	_, err = db.Exec(`
+	  CREATE TRIGGER refuse_sale BEFORE UPDATE OF date_sold ON VinylItems
+	  BEGIN SELECT RAISE(ABORT, 'refused'); END`)
+	require.NoError(t, err)
+	_, err = ChangeOrder(ctx, db, order.ID, OrderChange{UserID: 2, Status: status(schema.StatusConfirmed)})
+	require.Error(t, err)
+	vinyl, err := collection.GetVinyl(ctx, db, danas)
+	require.NoError(t, err)
+	assert.Nil(t, vinyl.DateTraded, "the trade is not marked")
+	_, err = db.Exec(`DROP TRIGGER refuse_sale`)
+	require.NoError(t, err)
+
+	_, err = ChangeOrder(ctx, db, order.ID, OrderChange{UserID: 2, Status: status(schema.StatusConfirmed)})
+	require.NoError(t, err)
+	sold, err := collection.GetVinyl(ctx, db, first)
+	require.NoError(t, err)
+	assert.Equal(t, schema.Today(), *sold.DateSold)
+	traded, err := collection.GetVinyl(ctx, db, danas)
+	require.NoError(t, err)
+	assert.Equal(t, schema.Today(), *traded.DateTraded)
+	for _, key := range []schema.VinylKey{first, danas} {
+		listing, err := GetListing(ctx, db, key)
+		require.NoError(t, err)
+		assert.NotNil(t, listing.DateClosed, key.String())
+	}
+	listing, err := GetListing(ctx, db, second)
+	require.NoError(t, err)
+	assert.Nil(t, listing.DateClosed, "only the ordered listings are closed")
+}
+
+func TestCancelOrderWithUnlistedTrade(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	add_test_listings(t, db)
+	order, err := CreateOrder(ctx, db, NewOrder{BuyerID: 2, Purchases: []schema.VinylKey{first},
+		Trades: []TradeOffer{{Vinyl: danas, TradeValue: usd(500)}}})
+	require.NoError(t, err)
+	_, err = CreateOrder(ctx, db, NewOrder{BuyerID: 2, Purchases: []schema.VinylKey{second},
+		Trades: []TradeOffer{{Vinyl: danas, TradeValue: usd(400)}}})
+	assert.ErrorIs(t, err, ErrReserved, "the traded copy is in the first order")
+
+	_, err = ChangeOrder(ctx, db, order.ID, OrderChange{UserID: 2, Status: status(schema.StatusCancelled)})
+	require.NoError(t, err)
+	first_page := pagination.First(ListingSorts[0])
+	listings, _, err := ListListings(ctx, db, 2, ListingQuery{Paging: first_page})
+	require.NoError(t, err)
+	assert.Empty(t, listings, "the buyer's copy is not left on sale")
+	vinyl, err := collection.GetVinyl(ctx, db, danas)
+	require.NoError(t, err)
+	assert.Nil(t, vinyl.DateTraded)
+
+	// It may be offered in another trade.
+	_, err = CreateOrder(ctx, db, NewOrder{BuyerID: 2, Purchases: []schema.VinylKey{second},
+		Trades: []TradeOffer{{Vinyl: danas, TradeValue: usd(400)}}})
+	assert.NoError(t, err)
+}
+
+func TestCancelOrderReleasesListings(t *testing.T) {
+	ctx := context.Background()
+	db := open_test_db(t)
+	add_test_listings(t, db)
+	order, err := CreateOrder(ctx, db, NewOrder{BuyerID: 2, Purchases: []schema.VinylKey{first}})
+	require.NoError(t, err)
+
+	order, err = ChangeOrder(ctx, db, order.ID, OrderChange{UserID: 2, Status: status(schema.StatusCancelled)})
+	require.NoError(t, err)
+	assert.NotNil(t, order.DateClosed)
+	vinyl, err := collection.GetVinyl(ctx, db, first)
+	require.NoError(t, err)
+	assert.Nil(t, vinyl.DateSold)
+
+	_, err = CreateOrder(ctx, db, NewOrder{BuyerID: 2, Purchases: []schema.VinylKey{first}})
+	assert.NoError(t, err, "the listing may be ordered again")
+
+	first_page := pagination.First(OrderSorts[0])
+	orders, page, err := ListOrders(ctx, db, 2, OrderQuery{Role: RoleBuyer, Open: true, Paging: first_page})
+	require.NoError(t, err)
+	assert.Equal(t, 1, page.Total)
+	assert.Len(t, orders, 1)
+	_, page, err = ListOrders(ctx, db, 1, OrderQuery{Status: status(schema.StatusCancelled), Paging: first_page})
+	require.NoError(t, err)
+	assert.Equal(t, 1, page.Total)
+	_, page, err = ListOrders(ctx, db, 1, OrderQuery{Role: RoleBuyer, Paging: first_page})
+	require.NoError(t, err)
+	assert.Zero(t, page.Total)
+
+	// The cursor's parameters follow the numbered userID parameter.
+	by_id := pagination.Request{Sort: OrderSorts[2], Limit: 1}
+	orders, page, err = ListOrders(ctx, db, 1, OrderQuery{Role: RoleSeller, Paging: by_id})
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	assert.Equal(t, order.ID, orders[0].ID)
+	next, err := pagination.ParseCursor(page.Next)
+	require.NoError(t, err)
+	by_id.Cursor = &next
+	orders, page, err = ListOrders(ctx, db, 1, OrderQuery{Role: RoleSeller, Paging: by_id})
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	assert.Greater(t, orders[0].ID, order.ID)
+	assert.Empty(t, page.Next)
+}
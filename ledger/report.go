@@ -0,0 +1,102 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/ledger/report.go
+
+package ledger
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kevindamm/cratedigdb/schema"
+)
+
+// A user's completed orders, valued in a single currency.
+type OrderTotals struct {
+	Currency  schema.CurrencyEnum `json:"currency"`
+	Orders    int                 `json:"orders"`
+	Sales     schema.Money        `json:"sales"`
+	Purchases schema.Money        `json:"purchases"`
+}
+
+// Totals the offer price of every completed (Confirmed) order where the user
+// is the seller or the buyer, converted into currency at the rates for the
+// date the order was closed.  Orders which are still open have not been paid
+// for (or may yet be refunded), and merged orders are counted in the order
+// they were merged into, so neither is included, nor are cancelled orders.
+func (converter *Converter) OrderTotals(ctx context.Context, userID uint64, currency schema.CurrencyEnum) (OrderTotals, error) {
+	totals := OrderTotals{
+		Currency:  currency,
+		Sales:     schema.NewMoney(0, currency),
+		Purchases: schema.NewMoney(0, currency),
+	}
+	rows, err := converter.db.QueryContext(ctx, `
+	  SELECT orderID, seller_userID, offer_price, price_currency,
+	         COALESCE(date_closed, date_opened)
+	  FROM Orders
+	  WHERE (seller_userID = ?1 OR buyer_userID = ?1) AND status = ?2`,
+		userID, schema.StatusConfirmed)
+	if err != nil {
+		return totals, err
+	}
+	defer rows.Close()
+
+	// The orders are read before converting so that the rate lookups do not
+	// need a second connection while these rows are open.
+	type valuation struct {
+		orderID, sellerID uint64
+		price             schema.Money
+		date              schema.Date
+	}
+	orders := make([]valuation, 0)
+	for rows.Next() {
+		var order valuation
+		if err := rows.Scan(&order.orderID, &order.sellerID,
+			&order.price, &order.price.Currency, &order.date); err != nil {
+			return totals, err
+		}
+		orders = append(orders, order)
+	}
+	if err := rows.Err(); err != nil {
+		return totals, err
+	}
+	rows.Close()
+
+	for _, order := range orders {
+		if order.date.IsZero() {
+			return totals, fmt.Errorf("order %d has no date to value it on", order.orderID)
+		}
+		value, err := converter.Convert(ctx, order.price, currency, order.date)
+		if err != nil {
+			return totals, fmt.Errorf("order %d: %w", order.orderID, err)
+		}
+
+		total := &totals.Purchases
+		if order.sellerID == userID {
+			total = &totals.Sales
+		}
+		if *total, err = total.Add(value); err != nil {
+			return totals, err
+		}
+		totals.Orders++
+	}
+	return totals, nil
+}
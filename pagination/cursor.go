@@ -0,0 +1,78 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/pagination/cursor.go
+
+package pagination
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// The position of an item in a sort order, by its values for the sort's
+// columns.  A cursor selects the items after it or, if Before, the items
+// before it.  Clients treat its String form as opaque.
+type Cursor struct {
+	Sort   string `json:"s"`
+	Keys   []any  `json:"k"`
+	Before bool   `json:"b,omitempty"`
+}
+
+// The URL-safe (unpadded base64) encoding of the cursor.
+func (cursor Cursor) String() string {
+	encoded, err := json.Marshal(cursor)
+	if err != nil {
+		// The keys are scanned from the database, which are always encodable.
+		panic(fmt.Sprintf("encoding cursor: %v", err))
+	}
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+func ParseCursor(text string) (Cursor, error) {
+	var cursor Cursor
+	encoded, err := base64.RawURLEncoding.DecodeString(text)
+	if err != nil {
+		return cursor, fmt.Errorf("%w: malformed cursor", ErrInvalid)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	if err := decoder.Decode(&cursor); err != nil || len(cursor.Keys) == 0 {
+		return Cursor{}, fmt.Errorf("%w: malformed cursor", ErrInvalid)
+	}
+	for i, key := range cursor.Keys {
+		switch value := key.(type) {
+		case json.Number:
+			if integer, err := value.Int64(); err == nil {
+				cursor.Keys[i] = integer
+			} else if real, err := value.Float64(); err == nil {
+				cursor.Keys[i] = real
+			} else {
+				return Cursor{}, fmt.Errorf("%w: malformed cursor", ErrInvalid)
+			}
+		case string, bool:
+		default:
+			return Cursor{}, fmt.Errorf("%w: malformed cursor", ErrInvalid)
+		}
+	}
+	return cursor, nil
+}
@@ -0,0 +1,270 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/pagination/pagination.go
+
+// Package pagination pages through lists with opaque keyset cursors, which
+// (unlike page numbers) stay stable when items are inserted or removed
+// between requests.  Each list has named sort orders whose columns together
+// identify an item; a cursor holds the sort key of the item at the edge of a
+// page, and the next page is everything after it in that order.
+package pagination
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 100
+)
+
+// Returned (wrapped) for malformed sort, limit or cursor parameters.
+var ErrInvalid = errors.New("invalid pagination")
+
+// A column (or any SQL expression which is never NULL) of a sort order.
+type Column struct {
+	Expr string
+	Desc bool
+}
+
+// A named sort order.  The last columns must make it unique (e.g. the primary
+// key) so that every item has a distinct position between cursors.
+type Sort struct {
+	Name    string
+	Columns []Column
+}
+
+// The reverse order, named with a "-" prefix (or without it when reversing a
+// reversed order).
+func (sort Sort) Reverse() Sort {
+	reversed := Sort{Name: "-" + sort.Name, Columns: make([]Column, len(sort.Columns))}
+	if name, found := strings.CutPrefix(sort.Name, "-"); found {
+		reversed.Name = name
+	}
+	for i, column := range sort.Columns {
+		reversed.Columns[i] = Column{column.Expr, !column.Desc}
+	}
+	return reversed
+}
+
+// The sort orders a list supports; the first is its default.
+type Sorts []Sort
+
+// Finds the sort by name, where a "-" prefix reverses it.  The empty name is
+// the default sort.
+func (sorts Sorts) Find(name string) (Sort, error) {
+	if name == "" && len(sorts) > 0 {
+		return sorts[0], nil
+	}
+	base, reverse := strings.CutPrefix(name, "-")
+	for _, sort := range sorts {
+		if sort.Name == base {
+			if reverse {
+				return sort.Reverse(), nil
+			}
+			return sort, nil
+		}
+	}
+	names := make([]string, len(sorts))
+	for i, sort := range sorts {
+		names[i] = sort.Name
+	}
+	return Sort{}, fmt.Errorf("%w: unknown sort %q, expected one of %s",
+		ErrInvalid, name, strings.Join(names, ", "))
+}
+
+// A request for the page at the cursor (or the first page), in the given
+// sort order, of at most Limit items.
+type Request struct {
+	Sort   Sort
+	Limit  int
+	Cursor *Cursor
+}
+
+// The first page in the sort order, with the default limit.
+func First(sort Sort) Request {
+	return Request{Sort: sort, Limit: DefaultLimit}
+}
+
+// Reads the sort, limit and cursor query parameters.  A cursor must have
+// been made for the same sort order.
+func Parse(values url.Values, sorts Sorts) (Request, error) {
+	sort, err := sorts.Find(values.Get("sort"))
+	if err != nil {
+		return Request{}, err
+	}
+	request := First(sort)
+	if value := values.Get("limit"); value != "" {
+		request.Limit, err = strconv.Atoi(value)
+		if err != nil || request.Limit < 1 || request.Limit > MaxLimit {
+			return Request{}, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalid, MaxLimit)
+		}
+	}
+	if value := values.Get("cursor"); value != "" {
+		cursor, err := ParseCursor(value)
+		if err != nil {
+			return Request{}, err
+		}
+		if cursor.Sort != sort.Name || len(cursor.Keys) != len(sort.Columns) {
+			return Request{}, fmt.Errorf("%w: the cursor is not for sort %q", ErrInvalid, sort.Name)
+		}
+		request.Cursor = &cursor
+	}
+	return request, nil
+}
+
+// Whether the request reads backwards from its cursor (for a previous page).
+func (request Request) backward() bool {
+	return request.Cursor != nil && request.Cursor.Before
+}
+
+// The ORDER BY terms for reading the page, which are reversed when reading
+// backwards from a cursor.
+func (request Request) OrderBy() string {
+	sort := request.Sort
+	if request.backward() {
+		sort = sort.Reverse()
+	}
+	terms := make([]string, len(sort.Columns))
+	for i, column := range sort.Columns {
+		terms[i] = column.Expr + " ASC"
+		if column.Desc {
+			terms[i] = column.Expr + " DESC"
+		}
+	}
+	return strings.Join(terms, ", ")
+}
+
+// A condition (with its arguments as plain ? parameters) selecting the items
+// after the cursor in the read order, or TRUE if there is no cursor.
+func (request Request) Where() (string, []any) {
+	if request.Cursor == nil {
+		return "TRUE", nil
+	}
+	sort := request.Sort
+	if request.backward() {
+		sort = sort.Reverse()
+	}
+	// (c1 > k1) OR (c1 = k1 AND c2 > k2) OR ..., with < for descending columns.
+	var terms []string
+	var args []any
+	for i, column := range sort.Columns {
+		var term []string
+		for j := range i {
+			term = append(term, sort.Columns[j].Expr+" = ?")
+			args = append(args, request.Cursor.Keys[j])
+		}
+		operator := " > ?"
+		if column.Desc {
+			operator = " < ?"
+		}
+		term = append(term, column.Expr+operator)
+		args = append(args, request.Cursor.Keys[i])
+		terms = append(terms, "("+strings.Join(term, " AND ")+")")
+	}
+	return "(" + strings.Join(terms, " OR ") + ")", args
+}
+
+// The LIMIT for reading the page: one more than requested, so that Paginate
+// can tell whether there are more items beyond it.
+func (request Request) Fetch() int {
+	return request.Limit + 1
+}
+
+// The page of results: its cursors and the total number of items (on every
+// page).  The next or previous cursor is empty if there is no such page.
+type Page struct {
+	Sort  string `json:"sort"`
+	Limit int    `json:"limit"`
+	Total int    `json:"total"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+}
+
+// Trims the items, read with OrderBy, Where and Fetch, to the requested page
+// and describes it.  The key function returns an item's values for the
+// columns of the request's sort order.
+func Paginate[T any](request Request, items []T, total int, key func(T) []any) ([]T, Page) {
+	page := Page{Sort: request.Sort.Name, Limit: request.Limit, Total: total}
+	more := len(items) > request.Limit
+	if more {
+		items = items[:request.Limit]
+	}
+	if request.backward() {
+		slices.Reverse(items)
+	}
+	if len(items) == 0 {
+		return items, page
+	}
+
+	first, last := key(items[0]), key(items[len(items)-1])
+	name := request.Sort.Name
+	if request.backward() {
+		// Reached by going back from the next page, so there is one.
+		page.Next = Cursor{Sort: name, Keys: last}.String()
+		if more {
+			page.Prev = Cursor{Sort: name, Keys: first, Before: true}.String()
+		}
+	} else {
+		if more {
+			page.Next = Cursor{Sort: name, Keys: last}.String()
+		}
+		if request.Cursor != nil {
+			page.Prev = Cursor{Sort: name, Keys: first, Before: true}.String()
+		}
+	}
+	return items, page
+}
+
+// The RFC 8288 Link header value for the page, relative to the request URL
+// (whose other query parameters are kept): the first page and, if there are
+// any, the next and previous pages.
+func (page Page) Links(request *url.URL) string {
+	link := func(cursor, rel string) string {
+		return fmt.Sprintf(`<%s>; rel="%s"`, WithCursor(request, cursor), rel)
+	}
+	links := []string{link("", "first")}
+	if page.Prev != "" {
+		links = append(links, link(page.Prev, "prev"))
+	}
+	if page.Next != "" {
+		links = append(links, link(page.Next, "next"))
+	}
+	return strings.Join(links, ", ")
+}
+
+// The request URL with its cursor parameter replaced (or removed, if empty),
+// keeping its other query parameters.
+func WithCursor(request *url.URL, cursor string) string {
+	target := *request
+	query := target.Query()
+	query.Del("cursor")
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+	target.RawQuery = query.Encode()
+	return target.String()
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/pagination/pagination_test.go
+
+package pagination
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"testing"
+
+	database "github.com/kevindamm/cratedigdb/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var test_sorts = Sorts{
+	{"name", []Column{{Expr: "name COLLATE NOCASE"}, {Expr: "id"}}},
+	{"added", []Column{{Expr: "added", Desc: true}, {Expr: "id"}}},
+}
+
+func TestParse(t *testing.T) {
+	request, err := Parse(url.Values{}, test_sorts)
+	require.NoError(t, err)
+	assert.Equal(t, "name", request.Sort.Name)
+	assert.Equal(t, DefaultLimit, request.Limit)
+	assert.Nil(t, request.Cursor)
+
+	request, err = Parse(url.Values{"sort": {"-added"}, "limit": {"10"}}, test_sorts)
+	require.NoError(t, err)
+	assert.Equal(t, "-added", request.Sort.Name)
+	assert.Equal(t, "added ASC, id DESC", request.OrderBy())
+
+	cursor := Cursor{Sort: "-added", Keys: []any{"2025-01-02", 7}}
+	request, err = Parse(url.Values{"sort": {"-added"}, "cursor": {cursor.String()}}, test_sorts)
+	require.NoError(t, err)
+	assert.Equal(t, []any{"2025-01-02", int64(7)}, request.Cursor.Keys)
+
+	for _, values := range []url.Values{
+		{"sort": {"price"}},
+		{"limit": {"0"}},
+		{"limit": {"101"}},
+		{"cursor": {"not a cursor"}},
+		{"cursor": {cursor.String()}},
+	} {
+		_, err := Parse(values, test_sorts)
+		assert.ErrorIs(t, err, ErrInvalid, values.Encode())
+	}
+}
+
+func TestWhere(t *testing.T) {
+	request := First(test_sorts[1])
+	condition, args := request.Where()
+	assert.Equal(t, "TRUE", condition)
+	assert.Empty(t, args)
+
+	request.Cursor = &Cursor{Sort: "added", Keys: []any{"2025-01-02", int64(7)}}
+	condition, args = request.Where()
+	assert.Equal(t, "((added < ?) OR (added = ? AND id > ?))", condition)
+	assert.Equal(t, []any{"2025-01-02", "2025-01-02", int64(7)}, args)
+
+	request.Cursor.Before = true
+	condition, _ = request.Where()
+	assert.Equal(t, "((added > ?) OR (added = ? AND id < ?))", condition)
+	assert.Equal(t, "added ASC, id DESC", request.OrderBy())
+}
+
+type named struct {
+	id   int64
+	name string
+}
+
+// Reads a page of the test table with the request, as the list functions do.
+func read_page(t *testing.T, db *sql.DB, request Request) ([]named, Page) {
+	condition, args := request.Where()
+	items, err := database.QueryList(context.Background(), db, func(rows *sql.Rows) (named, error) {
+		var item named
+		return item, rows.Scan(&item.id, &item.name)
+	}, `SELECT id, name FROM paged WHERE `+condition+`
+	    ORDER BY `+request.OrderBy()+` LIMIT ?`, append(args, request.Fetch())...)
+	require.NoError(t, err)
+	return Paginate(request, items, 5, func(item named) []any {
+		return []any{item.name, item.id}
+	})
+}
+
+func names(items []named) []string {
+	names := make([]string, len(items))
+	for i, item := range items {
+		names[i] = item.name
+	}
+	return names
+}
+
+func TestPaginate(t *testing.T) {
+	db, err := database.Open(context.Background(), ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE paged (id INTEGER PRIMARY KEY, name TEXT NOT NULL)`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO paged (id, name) VALUES
+	  (1, "delta"), (2, "Alpha"), (3, "charlie"), (4, "bravo"), (5, "alpha")`)
+	require.NoError(t, err)
+
+	request := Request{Sort: test_sorts[0], Limit: 2}
+	items, page := read_page(t, db, request)
+	assert.Equal(t, []string{"Alpha", "alpha"}, names(items))
+	assert.Equal(t, 5, page.Total)
+	assert.Empty(t, page.Prev)
+	require.NotEmpty(t, page.Next)
+
+	// Items inserted before the cursor do not shift the next page.
+	_, err = db.Exec(`INSERT INTO paged (id, name) VALUES (6, "aardvark")`)
+	require.NoError(t, err)
+	next, err := ParseCursor(page.Next)
+	require.NoError(t, err)
+	request.Cursor = &next
+	items, page = read_page(t, db, request)
+	assert.Equal(t, []string{"bravo", "charlie"}, names(items))
+	require.NotEmpty(t, page.Prev)
+
+	next, err = ParseCursor(page.Next)
+	require.NoError(t, err)
+	request.Cursor = &next
+	items, page = read_page(t, db, request)
+	assert.Equal(t, []string{"delta"}, names(items))
+	assert.Empty(t, page.Next)
+
+	prev, err := ParseCursor(page.Prev)
+	require.NoError(t, err)
+	request.Cursor = &prev
+	items, page = read_page(t, db, request)
+	assert.Equal(t, []string{"bravo", "charlie"}, names(items))
+	assert.NotEmpty(t, page.Next)
+	prev, err = ParseCursor(page.Prev)
+	require.NoError(t, err)
+	request.Cursor = &prev
+	items, _ = read_page(t, db, request)
+	assert.Equal(t, []string{"Alpha", "alpha"}, names(items))
+}
+
+func TestLinks(t *testing.T) {
+	request, err := url.Parse("/artist?name=mass&limit=2&cursor=abc")
+	require.NoError(t, err)
+	links := Page{Next: "def", Prev: "xyz"}.Links(request)
+	assert.Equal(t, `</artist?limit=2&name=mass>; rel="first", `+
+		`</artist?cursor=xyz&limit=2&name=mass>; rel="prev", `+
+		`</artist?cursor=def&limit=2&name=mass>; rel="next"`, links)
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/schema/account.go
+
+package schema
+
+import (
+	"encoding/json"
+)
+
+type UserAccount struct {
+	ID       uint64 `json:"userID"`
+	Username string `json:"username"`
+
+	Fullname  string `json:"fullname,omitempty"`
+	About     string `json:"about,omitempty"`
+	DiscogsID string `json:"discogsID,omitempty"`
+	AvatarURL string `json:"avatar,omitempty"`
+
+	// A nil value implies the user is not banned.
+	DateBanned *Date `json:"date_banned,omitempty"`
+}
+
+func (account UserAccount) Typename() string { return "account" }
+func (account UserAccount) Key() string      { return account.Username }
+
+func (account UserAccount) MarshalJSON() ([]byte, error) {
+	type user_account_json UserAccount
+	return json.Marshal(user_account_json(account))
+}
+
+func (account UserAccount) Validate() error {
+	v := validate(account.Typename())
+	v.required(account.Username, "username")
+	v.check(account.Username == "" || username_pattern.MatchString(account.Username),
+		"username", "may only contain letters, digits, '.', '-' and '_'")
+	return v.err()
+}
+
+func NewUserAccountParser(schema string) JsonParser[UserAccount] {
+	return newStrictParser[UserAccount](schema, "#UserAccount")
+}
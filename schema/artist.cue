@@ -27,6 +27,34 @@ package cratedigdb
   artistID!: uint64
   name!:     string
 
-  mbID?:    string // UUID
-  profile?: string
+  mbID?:     string // UUID
+  profile?:  string
+  realname?: string
+
+  data_quality?: #DataQuality
+
+  namevariations?: [...string]
+  aliases?: [...{
+    name!:     string
+    artistID?: uint64
+  }]
+  members?: [...#ArtistMember]
+  groups?:  [...#ArtistMember]
+  urls?:    [...string]
+}
+
+#ArtistCredit: {
+  artistID!: uint64
+  name!:     string
+  role?:     string
+  tracks?:   string
 }
+
+#ArtistMember: {
+  artistID!: uint64
+  name!:     string
+}
+
+#DataQuality: "Needs Vote" | "Entirely Incorrect" | "Entirely Incorrect Edit" |
+  "Needs Major Changes" | "Needs Minor Changes" | "Correct" | "Disagreement" |
+  "Complete And Correct"
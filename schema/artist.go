@@ -24,7 +24,7 @@ package schema
 
 import (
 	"encoding/json"
-	"log"
+	"strconv"
 )
 
 type Artist struct {
@@ -35,21 +35,58 @@ type Artist struct {
 	MusicBrainzID string `json:"mbID,omitempty"`
 	// Additional notes about the artist.
 	Profile string `json:"profile,omitempty"`
+	// The artist's real name, when the artist is an individual.
+	RealName string `json:"realname,omitempty"`
+
+	DataQuality DataQualityEnum `json:"data_quality"`
+
+	// Artist name variations (ANVs), from Artist_Names.
+	NameVariations []string      `json:"namevariations,omitempty"`
+	Aliases        []ArtistAlias `json:"aliases,omitempty"`
+	// When the artist is a group, its members; when an individual, its groups.
+	Members []ArtistMember `json:"members,omitempty"`
+	Groups  []ArtistMember `json:"groups,omitempty"`
+	URLs    []string       `json:"urls,omitempty"`
 }
 
-func (artist Artist) Typename() string { return "artist" }
-func (artist Artist) ToJson() string {
-	bytes, err := json.MarshalIndent(artist, "", "  ")
-	if err != nil {
-		log.Fatal(err)
-	}
-	return string(bytes)
+// Another identity of the artist; it may also be an artist of its own.
+type ArtistAlias struct {
+	Name     string `json:"name"`
+	ArtistID uint64 `json:"artistID,omitempty"`
 }
 
-func NewArtistParser(schema string) JsonParser[Artist] {
+// A member of a group or a group that the artist is a member of.
+type ArtistMember struct {
+	ArtistID uint64 `json:"artistID"`
+	Name     string `json:"name"`
+}
 
-	return func(json string, artist *Artist) error {
+// An artist's credit on a release, version or track.  The name is the artist
+// name variation (ANV) used in the credit, if there was one.
+type ArtistCredit struct {
+	ArtistID uint64 `json:"artistID"`
+	Name     string `json:"name"`
+	Role     string `json:"role,omitempty"`
+	// The tracks this credit applies to, e.g. "A1 to A3", if not all of them.
+	Tracks string `json:"tracks,omitempty"`
+}
 
-		return nil
-	}
+func (artist Artist) Typename() string { return "artist" }
+func (artist Artist) Key() string      { return strconv.FormatUint(artist.ID, 10) }
+
+func (artist Artist) MarshalJSON() ([]byte, error) {
+	type artist_json Artist
+	return json.Marshal(artist_json(artist))
+}
+
+func (artist Artist) Validate() error {
+	v := validate(artist.Typename())
+	v.required(artist.ID, "artistID")
+	v.required(artist.Name, "name")
+	v.check(data_quality_names.valid(int(artist.DataQuality)), "data_quality", "is not a valid data quality")
+	return v.err()
+}
+
+func NewArtistParser(schema string) JsonParser[Artist] {
+	return newStrictParser[Artist](schema, "#Artist")
 }
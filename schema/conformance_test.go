@@ -0,0 +1,362 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/schema/conformance_test.go
+
+package schema
+
+import (
+	"encoding"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden drift report")
+
+const fixtures_path = "testdata/conformance"
+const tsmodels_path = "../tsmodels"
+
+// Each Go resource type, how to parse it, and its Zod counterpart (if any).
+type conformance struct {
+	resource Resource
+	parse    func(string) (any, error)
+	tsmodel  string
+}
+
+func conformanceCases() []conformance {
+	return []conformance{
+		{UserAccount{}, roundtrip(accountParser), "UserAccount"},
+		{Artist{}, roundtrip(artistParser), "ArtistResource"},
+		{Crate{}, roundtrip(crateParser), "VinylCrate"},
+		{Image{}, roundtrip(imageParser), "ImageInfo"},
+		{Label{}, roundtrip(labelParser), "RecordLabelResource"},
+		{Listing{}, roundtrip(listingParser), "ListingInfo"},
+		{Order{}, roundtrip(orderParser), ""},
+		{OrderUpdate{}, roundtrip(orderUpdateParser), ""},
+		{Release{}, roundtrip(releaseParser), "ReleaseResource"},
+		{Tag{}, roundtrip(tagParser), ""},
+		{Track{}, roundtrip(trackParser), "TrackInfo"},
+		{ReleaseVersion{}, roundtrip(versionParser), "ReleaseVersionResource"},
+		{Vinyl{}, roundtrip(vinylParser), "VinylRecord"},
+	}
+}
+
+func roundtrip[T Resource](parser JsonParser[T]) func(string) (any, error) {
+	return func(data string) (any, error) {
+		var value T
+		err := parser(data, &value)
+		return value, err
+	}
+}
+
+func TestConformanceFixtures(t *testing.T) {
+	for _, test := range conformanceCases() {
+		typename := test.resource.Typename()
+		t.Run(typename, func(t *testing.T) {
+			fixture, err := os.ReadFile(filepath.Join(fixtures_path, typename+".json"))
+			require.NoError(t, err)
+
+			value, err := test.parse(string(fixture))
+			require.NoError(t, err)
+			encoded, err := json.Marshal(value)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(fixture), string(encoded))
+		})
+	}
+}
+
+func TestParserRejectsUnknownFields(t *testing.T) {
+	var tag Tag
+	err := tagParser(`{"tagID": 9, "userID": 42, "name": "disco", "color": "red"}`, &tag)
+	assert.ErrorContains(t, err, "color")
+}
+
+func TestParserRequiresFields(t *testing.T) {
+	var tag Tag
+	err := tagParser(`{"tagID": 9, "name": "disco"}`, &tag)
+	assert.ErrorContains(t, err, `"userID"`)
+}
+
+// Compares the JSON fields of each Go resource type with the fields of its Zod
+// counterpart in tsmodels/ and reports fields which only exist on one side or
+// which have a different kind of value (string, number, array, ...).
+//
+// The report is compared with a golden file, run with -update to rewrite it
+// after resolving (or accepting) a difference.
+func TestTsmodelDrift(t *testing.T) {
+	models, err := read_tsmodels(tsmodels_path)
+	require.NoError(t, err)
+
+	report := drift_report(conformanceCases(), models)
+	golden := filepath.Join(fixtures_path, "drift.golden")
+	if *update {
+		require.NoError(t, os.WriteFile(golden, []byte(report), 0644))
+	}
+	expected, err := os.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), report,
+		"drift between schema and tsmodels changed, run with -update if intended")
+}
+
+func drift_report(cases []conformance, models map[string]map[string]string) string {
+	var report strings.Builder
+	report.WriteString("# Drift between the Go schema types and the Zod models in tsmodels/.\n")
+	report.WriteString("# Regenerate with `go test ./schema -run TestTsmodelDrift -update`.\n")
+
+	for _, test := range cases {
+		typename := test.resource.Typename()
+		gofields := go_fields(reflect.TypeOf(test.resource))
+		report.WriteString("\n")
+		if test.tsmodel == "" {
+			fmt.Fprintf(&report, "%s: no tsmodels counterpart\n", typename)
+			continue
+		}
+		tsfields, found := models[test.tsmodel]
+		if !found {
+			fmt.Fprintf(&report, "%s <-> %s: not found in tsmodels\n", typename, test.tsmodel)
+			continue
+		}
+
+		fmt.Fprintf(&report, "%s <-> %s\n", typename, test.tsmodel)
+		for _, name := range sorted_keys(gofields) {
+			tskind, found := tsfields[name]
+			if !found {
+				fmt.Fprintf(&report, "  go only: %s (%s)\n", name, gofields[name])
+			} else if tskind != gofields[name] {
+				fmt.Fprintf(&report, "  mismatch: %s (go %s, ts %s)\n", name, gofields[name], tskind)
+			}
+		}
+		for _, name := range sorted_keys(tsfields) {
+			if _, found := gofields[name]; !found {
+				fmt.Fprintf(&report, "  ts only: %s (%s)\n", name, tsfields[name])
+			}
+		}
+	}
+	return report.String()
+}
+
+// Maps the JSON field names of a struct type to the kind of their value.
+func go_fields(structType reflect.Type) map[string]string {
+	fields := make(map[string]string)
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[name] = go_kind(field.Type)
+	}
+	return fields
+}
+
+var json_marshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+var text_marshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+func go_kind(fieldType reflect.Type) string {
+	if fieldType.Kind() == reflect.Pointer {
+		fieldType = fieldType.Elem()
+	}
+	if fieldType.Implements(text_marshaler) {
+		// e.g. Date and Timestamp, which encode their zero value as null.
+		return "string"
+	}
+	if fieldType.Implements(json_marshaler) {
+		// e.g. time.Time and the enum types, classified by their zero value.
+		encoded, err := json.Marshal(reflect.Zero(fieldType).Interface())
+		if err == nil && len(encoded) > 0 {
+			switch encoded[0] {
+			case '"':
+				return "string"
+			case '[':
+				return "array"
+			case '{':
+				return "object"
+			}
+		}
+	}
+	switch fieldType.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	}
+	return "object"
+}
+
+var ts_declaration = regexp.MustCompile(
+	`export const (\w+) = (z\.object\(\{|z\.enum\(|(\w+)\.extend\(\{)`)
+
+// Reads the Zod object declarations in the .ts files of a directory, mapping
+// each object's name to its fields and each field to the kind of its value.
+// This is not a TypeScript parser, it assumes the formatting of tsmodels/.
+func read_tsmodels(dirpath string) (map[string]map[string]string, error) {
+	paths, err := filepath.Glob(filepath.Join(dirpath, "*.ts"))
+	if err != nil {
+		return nil, err
+	}
+
+	enums := make(map[string]bool)
+	objects := make(map[string]string) // name => body of the object's fields
+	extends := make(map[string]string) // name => base object's name
+	for _, path := range paths {
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		content := strip_comments(string(source))
+		for _, match := range ts_declaration.FindAllStringSubmatchIndex(content, -1) {
+			name := content[match[2]:match[3]]
+			if content[match[4]:match[5]] == "z.enum(" {
+				enums[name] = true
+				continue
+			}
+			if match[6] >= 0 {
+				extends[name] = content[match[6]:match[7]]
+			}
+			objects[name] = balanced(content[match[5]-1:])
+		}
+	}
+
+	models := make(map[string]map[string]string)
+	for name, body := range objects {
+		fields := make(map[string]string)
+		for base := extends[name]; base != ""; base = extends[base] {
+			for field, expr := range ts_fields(objects[base]) {
+				fields[field] = ts_kind(expr, enums, objects)
+			}
+		}
+		for field, expr := range ts_fields(body) {
+			fields[field] = ts_kind(expr, enums, objects)
+		}
+		models[name] = fields
+	}
+	return models, nil
+}
+
+func strip_comments(source string) string {
+	lines := strings.Split(source, "\n")
+	for i, line := range lines {
+		if index := strings.Index(line, "//"); index >= 0 {
+			lines[i] = line[:index]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Returns the contents between an opening brace (at text[0]) and its match.
+func balanced(text string) string {
+	depth := 0
+	for i, char := range text {
+		switch char {
+		case '{', '(', '[':
+			depth++
+		case '}', ')', ']':
+			depth--
+			if depth == 0 {
+				return text[1:i]
+			}
+		}
+	}
+	return text[1:]
+}
+
+var ts_field = regexp.MustCompile(`^\s*['"]?(\w+)['"]?\s*:\s*(.*)$`)
+
+// Splits the body of an object into its field names and value expressions.
+func ts_fields(body string) map[string]string {
+	fields := make(map[string]string)
+	depth, start := 0, 0
+	for i := 0; i <= len(body); i++ {
+		if i < len(body) {
+			switch body[i] {
+			case '{', '(', '[':
+				depth++
+				continue
+			case '}', ')', ']':
+				depth--
+				continue
+			case ',':
+				if depth > 0 {
+					continue
+				}
+			default:
+				continue
+			}
+		}
+		segment := strings.TrimSpace(body[start:i])
+		if match := ts_field.FindStringSubmatch(strings.ReplaceAll(segment, "\n", " ")); match != nil {
+			fields[match[1]] = strings.TrimSpace(match[2])
+		}
+		start = i + 1
+	}
+	return fields
+}
+
+func ts_kind(expr string, enums map[string]bool, objects map[string]string) string {
+	expr = strings.Replace(expr, "z.coerce.", "z.", 1)
+	switch {
+	case strings.HasPrefix(expr, "z.array(") ||
+		strings.HasPrefix(expr, "z.set(") ||
+		strings.Contains(expr, ".array()"):
+		return "array"
+	case strings.HasPrefix(expr, "z.string("), strings.HasPrefix(expr, "z.enum("):
+		return "string"
+	case strings.HasPrefix(expr, "z.number("):
+		return "number"
+	case strings.HasPrefix(expr, "z.boolean("):
+		return "boolean"
+	case strings.HasPrefix(expr, "z.object("):
+		return "object"
+	}
+	identifier, _, _ := strings.Cut(expr, ".")
+	if enums[identifier] {
+		return "string"
+	}
+	if _, found := objects[identifier]; found {
+		return "object"
+	}
+	return "unknown"
+}
+
+func sorted_keys(fields map[string]string) []string {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,93 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/schema/crate.go
+
+package schema
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A crate is a (possibly nested) folder for organizing a user's collection
+// with a physical metaphor; each vinyl item is in at most one crate.
+//
+// The crate with ID zero is the special 'ALL' crate which every user
+// implicitly has, no item is ever explicitly placed in it.
+type Crate struct {
+	ID     uint64 `json:"crateID"`
+	UserID uint64 `json:"userID"`
+	// A zero value indicates this is a top-level crate.
+	ParentID uint64 `json:"parentID,omitempty"`
+
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+	// The slash-separated slugs from the top-level crate to this crate.
+	Path     string `json:"path,omitempty"`
+	Username string `json:"username,omitempty"`
+
+	Visible bool   `json:"visible"`
+	Notes   string `json:"notes"`
+}
+
+func (crate Crate) Typename() string { return "crate" }
+func (crate Crate) Key() string      { return strconv.FormatUint(crate.ID, 10) }
+
+func (crate Crate) MarshalJSON() ([]byte, error) {
+	type crate_json Crate
+	return json.Marshal(crate_json(crate))
+}
+
+func (crate Crate) Validate() error {
+	v := validate(crate.Typename())
+	v.required(crate.UserID, "userID")
+	v.required(crate.Name, "name")
+	v.check(crate.Slug == "" || url_segment.MatchString(crate.Slug),
+		"slug", "may only contain lowercase letters, digits, '-' and '_'")
+	v.check(crate.ID == 0 || crate.ParentID != crate.ID, "parentID", "cannot be the crate itself")
+	return v.err()
+}
+
+// Derives a crate's slug from its name: lowercase letters and digits, with any
+// other characters collapsed into single dashes.  May return "" if the name
+// has no ASCII letters or digits.
+func Slugify(name string) string {
+	var slug strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if dash && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+			slug.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return slug.String()
+}
+
+func NewCrateParser(schema string) JsonParser[Crate] {
+	return newStrictParser[Crate](schema, "#Crate")
+}
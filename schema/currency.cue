@@ -22,4 +22,12 @@
 
 package cratedigdb
 
+#Currency: "USD" | "GBP" | "EUR" | "CAD" | "AUD" | "JPY" |
+           "CHF" | "MXN" | "BRL" | "NZD" | "SEK" | "ZAR"
 
+// The amount is a decimal in major units, e.g. "27.50" (JPY has no decimals).
+// It is stored in SQL as an INTEGER count of the currency's minor units.
+#Money: {
+  amount!:   string | number
+  currency?: #Currency | *"USD"
+}
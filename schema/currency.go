@@ -22,10 +22,19 @@
 
 package schema
 
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// Currencies are identified by their ISO 4217 code, as used by Discogs.
+// The empty value is treated as USD, matching the SQL columns where a NULL
+// price_currency assumes U.S. Dollars.
 type CurrencyEnum string
 
 func (currency CurrencyEnum) Name() string {
-	return currency_names[currency]
+	return currency_names[currency.or_default()]
 }
 
 const (
@@ -58,7 +67,90 @@ var currency_names = map[CurrencyEnum]string{
 	CurrencyZAR: "South African rand",
 }
 
-// TODO? add symbols (prefix) for each of these too.
+// The symbols as displayed by the Discogs marketplace; where a symbol is shared
+// between currencies (as with the dollar) the more common currency gets the
+// bare symbol.
+var currency_symbols = map[CurrencyEnum]string{
+	CurrencyUSD: "$",
+	CurrencyGBP: "£",
+	CurrencyEUR: "€",
+	CurrencyCAD: "CA$",
+	CurrencyAUD: "A$",
+	CurrencyJPY: "¥",
+	CurrencyCHF: "CHF",
+	CurrencyMXN: "MX$",
+	CurrencyBRL: "R$",
+	CurrencyNZD: "NZ$",
+	CurrencySEK: "kr",
+	CurrencyZAR: "R",
+}
+
+// The number of decimal places in the currency's minor unit (ISO 4217).
+// Any currency not listed here has two decimal places.
+var currency_exponents = map[CurrencyEnum]int{
+	CurrencyJPY: 0,
+}
+
+func (currency CurrencyEnum) Symbol() string {
+	if symbol, found := currency_symbols[currency.or_default()]; found {
+		return symbol
+	}
+	return string(currency)
+}
+
+// The number of minor units (e.g. cents) is 10^Exponent() per major unit.
+func (currency CurrencyEnum) Exponent() int {
+	if exponent, found := currency_exponents[currency.or_default()]; found {
+		return exponent
+	}
+	return 2
+}
+
+func (currency CurrencyEnum) IsValid() bool {
+	_, found := currency_names[currency.or_default()]
+	return found
+}
+
+func (currency CurrencyEnum) or_default() CurrencyEnum {
+	if currency == "" {
+		return CurrencyUSD
+	}
+	return currency
+}
+
+// Finds the currency for its (case-insensitive) code, e.g. "usd".
+func ParseCurrency(code string) (CurrencyEnum, error) {
+	currency := CurrencyEnum(strings.ToUpper(strings.TrimSpace(code)))
+	if currency == "" || !currency.IsValid() {
+		return "", fmt.Errorf("unrecognized currency %q", code)
+	}
+	return currency, nil
+}
+
+func (currency *CurrencyEnum) Scan(src any) error {
+	var code string
+	switch value := src.(type) {
+	case nil:
+		*currency = CurrencyUSD
+		return nil
+	case string:
+		code = value
+	case []byte:
+		code = string(value)
+	default:
+		return fmt.Errorf("cannot scan %T into currency", src)
+	}
+	parsed, err := ParseCurrency(code)
+	if err != nil {
+		return err
+	}
+	*currency = parsed
+	return nil
+}
+
+func (currency CurrencyEnum) Value() (driver.Value, error) {
+	return string(currency.or_default()), nil
+}
 
 func CurrencyOptions() []CurrencyEnum {
 	return []CurrencyEnum{
@@ -0,0 +1,103 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/schema/dataquality.go
+
+package schema
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+)
+
+// The latest status of voting on the data quality of artist profiles, labels,
+// releases and release versions.  Values match the DataQualityEnum table, see
+// https://www.discogs.com/help/voting-guidelines.html
+type DataQualityEnum uint8
+
+const (
+	QualityNeedsVote DataQualityEnum = iota
+	QualityEntirelyIncorrect
+	QualityEntirelyIncorrectEdit
+	QualityNeedsMajorChanges
+	QualityNeedsMinorChanges
+	QualityCorrect
+	QualityDisagreement
+	QualityCompleteAndCorrect
+)
+
+var data_quality_names = enum_names{
+	QualityNeedsVote:             "Needs Vote",
+	QualityEntirelyIncorrect:     "Entirely Incorrect",
+	QualityEntirelyIncorrectEdit: "Entirely Incorrect Edit",
+	QualityNeedsMajorChanges:     "Needs Major Changes",
+	QualityNeedsMinorChanges:     "Needs Minor Changes",
+	QualityCorrect:               "Correct",
+	QualityDisagreement:          "Disagreement",
+	QualityCompleteAndCorrect:    "Complete And Correct",
+}
+
+func DataQualityOptions() []DataQualityEnum {
+	options := make([]DataQualityEnum, len(data_quality_names))
+	for i := range options {
+		options[i] = DataQualityEnum(i)
+	}
+	return options
+}
+
+func (quality DataQualityEnum) String() string {
+	return data_quality_names.name(int(quality))
+}
+
+func ParseDataQuality(name string) (DataQualityEnum, error) {
+	id, err := data_quality_names.parse("data quality", name)
+	return DataQualityEnum(id), err
+}
+
+func parse_data_quality(name string) (int, error) {
+	quality, err := ParseDataQuality(name)
+	return int(quality), err
+}
+
+func (quality DataQualityEnum) MarshalJSON() ([]byte, error) {
+	return json.Marshal(quality.String())
+}
+
+func (quality *DataQualityEnum) UnmarshalJSON(data []byte) error {
+	id, err := data_quality_names.unmarshal("data quality", data, parse_data_quality)
+	if err != nil {
+		return err
+	}
+	*quality = DataQualityEnum(id)
+	return nil
+}
+
+func (quality *DataQualityEnum) Scan(src any) error {
+	id, err := data_quality_names.scan("data quality", src, parse_data_quality)
+	if err != nil {
+		return err
+	}
+	*quality = DataQualityEnum(id)
+	return nil
+}
+
+func (quality DataQualityEnum) Value() (driver.Value, error) {
+	return enum_value(int(quality))
+}
@@ -0,0 +1,258 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/schema/date.go
+
+package schema
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// A calendar date without a time of day or time zone, as used for the dates
+// that vinyl was added, graded, sold or traded and for the ledger's dates.
+//
+// Dates are written as YYYY-MM-DD (as in tsmodels' z.string().date()) but
+// YYYY/MM/DD is also read, as found in older ledger rows.  The zero Date is
+// written as NULL in SQL and null in JSON; use *Date for optional fields.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+const date_format = "2006-01-02"
+
+func NewDate(year int, month time.Month, day int) Date {
+	return DateOf(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+}
+
+// The date of the time instant, in the time's location.
+func DateOf(instant time.Time) Date {
+	year, month, day := instant.Date()
+	return Date{year, month, day}
+}
+
+func Today() Date {
+	return DateOf(time.Now().UTC())
+}
+
+// Reads YYYY-MM-DD or YYYY/MM/DD, rejecting dates that do not exist.
+func ParseDate(text string) (Date, error) {
+	text = strings.TrimSpace(text)
+	instant, err := time.Parse(date_format, strings.ReplaceAll(text, "/", "-"))
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", text)
+	}
+	return DateOf(instant), nil
+}
+
+func (date Date) IsZero() bool {
+	return date == Date{}
+}
+
+// Midnight (UTC) at the start of the date.
+func (date Date) Time() time.Time {
+	return time.Date(date.Year, date.Month, date.Day, 0, 0, 0, 0, time.UTC)
+}
+
+func (date Date) AddDays(days int) Date {
+	return DateOf(date.Time().AddDate(0, 0, days))
+}
+
+func (date Date) Before(other Date) bool {
+	return date.Time().Before(other.Time())
+}
+
+func (date Date) After(other Date) bool {
+	return date.Time().After(other.Time())
+}
+
+// The canonical YYYY-MM-DD form, or the empty string for the zero Date.
+func (date Date) String() string {
+	if date.IsZero() {
+		return ""
+	}
+	return date.Time().Format(date_format)
+}
+
+func (date Date) MarshalText() ([]byte, error) {
+	return []byte(date.String()), nil
+}
+
+func (date *Date) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*date = Date{}
+		return nil
+	}
+	parsed, err := ParseDate(string(text))
+	if err != nil {
+		return err
+	}
+	*date = parsed
+	return nil
+}
+
+func (date Date) MarshalJSON() ([]byte, error) {
+	if date.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(date.String())
+}
+
+func (date *Date) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*date = Date{}
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("date must be a string, got %s", data)
+	}
+	return date.UnmarshalText([]byte(text))
+}
+
+func (date *Date) Scan(src any) error {
+	switch value := src.(type) {
+	case nil:
+		*date = Date{}
+		return nil
+	case string:
+		return date.UnmarshalText([]byte(value))
+	case []byte:
+		return date.UnmarshalText(value)
+	case time.Time:
+		*date = DateOf(value)
+		return nil
+	}
+	return fmt.Errorf("cannot scan %T into date", src)
+}
+
+func (date Date) Value() (driver.Value, error) {
+	if date.IsZero() {
+		return nil, nil
+	}
+	return date.String(), nil
+}
+
+// An instant with second precision, as used for Orders.last_activity and
+// OrderUpdates.update_time.  Timestamps are always written in UTC in the
+// format of SQLite's CURRENT_TIMESTAMP ("YYYY-MM-DD HH:MM:SS"), and also read
+// with '/' date separators, fractional seconds or in RFC 3339 format.
+// The zero Timestamp is written as NULL in SQL and null in JSON.
+type Timestamp struct {
+	time.Time
+}
+
+const timestamp_format = "2006-01-02 15:04:05"
+
+func NewTimestamp(instant time.Time) Timestamp {
+	return Timestamp{instant.UTC().Truncate(time.Second)}
+}
+
+func Now() Timestamp {
+	return NewTimestamp(time.Now())
+}
+
+func ParseTimestamp(text string) (Timestamp, error) {
+	text = strings.TrimSpace(text)
+	if instant, err := time.Parse(time.RFC3339Nano, text); err == nil {
+		return NewTimestamp(instant), nil
+	}
+	// Only the date part may use '/' separators.
+	if len(text) >= len(date_format) {
+		text = strings.ReplaceAll(text[:len(date_format)], "/", "-") + text[len(date_format):]
+	}
+	instant, err := time.Parse("2006-01-02 15:04:05.999999999", text)
+	if err != nil {
+		return Timestamp{}, fmt.Errorf("invalid timestamp %q, expected YYYY-MM-DD HH:MM:SS", text)
+	}
+	return NewTimestamp(instant), nil
+}
+
+func (timestamp Timestamp) String() string {
+	if timestamp.IsZero() {
+		return ""
+	}
+	return timestamp.UTC().Format(timestamp_format)
+}
+
+func (timestamp Timestamp) MarshalText() ([]byte, error) {
+	return []byte(timestamp.String()), nil
+}
+
+func (timestamp *Timestamp) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*timestamp = Timestamp{}
+		return nil
+	}
+	parsed, err := ParseTimestamp(string(text))
+	if err != nil {
+		return err
+	}
+	*timestamp = parsed
+	return nil
+}
+
+func (timestamp Timestamp) MarshalJSON() ([]byte, error) {
+	if timestamp.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(timestamp.String())
+}
+
+func (timestamp *Timestamp) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*timestamp = Timestamp{}
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return fmt.Errorf("timestamp must be a string, got %s", data)
+	}
+	return timestamp.UnmarshalText([]byte(text))
+}
+
+func (timestamp *Timestamp) Scan(src any) error {
+	switch value := src.(type) {
+	case nil:
+		*timestamp = Timestamp{}
+		return nil
+	case string:
+		return timestamp.UnmarshalText([]byte(value))
+	case []byte:
+		return timestamp.UnmarshalText(value)
+	case time.Time:
+		*timestamp = NewTimestamp(value)
+		return nil
+	}
+	return fmt.Errorf("cannot scan %T into timestamp", src)
+}
+
+func (timestamp Timestamp) Value() (driver.Value, error) {
+	if timestamp.IsZero() {
+		return nil, nil
+	}
+	return timestamp.String(), nil
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/schema/date_test.go
+
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDateFormats(t *testing.T) {
+	for _, text := range []string{"2025-02-03", "2025/02/03", " 2025-02-03 "} {
+		date, err := ParseDate(text)
+		require.NoError(t, err, text)
+		assert.Equal(t, NewDate(2025, time.February, 3), date)
+		assert.Equal(t, "2025-02-03", date.String())
+	}
+	_, err := ParseDate("2025-02-30")
+	assert.Error(t, err)
+	_, err = ParseDate("02/03/2025")
+	assert.Error(t, err)
+
+	date := NewDate(2024, time.December, 31)
+	assert.Equal(t, NewDate(2025, time.January, 1), date.AddDays(1))
+	assert.True(t, date.Before(date.AddDays(1)))
+}
+
+func TestDateCodecs(t *testing.T) {
+	date := NewDate(2025, time.January, 23)
+	vinyl := Vinyl{UserID: 42, VersionID: 1178, Item: 1, DateAdded: &date}
+	encoded, err := json.Marshal(vinyl)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"date_added":"2025-01-23"`)
+	assert.NotContains(t, string(encoded), `date_sold`)
+
+	var decoded Vinyl
+	require.NoError(t, json.Unmarshal([]byte(`{"date_sold": "2025/03/01", "date_traded": null}`), &decoded))
+	assert.Equal(t, NewDate(2025, time.March, 1), *decoded.DateSold)
+	assert.Nil(t, decoded.DateTraded)
+
+	var scanned Date
+	require.NoError(t, scanned.Scan("2025/03/01"))
+	assert.Equal(t, NewDate(2025, time.March, 1), scanned)
+	require.NoError(t, scanned.Scan(nil))
+	assert.True(t, scanned.IsZero())
+	value, err := scanned.Value()
+	require.NoError(t, err)
+	assert.Nil(t, value)
+	value, err = date.Value()
+	require.NoError(t, err)
+	assert.Equal(t, "2025-01-23", value)
+}
+
+func TestTimestampFormats(t *testing.T) {
+	expected := NewTimestamp(time.Date(2025, time.February, 4, 12, 30, 0, 0, time.UTC))
+	for _, text := range []string{
+		"2025-02-04 12:30:00",
+		"2025/02/04 12:30:00.125",
+		"2025-02-04T13:30:00+01:00",
+	} {
+		timestamp, err := ParseTimestamp(text)
+		require.NoError(t, err, text)
+		assert.True(t, expected.Equal(timestamp.Time), text)
+		assert.Equal(t, "2025-02-04 12:30:00", timestamp.String())
+	}
+	_, err := ParseTimestamp("yesterday")
+	assert.Error(t, err)
+
+	encoded, err := json.Marshal(OrderUpdate{ID: 1, OrderID: 5, UpdateTime: &expected})
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"update_time":"2025-02-04 12:30:00"`)
+
+	var scanned Timestamp
+	require.NoError(t, scanned.Scan([]byte("2025/02/04 12:30:00")))
+	assert.Equal(t, expected, scanned)
+	require.NoError(t, scanned.Scan(nil))
+	value, err := scanned.Value()
+	require.NoError(t, err)
+	assert.Nil(t, value)
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/schema/enum.go
+
+package schema
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// The enumerations backed by a base data table (see sql/create_5_basedata.sql)
+// are stored as their integer ID in SQL and as their text name in JSON.  These
+// helpers share the parsing and scanning between each of the enum types.
+
+// Constant names indexed by their ID; an empty name is a gap in the IDs.
+type enum_names []string
+
+func (names enum_names) valid(id int) bool {
+	return 0 <= id && id < len(names) && (id == 0 || names[id] != "")
+}
+
+func (names enum_names) name(id int) string {
+	if !names.valid(id) {
+		return fmt.Sprintf("(invalid %d)", id)
+	}
+	return names[id]
+}
+
+// Finds the ID of name, preferring an exact match over a case-insensitive one.
+func (names enum_names) parse(kind string, name string) (int, error) {
+	for id, option := range names {
+		if option == name && names.valid(id) {
+			return id, nil
+		}
+	}
+	for id, option := range names {
+		if strings.EqualFold(option, name) && names.valid(id) {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("unrecognized %s %q", kind, name)
+}
+
+// Accepts either the JSON string of the enum's name or its (numeric) ID.
+func (names enum_names) unmarshal(kind string, data []byte, parse func(string) (int, error)) (int, error) {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		return parse(name)
+	}
+	var id int
+	if err := json.Unmarshal(data, &id); err != nil {
+		return 0, fmt.Errorf("%s must be a string or integer, got %s", kind, data)
+	}
+	if !names.valid(id) {
+		return 0, fmt.Errorf("%s ID %d is out of range", kind, id)
+	}
+	return id, nil
+}
+
+// Accepts the integer ID (as stored in SQL) or the text of the enum's name.
+func (names enum_names) scan(kind string, src any, parse func(string) (int, error)) (int, error) {
+	switch value := src.(type) {
+	case int64:
+		if !names.valid(int(value)) {
+			return 0, fmt.Errorf("%s ID %d is out of range", kind, value)
+		}
+		return int(value), nil
+	case string:
+		return parse(value)
+	case []byte:
+		return parse(string(value))
+	case nil:
+		return 0, nil
+	}
+	return 0, fmt.Errorf("cannot scan %T into %s", src, kind)
+}
+
+func enum_value(id int) (driver.Value, error) {
+	return int64(id), nil
+}
@@ -0,0 +1,181 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/schema/enum_test.go
+
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataQualityCodecs(t *testing.T) {
+	quality, err := ParseDataQuality("Needs Minor Changes")
+	require.NoError(t, err)
+	assert.Equal(t, QualityNeedsMinorChanges, quality)
+	assert.Equal(t, "Needs Minor Changes", quality.String())
+
+	_, err = ParseDataQuality("Recently Edited")
+	assert.Error(t, err)
+
+	encoded, err := json.Marshal(QualityCompleteAndCorrect)
+	require.NoError(t, err)
+	assert.Equal(t, `"Complete And Correct"`, string(encoded))
+	require.NoError(t, json.Unmarshal([]byte(`"correct"`), &quality))
+	assert.Equal(t, QualityCorrect, quality)
+	require.NoError(t, json.Unmarshal([]byte(`6`), &quality))
+	assert.Equal(t, QualityDisagreement, quality)
+	assert.Error(t, json.Unmarshal([]byte(`8`), &quality))
+
+	value, err := QualityCorrect.Value()
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), value)
+	require.NoError(t, quality.Scan(int64(2)))
+	assert.Equal(t, QualityEntirelyIncorrectEdit, quality)
+	assert.Error(t, quality.Scan(int64(-1)))
+}
+
+func TestMediaFormatCodecs(t *testing.T) {
+	assert.Equal(t, "Vinyl", FormatVinyl.String())
+	assert.Equal(t, "Cass", FormatCassette.Abbr())
+	assert.Equal(t, "Box Set", FormatBoxSet.String())
+	assert.Len(t, MediaFormatOptions(), 64)
+
+	format, err := ParseMediaFormat("Cass")
+	require.NoError(t, err)
+	assert.Equal(t, FormatCassette, format)
+	format, err = ParseMediaFormat("reel-to-reel")
+	require.NoError(t, err)
+	assert.Equal(t, FormatReelToReel, format)
+	_, err = ParseMediaFormat("Wax Cylinder")
+	assert.Error(t, err)
+
+	encoded, err := json.Marshal(MediaFormat{Quantity: 2, Format: FormatVinyl})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"quantity": 2, "format": "Vinyl"}`, string(encoded))
+
+	require.NoError(t, format.Scan(int64(3)))
+	assert.Equal(t, FormatCD, format)
+	assert.Error(t, format.Scan(int64(65)))
+}
+
+func TestOrderStatusCodecs(t *testing.T) {
+	assert.Len(t, OrderStatusOptions(), 9)
+	status, err := ParseOrderStatus("Shipped")
+	require.NoError(t, err)
+	assert.Equal(t, StatusShipped, status)
+
+	encoded, err := json.Marshal(StatusInvoiceSent)
+	require.NoError(t, err)
+	assert.Equal(t, `"Invoice Sent"`, string(encoded))
+	require.NoError(t, json.Unmarshal([]byte(`"Refund Pending"`), &status))
+	assert.Equal(t, StatusRefundPending, status)
+
+	value, err := StatusCancelled.Value()
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), value)
+	require.NoError(t, status.Scan("Confirmed"))
+	assert.Equal(t, StatusConfirmed, status)
+}
+
+func TestGradingCodecs(t *testing.T) {
+	grade, err := ParseGrading("VG+")
+	require.NoError(t, err)
+	assert.Equal(t, GradeVeryGoodPlus, grade)
+	assert.Equal(t, "Very Good Plus", grade.Name())
+	assert.Equal(t, 70, grade.Quality())
+
+	grade, err = ParseGrading("near mint")
+	require.NoError(t, err)
+	assert.Equal(t, GradeNearMint, grade)
+
+	encoded, err := json.Marshal(Vinyl{MediaGrade: GradeMint})
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"media_grade":"M"`)
+	assert.Contains(t, string(encoded), `"sleeve_grade":""`)
+
+	require.NoError(t, grade.Scan(int64(8)))
+	assert.Equal(t, GradePoor, grade)
+	assert.Equal(t, Grading{8, "P", "Poor", 5}, grade.Grading())
+}
+
+func TestGradingDiscogsConditions(t *testing.T) {
+	for _, grade := range GradingOptions() {
+		parsed, err := ParseGrading(grade.DiscogsCondition())
+		require.NoError(t, err, grade.DiscogsCondition())
+		assert.Equal(t, grade, parsed)
+	}
+
+	for condition, expected := range map[string]GradingEnum{
+		"Near Mint (NM or M-)": GradeNearMint,
+		"Very Good Plus (VG+)": GradeVeryGoodPlus,
+		"M-":                   GradeNearMint,
+		"Generic":              GradeGeneric,
+		"No Cover":             GradeNoCover,
+		"Not Graded":           GradeUnknown,
+		" g+ ":                 GradeGoodPlus,
+	} {
+		grade, err := ParseGrading(condition)
+		require.NoError(t, err, condition)
+		assert.Equal(t, expected, grade, condition)
+	}
+
+	_, err := ParseGrading("Excellent (EX)")
+	assert.Error(t, err)
+	assert.True(t, GradeGeneric.IsSleeveOnly())
+	assert.False(t, GradeGeneric.IsGraded())
+}
+
+func TestGradingCompare(t *testing.T) {
+	assert.Equal(t, 1, GradeMint.Compare(GradeNearMint))
+	assert.Equal(t, -1, GradeGood.Compare(GradeGoodPlus))
+	assert.Equal(t, 0, GradeFair.Compare(GradeFair))
+	assert.Equal(t, 1, GradePoor.Compare(GradeUnknown))
+	assert.Equal(t, 0, GradeNoCover.Compare(GradeGeneric))
+	assert.Greater(t, GradeVeryGoodPlus.Quality(), GradeVeryGood.Quality())
+}
+
+func TestGradeFilter(t *testing.T) {
+	filter, err := ParseGradeFilter("grade>=VG+")
+	require.NoError(t, err)
+	assert.Equal(t, GradeFilter{"media", ">=", GradeVeryGoodPlus}, filter)
+	assert.Equal(t, "media_grade", filter.Column())
+	assert.Equal(t, []GradingEnum{GradeMint, GradeNearMint, GradeVeryGoodPlus}, filter.Grades())
+	assert.False(t, filter.Matches(GradeUnknown))
+
+	filter, err = ParseGradeFilter("sleeve < Very Good (VG)")
+	require.NoError(t, err)
+	assert.Equal(t, "sleeve_grade", filter.Column())
+	assert.Equal(t, []GradingEnum{GradeGoodPlus, GradeGood, GradeFair, GradePoor}, filter.Grades())
+
+	filter, err = ParseGradeFilter("sleeve==Generic")
+	require.NoError(t, err)
+	assert.Equal(t, "sleeve=Generic", filter.String())
+	assert.True(t, filter.Matches(GradeGeneric))
+
+	_, err = ParseGradeFilter("label>=VG")
+	assert.Error(t, err)
+	_, err = ParseGradeFilter("grade VG")
+	assert.Error(t, err)
+}
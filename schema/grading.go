@@ -22,9 +22,287 @@
 
 package schema
 
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// A row of the Grading table, see GradingEnum for the values.
 type Grading struct {
 	GradeID int64  `json:"gradeID"`
 	Grade   string `json:"grade"` // shortened form, "M", "VG", ...
 	Name    string `json:"name"`
 	Quality int    `json:"quality"`
 }
+
+// The grade of a record's media or sleeve, based on the Goldmine Grading Guide:
+// https://www.discogs.com/selling/resources/how-to-grade-items/
+// Values match the Grading table; the zero value indicates an unknown grade.
+type GradingEnum uint8
+
+const (
+	GradeUnknown GradingEnum = iota
+	GradeMint
+	GradeNearMint
+	GradeVeryGoodPlus
+	GradeVeryGood
+	GradeGoodPlus
+	GradeGood
+	GradeFair
+	GradePoor
+
+	// Sleeve-only conditions used by the Discogs marketplace.
+	GradeGeneric
+	GradeNoCover
+)
+
+var gradings = []Grading{
+	GradeUnknown:      {0, "", "UNKNOWN", 50},
+	GradeMint:         {1, "M", "Mint", 100},
+	GradeNearMint:     {2, "NM", "Near Mint", 90},
+	GradeVeryGoodPlus: {3, "VG+", "Very Good Plus", 70},
+	GradeVeryGood:     {4, "VG", "Very Good", 45},
+	GradeGoodPlus:     {5, "G+", "Good Plus", 35},
+	GradeGood:         {6, "G", "Good", 30},
+	GradeFair:         {7, "F", "Fair", 10},
+	GradePoor:         {8, "P", "Poor", 5},
+	GradeGeneric:      {9, "Generic", "Generic", 15},
+	GradeNoCover:      {10, "No Cover", "No Cover", 0},
+}
+
+var grading_codes = func() enum_names {
+	codes := make(enum_names, len(gradings))
+	for i, grading := range gradings {
+		codes[i] = grading.Grade
+	}
+	return codes
+}()
+
+func GradingOptions() []GradingEnum {
+	options := make([]GradingEnum, len(gradings))
+	for i := range options {
+		options[i] = GradingEnum(i)
+	}
+	return options
+}
+
+// Returns the row of the Grading table for this grade.
+func (grade GradingEnum) Grading() Grading {
+	if !grading_codes.valid(int(grade)) {
+		return Grading{GradeID: int64(grade), Name: grade.String()}
+	}
+	return gradings[grade]
+}
+
+// The shortened form of the grade, e.g. "VG+".
+func (grade GradingEnum) String() string {
+	return grading_codes.name(int(grade))
+}
+
+func (grade GradingEnum) Name() string {
+	return grade.Grading().Name
+}
+
+func (grade GradingEnum) Quality() int {
+	return grade.Grading().Quality
+}
+
+// True for grades on the Goldmine scale, Mint through Poor.
+func (grade GradingEnum) IsGraded() bool {
+	return GradeMint <= grade && grade <= GradePoor
+}
+
+// True for the conditions that only describe a sleeve ("Generic", "No Cover").
+func (grade GradingEnum) IsSleeveOnly() bool {
+	return grade == GradeGeneric || grade == GradeNoCover
+}
+
+// The condition as written by the Discogs marketplace, e.g. "Very Good (VG)".
+func (grade GradingEnum) DiscogsCondition() string {
+	switch {
+	case grade == GradeUnknown:
+		return "Not Graded"
+	case grade == GradeNearMint:
+		return "Near Mint (NM or M-)"
+	case grade.IsGraded():
+		return fmt.Sprintf("%s (%s)", grade.Name(), grade.String())
+	}
+	return grade.Name()
+}
+
+// Position on the Goldmine scale, Mint highest; zero for ungraded values.
+func (grade GradingEnum) rank() int {
+	if !grade.IsGraded() {
+		return 0
+	}
+	return int(GradePoor-grade) + 1
+}
+
+// Compares the grades by condition, returning +1 if grade is better than
+// other, -1 if it is worse and 0 if they are equivalent.  Grades that are not
+// on the Goldmine scale (unknown and sleeve-only) rank below Poor.
+func (grade GradingEnum) Compare(other GradingEnum) int {
+	left, right := grade.rank(), other.rank()
+	switch {
+	case left > right:
+		return 1
+	case left < right:
+		return -1
+	}
+	return 0
+}
+
+// Alternative spellings seen in Discogs exports and marketplace listings.
+var grading_aliases = map[string]GradingEnum{
+	"m-":             GradeNearMint,
+	"nm or m-":       GradeNearMint,
+	"not graded":     GradeUnknown,
+	"generic sleeve": GradeGeneric,
+}
+
+// Finds the grade with the given short form ("VG+"), name ("Very Good Plus")
+// or Discogs condition string ("Near Mint (NM or M-)", "Generic").
+func ParseGrading(name string) (GradingEnum, error) {
+	name = strings.TrimSpace(name)
+	if id, err := grading_codes.parse("grade", name); err == nil {
+		return GradingEnum(id), nil
+	}
+	for i, grading := range gradings {
+		if strings.EqualFold(grading.Name, name) {
+			return GradingEnum(i), nil
+		}
+	}
+	if grade, found := grading_aliases[strings.ToLower(name)]; found {
+		return grade, nil
+	}
+
+	// Discogs writes the name followed by its code in parentheses.
+	if open := strings.LastIndex(name, "("); open > 0 && strings.HasSuffix(name, ")") {
+		grade, err := ParseGrading(name[:open])
+		if err == nil {
+			return grade, nil
+		}
+		return ParseGrading(name[open+1 : len(name)-1])
+	}
+	return GradeUnknown, fmt.Errorf("unrecognized grade %q", name)
+}
+
+func parse_grading(name string) (int, error) {
+	grade, err := ParseGrading(name)
+	return int(grade), err
+}
+
+func (grade GradingEnum) MarshalJSON() ([]byte, error) {
+	return json.Marshal(grade.String())
+}
+
+func (grade *GradingEnum) UnmarshalJSON(data []byte) error {
+	id, err := grading_codes.unmarshal("grade", data, parse_grading)
+	if err != nil {
+		return err
+	}
+	*grade = GradingEnum(id)
+	return nil
+}
+
+func (grade *GradingEnum) Scan(src any) error {
+	id, err := grading_codes.scan("grade", src, parse_grading)
+	if err != nil {
+		return err
+	}
+	*grade = GradingEnum(id)
+	return nil
+}
+
+func (grade GradingEnum) Value() (driver.Value, error) {
+	return enum_value(int(grade))
+}
+
+// A comparison of a vinyl's grade against a fixed grade, as in "grade>=VG+".
+//
+// The field may be "media" or "sleeve", and "grade" is shorthand for media.
+// The operators are <, <=, >, >=, = (or ==) and !=, where "better" grades are
+// greater.  Ungraded and sleeve-only values only satisfy (in)equality.
+type GradeFilter struct {
+	Field string
+	Op    string
+	Grade GradingEnum
+}
+
+var grade_filter_ops = []string{">=", "<=", "==", "!=", ">", "<", "="}
+
+func ParseGradeFilter(expr string) (GradeFilter, error) {
+	var filter GradeFilter
+	for _, op := range grade_filter_ops {
+		index := strings.Index(expr, op)
+		if index < 0 {
+			continue
+		}
+		filter.Field = strings.ToLower(strings.TrimSpace(expr[:index]))
+		filter.Op = op
+		if op == "==" {
+			filter.Op = "="
+		}
+		switch filter.Field {
+		case "grade":
+			filter.Field = "media"
+		case "media", "sleeve":
+		default:
+			return filter, fmt.Errorf("unknown grade field %q in %q", filter.Field, expr)
+		}
+
+		grade, err := ParseGrading(expr[index+len(op):])
+		if err != nil {
+			return filter, err
+		}
+		filter.Grade = grade
+		return filter, nil
+	}
+	return filter, fmt.Errorf("grade filter %q has no comparison operator", expr)
+}
+
+// The column of the VinylItems table that this filter applies to.
+func (filter GradeFilter) Column() string {
+	return filter.Field + "_grade"
+}
+
+func (filter GradeFilter) Matches(grade GradingEnum) bool {
+	switch filter.Op {
+	case "=":
+		return grade == filter.Grade
+	case "!=":
+		return grade != filter.Grade
+	}
+	if !grade.IsGraded() || !filter.Grade.IsGraded() {
+		return false
+	}
+	order := grade.Compare(filter.Grade)
+	switch filter.Op {
+	case ">":
+		return order > 0
+	case ">=":
+		return order >= 0
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	}
+	return false
+}
+
+// All grades which satisfy the filter, for use in a SQL "IN (...)" clause.
+func (filter GradeFilter) Grades() []GradingEnum {
+	grades := make([]GradingEnum, 0, len(gradings))
+	for _, grade := range GradingOptions() {
+		if filter.Matches(grade) {
+			grades = append(grades, grade)
+		}
+	}
+	return grades
+}
+
+func (filter GradeFilter) String() string {
+	return fmt.Sprintf("%s%s%s", filter.Field, filter.Op, filter.Grade)
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/schema/image.go
+
+package schema
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// The path, type and size of an image asset (cover art, avatars, etc.).
+type Image struct {
+	ID uint64 `json:"imageID"`
+	// Either "primary" or "secondary".
+	Type string `json:"type,omitempty"`
+
+	// Path in object storage, relative to the bucket where images are stored.
+	Path     string `json:"path"`
+	Filetype string `json:"filetype,omitempty"`
+	Width    int    `json:"width,omitempty"`
+	Height   int    `json:"height,omitempty"`
+
+	// These will be the empty string for unauthenticated requests.
+	URI    string `json:"uri,omitempty"`
+	URI150 string `json:"uri150,omitempty"`
+}
+
+func (image Image) Typename() string { return "image" }
+func (image Image) Key() string      { return strconv.FormatUint(image.ID, 10) }
+
+func (image Image) MarshalJSON() ([]byte, error) {
+	type image_json Image
+	return json.Marshal(image_json(image))
+}
+
+func (image Image) Validate() error {
+	v := validate(image.Typename())
+	v.check(image.Path != "" || image.URI != "", "path", "is required when there is no uri")
+	v.check(image.Type == "" || image.Type == "primary" || image.Type == "secondary",
+		"type", "must be \"primary\" or \"secondary\"")
+	v.check(image.Width >= 0, "width", "must not be negative")
+	v.check(image.Height >= 0, "height", "must not be negative")
+	return v.err()
+}
+
+func NewImageParser(schema string) JsonParser[Image] {
+	return newStrictParser[Image](schema, "#Image")
+}
@@ -0,0 +1,226 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/schema/jsonschema.go
+
+package schema
+
+import (
+	"encoding"
+	"fmt"
+	"go/token"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// A JSON Schema (draft 2020-12, the dialect of OpenAPI 3.1) as it is encoded.
+type JSONSchema map[string]any
+
+// Generates JSON Schemas for Go types by reflection, following their json
+// field tags and the custom encodings of this package's types (dates, money
+// and the enums).  Exported struct types become shared definitions, which the
+// schemas refer to with a $ref; unexported and anonymous structs are inlined.
+type SchemaGenerator struct {
+	// Prepended to definition names in $ref, e.g. "#/components/schemas/".
+	RefPrefix   string
+	Definitions map[string]JSONSchema
+
+	types map[string]reflect.Type
+}
+
+func NewSchemaGenerator(ref_prefix string) *SchemaGenerator {
+	return &SchemaGenerator{
+		RefPrefix:   ref_prefix,
+		Definitions: make(map[string]JSONSchema),
+		types:       make(map[string]reflect.Type),
+	}
+}
+
+// The schema of the value's type (the value itself is not inspected).
+func (generator *SchemaGenerator) Of(value any) JSONSchema {
+	return generator.OfType(reflect.TypeOf(value))
+}
+
+var text_encoded = reflect.TypeFor[encoding.TextMarshaler]()
+
+func (generator *SchemaGenerator) OfType(goType reflect.Type) JSONSchema {
+	for goType.Kind() == reflect.Pointer {
+		goType = goType.Elem()
+	}
+	if custom, found := custom_schemas[goType]; found {
+		if !custom.shared {
+			return custom.schema(generator)
+		}
+		return generator.define(goType, func() JSONSchema { return custom.schema(generator) })
+	}
+	if goType.Implements(text_encoded) {
+		return JSONSchema{"type": "string"}
+	}
+
+	switch goType.Kind() {
+	case reflect.Bool:
+		return JSONSchema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return JSONSchema{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return JSONSchema{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return JSONSchema{"type": "number"}
+	case reflect.String:
+		return JSONSchema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if goType.Elem().Kind() == reflect.Uint8 {
+			return JSONSchema{"type": "string", "contentEncoding": "base64"}
+		}
+		return JSONSchema{"type": "array", "items": generator.OfType(goType.Elem())}
+	case reflect.Map:
+		return JSONSchema{"type": "object", "additionalProperties": generator.OfType(goType.Elem())}
+	case reflect.Struct:
+		if !token.IsExported(goType.Name()) {
+			return generator.object(goType)
+		}
+		return generator.define(goType, func() JSONSchema { return generator.object(goType) })
+	}
+	// Interfaces (and anything else) may hold any value.
+	return JSONSchema{}
+}
+
+// Adds the definition for the named type, if it is not yet defined, and
+// returns a reference to it.  Types of the same name in different packages
+// are an error (as both would be referred to by the same name).
+func (generator *SchemaGenerator) define(goType reflect.Type, schema func() JSONSchema) JSONSchema {
+	name := goType.Name()
+	ref := JSONSchema{"$ref": generator.RefPrefix + name}
+	if defined, found := generator.types[name]; found {
+		if defined != goType {
+			panic(fmt.Sprintf("schema definition %q is both %v and %v", name, defined, goType))
+		}
+		return ref
+	}
+	// Registered before generating, so that recursive types refer to it.
+	generator.types[name] = goType
+	generator.Definitions[name] = schema()
+	return ref
+}
+
+// The schema of a struct's fields, as encoding/json writes them.  Fields are
+// required unless they are omitempty or pointers.
+func (generator *SchemaGenerator) object(goType reflect.Type) JSONSchema {
+	properties := make(map[string]JSONSchema)
+	required := generator.fields(goType, properties, []string{})
+	schema := JSONSchema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// Adds the struct's fields to the properties, including the fields of untagged
+// embedded structs (which encoding/json flattens), returning the required ones.
+func (generator *SchemaGenerator) fields(goType reflect.Type, properties map[string]JSONSchema, required []string) []string {
+	for i := range goType.NumField() {
+		field := goType.Field(i)
+		tag := field.Tag.Get("json")
+		name, options, _ := strings.Cut(tag, ",")
+		if tag == "-" || !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				required = generator.fields(embedded, properties, required)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = generator.OfType(field.Type)
+		if !strings.Contains(options, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			required = append(required, name)
+		}
+	}
+	return required
+}
+
+type custom_schema struct {
+	// Whether the schema is a shared definition (rather than inlined).
+	shared bool
+	schema func(*SchemaGenerator) JSONSchema
+}
+
+// Schemas of the types whose JSON encoding is not their Go structure.
+var custom_schemas = map[reflect.Type]custom_schema{
+	reflect.TypeFor[Date](): {false, func(*SchemaGenerator) JSONSchema {
+		return JSONSchema{"type": "string", "format": "date"}
+	}},
+	reflect.TypeFor[Timestamp](): {false, func(*SchemaGenerator) JSONSchema {
+		return JSONSchema{"type": "string", "pattern": `^\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}$`,
+			"description": "in UTC, as YYYY-MM-DD HH:MM:SS"}
+	}},
+	reflect.TypeFor[time.Time](): {false, func(*SchemaGenerator) JSONSchema {
+		return JSONSchema{"type": "string", "format": "date-time"}
+	}},
+	reflect.TypeFor[VinylKey](): {false, func(*SchemaGenerator) JSONSchema {
+		return JSONSchema{"type": "string", "pattern": `^\d+-\d+-\d+$`,
+			"description": "userID-versionID-item, e.g. 42-1178-2"}
+	}},
+	reflect.TypeFor[Money](): {true, func(generator *SchemaGenerator) JSONSchema {
+		return JSONSchema{
+			"type": "object",
+			"properties": map[string]JSONSchema{
+				"amount": {"type": "string", "pattern": `^-?\d+(\.\d+)?$`,
+					"description": "a decimal in the currency's major units, e.g. 27.50"},
+				"currency": generator.define(reflect.TypeFor[CurrencyEnum](),
+					func() JSONSchema { return currency_schema(generator) }),
+			},
+			"required": []string{"amount"},
+		}
+	}},
+	reflect.TypeFor[CurrencyEnum]():    {true, currency_schema},
+	reflect.TypeFor[DataQualityEnum](): {true, enum_schema(DataQualityOptions, DataQualityEnum.String)},
+	reflect.TypeFor[GradingEnum]():     {true, enum_schema(GradingOptions, GradingEnum.String)},
+	reflect.TypeFor[MediaFormatEnum](): {true, enum_schema(MediaFormatOptions, MediaFormatEnum.String)},
+	reflect.TypeFor[OrderStatusEnum](): {true, enum_schema(OrderStatusOptions, OrderStatusEnum.String)},
+}
+
+var currency_schema = enum_schema(CurrencyOptions,
+	func(currency CurrencyEnum) string { return string(currency) })
+
+// A string schema of the options' names, skipping gaps in their IDs (but
+// keeping the zero value, which is the empty string for an unknown value).
+func enum_schema[T any](options func() []T, name_of func(T) string) func(*SchemaGenerator) JSONSchema {
+	return func(*SchemaGenerator) JSONSchema {
+		names := []string{}
+		for i, option := range options() {
+			if name := name_of(option); i == 0 || name != "" {
+				names = append(names, name)
+			}
+		}
+		return JSONSchema{"type": "string", "enum": names}
+	}
+}
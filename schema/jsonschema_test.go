@@ -0,0 +1,86 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/schema/jsonschema_test.go
+
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaOfResource(t *testing.T) {
+	generator := NewSchemaGenerator("#/components/schemas/")
+	assert.Equal(t, JSONSchema{"$ref": "#/components/schemas/Listing"}, generator.Of(Listing{}))
+	assert.Equal(t, JSONSchema{"$ref": "#/components/schemas/Listing"}, generator.Of(&Listing{}))
+
+	listing := generator.Definitions["Listing"]
+	assert.Equal(t, "object", listing["type"])
+	assert.Equal(t, []string{"userID", "versionID", "item", "allow_offers"}, listing["required"])
+	properties := listing["properties"].(map[string]JSONSchema)
+	assert.Equal(t, JSONSchema{"type": "integer", "minimum": 0}, properties["userID"])
+	assert.Equal(t, JSONSchema{"type": "boolean"}, properties["allow_offers"])
+	assert.Equal(t, JSONSchema{"type": "string", "format": "date"}, properties["date_opened"])
+	assert.Equal(t, JSONSchema{"$ref": "#/components/schemas/Money"}, properties["price_low"])
+
+	// Money and its currency are shared definitions.
+	assert.Contains(t, generator.Definitions, "Money")
+	assert.Contains(t, generator.Definitions["CurrencyEnum"]["enum"], "JPY")
+}
+
+func TestSchemaOfEnums(t *testing.T) {
+	generator := NewSchemaGenerator("#/$defs/")
+	assert.Equal(t, JSONSchema{"$ref": "#/$defs/GradingEnum"}, generator.Of(GradeMint))
+
+	grades := generator.Definitions["GradingEnum"]["enum"].([]string)
+	assert.Equal(t, "", grades[0], "the unknown grade is written as an empty string")
+	assert.Contains(t, grades, "VG+")
+
+	generator.Of(MediaFormatEnum(0))
+	formats := generator.Definitions["MediaFormatEnum"]["enum"].([]string)
+	assert.Contains(t, formats, "Vinyl")
+}
+
+func TestSchemaOfStructs(t *testing.T) {
+	type embedded struct {
+		Shared string `json:"shared"`
+	}
+	type body struct {
+		embedded
+		Keys     []VinylKey `json:"keys"`
+		Optional *int       `json:"optional"`
+		Skipped  string     `json:"-"`
+		Any      any        `json:"any,omitempty"`
+		hidden   string
+	}
+	generator := NewSchemaGenerator("#/$defs/")
+	schema := generator.Of(body{})
+	assert.Empty(t, generator.Definitions, "unexported structs are inlined")
+	assert.Equal(t, []string{"shared", "keys"}, schema["required"])
+
+	properties := schema["properties"].(map[string]JSONSchema)
+	assert.Len(t, properties, 4)
+	assert.Equal(t, JSONSchema{"type": "string"}, properties["shared"])
+	assert.Equal(t, "string", properties["keys"]["items"].(JSONSchema)["type"])
+	assert.Equal(t, JSONSchema{"type": "integer"}, properties["optional"])
+	assert.Equal(t, JSONSchema{}, properties["any"])
+}
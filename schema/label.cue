@@ -1,4 +1,4 @@
-// Copyright (c) 2024 Kevin Damm
+// Copyright (c) 2025 Kevin Damm
 //
 // Permission is hereby granted, free of charge, to any person obtaining a copy
 // of this software and associated documentation files (the "Software"), to deal
@@ -25,4 +25,16 @@ package cratedigdb
 #Label: {
   type?: "label"
   labelID!: uint64
+  name!:    string
+
+  contact?: string
+  profile?: string
+
+  parentID?:    uint64
+  parent_name?: string
+
+  data_quality?: #DataQuality
+
+  urls?: [...string]
+  images?: [...#Image]
 }
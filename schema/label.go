@@ -24,26 +24,44 @@ package schema
 
 import (
 	"encoding/json"
-	"log"
+	"strconv"
 )
 
 type Label struct {
-	ID uint64 `json:"labelID"`
+	ID   uint64 `json:"labelID"`
+	Name string `json:"name"`
+
+	Contact string `json:"contact,omitempty"`
+	Profile string `json:"profile,omitempty"`
+
+	// The parent label (e.g. a distributor or holding company); a zero value
+	// indicates this label is not a sublabel.
+	ParentID   uint64 `json:"parentID,omitempty"`
+	ParentName string `json:"parent_name,omitempty"`
+
+	DataQuality DataQualityEnum `json:"data_quality"`
+
+	URLs   []string `json:"urls,omitempty"`
+	Images []Image  `json:"images,omitempty"`
 }
 
 func (label Label) Typename() string { return "label" }
-func (label Label) ToJson() string {
-	bytes, err := json.MarshalIndent(label, "", "  ")
-	if err != nil {
-		log.Fatal(err)
-	}
-	return string(bytes)
-}
+func (label Label) Key() string      { return strconv.FormatUint(label.ID, 10) }
 
-func NewLabelParser(schema string) JsonParser[Label] {
+func (label Label) MarshalJSON() ([]byte, error) {
+	type label_json Label
+	return json.Marshal(label_json(label))
+}
 
-	return func(json string, label *Label) error {
+func (label Label) Validate() error {
+	v := validate(label.Typename())
+	v.required(label.ID, "labelID")
+	v.required(label.Name, "name")
+	v.check(label.ParentID != label.ID, "parentID", "cannot be the label itself")
+	v.check(data_quality_names.valid(int(label.DataQuality)), "data_quality", "is not a valid data quality")
+	return v.err()
+}
 
-		return nil
-	}
+func NewLabelParser(schema string) JsonParser[Label] {
+	return newStrictParser[Label](schema, "#Label")
 }
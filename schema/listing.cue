@@ -1,4 +1,4 @@
-// Copyright (c) 2024 Kevin Damm
+// Copyright (c) 2025 Kevin Damm
 //
 // Permission is hereby granted, free of charge, to any person obtaining a copy
 // of this software and associated documentation files (the "Software"), to deal
@@ -24,7 +24,14 @@ package cratedigdb
 
 #Listing: {
   type?: "listing"
-  userID: uint64
-  versionID: uint64
-  item: uint
+  userID!:    uint64
+  versionID!: uint64
+  item!:      uint
+
+  price_low?:   #Money
+  price_high?:  #Money
+  allow_offers: bool | *false
+
+  date_opened?: string // YYYY-MM-DD
+  date_closed?: string // YYYY-MM-DD
 }
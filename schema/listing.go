@@ -24,28 +24,51 @@ package schema
 
 import (
 	"encoding/json"
-	"log"
 )
 
+// An offer to sell a specific copy (the vinyl item) on the marketplace.
 type Listing struct {
-	UserID    uint64 `json:"vinylID"`
+	UserID    uint64 `json:"userID"`
 	VersionID uint64 `json:"versionID"`
 	Item      uint   `json:"item"`
+
+	// Both prices are in the same currency, stored as price_currency in SQL.
+	PriceLow    *Money `json:"price_low,omitempty"`
+	PriceHigh   *Money `json:"price_high,omitempty"`
+	AllowOffers bool   `json:"allow_offers"`
+
+	DateOpened *Date `json:"date_opened,omitempty"`
+	// If nil, this listing is still available.
+	DateClosed *Date `json:"date_closed,omitempty"`
 }
 
 func (listing Listing) Typename() string { return "listing" }
-func (listing Listing) ToJson() string {
-	bytes, err := json.MarshalIndent(listing, "", "  ")
-	if err != nil {
-		log.Fatal(err)
+func (listing Listing) Key() string      { return listing.VinylKey().String() }
+
+func (listing Listing) MarshalJSON() ([]byte, error) {
+	type listing_json Listing
+	return json.Marshal(listing_json(listing))
+}
+
+func (listing Listing) Validate() error {
+	v := validate(listing.Typename())
+	v.required(listing.UserID, "userID")
+	v.required(listing.VersionID, "versionID")
+	v.required(listing.Item, "item")
+	if listing.PriceLow != nil {
+		v.money(*listing.PriceLow, "price_low")
 	}
-	return string(bytes)
+	if listing.PriceHigh != nil {
+		v.money(*listing.PriceHigh, "price_high")
+	}
+	if listing.PriceLow != nil && listing.PriceHigh != nil {
+		order, err := listing.PriceLow.Compare(*listing.PriceHigh)
+		v.check(err == nil, "price_high.currency", "must be the same currency as price_low")
+		v.check(order <= 0, "price_high", "must not be less than price_low")
+	}
+	return v.err()
 }
 
 func NewListingParser(schema string) JsonParser[Listing] {
-
-	return func(json string, listing *Listing) error {
-
-		return nil
-	}
+	return newStrictParser[Listing](schema, "#Listing")
 }
@@ -0,0 +1,256 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/schema/mediaformat.go
+
+package schema
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"strings"
+)
+
+// The media format of a release version, matching the MediaFormatEnum table.
+// Values are based on the Formats List at https://www.discogs.com/help/formatslist
+//
+// The IDs follow the insertion order of the base data, starting at 1; the zero
+// value indicates an unknown format.
+type MediaFormatEnum uint8
+
+// The most common formats; any of the others can be found with ParseMediaFormat.
+const (
+	FormatVinyl      MediaFormatEnum = 1
+	FormatCassette   MediaFormatEnum = 2
+	FormatCD         MediaFormatEnum = 3
+	FormatCDr        MediaFormatEnum = 4
+	FormatFile       MediaFormatEnum = 5
+	FormatAcetate    MediaFormatEnum = 6
+	FormatFlexiDisc  MediaFormatEnum = 7
+	FormatLatheCut   MediaFormatEnum = 8
+	FormatShellac    MediaFormatEnum = 9
+	FormatDVD        MediaFormatEnum = 16
+	FormatBluRay     MediaFormatEnum = 20
+	Format8Track     MediaFormatEnum = 25
+	FormatReelToReel MediaFormatEnum = 37
+	FormatMinidisc   MediaFormatEnum = 56
+	FormatAllMedia   MediaFormatEnum = 63
+	FormatBoxSet     MediaFormatEnum = 64
+)
+
+var media_format_names = enum_names{
+	"",
+	"Vinyl",
+	"Cassette",
+	"CD",
+	"CDr",
+	"File",
+	"Acetate",
+	"Flexi-disc",
+	"Lathe Cut",
+	"Shellac",
+	"Mighty Tiny",
+	"Sopic",
+	"Pathé Disc",
+	"Edison Disc",
+	"Cylinder",
+	"CDV",
+	"DVD",
+	"DVDr",
+	"HD DVD",
+	"HD DVD-R",
+	"Blu-ray",
+	"Blu-ray-R",
+	"Ultra HD Blu-ray",
+	"SACD",
+	"4-Track Cartridge",
+	"8-Track Cartridge",
+	"DC-International",
+	"Elcaset",
+	"PlayTape",
+	"RCA Tape Cartridge",
+	"DAT",
+	"DCC",
+	"Microcassette",
+	"NT Cassette",
+	"Pocket Rocker",
+	"Revere Magnetic Stereo Tape Ca",
+	"Tefifon",
+	"Reel-To-Reel",
+	"Sabamobil",
+	"Betacam",
+	"Betacam SP",
+	"Betamax",
+	"Cartrivision",
+	"MiniDV",
+	"Super VHS",
+	"U-matic",
+	"VHS",
+	"Video 2000",
+	"Video8",
+	"Film Reel",
+	"HitClips",
+	"Laserdisc",
+	"SelectaVision",
+	"TeD",
+	"VHD",
+	"Wire Recording",
+	"Minidisc",
+	"MVD",
+	"UMD",
+	"Floppy Disk",
+	"Zip Disk",
+	"Memory Stick",
+	"Hybrid",
+	"All Media",
+	"Box Set",
+}
+
+var media_format_abbrs = []string{
+	"",
+	"Vinyl",
+	"Cass",
+	"CD",
+	"CDr",
+	"File",
+	"Acetate",
+	"Flexi",
+	"Lathe",
+	"Shellac",
+	"Mighty Tiny",
+	"Sopic",
+	"Pathé Disc",
+	"Edison Disc",
+	"Cyl",
+	"CDV",
+	"DVD",
+	"DVDr",
+	"HD DVD",
+	"HD DVD-R",
+	"Blu-ray",
+	"Blu-ray-R",
+	"Ultra HD Blu-ray",
+	"SACD",
+	"4-Trk",
+	"8-Trk",
+	"DC-International",
+	"Elcaset",
+	"PlayTape",
+	"RCA Tape Cartridge",
+	"DAT",
+	"DCC",
+	"M/cass",
+	"NT",
+	"Pocket Rocker",
+	"Revere Magnetic Stereo Tape Ca",
+	"Tefifon",
+	"Reel",
+	"Sabamobil",
+	"Betacam",
+	"Betacam SP",
+	"Beta",
+	"Cartrivision",
+	"MiniDV",
+	"S-VHS",
+	"Umatic",
+	"VHS",
+	"V2000",
+	"Video8",
+	"Film",
+	"HitClips",
+	"Laserdisc",
+	"S/Vision",
+	"TeD",
+	"VHD",
+	"Wire",
+	"MD",
+	"MVD",
+	"UMD",
+	"Floppy",
+	"Zip Disk",
+	"M/Stick",
+	"Hybrid",
+	"All Media",
+	"Box",
+}
+
+func MediaFormatOptions() []MediaFormatEnum {
+	options := make([]MediaFormatEnum, 0, len(media_format_names)-1)
+	for i := 1; i < len(media_format_names); i++ {
+		options = append(options, MediaFormatEnum(i))
+	}
+	return options
+}
+
+func (format MediaFormatEnum) String() string {
+	return media_format_names.name(int(format))
+}
+
+// The abbreviated form of the format name, e.g. "Cass" for "Cassette".
+func (format MediaFormatEnum) Abbr() string {
+	if !media_format_names.valid(int(format)) {
+		return ""
+	}
+	return media_format_abbrs[format]
+}
+
+// Finds the format with the given name or abbreviation.
+func ParseMediaFormat(name string) (MediaFormatEnum, error) {
+	id, err := media_format_names.parse("media format", name)
+	if err != nil {
+		for i, abbr := range media_format_abbrs {
+			if i > 0 && strings.EqualFold(abbr, name) {
+				return MediaFormatEnum(i), nil
+			}
+		}
+	}
+	return MediaFormatEnum(id), err
+}
+
+func parse_media_format(name string) (int, error) {
+	format, err := ParseMediaFormat(name)
+	return int(format), err
+}
+
+func (format MediaFormatEnum) MarshalJSON() ([]byte, error) {
+	return json.Marshal(format.String())
+}
+
+func (format *MediaFormatEnum) UnmarshalJSON(data []byte) error {
+	id, err := media_format_names.unmarshal("media format", data, parse_media_format)
+	if err != nil {
+		return err
+	}
+	*format = MediaFormatEnum(id)
+	return nil
+}
+
+func (format *MediaFormatEnum) Scan(src any) error {
+	id, err := media_format_names.scan("media format", src, parse_media_format)
+	if err != nil {
+		return err
+	}
+	*format = MediaFormatEnum(id)
+	return nil
+}
+
+func (format MediaFormatEnum) Value() (driver.Value, error) {
+	return enum_value(int(format))
+}
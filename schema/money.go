@@ -0,0 +1,375 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/schema/money.go
+
+package schema
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// An amount of money in the minor units of its currency (cents for USD, yen
+// for JPY).  Amounts are never represented as floating point, and arithmetic
+// between different currencies is refused rather than silently mixed.
+type Money struct {
+	Amount   int64        `json:"amount"`
+	Currency CurrencyEnum `json:"currency"`
+}
+
+var ErrCurrencyMismatch = errors.New("cannot combine amounts in different currencies")
+var ErrMoneyOverflow = errors.New("money amount overflows int64 minor units")
+
+func NewMoney(amount int64, currency CurrencyEnum) Money {
+	return Money{amount, currency.or_default()}
+}
+
+// Parses a decimal amount in the currency's major units, e.g. "27.50" or
+// "1,234.5" for USD, and rejects more decimal places than the currency has.
+func ParseMoney(decimal string, currency CurrencyEnum) (Money, error) {
+	currency = currency.or_default()
+	if !currency.IsValid() {
+		return Money{}, fmt.Errorf("unrecognized currency %q", string(currency))
+	}
+	text := strings.ReplaceAll(strings.TrimSpace(decimal), ",", "")
+	negative := strings.HasPrefix(text, "-")
+	text = strings.TrimPrefix(text, "-")
+
+	whole, fraction, _ := strings.Cut(text, ".")
+	if whole == "" && fraction == "" {
+		return Money{}, fmt.Errorf("invalid amount %q", decimal)
+	}
+	exponent := currency.Exponent()
+	if len(fraction) > exponent {
+		return Money{}, fmt.Errorf("amount %q has more than %d decimal places for %s",
+			decimal, exponent, currency)
+	}
+	fraction += strings.Repeat("0", exponent-len(fraction))
+
+	var amount int64
+	for _, digit := range whole + fraction {
+		if digit < '0' || digit > '9' {
+			return Money{}, fmt.Errorf("invalid amount %q", decimal)
+		}
+		if amount > (math.MaxInt64-int64(digit-'0'))/10 {
+			return Money{}, ErrMoneyOverflow
+		}
+		amount = amount*10 + int64(digit-'0')
+	}
+	if negative {
+		amount = -amount
+	}
+	return Money{amount, currency}, nil
+}
+
+func (money Money) IsZero() bool {
+	return money.Amount == 0
+}
+
+// The amount in major units without grouping or symbol, e.g. "1234.50".
+func (money Money) Decimal() string {
+	return money.format(".", "")
+}
+
+// The amount and currency code, e.g. "27.50 USD".
+func (money Money) String() string {
+	return money.Decimal() + " " + string(money.Currency.or_default())
+}
+
+// Conventions for writing amounts in a locale, identified by a BCP 47 tag.
+type money_locale struct {
+	decimal, group string
+	// If true, the symbol follows the amount ("12,50 €") instead of leading.
+	suffix bool
+	// If true, the symbol is separated from the amount by a space.
+	spaced bool
+}
+
+var money_locales = map[string]money_locale{
+	"en":    {".", ",", false, false},
+	"ja":    {".", ",", false, false},
+	"de":    {",", ".", true, true},
+	"de-CH": {".", "'", false, true},
+	"es":    {",", ".", true, true},
+	"es-MX": {".", ",", false, false},
+	"fr":    {",", " ", true, true},
+	"fr-CA": {",", " ", true, true},
+	"it":    {",", ".", true, true},
+	"nl":    {",", ".", false, true},
+	"pt":    {",", ".", false, true},
+	"sv":    {",", " ", true, true},
+}
+
+// Lookup by the full tag ("de-CH") then by its language ("de"), else English.
+func find_money_locale(tag string) money_locale {
+	tag = strings.ReplaceAll(tag, "_", "-")
+	if locale, found := money_locales[tag]; found {
+		return locale
+	}
+	language, _, _ := strings.Cut(tag, "-")
+	if locale, found := money_locales[strings.ToLower(language)]; found {
+		return locale
+	}
+	return money_locales["en"]
+}
+
+// Formats the amount for display in the given locale (e.g. "en-US", "de-DE"),
+// using the currency's symbol: "$1,234.50", "1.234,50 €", "¥1,235".
+func (money Money) Format(locale string) string {
+	conventions := find_money_locale(locale)
+	amount := money.format(conventions.decimal, conventions.group)
+	sign := ""
+	if strings.HasPrefix(amount, "-") {
+		sign, amount = "-", amount[1:]
+	}
+	space := ""
+	if conventions.spaced {
+		space = " "
+	}
+	symbol := money.Currency.Symbol()
+	if conventions.suffix {
+		return sign + amount + space + symbol
+	}
+	return sign + symbol + space + amount
+}
+
+func (money Money) format(decimal string, group string) string {
+	exponent := money.Currency.Exponent()
+	magnitude := fmt.Sprintf("%0*d", exponent+1, money.Amount)
+	sign := ""
+	if money.Amount < 0 {
+		// The minimum int64 has no positive counterpart, so trim the text.
+		sign = "-"
+		magnitude = fmt.Sprintf("%0*s", exponent+1, strings.TrimPrefix(magnitude, "-"))
+	}
+	whole := magnitude[:len(magnitude)-exponent]
+	fraction := magnitude[len(magnitude)-exponent:]
+
+	if group != "" {
+		var grouped strings.Builder
+		for i, digit := range whole {
+			if i > 0 && (len(whole)-i)%3 == 0 {
+				grouped.WriteString(group)
+			}
+			grouped.WriteRune(digit)
+		}
+		whole = grouped.String()
+	}
+	if exponent == 0 {
+		return sign + whole
+	}
+	return sign + whole + decimal + fraction
+}
+
+func (money Money) same_currency(other Money) error {
+	if money.Currency.or_default() != other.Currency.or_default() {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch,
+			money.Currency.or_default(), other.Currency.or_default())
+	}
+	return nil
+}
+
+func (money Money) Add(other Money) (Money, error) {
+	if err := money.same_currency(other); err != nil {
+		return Money{}, err
+	}
+	sum := money.Amount + other.Amount
+	if (other.Amount > 0 && sum < money.Amount) || (other.Amount < 0 && sum > money.Amount) {
+		return Money{}, ErrMoneyOverflow
+	}
+	return Money{sum, money.Currency.or_default()}, nil
+}
+
+func (money Money) Sub(other Money) (Money, error) {
+	if other.Amount == math.MinInt64 {
+		return Money{}, ErrMoneyOverflow
+	}
+	return money.Add(Money{-other.Amount, other.Currency})
+}
+
+// Multiplies the amount by a whole quantity, e.g. for several identical items.
+func (money Money) Mul(quantity int64) (Money, error) {
+	if money.Amount != 0 && quantity != 0 {
+		product := money.Amount * quantity
+		if product/quantity != money.Amount || (money.Amount == math.MinInt64 && quantity == -1) {
+			return Money{}, ErrMoneyOverflow
+		}
+		return Money{product, money.Currency.or_default()}, nil
+	}
+	return Money{0, money.Currency.or_default()}, nil
+}
+
+// Returns -1, 0 or +1 as money is less than, equal to or greater than other.
+func (money Money) Compare(other Money) (int, error) {
+	if err := money.same_currency(other); err != nil {
+		return 0, err
+	}
+	switch {
+	case money.Amount < other.Amount:
+		return -1, nil
+	case money.Amount > other.Amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Totals the amounts, which must all be in the given currency.
+func SumMoney(currency CurrencyEnum, amounts ...Money) (Money, error) {
+	total := NewMoney(0, currency)
+	for _, amount := range amounts {
+		var err error
+		if total, err = total.Add(amount); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// Encodes the amount as a decimal string in major units, so that clients do
+// not need to know each currency's exponent: {"amount":"27.50","currency":"USD"}
+func (money Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string       `json:"amount"`
+		Currency CurrencyEnum `json:"currency"`
+	}{money.Decimal(), money.Currency.or_default()})
+}
+
+// Accepts the amount as a decimal string or a JSON number, both in major units.
+// If the currency is omitted it is assumed to be USD.
+func (money *Money) UnmarshalJSON(data []byte) error {
+	var encoded struct {
+		Amount   json.RawMessage `json:"amount"`
+		Currency string          `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	if len(encoded.Amount) == 0 {
+		return errors.New("money is missing its amount")
+	}
+	var decimal string
+	if err := json.Unmarshal(encoded.Amount, &decimal); err != nil {
+		var number json.Number
+		if err := json.Unmarshal(encoded.Amount, &number); err != nil {
+			return fmt.Errorf("amount must be a decimal string or number, got %s", encoded.Amount)
+		}
+		decimal = number.String()
+	}
+
+	currency := CurrencyUSD
+	if encoded.Currency != "" {
+		var err error
+		if currency, err = ParseCurrency(encoded.Currency); err != nil {
+			return err
+		}
+	}
+	parsed, err := ParseMoney(decimal, currency)
+	if err != nil {
+		return err
+	}
+	*money = parsed
+	return nil
+}
+
+// The amount in minor units, for its SQL column (e.g. Orders.offer_price).
+// The currency has a column of its own, bound as money.Currency.
+func (money Money) Value() (driver.Value, error) {
+	return money.Amount, nil
+}
+
+// Reads the amount in minor units from its SQL column, leaving the currency
+// as it is (scan its own column into money.Currency).  Amounts in REAL
+// columns must be whole numbers of minor units.
+func (money *Money) Scan(src any) error {
+	switch value := src.(type) {
+	case int64:
+		money.Amount = value
+		return nil
+	case float64:
+		if value != math.Trunc(value) || math.Abs(value) >= math.MaxInt64 {
+			return fmt.Errorf("amount %v is not a whole number of minor units", value)
+		}
+		money.Amount = int64(value)
+		return nil
+	case string:
+		return money.scan_text(value)
+	case []byte:
+		return money.scan_text(string(value))
+	case nil:
+		return errors.New("cannot scan NULL into money, see NullMoney")
+	}
+	return fmt.Errorf("cannot scan %T into money", src)
+}
+
+func (money *Money) scan_text(text string) error {
+	amount, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid amount %q", text)
+	}
+	money.Amount = amount
+	return nil
+}
+
+// An amount which may be NULL, such as a listing's price_low, for scanning
+// from and binding to its SQL column.  Its currency is in another column;
+// In combines the two.
+type NullMoney struct {
+	Money Money
+	Valid bool
+}
+
+// The nullable amount of the money, which is NULL if money is nil.
+func NullMoneyOf(money *Money) NullMoney {
+	if money == nil {
+		return NullMoney{}
+	}
+	return NullMoney{*money, true}
+}
+
+// The money of the amount in the currency, or nil if the amount is NULL.
+func (amount NullMoney) In(currency CurrencyEnum) *Money {
+	if !amount.Valid {
+		return nil
+	}
+	money := NewMoney(amount.Money.Amount, currency)
+	return &money
+}
+
+func (amount *NullMoney) Scan(src any) error {
+	if src == nil {
+		*amount = NullMoney{}
+		return nil
+	}
+	amount.Valid = true
+	return amount.Money.Scan(src)
+}
+
+func (amount NullMoney) Value() (driver.Value, error) {
+	if !amount.Valid {
+		return nil, nil
+	}
+	return amount.Money.Value()
+}
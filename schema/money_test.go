@@ -0,0 +1,156 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/schema/money_test.go
+
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMoney(t *testing.T) {
+	money, err := ParseMoney("1,234.5", CurrencyUSD)
+	require.NoError(t, err)
+	assert.Equal(t, Money{123450, CurrencyUSD}, money)
+	assert.Equal(t, "1234.50 USD", money.String())
+
+	money, err = ParseMoney("1500", CurrencyJPY)
+	require.NoError(t, err)
+	assert.Equal(t, Money{1500, CurrencyJPY}, money)
+	assert.Equal(t, "1500 JPY", money.String())
+
+	_, err = ParseMoney("1500.5", CurrencyJPY)
+	assert.Error(t, err)
+	_, err = ParseMoney("12.345", CurrencyEUR)
+	assert.Error(t, err)
+	_, err = ParseMoney("twelve", CurrencyEUR)
+	assert.Error(t, err)
+	_, err = ParseMoney("99999999999999999999", CurrencyEUR)
+	assert.ErrorIs(t, err, ErrMoneyOverflow)
+}
+
+func TestMoneyFormat(t *testing.T) {
+	usd := Money{123450, CurrencyUSD}
+	assert.Equal(t, "$1,234.50", usd.Format("en-US"))
+	assert.Equal(t, "1.234,50 $", usd.Format("de-DE"))
+
+	eur := Money{-5, CurrencyEUR}
+	assert.Equal(t, "-0,05 €", eur.Format("fr_FR"))
+	assert.Equal(t, "-€0.05", eur.Format("en-GB"))
+
+	assert.Equal(t, "¥1,235", Money{1235, CurrencyJPY}.Format("ja-JP"))
+	assert.Equal(t, "CHF 1'000.00", Money{100000, CurrencyCHF}.Format("de-CH"))
+	assert.Equal(t, "R$0.99", Money{99, CurrencyBRL}.Format("xx"))
+	assert.Equal(t, "-92233720368547758.08", Money{math.MinInt64, CurrencyUSD}.Decimal())
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	total, err := SumMoney(CurrencyGBP, Money{1250, CurrencyGBP}, Money{750, CurrencyGBP})
+	require.NoError(t, err)
+	assert.Equal(t, Money{2000, CurrencyGBP}, total)
+
+	_, err = total.Add(Money{100, CurrencyUSD})
+	assert.True(t, errors.Is(err, ErrCurrencyMismatch))
+	_, err = total.Compare(Money{100, CurrencyEUR})
+	assert.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	difference, err := total.Sub(Money{2500, CurrencyGBP})
+	require.NoError(t, err)
+	assert.Equal(t, int64(-500), difference.Amount)
+
+	order, err := difference.Compare(total)
+	require.NoError(t, err)
+	assert.Equal(t, -1, order)
+
+	tripled, err := total.Mul(3)
+	require.NoError(t, err)
+	assert.Equal(t, int64(6000), tripled.Amount)
+
+	// The zero currency is USD, as for a NULL price_currency.
+	sum, err := Money{1, ""}.Add(Money{2, CurrencyUSD})
+	require.NoError(t, err)
+	assert.Equal(t, Money{3, CurrencyUSD}, sum)
+
+	_, err = Money{math.MaxInt64, CurrencyUSD}.Add(Money{1, CurrencyUSD})
+	assert.ErrorIs(t, err, ErrMoneyOverflow)
+	_, err = Money{math.MinInt64, CurrencyUSD}.Mul(-1)
+	assert.ErrorIs(t, err, ErrMoneyOverflow)
+	_, err = Money{math.MaxInt64 / 2, CurrencyUSD}.Mul(3)
+	assert.ErrorIs(t, err, ErrMoneyOverflow)
+}
+
+func TestMoneyCodecs(t *testing.T) {
+	encoded, err := json.Marshal(Money{2750, CurrencyUSD})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"amount": "27.50", "currency": "USD"}`, string(encoded))
+
+	var money Money
+	require.NoError(t, json.Unmarshal([]byte(`{"amount": 27.5, "currency": "eur"}`), &money))
+	assert.Equal(t, Money{2750, CurrencyEUR}, money)
+	require.NoError(t, json.Unmarshal([]byte(`{"amount": "3000"}`), &money))
+	assert.Equal(t, Money{300000, CurrencyUSD}, money)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"amount": "1.5", "currency": "JPY"}`), &money))
+	assert.Error(t, json.Unmarshal([]byte(`{"amount": "1", "currency": "XYZ"}`), &money))
+	assert.Error(t, json.Unmarshal([]byte(`{"currency": "USD"}`), &money))
+
+	var currency CurrencyEnum
+	require.NoError(t, currency.Scan(nil))
+	assert.Equal(t, CurrencyUSD, currency)
+	require.NoError(t, currency.Scan([]byte("sek")))
+	assert.Equal(t, CurrencySEK, currency)
+	assert.Error(t, currency.Scan("XYZ"))
+
+	require.NoError(t, money.Scan(int64(1500)))
+	assert.Equal(t, Money{1500, CurrencyUSD}, money, "the currency is left as it was")
+	require.NoError(t, money.Scan(float64(2500)))
+	assert.Equal(t, int64(2500), money.Amount)
+	require.NoError(t, money.Scan([]byte("-30")))
+	assert.Equal(t, int64(-30), money.Amount)
+	assert.Error(t, money.Scan(12.5))
+	assert.Error(t, money.Scan(nil))
+	amount, err := money.Value()
+	require.NoError(t, err)
+	assert.Equal(t, int64(-30), amount)
+
+	var null NullMoney
+	require.NoError(t, null.Scan(nil))
+	assert.Nil(t, null.In(CurrencyEUR))
+	amount, err = null.Value()
+	require.NoError(t, err)
+	assert.Nil(t, amount)
+	require.NoError(t, null.Scan(int64(999)))
+	assert.Equal(t, &Money{999, CurrencyEUR}, null.In(CurrencyEUR))
+	assert.Equal(t, NullMoney{Money{999, CurrencyGBP}, true}, NullMoneyOf(null.In(CurrencyGBP)))
+	assert.Equal(t, NullMoney{}, NullMoneyOf(nil))
+
+	value, err := CurrencyEnum("").Value()
+	require.NoError(t, err)
+	assert.Equal(t, "USD", value)
+	assert.Equal(t, "kr", CurrencySEK.Symbol())
+	assert.Equal(t, 0, CurrencyJPY.Exponent())
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/schema/order.cue
+
+package cratedigdb
+
+#Order: {
+  type?: "order"
+  orderID!:  uint64
+  sellerID!: uint64
+  buyerID!:  uint64
+
+  offer_price!: #Money
+
+  date_opened?:   string // YYYY-MM-DD
+  date_closed?:   string // YYYY-MM-DD
+  last_activity?: string // YYYY-MM-DD HH:MM:SS
+  status!:        "Invoice Sent" | "Payment Pending" | "Payment Received" |
+                  "In Progress" | "Shipped" | "Merged" | "Refund Pending" |
+                  "Confirmed" | "Cancelled"
+
+  purchases?: [...#OrderPurchase]
+  trades?:    [...#OrderTrade]
+}
+
+#OrderPurchase: {
+  purchaseID!:     uint64
+  sellerID!:       uint64
+  versionID!:      uint64
+  item!:           uint
+  purchase_price!: #Money
+}
+
+#OrderTrade: {
+  tradeID!:     uint64
+  buyerID!:     uint64
+  versionID!:   uint64
+  item!:        uint
+  trade_value!: #Money
+}
+
+#OrderUpdate: {
+  type?: "order_update"
+  updateID!:    uint64
+  orderID!:     uint64
+  update_time?: string // YYYY-MM-DD HH:MM:SS
+  comment:      string | *""
+  status?:      "Invoice Sent" | "Payment Pending" | "Payment Received" |
+                "In Progress" | "Shipped" | "Merged" | "Refund Pending" |
+                "Confirmed" | "Cancelled"
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/schema/order.go
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// An order between a seller and a buyer, following the Discogs guidelines:
+// https://support.discogs.com/hc/en-us/articles/360007525494
+type Order struct {
+	ID       uint64 `json:"orderID"`
+	SellerID uint64 `json:"sellerID"`
+	BuyerID  uint64 `json:"buyerID"`
+
+	// The total price minus the trade value, reflecting the most recent value.
+	// Its currency is the currency of every purchase and trade in the order.
+	OfferPrice Money `json:"offer_price"`
+
+	DateOpened   *Date           `json:"date_opened,omitempty"`
+	DateClosed   *Date           `json:"date_closed,omitempty"`
+	LastActivity *Timestamp      `json:"last_activity,omitempty"`
+	Status       OrderStatusEnum `json:"status"`
+
+	Purchases []OrderPurchase `json:"purchases,omitempty"`
+	Trades    []OrderTrade    `json:"trades,omitempty"`
+}
+
+// A listed item (of the seller) that is being purchased as part of an order.
+type OrderPurchase struct {
+	ID        uint64 `json:"purchaseID"`
+	SellerID  uint64 `json:"sellerID"`
+	VersionID uint64 `json:"versionID"`
+	Item      uint   `json:"item"`
+	Price     Money  `json:"purchase_price"`
+}
+
+// An item (of the buyer) that is being traded as part of an order.
+type OrderTrade struct {
+	ID        uint64 `json:"tradeID"`
+	BuyerID   uint64 `json:"buyerID"`
+	VersionID uint64 `json:"versionID"`
+	Item      uint   `json:"item"`
+	Value     Money  `json:"trade_value"`
+}
+
+// An entry in the timeline of an order's activity.
+type OrderUpdate struct {
+	ID         uint64     `json:"updateID"`
+	OrderID    uint64     `json:"orderID"`
+	UpdateTime *Timestamp `json:"update_time,omitempty"`
+	Comment    string     `json:"comment"`
+	// The status the order moved into, nil if the update did not change it.
+	Status *OrderStatusEnum `json:"status,omitempty"`
+}
+
+func (order Order) Typename() string { return "order" }
+func (order Order) Key() string      { return strconv.FormatUint(order.ID, 10) }
+
+func (order Order) MarshalJSON() ([]byte, error) {
+	type order_json Order
+	return json.Marshal(order_json(order))
+}
+
+func (order Order) Validate() error {
+	v := validate(order.Typename())
+	v.required(order.SellerID, "sellerID")
+	v.check(order.BuyerID != order.SellerID, "buyerID", "cannot be the seller")
+	v.money(order.OfferPrice, "offer_price")
+	v.check(order_status_names.valid(int(order.Status)), "status", "is not a valid order status")
+	for i, purchase := range order.Purchases {
+		field := fmt.Sprintf("purchases[%d]", i)
+		v.required(purchase.VersionID, field+".versionID")
+		v.money(purchase.Price, field+".purchase_price")
+		_, err := purchase.Price.Compare(order.OfferPrice)
+		v.check(err == nil, field+".purchase_price.currency", "must be the order's currency")
+	}
+	for i, trade := range order.Trades {
+		field := fmt.Sprintf("trades[%d]", i)
+		v.required(trade.VersionID, field+".versionID")
+		v.money(trade.Value, field+".trade_value")
+		_, err := trade.Value.Compare(order.OfferPrice)
+		v.check(err == nil, field+".trade_value.currency", "must be the order's currency")
+	}
+	return v.err()
+}
+
+func NewOrderParser(schema string) JsonParser[Order] {
+	return newStrictParser[Order](schema, "#Order")
+}
+
+func (update OrderUpdate) Typename() string { return "order_update" }
+func (update OrderUpdate) Key() string      { return strconv.FormatUint(update.ID, 10) }
+
+func (update OrderUpdate) MarshalJSON() ([]byte, error) {
+	type order_update_json OrderUpdate
+	return json.Marshal(order_update_json(update))
+}
+
+func (update OrderUpdate) Validate() error {
+	v := validate(update.Typename())
+	v.required(update.OrderID, "orderID")
+	return v.err()
+}
+
+func NewOrderUpdateParser(schema string) JsonParser[OrderUpdate] {
+	return newStrictParser[OrderUpdate](schema, "#OrderUpdate")
+}
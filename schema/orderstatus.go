@@ -0,0 +1,105 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/schema/orderstatus.go
+
+package schema
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+)
+
+// The status of an order, matching the OrderStatus table and following the
+// Discogs guidelines: https://support.discogs.com/hc/en-us/articles/360007525494
+// with an additional status "Confirmed" for when the buyer received the item.
+type OrderStatusEnum uint8
+
+const (
+	StatusInvoiceSent OrderStatusEnum = iota
+	StatusPaymentPending
+	StatusPaymentReceived
+	StatusInProgress
+	StatusShipped
+	StatusMerged
+	StatusRefundPending
+	StatusConfirmed
+	StatusCancelled
+)
+
+var order_status_names = enum_names{
+	StatusInvoiceSent:     "Invoice Sent",
+	StatusPaymentPending:  "Payment Pending",
+	StatusPaymentReceived: "Payment Received",
+	StatusInProgress:      "In Progress",
+	StatusShipped:         "Shipped",
+	StatusMerged:          "Merged",
+	StatusRefundPending:   "Refund Pending",
+	StatusConfirmed:       "Confirmed",
+	StatusCancelled:       "Cancelled",
+}
+
+func OrderStatusOptions() []OrderStatusEnum {
+	options := make([]OrderStatusEnum, len(order_status_names))
+	for i := range options {
+		options[i] = OrderStatusEnum(i)
+	}
+	return options
+}
+
+func (status OrderStatusEnum) String() string {
+	return order_status_names.name(int(status))
+}
+
+func ParseOrderStatus(name string) (OrderStatusEnum, error) {
+	id, err := order_status_names.parse("order status", name)
+	return OrderStatusEnum(id), err
+}
+
+func parse_order_status(name string) (int, error) {
+	status, err := ParseOrderStatus(name)
+	return int(status), err
+}
+
+func (status OrderStatusEnum) MarshalJSON() ([]byte, error) {
+	return json.Marshal(status.String())
+}
+
+func (status *OrderStatusEnum) UnmarshalJSON(data []byte) error {
+	id, err := order_status_names.unmarshal("order status", data, parse_order_status)
+	if err != nil {
+		return err
+	}
+	*status = OrderStatusEnum(id)
+	return nil
+}
+
+func (status *OrderStatusEnum) Scan(src any) error {
+	id, err := order_status_names.scan("order status", src, parse_order_status)
+	if err != nil {
+		return err
+	}
+	*status = OrderStatusEnum(id)
+	return nil
+}
+
+func (status OrderStatusEnum) Value() (driver.Value, error) {
+	return enum_value(int(status))
+}
@@ -1,4 +1,4 @@
-// Copyright (c) 2024 Kevin Damm
+// Copyright (c) 2025 Kevin Damm
 //
 // Permission is hereby granted, free of charge, to any person obtaining a copy
 // of this software and associated documentation files (the "Software"), to deal
@@ -25,5 +25,24 @@ package cratedigdb
 #Release: {
   type?: "release"
   releaseID!: uint64
+  title!:     string
+  year?:      int
 
+  main_version!: uint64
+
+  data_quality?: #DataQuality
+
+  artists?: [...#ArtistCredit]
+  genres?:  [...string]
+  styles?:  [...string]
+  videos?:  [...#Video]
+
+  versions?: [...#ReleaseVersion]
+}
+
+#Video: {
+  url!:         string
+  title?:       string
+  description?: string
+  duration?:    int // seconds
 }
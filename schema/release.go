@@ -24,26 +24,55 @@ package schema
 
 import (
 	"encoding/json"
-	"log"
+	"strconv"
 )
 
+// A release is the abstract album, what Discogs calls a "master".  The
+// physical variations of it (pressings, editions, etc.) are ReleaseVersions.
 type Release struct {
-	ID uint64 `json:"releaseID"`
+	ID    uint64 `json:"releaseID"`
+	Title string `json:"title"`
+	Year  int    `json:"year,omitempty"`
+
+	// The versionID which best represents this release.
+	MainVersion uint64 `json:"main_version"`
+
+	DataQuality DataQualityEnum `json:"data_quality"`
+
+	Artists []ArtistCredit `json:"artists,omitempty"`
+	Genres  []string       `json:"genres,omitempty"`
+	Styles  []string       `json:"styles,omitempty"`
+	Videos  []Video        `json:"videos,omitempty"`
+
+	// Summaries of the versions (without their formats, labels, tracks, etc.).
+	Versions []ReleaseVersion `json:"versions,omitempty"`
 }
 
-func (release Release) Typename() string { return "release" }
-func (release Release) ToJson() string {
-	bytes, err := json.MarshalIndent(release, "", "  ")
-	if err != nil {
-		log.Fatal(err)
-	}
-	return string(bytes)
+// A video of (a track of) the release, usually hosted on YouTube.
+type Video struct {
+	URL         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Length of the video in seconds.
+	Duration int `json:"duration,omitempty"`
 }
 
-func NewReleaseParser(schema string) JsonParser[Release] {
+func (release Release) Typename() string { return "release" }
+func (release Release) Key() string      { return strconv.FormatUint(release.ID, 10) }
 
-	return func(json string, release *Release) error {
+func (release Release) MarshalJSON() ([]byte, error) {
+	type release_json Release
+	return json.Marshal(release_json(release))
+}
 
-		return nil
-	}
+func (release Release) Validate() error {
+	v := validate(release.Typename())
+	v.required(release.ID, "releaseID")
+	v.required(release.Title, "title")
+	v.check(data_quality_names.valid(int(release.DataQuality)), "data_quality", "is not a valid data quality")
+	return v.err()
+}
+
+func NewReleaseParser(schema string) JsonParser[Release] {
+	return newStrictParser[Release](schema, "#Release")
 }
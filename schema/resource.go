@@ -1,6 +1,85 @@
 package schema
 
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Every resource served by the API.  Marshaling returns an error rather than
+// terminating the process, Key() is the resource's identity within URLs (e.g.
+// the artistID, or the username of an account) and Validate() reports every
+// problem with the resource's fields as a *ValidationError.
 type Resource interface {
 	Typename() string
-	ToJson() string
+	Key() string
+	Validate() error
+	json.Marshaler
+}
+
+// Renders the resource as indented JSON, as used in fixtures and debugging.
+func ToJson(resource Resource) (string, error) {
+	bytes, err := json.MarshalIndent(resource, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("rendering %s %s: %w", resource.Typename(), resource.Key(), err)
+	}
+	return string(bytes), nil
+}
+
+// Describes a resource type in the registry, so that handlers can be written
+// once for every type and select the type by its Typename().
+type ResourceType struct {
+	Typename string
+	// Returns the zero value of the resource.
+	New func() Resource
+	// Parses (strictly, see newStrictParser) and validates the JSON.
+	Parse func(data string) (Resource, error)
+}
+
+var resource_types = make(map[string]ResourceType)
+
+// Adds the resource type T to the registry, with its parser.  It is an error
+// to register the same typename more than once.
+func register_resource[T Resource](parser JsonParser[T]) {
+	var zero T
+	typename := zero.Typename()
+	if _, found := resource_types[typename]; found {
+		panic(fmt.Sprintf("resource type %q is already registered", typename))
+	}
+	resource_types[typename] = ResourceType{
+		Typename: typename,
+		New:      func() Resource { return zero },
+		Parse: func(data string) (Resource, error) {
+			value, err := ParseResource(parser, data)
+			if err != nil {
+				return nil, err
+			}
+			return value, nil
+		},
+	}
+}
+
+// Parses the JSON with the given parser and validates the result.
+func ParseResource[T Resource](parser JsonParser[T], data string) (T, error) {
+	var value T
+	if err := parser(data, &value); err != nil {
+		return value, err
+	}
+	return value, value.Validate()
+}
+
+// Finds the registered resource type for the typename, e.g. "artist".
+func LookupResourceType(typename string) (ResourceType, bool) {
+	resource, found := resource_types[typename]
+	return resource, found
+}
+
+// The names of all registered resource types, in sorted order.
+func ResourceTypenames() []string {
+	names := make([]string, 0, len(resource_types))
+	for name := range resource_types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
 }
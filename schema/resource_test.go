@@ -0,0 +1,97 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/schema/resource_test.go
+
+package schema
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceRegistry(t *testing.T) {
+	names := ResourceTypenames()
+	assert.Len(t, names, len(conformanceCases()))
+	for _, name := range names {
+		resource, found := LookupResourceType(name)
+		require.True(t, found, name)
+		assert.Equal(t, name, resource.New().Typename())
+
+		// Every fixture is also a valid resource.
+		data, err := os.ReadFile(filepath.Join(fixtures_path, name+".json"))
+		require.NoError(t, err)
+		value, err := resource.Parse(string(data))
+		require.NoError(t, err, name)
+		assert.Equal(t, name, value.Typename())
+		assert.NotEmpty(t, value.Key(), name)
+
+		rendered, err := ToJson(value)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(data), rendered, name)
+	}
+
+	_, found := LookupResourceType("playlist")
+	assert.False(t, found)
+}
+
+func TestValidateFieldPaths(t *testing.T) {
+	order := Order{
+		ID:         5,
+		SellerID:   42,
+		BuyerID:    42,
+		OfferPrice: NewMoney(2750, CurrencyUSD),
+		Purchases: []OrderPurchase{
+			{ID: 1, SellerID: 42, VersionID: 1178, Item: 1, Price: NewMoney(2750, CurrencyUSD)},
+			{ID: 2, SellerID: 42, Item: 1, Price: NewMoney(-100, CurrencyEUR)},
+		},
+	}
+	err := order.Validate()
+	var invalid *ValidationError
+	require.True(t, errors.As(err, &invalid))
+	assert.Equal(t, "order", invalid.Typename)
+	assert.Equal(t, []FieldError{
+		{"buyerID", "cannot be the seller"},
+		{"purchases[1].versionID", "is required"},
+		{"purchases[1].purchase_price.amount", "must not be negative"},
+		{"purchases[1].purchase_price.currency", "must be the order's currency"},
+	}, invalid.Fields)
+
+	low, high := NewMoney(3000, CurrencyUSD), NewMoney(2500, CurrencyUSD)
+	listing := Listing{UserID: 42, VersionID: 1178, Item: 1, PriceLow: &low, PriceHigh: &high}
+	assert.EqualError(t, listing.Validate(), "invalid listing: price_high: must not be less than price_low")
+	assert.Equal(t, "42-1178-1", listing.Key())
+
+	vinyl := Vinyl{UserID: 42, VersionID: 1178, Item: 1, MediaGrade: GradeGeneric}
+	assert.EqualError(t, vinyl.Validate(), "invalid vinyl: media_grade: is not a valid media grade")
+
+	crate := Crate{ID: 3, UserID: 42, ParentID: 3, Name: "Deep House", Slug: "Deep House"}
+	assert.Error(t, crate.Validate())
+	crate.ParentID, crate.Slug = 1, "deep-house"
+	assert.NoError(t, crate.Validate())
+
+	_, err = ParseResource(tagParser, `{"tagID": 9, "userID": 42, "name": " "}`)
+	assert.EqualError(t, err, "invalid tag: name: is required")
+}
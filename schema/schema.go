@@ -24,8 +24,12 @@ package schema
 
 import (
 	"embed"
+	"encoding/json"
+	"fmt"
 	"io"
 	"log"
+	"regexp"
+	"strings"
 )
 
 //go:embed *.cue
@@ -33,21 +37,48 @@ var cueSchema embed.FS
 
 type JsonParser[T Resource] func(json string, value *T) error
 
+var accountParser JsonParser[UserAccount]
 var artistParser JsonParser[Artist]
+var crateParser JsonParser[Crate]
+var imageParser JsonParser[Image]
 var labelParser JsonParser[Label]
 var listingParser JsonParser[Listing]
+var orderParser JsonParser[Order]
+var orderUpdateParser JsonParser[OrderUpdate]
 var releaseParser JsonParser[Release]
+var tagParser JsonParser[Tag]
+var trackParser JsonParser[Track]
 var versionParser JsonParser[ReleaseVersion]
 var vinylParser JsonParser[Vinyl]
 
 func init() {
+	accountParser = NewUserAccountParser(must_read_cue("account.cue"))
 	artistParser = NewArtistParser(must_read_cue("artist.cue"))
+	crateParser = NewCrateParser(must_read_cue("crate.cue"))
+	imageParser = NewImageParser(must_read_cue("image.cue"))
 	labelParser = NewLabelParser(must_read_cue("label.cue"))
 	listingParser = NewListingParser(must_read_cue("listing.cue"))
+	orderParser = NewOrderParser(must_read_cue("order.cue"))
+	orderUpdateParser = NewOrderUpdateParser(must_read_cue("order.cue"))
 	releaseParser = NewReleaseParser(must_read_cue("release.cue"))
+	tagParser = NewTagParser(must_read_cue("tag.cue"))
+	trackParser = NewTrackParser(must_read_cue("track.cue"))
 	versionParser = NewReleaseVersionParser(must_read_cue("version.cue"))
 	vinylParser = NewVinylParser(must_read_cue("vinyl.cue"))
 
+	register_resource(accountParser)
+	register_resource(artistParser)
+	register_resource(crateParser)
+	register_resource(imageParser)
+	register_resource(labelParser)
+	register_resource(listingParser)
+	register_resource(orderParser)
+	register_resource(orderUpdateParser)
+	register_resource(releaseParser)
+	register_resource(tagParser)
+	register_resource(trackParser)
+	register_resource(versionParser)
+	register_resource(vinylParser)
 }
 
 func must_read_cue(path string) string {
@@ -61,3 +92,47 @@ func must_read_cue(path string) string {
 	}
 	return string(bytes)
 }
+
+// Returns a parser which decodes JSON into a value of type T, rejecting any
+// fields that are not part of the type and any objects missing a field that
+// the cue definition marks as required (i.e., `name!: type`).
+func newStrictParser[T Resource](schema string, definition string) JsonParser[T] {
+	required := cue_required_fields(schema, definition)
+	return func(data string, value *T) error {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(data), &fields); err != nil {
+			return err
+		}
+		for _, name := range required {
+			if _, found := fields[name]; !found {
+				return fmt.Errorf("%s is missing required field %q", definition, name)
+			}
+		}
+
+		decoder := json.NewDecoder(strings.NewReader(data))
+		decoder.DisallowUnknownFields()
+		return decoder.Decode(value)
+	}
+}
+
+var cue_required_field = regexp.MustCompile(`^\s*"?(\w+)"?!:`)
+
+// Finds the names of required fields in the top level of a cue definition.
+// This is not a general cue parser, it assumes the formatting used in ./*.cue
+func cue_required_fields(schema string, definition string) []string {
+	required := make([]string, 0)
+	depth := 0
+	for _, line := range strings.Split(schema, "\n") {
+		if depth == 0 {
+			if strings.HasPrefix(line, definition+":") {
+				depth = strings.Count(line, "{") - strings.Count(line, "}")
+			}
+			continue
+		}
+		if match := cue_required_field.FindStringSubmatch(line); depth == 1 && match != nil {
+			required = append(required, match[1])
+		}
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+	}
+	return required
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/schema/tag.go
+
+package schema
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// A tag is a user-defined label for organizing a collection with a virtual
+// metaphor, where (unlike crates) an item may have more than one tag.
+type Tag struct {
+	ID     uint64 `json:"tagID"`
+	UserID uint64 `json:"userID"`
+	Name   string `json:"name"`
+}
+
+func (tag Tag) Typename() string { return "tag" }
+func (tag Tag) Key() string      { return strconv.FormatUint(tag.ID, 10) }
+
+func (tag Tag) MarshalJSON() ([]byte, error) {
+	type tag_json Tag
+	return json.Marshal(tag_json(tag))
+}
+
+func (tag Tag) Validate() error {
+	v := validate(tag.Typename())
+	v.required(tag.UserID, "userID")
+	v.required(tag.Name, "name")
+	return v.err()
+}
+
+func NewTagParser(schema string) JsonParser[Tag] {
+	return newStrictParser[Tag](schema, "#Tag")
+}
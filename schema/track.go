@@ -0,0 +1,61 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/schema/track.go
+
+package schema
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// A track on a release version's tracklist.
+type Track struct {
+	ID        uint64 `json:"trackID"`
+	VersionID uint64 `json:"versionID"`
+	Number    int    `json:"track_number"`
+
+	Title    string `json:"title"`
+	Duration string `json:"duration,omitempty"`
+
+	// Credits for this track, in addition to those of the release version.
+	Artists      []ArtistCredit `json:"artists,omitempty"`
+	ExtraArtists []ArtistCredit `json:"featured,omitempty"`
+}
+
+func (track Track) Typename() string { return "track" }
+func (track Track) Key() string      { return strconv.FormatUint(track.ID, 10) }
+
+func (track Track) MarshalJSON() ([]byte, error) {
+	type track_json Track
+	return json.Marshal(track_json(track))
+}
+
+func (track Track) Validate() error {
+	v := validate(track.Typename())
+	v.required(track.VersionID, "versionID")
+	v.required(track.Title, "title")
+	return v.err()
+}
+
+func NewTrackParser(schema string) JsonParser[Track] {
+	return newStrictParser[Track](schema, "#Track")
+}
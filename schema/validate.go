@@ -0,0 +1,101 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/schema/validate.go
+
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// A problem with one field of a resource.  The field is a path of JSON names
+// from the resource's top level, e.g. "purchases[0].purchase_price".
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (err FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", err.Field, err.Message)
+}
+
+// Returned by Validate() with every problem found in the resource.
+type ValidationError struct {
+	Typename string
+	Fields   []FieldError
+}
+
+func (err *ValidationError) Error() string {
+	problems := make([]string, len(err.Fields))
+	for i, field := range err.Fields {
+		problems[i] = field.Error()
+	}
+	return fmt.Sprintf("invalid %s: %s", err.Typename, strings.Join(problems, "; "))
+}
+
+// Collects field errors while checking a resource, see Validate() methods.
+type validation struct {
+	typename string
+	fields   []FieldError
+}
+
+func validate(typename string) *validation {
+	return &validation{typename: typename}
+}
+
+// Adds a field error (with the message formatted from args) if ok is false.
+func (v *validation) check(ok bool, field string, message string, args ...any) {
+	if !ok {
+		v.fields = append(v.fields, FieldError{field, fmt.Sprintf(message, args...)})
+	}
+}
+
+func (v *validation) required(value any, field string) {
+	switch value := value.(type) {
+	case uint64:
+		v.check(value != 0, field, "is required")
+	case uint:
+		v.check(value != 0, field, "is required")
+	case string:
+		v.check(strings.TrimSpace(value) != "", field, "is required")
+	}
+}
+
+func (v *validation) money(money Money, field string) {
+	v.check(money.Currency.IsValid(), field+".currency",
+		"unrecognized currency %q", string(money.Currency))
+	v.check(money.Amount >= 0, field+".amount", "must not be negative")
+}
+
+func (v *validation) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &ValidationError{v.typename, v.fields}
+}
+
+// Slugs and usernames are used in URL paths without escaping.
+var url_segment = regexp.MustCompile(`^[a-z0-9]+(?:[-_][a-z0-9]+)*$`)
+
+// Usernames may also be mixed case and contain '.', as on Discogs.
+var username_pattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
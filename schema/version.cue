@@ -1,4 +1,4 @@
-// Copyright (c) 2024 Kevin Damm
+// Copyright (c) 2025 Kevin Damm
 //
 // Permission is hereby granted, free of charge, to any person obtaining a copy
 // of this software and associated documentation files (the "Software"), to deal
@@ -25,4 +25,42 @@ package cratedigdb
 #ReleaseVersion: {
   type?: "version"
   versionID!: uint64
+  releaseID!: uint64
+  title!:     string
+  year?:      int
+
+  country?: string
+  notes?:   string
+
+  data_quality?: #DataQuality
+
+  artists?:   [...#ArtistCredit]
+  featured?:  [...#ArtistCredit]
+  formats?:   [...#MediaFormat]
+  labels?:    [...#VersionLabel]
+  genres?:    [...string]
+  styles?:    [...string]
+  tracklist?: [...#Track]
+  images?:    [...#Image]
+
+  identifiers?: [...#VersionIdentifier]
+}
+
+#MediaFormat: {
+  quantity?:    int
+  format!:      string
+  description?: string
+  notes?:       string
+}
+
+#VersionIdentifier: {
+  type!:        string
+  value!:       string
+  description?: string
+}
+
+#VersionLabel: {
+  labelID!:    uint64
+  name!:       string
+  catalog_id?: string
 }
@@ -24,26 +24,82 @@ package schema
 
 import (
 	"encoding/json"
-	"log"
+	"fmt"
+	"strconv"
 )
 
+// A specific variation, edition or pressing of a release.
 type ReleaseVersion struct {
-	ID int64 `json:"versionID"`
+	ID        uint64 `json:"versionID"`
+	ReleaseID uint64 `json:"releaseID"`
+	Title     string `json:"title"`
+	Year      int    `json:"year,omitempty"`
+
+	// The country this version was released in.
+	Country string `json:"country,omitempty"`
+	Notes   string `json:"notes,omitempty"`
+
+	DataQuality DataQualityEnum `json:"data_quality"`
+
+	Artists []ArtistCredit `json:"artists,omitempty"`
+	// Additional credits (producers, engineers, etc.), what Discogs calls the
+	// extraartists and the tsmodels call featured.
+	ExtraArtists []ArtistCredit `json:"featured,omitempty"`
+
+	Formats   []MediaFormat  `json:"formats,omitempty"`
+	Labels    []VersionLabel `json:"labels,omitempty"`
+	Genres    []string       `json:"genres,omitempty"`
+	Styles    []string       `json:"styles,omitempty"`
+	Tracklist []Track        `json:"tracklist,omitempty"`
+	Images    []Image        `json:"images,omitempty"`
+
+	Identifiers []VersionIdentifier `json:"identifiers,omitempty"`
 }
 
-func (version ReleaseVersion) Typename() string { return "version" }
-func (version ReleaseVersion) ToJson() string {
-	bytes, err := json.MarshalIndent(version, "", "  ")
-	if err != nil {
-		log.Fatal(err)
-	}
-	return string(bytes)
+// The media format of a release version, e.g. {2, "Vinyl", "LP, Album"}.
+type MediaFormat struct {
+	Quantity    int             `json:"quantity,omitempty"`
+	Format      MediaFormatEnum `json:"format"`
+	Description string          `json:"description,omitempty"`
+	Notes       string          `json:"notes,omitempty"`
 }
 
-func NewReleaseVersionParser(schema string) JsonParser[ReleaseVersion] {
+// A barcode, matrix / runout etching or other identifying mark of a release
+// version, e.g. {"Barcode", "5 012093 201129", "Text"}.
+type VersionIdentifier struct {
+	Type        string `json:"type"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+}
 
-	return func(json string, version *ReleaseVersion) error {
+// The label (and the label's catalog number) that produced a release version.
+// The name may differ between versions while still being the same label.
+type VersionLabel struct {
+	LabelID   uint64 `json:"labelID"`
+	Name      string `json:"name"`
+	CatalogID string `json:"catalog_id,omitempty"`
+}
+
+func (version ReleaseVersion) Typename() string { return "version" }
+func (version ReleaseVersion) Key() string      { return strconv.FormatUint(version.ID, 10) }
+
+func (version ReleaseVersion) MarshalJSON() ([]byte, error) {
+	type release_version_json ReleaseVersion
+	return json.Marshal(release_version_json(version))
+}
 
-		return nil
+func (version ReleaseVersion) Validate() error {
+	v := validate(version.Typename())
+	v.required(version.ID, "versionID")
+	v.required(version.Title, "title")
+	v.check(data_quality_names.valid(int(version.DataQuality)), "data_quality", "is not a valid data quality")
+	for i, format := range version.Formats {
+		v.check(format.Format != 0 && media_format_names.valid(int(format.Format)),
+			fmt.Sprintf("formats[%d].format", i), "is not a valid media format")
 	}
+	return v.err()
+}
+
+func NewReleaseVersionParser(schema string) JsonParser[ReleaseVersion] {
+	return newStrictParser[ReleaseVersion](schema, "#ReleaseVersion")
 }
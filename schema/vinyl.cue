@@ -1,4 +1,4 @@
-// Copyright (c) 2024 Kevin Damm
+// Copyright (c) 2025 Kevin Damm
 //
 // Permission is hereby granted, free of charge, to any person obtaining a copy
 // of this software and associated documentation files (the "Software"), to deal
@@ -23,6 +23,26 @@
 package cratedigdb
 
 #Vinyl: {
-  type?: "version"
+  type?: "vinyl"
+  userID!:    uint64
   versionID!: uint64
+  item!:      uint
+
+  releaseID!: uint64
+  crateID?:   uint64
+
+  date_added?:  string // YYYY-MM-DD
+  date_graded?: string // YYYY-MM-DD
+  date_sold?:   string // YYYY-MM-DD
+  date_traded?: string // YYYY-MM-DD
+  date_archived?: string // YYYY-MM-DD
+
+  media_grade?:  #Grading
+  sleeve_grade?: #Grading
+
+  tags?: [...string]
+  notes?: string
 }
+
+#Grading: "" | "M" | "NM" | "VG+" | "VG" | "G+" | "G" | "F" | "P" |
+  "Generic" | "No Cover" // sleeve only
@@ -24,39 +24,58 @@ package schema
 
 import (
 	"encoding/json"
-	"log"
-	"time"
 )
 
+// A single copy of a release version in a user's collection.  Each copy is
+// tracked individually for its own grading and purchase/sale history.
 type Vinyl struct {
-	UserID    int64 `json:"vinylID"`
-	VersionID int64 `json:"versionID"`
-	Item      int   `json:"item"`
+	UserID    uint64 `json:"userID"`
+	VersionID uint64 `json:"versionID"`
+	Item      uint   `json:"item"`
 
-	ReleaseID int64 `json:"releaseID"`
-	CrateID   int64 `json:"crateID"`
+	ReleaseID uint64 `json:"releaseID"`
+	// A zero value indicates the item is unsorted (not in any crate).
+	CrateID uint64 `json:"crateID,omitempty"`
 
-	DateAdded  *time.Time
-	DateGraded *time.Time
-	DateSold   *time.Time
-	DateTraded *time.Time
+	DateAdded  *Date `json:"date_added,omitempty"`
+	DateGraded *Date `json:"date_graded,omitempty"`
+	DateSold   *Date `json:"date_sold,omitempty"`
+	DateTraded *Date `json:"date_traded,omitempty"`
+	// Set when the copy is removed but kept for its history in the ledger.
+	DateArchived *Date `json:"date_archived,omitempty"`
 
-	MediaGrade Grading
+	MediaGrade  GradingEnum `json:"media_grade"`
+	SleeveGrade GradingEnum `json:"sleeve_grade"`
+
+	Tags  []string `json:"tags,omitempty"`
+	Notes string   `json:"notes,omitempty"`
+
+	// What was paid for the copy, if known.
+	PurchasePrice *Money `json:"purchase_price,omitempty"`
 }
 
 func (vinyl Vinyl) Typename() string { return "vinyl" }
-func (vinyl Vinyl) ToJson() string {
-	bytes, err := json.MarshalIndent(vinyl, "", "  ")
-	if err != nil {
-		log.Fatal(err)
+func (vinyl Vinyl) Key() string      { return vinyl.VinylKey().String() }
+
+func (vinyl Vinyl) MarshalJSON() ([]byte, error) {
+	type vinyl_json Vinyl
+	return json.Marshal(vinyl_json(vinyl))
+}
+
+func (vinyl Vinyl) Validate() error {
+	v := validate(vinyl.Typename())
+	v.required(vinyl.UserID, "userID")
+	v.required(vinyl.VersionID, "versionID")
+	v.required(vinyl.Item, "item")
+	v.check(grading_codes.valid(int(vinyl.MediaGrade)) && !vinyl.MediaGrade.IsSleeveOnly(),
+		"media_grade", "is not a valid media grade")
+	v.check(grading_codes.valid(int(vinyl.SleeveGrade)), "sleeve_grade", "is not a valid grade")
+	if vinyl.PurchasePrice != nil {
+		v.money(*vinyl.PurchasePrice, "purchase_price")
 	}
-	return string(bytes)
+	return v.err()
 }
 
 func NewVinylParser(schema string) JsonParser[Vinyl] {
-
-	return func(json string, vinyl *Vinyl) error {
-
-		return nil
-	}
+	return newStrictParser[Vinyl](schema, "#Vinyl")
 }
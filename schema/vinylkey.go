@@ -0,0 +1,109 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/schema/vinylkey.go
+
+package schema
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Identifies a physical copy: the item-th copy of a release version owned by
+// a user.  This is the composite key of VinylItems and of the Listings,
+// OrderPurchases and OrderTrades that refer to a copy.
+type VinylKey struct {
+	UserID    uint64
+	VersionID uint64
+	Item      uint
+}
+
+// The compact form, "userID-versionID-item" (e.g. "42-1178-2"), which needs
+// no escaping in URLs, form values or HTML attributes.
+func (key VinylKey) String() string {
+	return fmt.Sprintf("%d-%d-%d", key.UserID, key.VersionID, key.Item)
+}
+
+func ParseVinylKey(text string) (VinylKey, error) {
+	parts := strings.Split(strings.TrimSpace(text), "-")
+	if len(parts) != 3 {
+		return VinylKey{}, fmt.Errorf("invalid vinyl key %q, expected userID-versionID-item", text)
+	}
+	userID, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil || userID == 0 {
+		return VinylKey{}, fmt.Errorf("invalid userID in vinyl key %q", text)
+	}
+	return VinylKeyFromPath(userID, parts[1], parts[2])
+}
+
+func (key VinylKey) MarshalText() ([]byte, error) {
+	return []byte(key.String()), nil
+}
+
+func (key *VinylKey) UnmarshalText(text []byte) error {
+	parsed, err := ParseVinylKey(string(text))
+	if err != nil {
+		return err
+	}
+	*key = parsed
+	return nil
+}
+
+// The route for every endpoint that addresses a single copy.  Copies are
+// addressed by the owner's username, which the handler resolves to a userID.
+const VinylItemRoute = "/vinyl/:username/:versionID/:item"
+
+// The path of this copy's VinylItemRoute, for the owner's username.
+func (key VinylKey) Path(username string) string {
+	return fmt.Sprintf("/vinyl/%s/%d/%d", url.PathEscape(username), key.VersionID, key.Item)
+}
+
+// Builds the key from the route parameters of VinylItemRoute, once the
+// username has been resolved to userID.
+func VinylKeyFromPath(userID uint64, versionID string, item string) (VinylKey, error) {
+	version, err := strconv.ParseUint(versionID, 10, 64)
+	if err != nil || version == 0 {
+		return VinylKey{}, fmt.Errorf("invalid versionID %q", versionID)
+	}
+	number, err := strconv.ParseUint(item, 10, 32)
+	if err != nil || number == 0 {
+		return VinylKey{}, fmt.Errorf("invalid item number %q", item)
+	}
+	return VinylKey{userID, version, uint(number)}, nil
+}
+
+func (vinyl Vinyl) VinylKey() VinylKey {
+	return VinylKey{vinyl.UserID, vinyl.VersionID, vinyl.Item}
+}
+
+func (listing Listing) VinylKey() VinylKey {
+	return VinylKey{listing.UserID, listing.VersionID, listing.Item}
+}
+
+func (purchase OrderPurchase) VinylKey() VinylKey {
+	return VinylKey{purchase.SellerID, purchase.VersionID, purchase.Item}
+}
+
+func (trade OrderTrade) VinylKey() VinylKey {
+	return VinylKey{trade.BuyerID, trade.VersionID, trade.Item}
+}
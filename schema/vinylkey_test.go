@@ -0,0 +1,58 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/schema/vinylkey_test.go
+
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVinylKeyForms(t *testing.T) {
+	key := VinylKey{UserID: 42, VersionID: 1178, Item: 2}
+	assert.Equal(t, "42-1178-2", key.String())
+	assert.Equal(t, "/vinyl/dj%20kevin/1178/2", key.Path("dj kevin"))
+
+	parsed, err := ParseVinylKey("42-1178-2")
+	require.NoError(t, err)
+	assert.Equal(t, key, parsed)
+	for _, invalid := range []string{"42-1178", "0-1178-2", "42-1178-0", "42-x-1", "42-1178-2-1"} {
+		_, err := ParseVinylKey(invalid)
+		assert.Error(t, err, invalid)
+	}
+
+	fromPath, err := VinylKeyFromPath(42, "1178", "2")
+	require.NoError(t, err)
+	assert.Equal(t, key, fromPath)
+
+	encoded, err := json.Marshal(map[string]VinylKey{"copy": key})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"copy": "42-1178-2"}`, string(encoded))
+
+	vinyl := Vinyl{UserID: 42, VersionID: 1178, Item: 2}
+	assert.Equal(t, key, vinyl.VinylKey())
+	assert.Equal(t, key.String(), vinyl.Key())
+	assert.Equal(t, key, OrderPurchase{SellerID: 42, VersionID: 1178, Item: 2}.VinylKey())
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/sql/basedata.go
+
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/kevindamm/cratedigdb/schema"
+)
+
+// Checks that the enumeration tables of the database contain exactly the
+// values that are compiled into the schema package.  If these differ then the
+// IDs stored in other tables would be misinterpreted, so the server should
+// refuse to start rather than serve (or write) incorrect values.
+func VerifyBaseData(ctx context.Context, db *sql.DB) error {
+	var expected []baseRow
+	for _, quality := range schema.DataQualityOptions() {
+		expected = append(expected, baseRow{"DataQualityEnum", int64(quality), quality.String()})
+	}
+	for _, format := range schema.MediaFormatOptions() {
+		expected = append(expected, baseRow{"MediaFormatEnum", int64(format),
+			fmt.Sprintf("%s (%s)", format.String(), format.Abbr())})
+	}
+	for _, status := range schema.OrderStatusOptions() {
+		expected = append(expected, baseRow{"OrderStatus", int64(status), status.String()})
+	}
+	for _, grade := range schema.GradingOptions() {
+		grading := grade.Grading()
+		expected = append(expected, baseRow{"Grading", grading.GradeID,
+			fmt.Sprintf("%s %s %d", grading.Grade, grading.Name, grading.Quality)})
+	}
+
+	actual := make(map[baseRow]bool)
+	for table, query := range baseDataQueries {
+		rows, err := db.QueryContext(ctx, query)
+		if err != nil {
+			return fmt.Errorf("reading base data of %s: %w", table, err)
+		}
+		for rows.Next() {
+			row := baseRow{Table: table}
+			if err := rows.Scan(&row.ID, &row.Text); err != nil {
+				rows.Close()
+				return err
+			}
+			actual[row] = true
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+
+	var errs []error
+	for _, row := range expected {
+		if !actual[row] {
+			errs = append(errs, fmt.Errorf("%s is missing %d %q", row.Table, row.ID, row.Text))
+		}
+		delete(actual, row)
+	}
+	for row := range actual {
+		errs = append(errs, fmt.Errorf("%s has unexpected %d %q", row.Table, row.ID, row.Text))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("base data does not match schema: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// A row of an enumeration table, with its non-ID columns joined into Text.
+type baseRow struct {
+	Table string
+	ID    int64
+	Text  string
+}
+
+var baseDataQueries = map[string]string{
+	"DataQualityEnum": `SELECT dqID, quality FROM DataQualityEnum`,
+	"MediaFormatEnum": `SELECT formatID, format || ' (' || format_abbr || ')'
+                        FROM MediaFormatEnum`,
+	"OrderStatus": `SELECT statusID, status FROM OrderStatus`,
+	"Grading": `SELECT gradeID, grade || ' ' || name || ' ' || quality
+                FROM Grading`,
+}
@@ -127,6 +127,12 @@ CREATE TABLE IF NOT EXISTS "MediaFormatDescriptionEnum" (
       NOT NULL         DEFAULT ""
 );
 
+-- A description may appear more than once, with notes on its use for each
+-- format (e.g. NTSC for CDs, for DVDs, ...), but never twice with the same.
+CREATE UNIQUE INDEX IF NOT EXISTS "MediaFormatDescription__Unique"
+  ON MediaFormatDescriptionEnum (fmt_desc, comments)
+  ;
+
 -- Enumeration of genres, attributed to releases.
 -- The Primary Key is arbitrary, it matches insertion order.
 CREATE TABLE IF NOT EXISTS "GenreEnum" (
@@ -515,6 +521,7 @@ CREATE INDEX IF NOT EXISTS "Release__Style"
 --       |
 --       |--------[ReleaseVersion_Genres] +index[Genre__ReleaseVersion]
 --       |-------[ReleaseVersion_Formats] +index[Format__ReleaseVersion]
+--       |---[ReleaseVersion_Identifiers] +index[Identifier__ReleaseVersion]
 --       \------[ReleaseVersion_CoverArt] +index[unique(releaseID, imageID)]
 --
 
@@ -574,7 +581,7 @@ CREATE INDEX IF NOT EXISTS "ReleaseVersion__Artist"
 CREATE TABLE IF NOT EXISTS "ReleaseVersion_Labels" (
     "versionID"   INTEGER
       NOT NULL
-      REFERENCES    ReleasesVersions (versionID)
+      REFERENCES    ReleaseVersions (versionID)
       ON DELETE     CASCADE
   , "labelID"     INTEGER
       NOT NULL
@@ -590,12 +597,15 @@ CREATE INDEX IF NOT EXISTS "Label__ReleaseVersion"
   ON ReleaseVersion_Labels (versionID);
 CREATE INDEX IF NOT EXISTS "ReleaseVersion__Label"
   ON ReleaseVersion_Labels (labelID);
+-- For finding versions by catalog number, ignoring case, spaces and dashes.
+CREATE INDEX IF NOT EXISTS "ReleaseVersion__CatalogNumber"
+  ON ReleaseVersion_Labels (REPLACE(REPLACE(UPPER(catalog_id), ' ', ''), '-', ''));
 
 
 CREATE TABLE IF NOT EXISTS "ReleaseVersion_Genres" (
     "versionID"     INTEGER
       NOT NULL
-      REFERENCES  ReleasesVersions (versionID)
+      REFERENCES  ReleaseVersions (versionID)
       ON DELETE   CASCADE
   , "genreID"       INTEGER
       NOT NULL
@@ -614,11 +624,11 @@ CREATE INDEX IF NOT EXISTS "Genre__ReleaseVersion"
 CREATE TABLE IF NOT EXISTS "ReleaseVersion_Formats" (
     "versionID"    INTEGER
       NOT NULL
-      REFERENCES     ReleasesVersions (versionID)
+      REFERENCES     ReleaseVersions (versionID)
       ON DELETE      CASCADE
   , "formatID"     INTEGER
       NOT NULL
-      REFERENCES     Formats (formatID)
+      REFERENCES     MediaFormatEnum (formatID)
 
   , "quantity"     INTEGER
   , "notes"        TEXT
@@ -631,6 +641,28 @@ CREATE INDEX IF NOT EXISTS "Format__ReleaseVersion"
   ON ReleaseVersion_Formats (versionID);
 
 
+-- Barcodes, matrix / runout etchings and other identifying marks of a version,
+-- as Discogs lists them (the type is e.g. "Barcode" or "Matrix / Runout").
+CREATE TABLE IF NOT EXISTS "ReleaseVersion_Identifiers" (
+    "versionID"    INTEGER
+      NOT NULL
+      REFERENCES     ReleaseVersions (versionID)
+      ON DELETE      CASCADE
+  , "type"         TEXT
+      NOT NULL       CHECK (type <> "")
+  , "value"        TEXT
+      NOT NULL
+  , "description"  TEXT
+);
+
+CREATE INDEX IF NOT EXISTS "Identifier__ReleaseVersion"
+  ON ReleaseVersion_Identifiers (versionID);
+-- For finding versions by barcode, ignoring spaces and dashes.
+CREATE INDEX IF NOT EXISTS "ReleaseVersion__Barcode"
+  ON ReleaseVersion_Identifiers (REPLACE(REPLACE(value, ' ', ''), '-', ''))
+  WHERE type = 'Barcode';
+
+
 -- Each release version can have a few images of pre-defined views:
 -- Any or all of {front_sleeve, back_sleeve, media_A, media_B}.
 -- Some records could easily have two or three times this many but
@@ -644,11 +676,11 @@ CREATE TABLE IF NOT EXISTS "ReleaseVersion_CoverArt" (
   , "front_sleeve"  INTEGER
       NOT NULL
       CHECK           (front_sleeve <> 0)
-      REFERENCES      Images (imageID)
+      REFERENCES      ImageData (imageID)
 
   , "back_sleeve"   INTEGER
       NOT NULL        DEFAULT 0
-      REFERENCES      Images (imageID)
+      REFERENCES      ImageData (imageID)
 
   , PRIMARY KEY ("versionID", "front_sleeve")
 ) WITHOUT ROWID;
@@ -671,7 +703,7 @@ CREATE TABLE IF NOT EXISTS "ReleaseVersion_MediaArt" (
     "versionID"       INTEGER
       NOT NULL          CHECK (versionID <> 0)
   , "media_img"       INTEGER
-      REFERENCES      Images (imageID)
+      REFERENCES      ImageData (imageID)
   , PRIMARY KEY ("versionID", "media_img")
 ) WITHOUT ROWID;
 
@@ -762,3 +794,18 @@ CREATE INDEX IF NOT EXISTS "Style__Track"
 CREATE INDEX IF NOT EXISTS "Track__Style"
   ON Track_Styles (styleID)
   ;
+
+--
+-- GENERATIONS
+--
+
+-- Each import of (or change to) the catalog begins a new generation; the
+-- latest one's date_changed is the Last-Modified time of catalog resources.
+CREATE TABLE IF NOT EXISTS "CatalogGenerations" (
+    "generation"    INTEGER
+      PRIMARY KEY
+  , "date_changed"  TEXT  -- YYYY-MM-DD HH:MM:SS, in UTC
+      NOT NULL        DEFAULT CURRENT_TIMESTAMP
+  , "source"        TEXT  -- e.g. "discogs_20250101" or "api"
+      NOT NULL
+);
@@ -68,6 +68,12 @@ CREATE TABLE IF NOT EXISTS "UserAccounts" (
 
   , "date_banned" TEXT  -- YYYY-MM-DD
       -- A NULL date_banned value implies the user is not banned.
+
+  , "password_hash" TEXT  -- bcrypt
+      -- A NULL password_hash means the user cannot log in with a password.
+  , "is_curator"  BOOLEAN
+      NOT NULL  DEFAULT FALSE
+      -- Only curators may edit the (shared) catalog of artists and releases.
 );
 
 -- Lookup of (non-banned) accounts by their username.
@@ -94,14 +100,34 @@ CREATE UNIQUE INDEX IF NOT EXISTS  "Avatar__User"
   ON User_Avatars (userID)
   ;
 
--- Login tokens
+-- Login sessions and API tokens.  Only a hash of each token is kept (the
+-- token itself is known only to its holder), with the scopes of what it may
+-- change.  Sessions have every scope (but catalog only for curators); API
+-- tokens have those they were granted.
 CREATE TABLE IF NOT EXISTS "User_Tokens" (
-    "userID"      INTEGER
+    "tokenID"     INTEGER PRIMARY KEY
+  , "userID"      INTEGER
       NOT NULL
       REFERENCES    UserAccounts (userID)
       ON DELETE     CASCADE
+  , "token_hash"  BLOB  -- SHA-256
+      UNIQUE
+      NOT NULL
+  , "kind"        TEXT
+      NOT NULL
+      CHECK (kind IN ("session", "api"))
+  , "scopes"      TEXT  -- space separated, e.g. "collection ledger"
+      NOT NULL  DEFAULT ""
+  , "label"       TEXT
+      NOT NULL  DEFAULT ""
+
+  , "date_created"  TEXT  -- YYYY-MM-DD HH:MM:SS
+      NOT NULL  DEFAULT CURRENT_TIMESTAMP
+  , "date_expires"  TEXT  -- YYYY-MM-DD HH:MM:SS
+      NOT NULL
+  , "last_used"     TEXT  -- YYYY-MM-DD HH:MM:SS
 );
 
-CREATE UNIQUE INDEX IF NOT EXISTS "Token__User"
+CREATE INDEX IF NOT EXISTS "Token__User"
   ON User_Tokens (userID)
   ;
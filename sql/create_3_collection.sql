@@ -147,6 +147,7 @@ CREATE TABLE IF NOT EXISTS "VinylItems" (
   , "date_graded"   TEXT  -- if NULL, this item has not been graded
   , "date_sold"     TEXT  -- if NULL, this item has not been sold
   , "date_traded"   TEXT  -- if NULL, this item has not been traded
+  , "date_archived" TEXT  -- if not NULL, removed but kept for the ledger
 
   , "media_grade"   INTEGER
       NOT NULL
@@ -157,6 +158,10 @@ CREATE TABLE IF NOT EXISTS "VinylItems" (
   , "notes"         TEXT
       NOT NULL    DEFAULT ""
 
+  -- What was paid for the copy (if known), in minor units of the currency.
+  , "purchase_price"    INTEGER
+  , "purchase_currency" TEXT  -- discogs currency abbreviation
+
   , PRIMARY KEY ("userID", "versionID", "item")
 ) WITHOUT ROWID;
 
@@ -192,7 +197,8 @@ CREATE INDEX IF NOT EXISTS "VinylOwned__Version"
 --          \---N..1---| VinylTagging |---1..1---[(userID, releaseID)]
 --                     [--------------]
 --
--- Registry of tag names associated with each user and their records.
+-- Registry of tag names associated with each user and their records; names are
+-- unique (ignoring case) for each user.
 --
 -- Many-to-many relation for annotating vinyl with tags, with visibility
 -- restricted to the user's own.
@@ -202,10 +208,11 @@ CREATE TABLE IF NOT EXISTS "TagNames" (
       PRIMARY KEY
   , "userID"   INTEGER
       NOT NULL
-      REFERENCES Usernames (userID)
+      REFERENCES UserAccounts (userID)
       CHECK (userID <> 0)
 
-  , "name"  TEXT UNIQUE NOT NULL
+  , "name"  TEXT NOT NULL COLLATE NOCASE
+  , UNIQUE ("userID", "name")
 );
 
 CREATE TABLE IF NOT EXISTS "VinylTagging" (
@@ -220,5 +227,5 @@ CREATE TABLE IF NOT EXISTS "VinylTagging" (
       REFERENCES   TagNames (tagID)
       ON DELETE    CASCADE
 
-  , PRIMARY KEY ("userID", "releaseID", "tagID")
+  , PRIMARY KEY ("userID", "versionID", "tagID")
 ) WITHOUT ROWID;
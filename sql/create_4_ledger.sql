@@ -38,6 +38,7 @@ CREATE TABLE IF NOT EXISTS "OrderStatus" (
 --   [----------]   N..1
 --   | Listings |--------index[Inventory__User]
 --   [----------]
+--       |  '--*[ListingHistory]
 --       |
 --       |                *orderID
 --       | N..N   [--------]        (enum)
@@ -60,28 +61,29 @@ CREATE TABLE IF NOT EXISTS "OrderStatus" (
 --
 
 CREATE TABLE IF NOT EXISTS "Listings" (
-  , "userID"          INTEGER
+    "userID"          INTEGER
       NOT NULL          CHECK (userID <> 0)
-      REFERENCES        UserProfiles (userID)
+      REFERENCES        UserAccounts (userID)
       ON DELETE         RESTRICT
       ON UPDATE         RESTRICT
   , "versionID"       INTEGER
       NOT NULL          CHECK (versionID <> 0)
-      REFERENCES        ReleaseVersions (userID)
+      REFERENCES        ReleaseVersions (versionID)
       ON DELETE         RESTRICT
       ON UPDATE         RESTRICT
   , "item"            INTEGER
       NOT NULL          DEFAULT 1
   
+  -- Prices are in minor units of the currency (cents, or yen for JPY).
   , "price_low"       INTEGER
   , "price_high"      INTEGER
   , "price_currency"  TEXT  -- discogs currency abbreviation
 
   , "allow_offers"    BOOLEAN
       NOT NULL          DEFAULT FALSE
-  , "date_opened"     TEXT  -- YYYY/MM/DD
+  , "date_opened"     TEXT  -- YYYY-MM-DD
       NOT NULL          DEFAULT CURRENT_DATE
-  , "date_closed"     TEXT  -- YYYY/MM/DD
+  , "date_closed"     TEXT  -- YYYY-MM-DD
       -- if NULL, this listing is still available
 
   , FOREIGN KEY           ("userID", "versionID", "item")
@@ -103,6 +105,39 @@ CREATE INDEX IF NOT EXISTS "Listing__Opened"
   WHERE (date_closed IS NULL)
   ;
 
+-- The earlier listings of a copy.  Listings has one row for each copy (which
+-- purchases and trades refer to), so a closed listing is copied here before
+-- the copy is listed again, keeping its prices and dates.
+CREATE TABLE IF NOT EXISTS "ListingHistory" (
+    "historyID"       INTEGER
+      PRIMARY KEY
+  , "userID"          INTEGER
+      NOT NULL
+  , "versionID"       INTEGER
+      NOT NULL
+  , "item"            INTEGER
+      NOT NULL
+
+  , "price_low"       INTEGER
+  , "price_high"      INTEGER
+  , "price_currency"  TEXT
+
+  , "allow_offers"    BOOLEAN
+      NOT NULL          DEFAULT FALSE
+  , "date_opened"     TEXT  -- YYYY-MM-DD
+      NOT NULL
+  , "date_closed"     TEXT  -- YYYY-MM-DD
+      NOT NULL
+
+  , FOREIGN KEY         ("userID", "versionID", "item")
+    REFERENCES Listings ("userID", "versionID", "item")
+    ON DELETE           CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS "ListingHistory__Listing"
+  ON ListingHistory (userID, versionID, item)
+  ;
+
 
 CREATE TABLE IF NOT EXISTS "Orders" (
     "orderID"        INTEGER
@@ -111,25 +146,26 @@ CREATE TABLE IF NOT EXISTS "Orders" (
   , "seller_userID"  INTEGER
       -- Only orders for known sellers are tracked.
       NOT NULL         -- usually it's the person running this service.
-      REFERENCES       UserProfiles (userID)
+      REFERENCES       UserAccounts (userID)
       ON DELETE        CASCADE
   , "buyer_userID"   INTEGER
       NOT NULL         DEFAULT 0
-      REFERENCES       UserProfiles (userID)
+      REFERENCES       UserAccounts (userID)
       ON DELETE        SET NULL
 
   -- This is the total price minus the trade value and reflects the most recent
   -- value.  It must be positive; otherwise buyer and seller are swapped.
-  , "offer_price"     REAL
-      NOT NULL          CHECK (price >= 0.0)
+  -- All prices in the ledger are in minor units of price_currency (cents).
+  , "offer_price"     INTEGER
+      NOT NULL          CHECK (offer_price >= 0)
   , "price_currency"  TEXT     -- using discogs abbreviations
       -- if NULL, assumes USD currency.
 
-  , "date_opened"    TEXT  -- YYYY/MM/DD
+  , "date_opened"    TEXT  -- YYYY-MM-DD
       DEFAULT CURRENT_DATE
-  , "date_closed"    TEXT  -- YYYY/MM/DD
+  , "date_closed"    TEXT  -- YYYY-MM-DD
       DEFAULT NULL
-  , "last_activity"  TEXT  -- YYYY/MM/DD HH:MM:SS.SSS
+  , "last_activity"  TEXT  -- YYYY-MM-DD HH:MM:SS (UTC)
       DEFAULT CURRENT_TIMESTAMP
 
   -- Uses the enumeration defined in OrderStatus, following Discogs guidelines:
@@ -163,13 +199,13 @@ CREATE TABLE IF NOT EXISTS "OrderPurchases" (
       ON DELETE      CASCADE
 
   , "sellerID"     INTEGER
-      NOT NULL       CHECK (userID <> 0)
-      REFERENCES     UserProfiles (userID)
+      NOT NULL       CHECK (sellerID <> 0)
+      REFERENCES     UserAccounts (userID)
       ON DELETE      RESTRICT
       ON UPDATE      RESTRICT
   , "versionID"    INTEGER
       NOT NULL       CHECK (versionID <> 0)
-      REFERENCES     ReleaseVersions (userID)
+      REFERENCES     ReleaseVersions (versionID)
       ON DELETE      RESTRICT
       ON UPDATE      RESTRICT
   , "item"         INTEGER
@@ -179,8 +215,8 @@ CREATE TABLE IF NOT EXISTS "OrderPurchases" (
   -- order.  It must be positive; otherwise buyer and seller are swapped.
   -- If zero, the final price may also be adjusted with the Orders(offer_price)
   -- without needing to assign the difference to a specific purchase item.
-  , "purchase_price"  REAL
-      NOT NULL          CHECK (price >= 0.0)
+  , "purchase_price"  INTEGER  -- in the order's price_currency
+      NOT NULL          CHECK (purchase_price >= 0)
 
   , FOREIGN KEY         ("sellerID", "versionID", "item")
     REFERENCES Listings ("userID",   "versionID", "item")
@@ -208,12 +244,12 @@ CREATE TABLE IF NOT EXISTS "OrderTrades" (
   
   , "buyerID"      INTEGER
       NOT NULL       CHECK (buyerID <> 0)
-      REFERENCES     UserProfiles (userID)
+      REFERENCES     UserAccounts (userID)
       ON DELETE      RESTRICT
       ON UPDATE      RESTRICT
   , "versionID"    INTEGER
       NOT NULL       CHECK (versionID <> 0)
-      REFERENCES     ReleaseVersions (userID)
+      REFERENCES     ReleaseVersions (versionID)
       ON DELETE      RESTRICT
       ON UPDATE      RESTRICT
   , "item"         INTEGER
@@ -222,8 +258,8 @@ CREATE TABLE IF NOT EXISTS "OrderTrades" (
   -- This is the effective trade value for the Listing;
   -- if a listing did not exist then it will be created for the order/trade.
   -- The value may be zero, with an override on the related Orders(offer_price).
-  , "trade_value"  REAL
-      NOT NULL       CHECK (price >= 0.0)
+  , "trade_value"  INTEGER  -- in the order's price_currency
+      NOT NULL       CHECK (trade_value >= 0)
 
   , FOREIGN KEY         ("buyerID", "versionID", "item")
     REFERENCES Listings ("userID",  "versionID", "item")
@@ -248,11 +284,14 @@ CREATE TABLE IF NOT EXISTS "OrderUpdates" (
   , "orderID"      INTEGER
       NOT NULL       CHECK (orderID <> 0)
       REFERENCES     Orders (orderID)
-  , "update_time"  TEXT  -- YYYY/MM/DD HH:MM:SS
+  , "update_time"  TEXT  -- YYYY-MM-DD HH:MM:SS (UTC)
       DEFAULT CURRENT_TIMESTAMP
 
   , "comment"      TEXT
       NOT NULL       DEFAULT ""
+  -- The status the order moved into, NULL if this update did not change it.
+  , "status"       INTEGER
+      REFERENCES     OrderStatus (statusID)
 );
 
 CREATE INDEX IF NOT EXISTS "Update__Order"
@@ -262,3 +301,22 @@ CREATE INDEX IF NOT EXISTS "Update__Order"
 CREATE INDEX IF NOT EXISTS "Update__Timestamp"
   ON OrderUpdates (update_time)
   ;
+
+
+-- Historical reference rates for valuing the ledger in a single currency.
+-- Rates are imported from a local copy of the European Central Bank's
+-- euro foreign exchange reference rates (CSV or XML), so each rate is the
+-- number of units of the currency for one Euro on that date.
+--
+-- The ECB does not publish rates on weekends or TARGET holidays; conversion
+-- uses the most recent rate on or before the transaction date.
+CREATE TABLE IF NOT EXISTS "ExchangeRates" (
+    "currency"     TEXT  -- ISO 4217 code, as in price_currency
+      NOT NULL       CHECK (currency <> "EUR")
+  , "date"         TEXT  -- YYYY-MM-DD
+      NOT NULL
+  , "rate"         TEXT  -- decimal, as published (e.g. "1.0393")
+      NOT NULL
+
+  , PRIMARY KEY ("currency", "date")
+) WITHOUT ROWID;
@@ -33,6 +33,9 @@
 -- https://www.discogs.com/selling/resources/how-to-grade-items/
 -- except quality, my own approximation based on aggregate calculations, and
 -- these quality values may be subject to change, as the algorithm is tuned.
+--
+-- The last two grades are only for sleeves, as used by the Discogs marketplace
+-- for a sleeve that is not original to the release or that is missing.
 INSERT INTO Grading
          ("gradeID", "grade", "name",    "quality")
   VALUES (0,         "",      "UNKNOWN",        50)
@@ -44,6 +47,8 @@ INSERT INTO Grading
        , (6,         "G",     "Good",           30)
        , (7,         "F",     "Fair",           10)
        , (8,         "P",     "Poor",            5)
+       , (9,         "Generic",  "Generic",     15)
+       , (10,        "No Cover", "No Cover",     0)
        ;
 
 -- These values are based on the Voting Guidelines for data quality
@@ -53,7 +58,7 @@ INSERT INTO Grading
 --
 -- There is an additional entry for submissions with conflicting votes.
 -- The summary values (excepting this new entry) are from the above URL.
-INSERT INTO DataQuality
+INSERT INTO DataQualityEnum
     ("dqID", "quality",                 "summary")
   VALUES (0, "Needs Vote",              "There have not been any votes made on the quality of this artist, release or label data.")
 	     , (1, "Entirely Incorrect",      "For release, artist or label data that is totally incorrect, or so incomplete or badly entered as to be impossible to judge.")
@@ -161,7 +166,7 @@ INSERT INTO MediaFormatEnum
        , ("Zip Disk",           "Zip Disk",           "Floppy disk format available in 100, 250, and 750mb capacities.")
        , ("M/Stick",            "Memory Stick",       "Used at Discogs as a generic term, mostly found as USB 'flash drive'.")
        , ("Hybrid",             "Hybrid",             "For formats that combine two or more basic formats")
-       , ("",                   "All Media",          "Used to add further descriptions to a multi-media release, for example:<br><br>CD<br>2 &times; 12&quot;<br>All Media, Promo<br><br>This tag is not needed when 'Box Set' is used, as the descriptions can be added to the 'Box Set' format in this instance.")
+       , ("All Media",          "All Media",          "Used to add further descriptions to a multi-media release, for example:<br><br>CD<br>2 &times; 12&quot;<br>All Media, Promo<br><br>This tag is not needed when 'Box Set' is used, as the descriptions can be added to the 'Box Set' format in this instance.")
        , ("Box",                "Box Set",            "To note that all media are enclosed in extra packaging. Like &quot;All Media&quot;, Box Set goes on it's own line:<br><br>  5 x LP<br> Box Set")
        ;
 
@@ -186,32 +191,30 @@ INSERT INTO MediaFormatDescriptionEnum
        , ("3&quot;",         "3&quot;",              "")
        , ("2&quot;",         "2&quot;",              "")
        , ("1&quot;",         "1&quot;",              "")
-       , ("8 &frac13; RPM",  "",                     "")
-       , ("16 &frac23; RPM", "",                     "")
-       , ("33 &frac13; RPM", "",                     "")
-       , ("45 RPM",          "",                     "")
-       , ("78 RPM",          "",                     "Earlier records may run ±15%, i.e. about 66 to 90 RPM; a record cut at a speed in this range is normally called 'a 78'.")
+       , ("8 &frac13; RPM",  "8 &frac13; RPM",       "")
+       , ("16 &frac23; RPM", "16 &frac23; RPM",      "")
+       , ("33 &frac13; RPM", "33 &frac13; RPM",      "")
+       , ("45 RPM",          "45 RPM",               "")
+       , ("78 RPM",          "78 RPM",               "Earlier records may run ±15%, i.e. about 66 to 90 RPM; a record cut at a speed in this range is normally called 'a 78'.")
        , ("120 RPM",         "120 RPM",              "")
-       , ("21cm",            "",                     "")
-       , ("25cm",            "",                     "")
-       , ("27cm",            "",                     "")
-       , ("29cm",            "",                     "")
-       , ("35cm",            "",                     "")
-       , ("40cm",            "",                     "")
-       , ("50cm",            "",                     "")
-       , ("80 RPM",          "",                     "")
-       , ("90 RPM",          "",                     "")
-       , ("15/16 ips",       "",                     "")
+       , ("21cm",            "21cm",                 "")
+       , ("25cm",            "25cm",                 "")
+       , ("27cm",            "27cm",                 "")
+       , ("29cm",            "29cm",                 "")
+       , ("35cm",            "35cm",                 "")
+       , ("40cm",            "40cm",                 "")
+       , ("50cm",            "50cm",                 "")
        , ("80 RPM",          "80 RPM",               "")
-       , ("",                "1 &frac78; ips",       "ips stands for inches per second")
-       , ("",                "15 ips",               "")
-       , ("",                "15/16 ips",            "")
-       , ("",                "3 &frac34; ips",       "")
-       , ("",                "30 ips",               "")
-       , ("",                "7 &frac12; ips",       "")
-       , ("",                "&frac12;&quot;",       "")
-       , ("",                "&frac14;&quot;",       "The most popular open reel to reel tape size")
-       , ("",                "&frac18;&quot;",       "")
+       , ("90 RPM",          "90 RPM",               "")
+       , ("15/16 ips",       "15/16 ips",            "")
+       , ("1 &frac78; ips",  "1 &frac78; ips",       "ips stands for inches per second")
+       , ("15 ips",          "15 ips",               "")
+       , ("3 &frac34; ips",  "3 &frac34; ips",       "")
+       , ("30 ips",          "30 ips",               "")
+       , ("7 &frac12; ips",  "7 &frac12; ips",       "")
+       , ("&frac12;&quot;",  "&frac12;&quot;",       "")
+       , ("&frac14;&quot;",  "&frac14;&quot;",       "The most popular open reel to reel tape size")
+       , ("&frac18;&quot;",  "&frac18;&quot;",       "")
        , ("2tr Mono",        "2-Track Mono",         "Tape runs in both directions, with a mono track on each side.")
        , ("2tr Stereo",      "2-Track Stereo",       "Tape runs in one direction, as a stereo track.")
        , ("4tr Mono",        "4-Track Mono",         "")
@@ -241,8 +244,6 @@ INSERT INTO MediaFormatDescriptionEnum
        , ("SVCD",            "SVCD",                 "Super Video CD, a format used for storing video on standard compact discs. SVCD falls between Video CD and DVD in terms of technical capability and picture quality.")
        , ("XRCD",            "XRCD",                 "Extended Resolution Compact Disc")
        , ("12&quot;",        "12&quot;",             "")
-       , ("5&quot;",         "5&quot;",              "")
-       , ("8&quot;",         "8&quot;",              "")
        , ("4K",              "4K",                   "")
        , ("8K",              "8K",                   "")
        , ("Blu-ray-A",       "Blu-ray Audio",        "")
@@ -251,7 +252,6 @@ INSERT INTO MediaFormatDescriptionEnum
        , ("DVD-D",           "DVD-Data",             "")
        , ("DVD-V",           "DVD-Video",            "Only to be used when the release shows the DVD-Video logo.")
        , ("Hybrid",          "Hybrid",               "A version of SACD (Super Audio CD) that contains a CD layer as well as a SACD layer, and if therefore playable on normal CD players as well as SACD players.")
-       , ("Multichannel",    "Multichannel",         "")
        , ("AAC",             "AAC",                  "Advanced Audio Coding, was designed as an improved-performance codec relative to MP3")
        , ("AIFC",            "AIFC",                 "A compressed variant of AIFF, also known as AIFF-C, with various defined compression codecs.")
        , ("AIFF",            "AIFF",                 "Audio Interchange File Format (AIFF) is a uncompressed pulse-code modulation (PCM) file.")
@@ -265,7 +265,7 @@ INSERT INTO MediaFormatDescriptionEnum
        , ("FLAC",            "FLAC",                 "Free Lossless Audio Codec (FLAC) is a popular file format for audio data compression. Being a lossless compression format, FLAC does not remove information from the audio stream, as do lossy compression formats such as MP3 and AAC.")
        , ("FLV",             "FLV",                  "Flash Video is a container file format used to deliver video over the Internet. Flash Video content may also be embedded within SWF files. There are two different video file formats: FLV and F4V. The audio and video data within FLV files are encoded in the same way as they are within SWF files. The latter F4V file format is based on the ISO base media file format.")
        , ("MOV",             "MOV",                  "Apple Quicktime Movie (MOV)")
-       , ("",                "MP2",                  "")
+       , ("MP2",             "MP2",                  "")
        , ("MP3",             "MP3",                  "")
        , ("MPEG Video",      "MPEG Video",           "MPEG encoded video file.")
        , ("MPEG-4 Video",    "MPEG-4 Video",         "For any video in an MPEG-4 container. For MPEG-4 audio, please see AAC and ALAC ")
@@ -275,7 +275,7 @@ INSERT INTO MediaFormatDescriptionEnum
        , ("RM",              "RM",                   "RealMedia encoded file.")
        , ("SHN",             "SHN",                  "Shorten file format")
        , ("SPX",             "SPX",                  "(Speex) Lossy audio compression codec specifically tuned for the reproduction of human speech.")
-       , ("SWF",             "SWF",                  "(originally standing for "Small Web Format", later changed to "Shockwave Flash", then changed back to Small Web Format) (pronounced swiff or "swoof") - a partially open repository for multimedia and especially for vector graphics. Intended to be small enough for publication on the web, SWF files can contain animations or applets of varying degrees of interactivity and function.")
+       , ("SWF",             "SWF",                  "(originally standing for &quot;Small Web Format&quot;, later changed to &quot;Shockwave Flash&quot;, then changed back to Small Web Format) (pronounced swiff or &quot;swoof&quot;) - a partially open repository for multimedia and especially for vector graphics. Intended to be small enough for publication on the web, SWF files can contain animations or applets of varying degrees of interactivity and function.")
        , ("TTA",             "TTA",                  "True Audio (TTA) is a lossless compressor for multichannel 8, 16 and 24 bits audio data.")
        , ("WAV",             "WAV",                  "A Microsoft and IBM audio file format standard for storing audio. WAVs are compatible with Windows and Macintosh operating systems.")
        , ("WavPack",         "WavPack",              "")
@@ -289,11 +289,11 @@ INSERT INTO MediaFormatDescriptionEnum
        , ("DVDplus",         "DVDplus",              "An optical disc storage technology that combines the technology of DVD and CD in one disc. A DVD and a CD-compatible layer are bonded together to provide a multi-format hybrid disc.")
        , ("VinylDisc",       "VinylDisc",            "A combination of a digital layer, either in CD or DVD format, and an analogue layer which is a vinyl record ")
        , ("D/Sided",         "Double Sided",         "")
-       , ("S/Sided",         "Single Sided",         "For two sided tapes that only have audio on one side. Please note this tag is not to be used when the release has the same audio on both sides. In this case, please enter the tracklisting for both sides, and explain in the release notes that the audio is identical on both sides. See the <a href="http://www.discogs.com/help/submission-guidelines-release-trk.html#Same_Audio_On_Different_Sides">full guidelines here</a>.")
+       , ("S/Sided",         "Single Sided",         "For two sided tapes that only have audio on one side. Please note this tag is not to be used when the release has the same audio on both sides. In this case, please enter the tracklisting for both sides, and explain in the release notes that the audio is identical on both sides. See the <a href=&quot;http://www.discogs.com/help/submission-guidelines-release-trk.html#Same_Audio_On_Different_Sides&quot;>full guidelines here</a>.")
        , ("S/Sided",         "Single Sided",         "For cassettes that are blank on one side")
        , ("S/Sided",         "Single Sided",         "For Laserdiscs.")
        , ("S/Sided",         "Single Sided",         "")
-       , ("S/Sided",         "Single Sided",         "For two sided vinyl that only have audio on one side. Please note this tag is not to be used when the release has the same audio on both sides. In this case, please enter the tracklisting for both sides, and explain in the release notes that the audio is identical on both sides. See the <a href="http://www.discogs.com/help/submission-guidelines-release-trk.html#Same_Audio_On_Different_Sides">full guidelines here</a>.")
+       , ("S/Sided",         "Single Sided",         "For two sided vinyl that only have audio on one side. Please note this tag is not to be used when the release has the same audio on both sides. In this case, please enter the tracklisting for both sides, and explain in the release notes that the audio is identical on both sides. See the <a href=&quot;http://www.discogs.com/help/submission-guidelines-release-trk.html#Same_Audio_On_Different_Sides&quot;>full guidelines here</a>.")
        , ("Advance",         "Advance",              "Advance releases are sometimes issued prior to a release date to reviewers and other industry professionals. These are usually issued without artwork and are generally only feature the artist name, title, track listing, label, proposed release date, and/or promotional contact.")
        , ("Album",           "Album",                "Album tag usage has no relation to speed, media type, media item count (e.g. tracks spread over multiple 12&quot;s) or use of the Compilation tag (whether for Various or single artist releases). This tag is only to be used where it is clear the item was released as such.")
        , ("MiniAlbum",       "Mini-Album",           "Only to be used where it is clear the item was released as a Mini-Album and not for any short-form album release.")
@@ -302,7 +302,7 @@ INSERT INTO MediaFormatDescriptionEnum
        , ("RSD",             "Record Store Day",     "For use with Record Store Day releases.")
        , ("Single",          "Single",               "Only to be used where it is clear the item was released as a Single.")
        , ("Comp",            "Compilation",          "")
-       , ("",                "Stereo",               "Most music formats are stereo. This tag can be used for any stereo item, and must be used when the item was released in stereo and mono formats, or is otherwise necessary to point out.")
+       , ("Stereo",          "Stereo",               "Most music formats are stereo. This tag can be used for any stereo item, and must be used when the item was released in stereo and mono formats, or is otherwise necessary to point out.")
        , ("Mono",            "Mono",                 "")
        , ("Quad",            "Quadraphonic",         "A four speaker surround format. It had a number of different encoding methods, some of which were incompatible with each other. CD-4 / Compatible Discrete 4 / Quadradisc, UD-4 / UMX, Q4, Quad-8 / Quadraphonic 8-Track, SQ / Surround Quadraphonic / Stereo Quadraphonic, QS / Quadraphonic Stereo, EV / Stereo-4, DY / Dynaquad, Matrix H, Passive Pseudo Quad, Pseudo-surround sound.")
        , ("Amb",             "Ambisonic",            "")
@@ -332,7 +332,7 @@ INSERT INTO MediaFormatDescriptionEnum
        , ("RM",              "Remastered",           "This tag should only be used where it is clear the item was released as such, for example it is explicitly mentioned on the release, or by the label, artist, or other reliable source.")
        , ("RP",              "Repress",              "")
        , ("Smplr",           "Sampler",              "In English, &quot;sampler&quot; has a different meaning from &quot;Compilation&quot;, a sampler is a free or low-priced preview of a larger release(s). Although in other languages the two words may mean the same thing, in Discogs they should not be confused.")
-       , ("",                "Special Cut",          "Used to denote releases with locked grooves, parallel grooves, backward grooves, &quot;banded for radio play&quot;, etc. Release notes are required to provide further information.")
+       , ("Special Cut",     "Special Cut",          "Used to denote releases with locked grooves, parallel grooves, backward grooves, &quot;banded for radio play&quot;, etc. Release notes are required to provide further information.")
        , ("S/Edition",       "Special Edition",      "Only items that have this printed on them somewhere (stickers etc), or were originally marketed by the label as such, should be tagged as &quot;Special Edition&quot;.")
        , ("Styrene",         "Styrene",              "For discs made from Styrene.")
        , ("TP",              "Test Pressing",        "Typically a limited run of a record made to test the sound quality. Only list an item as a Test Pressing if the release is clearly marked as such.")
@@ -374,6 +374,7 @@ INSERT INTO MediaFormatDescriptionEnum
        , ("SECAM",           "SECAM",                "For other video formats.")
        , ("SECAM",           "SECAM",                "For SelectaVision.")
        , ("SECAM",           "SECAM",                "For Video Tape.")
+       ;
 
 --
 -- "UNKNOWN" representations
@@ -30,6 +30,7 @@
 DROP INDEX IF EXISTS "Listing__User";
 DROP INDEX IF EXISTS "Listing__Version";
 DROP INDEX IF EXISTS "Listing__Opened";
+DROP INDEX IF EXISTS "ListingHistory__Listing";
 DROP INDEX IF EXISTS "Order__Seller";
 DROP INDEX IF EXISTS "Order__Buyer";
 DROP INDEX IF EXISTS "Order__Opened";
@@ -81,8 +82,11 @@ DROP INDEX IF EXISTS "Artist__ReleaseVersion";
 DROP INDEX IF EXISTS "ReleaseVersion__Artist";
 DROP INDEX IF EXISTS "Label__ReleaseVersion";
 DROP INDEX IF EXISTS "ReleaseVersion__Label";
+DROP INDEX IF EXISTS "ReleaseVersion__CatalogNumber";
 DROP INDEX IF EXISTS "Genre__ReleaseVersion";
 DROP INDEX IF EXISTS "Format__ReleaseVersion";
+DROP INDEX IF EXISTS "Identifier__ReleaseVersion";
+DROP INDEX IF EXISTS "ReleaseVersion__Barcode";
 
 -- cover art
 DROP INDEX IF EXISTS "CoverArt__Version";
@@ -102,6 +106,7 @@ DROP INDEX IF EXISTS "Track__Style";
 -- enums
 DROP INDEX IF EXISTS "DataQuality__Unique";
 DROP INDEX IF EXISTS "MediaFormat__Unique";
+DROP INDEX IF EXISTS "MediaFormatDescription__Unique";
 DROP INDEX IF EXISTS "Genre__Unique";
 DROP INDEX IF EXISTS "Style__Unique";
 DROP INDEX IF EXISTS "Image__SHA1";
@@ -27,11 +27,13 @@
 -- Drop these after dropping the indices.
 
 -- drop ledger tables
+DROP TABLE IF EXISTS "ExchangeRates";
 DROP TABLE IF EXISTS "OrderUpdates";
 DROP TABLE IF EXISTS "OrderPurchases";
 DROP TABLE IF EXISTS "OrderTrades";
 DROP TABLE IF EXISTS "Orders";
 DROP TABLE IF EXISTS "OrderStatus";
+DROP TABLE IF EXISTS "ListingHistory";
 DROP TABLE IF EXISTS "Listings";
 
 -- drop collection tables
@@ -47,12 +49,15 @@ DROP TABLE IF EXISTS "User_Tokens";
 DROP TABLE IF EXISTS "UserAccounts";
 
 -- drop discogs tables
+DROP TABLE IF EXISTS "CatalogGenerations";
+
 DROP TABLE IF EXISTS "Track_Styles";
 DROP TABLE IF EXISTS "Track_Artists";
 DROP TABLE IF EXISTS "Tracks";
 
 DROP TABLE IF EXISTS "ReleaseVersion_MediaArt";
 DROP TABLE IF EXISTS "ReleaseVersion_CoverArt";
+DROP TABLE IF EXISTS "ReleaseVersion_Identifiers";
 DROP TABLE IF EXISTS "ReleaseVersion_Formats";
 DROP TABLE IF EXISTS "ReleaseVersion_Genres";
 DROP TABLE IF EXISTS "ReleaseVersion_Labels";
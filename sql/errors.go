@@ -0,0 +1,93 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/sql/errors.go
+
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Errors which handlers distinguish (e.g. as 404 or 409 responses); the
+// errors returned from this package and the collection, catalog and ledger
+// packages wrap these when applicable, test for them with errors.Is().
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrDuplicate  = errors.New("already exists")
+	ErrReferenced = errors.New("violates a foreign key reference")
+	ErrConstraint = errors.New("violates a table constraint")
+)
+
+// Wraps sqlite constraint violations with one of the errors above (keeping
+// the original message), sql.ErrNoRows as ErrNotFound, and returns any other
+// error (including nil) unchanged.
+func Classify(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w: %v", ErrNotFound, err)
+	}
+	var sqlite_err *sqlite.Error
+	if !errors.As(err, &sqlite_err) {
+		return err
+	}
+	switch sqlite_err.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		return fmt.Errorf("%w: %v", ErrDuplicate, err)
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return fmt.Errorf("%w: %v", ErrReferenced, err)
+	}
+	if sqlite_err.Code()&0xff == sqlite3.SQLITE_CONSTRAINT {
+		return fmt.Errorf("%w: %v", ErrConstraint, err)
+	}
+	return err
+}
+
+// Runs the function within a transaction, committing if it returns nil and
+// rolling back otherwise.  The returned error is classified (see Classify).
+//
+// If db is already a *sql.Tx, the function runs as part of it instead (and
+// the caller commits or rolls back), so that functions which take a Queryer
+// can be combined into one larger transaction, e.g. a batch of operations.
+func InTx(ctx context.Context, db Queryer, run func(tx *sql.Tx) error) error {
+	var tx *sql.Tx
+	switch db := db.(type) {
+	case *sql.Tx:
+		return Classify(run(db))
+	case *sql.DB:
+		var err error
+		if tx, err = db.BeginTx(ctx, nil); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("cannot begin a transaction on %T", db)
+	}
+	defer tx.Rollback()
+
+	if err := run(tx); err != nil {
+		return Classify(err)
+	}
+	return Classify(tx.Commit())
+}
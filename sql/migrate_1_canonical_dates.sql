@@ -0,0 +1,51 @@
+-- SQL statements for migrating ledger dates to the canonical YYYY-MM-DD format.
+-- Copyright (c) 2025, Kevin Damm
+-- All rights reserved.
+-- MIT License:
+--
+-- Permission is hereby granted, free of charge, to any person obtaining a copy
+-- of this software and associated documentation files (the "Software"), to deal
+-- in the Software without restriction, including without limitation the rights
+-- to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+-- copies of the Software, and to permit persons to whom the Software is
+-- furnished to do so, subject to the following conditions:
+--
+-- The above copyright notice and this permission notice shall be included in
+-- all copies or substantial portions of the Software.
+--
+-- THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+-- IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+-- FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+-- AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+-- LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+-- OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+-- SOFTWARE.
+--
+-- github:kevindamm/cratedigdb/sql/migrate_1_canonical_dates.sql
+
+--
+-- The ledger tables were documented as YYYY/MM/DD while their defaults (and
+-- the collection tables) use YYYY-MM-DD.  Rewrite any dates in the older form
+-- and truncate timestamps to whole seconds, matching CURRENT_TIMESTAMP.
+--
+
+UPDATE Listings
+  SET date_opened = REPLACE(date_opened, '/', '-')
+  WHERE date_opened LIKE '%/%';
+UPDATE Listings
+  SET date_closed = REPLACE(date_closed, '/', '-')
+  WHERE date_closed LIKE '%/%';
+
+UPDATE Orders
+  SET date_opened = REPLACE(date_opened, '/', '-')
+  WHERE date_opened LIKE '%/%';
+UPDATE Orders
+  SET date_closed = REPLACE(date_closed, '/', '-')
+  WHERE date_closed LIKE '%/%';
+UPDATE Orders
+  SET last_activity = SUBSTR(REPLACE(REPLACE(last_activity, '/', '-'), 'T', ' '), 1, 19)
+  WHERE last_activity LIKE '%/%' OR last_activity LIKE '%T%' OR LENGTH(last_activity) > 19;
+
+UPDATE OrderUpdates
+  SET update_time = SUBSTR(REPLACE(REPLACE(update_time, '/', '-'), 'T', ' '), 1, 19)
+  WHERE update_time LIKE '%/%' OR update_time LIKE '%T%' OR LENGTH(update_time) > 19;
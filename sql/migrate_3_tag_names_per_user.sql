@@ -0,0 +1,52 @@
+-- SQL statements for scoping tag names to the user who created them.
+-- Copyright (c) 2025, Kevin Damm
+-- All rights reserved.
+-- MIT License:
+--
+-- Permission is hereby granted, free of charge, to any person obtaining a copy
+-- of this software and associated documentation files (the "Software"), to deal
+-- in the Software without restriction, including without limitation the rights
+-- to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+-- copies of the Software, and to permit persons to whom the Software is
+-- furnished to do so, subject to the following conditions:
+--
+-- The above copyright notice and this permission notice shall be included in
+-- all copies or substantial portions of the Software.
+--
+-- THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+-- IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+-- FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+-- AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+-- LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+-- OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+-- SOFTWARE.
+
+--
+-- Tag names were unique across all users; they only need to be unique for
+-- each user (ignoring case).  SQLite cannot drop a column's UNIQUE constraint
+-- so the table is rebuilt, keeping the taggings that dropping it would cascade.
+--
+
+CREATE TEMP TABLE "VinylTagging_saved" AS SELECT * FROM VinylTagging;
+
+CREATE TABLE "TagNames_migrated" (
+    "tagID"    INTEGER
+      PRIMARY KEY
+  , "userID"   INTEGER
+      NOT NULL
+      REFERENCES UserAccounts (userID)
+      CHECK (userID <> 0)
+
+  , "name"  TEXT NOT NULL COLLATE NOCASE
+  , UNIQUE ("userID", "name")
+);
+
+INSERT INTO TagNames_migrated (tagID, userID, name)
+  SELECT tagID, userID, name FROM TagNames;
+
+DROP TABLE TagNames;
+ALTER TABLE TagNames_migrated RENAME TO TagNames;
+
+INSERT OR IGNORE INTO VinylTagging (userID, versionID, tagID)
+  SELECT userID, versionID, tagID FROM VinylTagging_saved;
+DROP TABLE VinylTagging_saved;
@@ -0,0 +1,62 @@
+-- SQL statements for password logins and hashed, expiring session and API tokens.
+-- Copyright (c) 2025, Kevin Damm
+-- All rights reserved.
+-- MIT License:
+--
+-- Permission is hereby granted, free of charge, to any person obtaining a copy
+-- of this software and associated documentation files (the "Software"), to deal
+-- in the Software without restriction, including without limitation the rights
+-- to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+-- copies of the Software, and to permit persons to whom the Software is
+-- furnished to do so, subject to the following conditions:
+--
+-- The above copyright notice and this permission notice shall be included in
+-- all copies or substantial portions of the Software.
+--
+-- THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+-- IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+-- FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+-- AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+-- LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+-- OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+-- SOFTWARE.
+
+--
+-- Accounts may have a (bcrypt) password for logging in, and may be curators of
+-- the catalog (which only they may edit).  User_Tokens had only
+-- a userID, unique for each user, and no way to check a token against it; it
+-- is replaced by a table of hashed tokens with their kind, scopes and expiry.
+-- There were no tokens to keep.
+--
+
+ALTER TABLE UserAccounts ADD COLUMN "password_hash" TEXT;
+ALTER TABLE UserAccounts ADD COLUMN "is_curator" BOOLEAN NOT NULL DEFAULT FALSE;
+
+DROP INDEX IF EXISTS "Token__User";
+DROP TABLE IF EXISTS "User_Tokens";
+
+CREATE TABLE "User_Tokens" (
+    "tokenID"     INTEGER PRIMARY KEY
+  , "userID"      INTEGER
+      NOT NULL
+      REFERENCES    UserAccounts (userID)
+      ON DELETE     CASCADE
+  , "token_hash"  BLOB
+      UNIQUE
+      NOT NULL
+  , "kind"        TEXT
+      NOT NULL
+      CHECK (kind IN ("session", "api"))
+  , "scopes"      TEXT
+      NOT NULL  DEFAULT ""
+  , "label"       TEXT
+      NOT NULL  DEFAULT ""
+
+  , "date_created"  TEXT
+      NOT NULL  DEFAULT CURRENT_TIMESTAMP
+  , "date_expires"  TEXT
+      NOT NULL
+  , "last_used"     TEXT
+);
+
+CREATE INDEX "Token__User" ON User_Tokens (userID);
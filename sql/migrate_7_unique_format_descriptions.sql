@@ -0,0 +1,37 @@
+-- SQL statements for removing duplicate media format descriptions.
+-- Copyright (c) 2025, Kevin Damm
+-- All rights reserved.
+-- MIT License:
+--
+-- Permission is hereby granted, free of charge, to any person obtaining a copy
+-- of this software and associated documentation files (the "Software"), to deal
+-- in the Software without restriction, including without limitation the rights
+-- to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+-- copies of the Software, and to permit persons to whom the Software is
+-- furnished to do so, subject to the following conditions:
+--
+-- The above copyright notice and this permission notice shall be included in
+-- all copies or substantial portions of the Software.
+--
+-- THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+-- IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+-- FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+-- AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+-- LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+-- OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+-- SOFTWARE.
+
+--
+-- The base data inserted some format descriptions twice (e.g. "80 RPM" and
+-- "15/16 ips"), with the same notes.  Only the first of each is kept, and a
+-- unique index prevents any more.  No other table refers to fmt_descID.
+--
+
+DELETE FROM MediaFormatDescriptionEnum
+  WHERE fmt_descID NOT IN (
+    SELECT MIN(fmt_descID) FROM MediaFormatDescriptionEnum
+    GROUP BY fmt_desc, comments);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "MediaFormatDescription__Unique"
+  ON MediaFormatDescriptionEnum (fmt_desc, comments)
+  ;
@@ -0,0 +1,257 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/sql/sql.go
+
+// Package sql embeds the table definitions and base data (as SQL statements)
+// so that the golang implementation can create and check its own database.
+// These are the same statements that are used for D1 by the Workers backend.
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+
+	_ "modernc.org/sqlite"
+)
+
+//go:embed create_*.sql migrate_*.sql
+var sqlScripts embed.FS
+
+// Opens (creating if necessary) the sqlite database at path, ensures that all
+// tables are defined and that the enumerations' base data matches the values
+// compiled into the schema package.  A path of ":memory:" opens an in-memory
+// database, useful for tests.
+//
+// Foreign key constraints are enforced on every connection.
+func Open(ctx context.Context, path string) (*sql.DB, error) {
+	dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)", path)
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if path == ":memory:" {
+		// Each connection to :memory: would otherwise be a separate database.
+		db.SetMaxOpenConns(1)
+	}
+
+	if err := create_or_migrate(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := VerifyBaseData(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// A new database is created with the latest tables and marked as having every
+// migration applied.  An existing database is migrated first, so that the
+// create_*.sql scripts may refer to any columns that the migrations add.
+func create_or_migrate(ctx context.Context, db *sql.DB) error {
+	var tables int
+	row := db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'Grading'`)
+	if err := row.Scan(&tables); err != nil {
+		return err
+	}
+
+	if tables == 0 {
+		if err := CreateTables(ctx, db); err != nil {
+			return err
+		}
+		migrations, err := migration_scripts()
+		if err != nil || len(migrations) == 0 {
+			return err
+		}
+		return set_schema_version(ctx, db, migrations[len(migrations)-1].version)
+	}
+
+	if err := Migrate(ctx, db); err != nil {
+		return err
+	}
+	return CreateTables(ctx, db)
+}
+
+// Runs the create_*.sql scripts in order, each within its own transaction.
+// The table definitions are idempotent but the base data is only inserted if
+// the Grading table is empty, i.e. when the database is first created.
+func CreateTables(ctx context.Context, db *sql.DB) error {
+	paths, err := fs.Glob(sqlScripts, "create_*.sql")
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_basedata.sql") {
+			var count int
+			row := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM Grading`)
+			if err := row.Scan(&count); err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+		}
+		if err := exec_script(ctx, db, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Executes all statements of an embedded script in a single transaction, so
+// that DEFERRABLE foreign keys are only checked once the script is complete.
+func exec_script(ctx context.Context, db *sql.DB, path string) error {
+	script, err := sqlScripts.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, string(script)); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return tx.Commit()
+}
+
+type migration struct {
+	version int
+	path    string
+}
+
+// The migrate_#_*.sql scripts, ordered by their number.
+func migration_scripts() ([]migration, error) {
+	paths, err := fs.Glob(sqlScripts, "migrate_*.sql")
+	if err != nil {
+		return nil, err
+	}
+	migrations := make([]migration, len(paths))
+	for i, path := range paths {
+		number, _, _ := strings.Cut(strings.TrimPrefix(path, "migrate_"), "_")
+		version, err := strconv.Atoi(number)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s is not numbered: %w", path, err)
+		}
+		migrations[i] = migration{version, path}
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+	return migrations, nil
+}
+
+// The number of the most recent migration applied to the database, as kept in
+// sqlite's user_version pragma.
+func SchemaVersion(ctx context.Context, db *sql.DB) (int, error) {
+	var version int
+	err := db.QueryRowContext(ctx, `PRAGMA user_version`).Scan(&version)
+	return version, err
+}
+
+func set_schema_version(ctx context.Context, db *sql.DB, version int) error {
+	_, err := db.ExecContext(ctx, fmt.Sprintf(`PRAGMA user_version = %d`, version))
+	return err
+}
+
+// Applies each migrate_#_*.sql script that is newer than the database's
+// SchemaVersion(), each in its own transaction along with its version update.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	current, err := SchemaVersion(ctx, db)
+	if err != nil {
+		return err
+	}
+	migrations, err := migration_scripts()
+	if err != nil {
+		return err
+	}
+
+	for _, migration := range migrations {
+		if migration.version <= current {
+			continue
+		}
+		if err := apply_migration(ctx, db, migration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func apply_migration(ctx context.Context, db *sql.DB, migration migration) error {
+	script, err := sqlScripts.ReadFile(migration.path)
+	if err != nil {
+		return err
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, string(script)); err != nil {
+		return fmt.Errorf("%s: %w", migration.path, err)
+	}
+	if _, err := tx.ExecContext(ctx,
+		fmt.Sprintf(`PRAGMA user_version = %d`, migration.version)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// The query methods shared by *sql.DB and *sql.Tx, so that the collection and
+// ledger logic can run on its own or as part of a larger transaction.
+type Queryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Runs the query and collects each row as scanned by the callback.  All rows
+// are read (and closed) before returning, so that the next query may run on
+// the same connection.
+func QueryList[T any](ctx context.Context, db Queryer, scan func(*sql.Rows) (T, error), query string, args ...any) ([]T, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []T
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, item)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,271 @@
+// Copyright (c) 2025 Kevin Damm
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+//
+// github:kevindamm/cratedigdb/sql/sql_test.go
+
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenInMemory(t *testing.T) {
+	ctx := context.Background()
+	db, err := Open(ctx, ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM MediaFormatEnum`).Scan(&count))
+	assert.Equal(t, 64, count)
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM MediaFormatDescriptionEnum`).Scan(&count))
+	assert.Equal(t, 202, count)
+
+	rows, err := db.Query(`PRAGMA foreign_key_check`)
+	require.NoError(t, err)
+	defer rows.Close()
+	assert.False(t, rows.Next(), "base data violates a foreign key")
+}
+
+func TestReopenKeepsBaseData(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cratedig.db")
+	db, err := Open(ctx, path)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	db, err = Open(ctx, path)
+	require.NoError(t, err)
+	defer db.Close()
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM Grading`).Scan(&count))
+	assert.Equal(t, 11, count)
+}
+
+func TestVerifyBaseDataMismatch(t *testing.T) {
+	ctx := context.Background()
+	db, err := Open(ctx, ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = db.Exec(`UPDATE Grading SET quality = 60 WHERE gradeID = 3`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO OrderStatus (statusID, status) VALUES (9, "Lost")`)
+	require.NoError(t, err)
+
+	err = VerifyBaseData(ctx, db)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `Grading is missing 3 "VG+ Very Good Plus 70"`)
+	assert.Contains(t, err.Error(), `Grading has unexpected 3 "VG+ Very Good Plus 60"`)
+	assert.Contains(t, err.Error(), `OrderStatus has unexpected 9 "Lost"`)
+}
+
+// Recreates OrderUpdates as it was before migration 4 added its status column
+// (which cannot be dropped while it refers to OrderStatus).
+func downgrade_order_updates(t *testing.T, db *sql.DB) {
+	_, err := db.Exec(`DROP TABLE OrderUpdates`)
+	require.NoError(t, err)
+	_, err = db.Exec(`
+	  CREATE TABLE "OrderUpdates" (
+	      "updateID"     INTEGER PRIMARY KEY
+	    , "orderID"      INTEGER NOT NULL CHECK (orderID <> 0) REFERENCES Orders (orderID)
+	    , "update_time"  TEXT DEFAULT CURRENT_TIMESTAMP
+	    , "comment"      TEXT NOT NULL DEFAULT ""
+	  )`)
+	require.NoError(t, err)
+}
+
+// Drops the columns which migration 5 adds to VinylItems.
+func downgrade_vinyl_items(t *testing.T, db *sql.DB) {
+	for _, column := range []string{"purchase_price", "purchase_currency"} {
+		_, err := db.Exec(`ALTER TABLE VinylItems DROP COLUMN ` + column)
+		require.NoError(t, err)
+	}
+}
+
+// Recreates the accounts tables as they were before migration 6, without
+// passwords and with only the userID of each token.
+func downgrade_accounts(t *testing.T, db *sql.DB) {
+	for _, statement := range []string{
+		`ALTER TABLE UserAccounts DROP COLUMN password_hash`,
+		`ALTER TABLE UserAccounts DROP COLUMN is_curator`,
+		`DROP TABLE User_Tokens`,
+		`CREATE TABLE "User_Tokens" ("userID" INTEGER NOT NULL REFERENCES UserAccounts (userID))`,
+		`CREATE UNIQUE INDEX "Token__User" ON User_Tokens (userID)`,
+	} {
+		_, err := db.Exec(statement)
+		require.NoError(t, err)
+	}
+}
+
+// Drops the index which migration 7 adds to MediaFormatDescriptionEnum and
+// inserts one of the descriptions which the base data had twice.
+func downgrade_format_descriptions(t *testing.T, db *sql.DB) {
+	for _, statement := range []string{
+		`DROP INDEX MediaFormatDescription__Unique`,
+		`INSERT INTO MediaFormatDescriptionEnum (fmt_desc_abbr, fmt_desc) VALUES ("80 RPM", "80 RPM")`,
+	} {
+		_, err := db.Exec(statement)
+		require.NoError(t, err)
+	}
+}
+
+func TestMigrateCanonicalDates(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cratedig.db")
+	db, err := Open(ctx, path)
+	require.NoError(t, err)
+	version, err := SchemaVersion(ctx, db)
+	require.NoError(t, err)
+	migrations, err := migration_scripts()
+	require.NoError(t, err)
+	latest := migrations[len(migrations)-1].version
+	assert.Equal(t, latest, version, "a new database has every migration applied")
+
+	// Write rows as they were before the migrations and roll back the version.
+	_, err = db.Exec(`ALTER TABLE VinylItems DROP COLUMN date_archived`)
+	require.NoError(t, err)
+	downgrade_order_updates(t, db)
+	downgrade_vinyl_items(t, db)
+	downgrade_accounts(t, db)
+	downgrade_format_descriptions(t, db)
+	_, err = db.Exec(`INSERT INTO UserAccounts (userID, username) VALUES (1, "kevin")`)
+	require.NoError(t, err)
+	_, err = db.Exec(`
+	  INSERT INTO Orders (orderID, seller_userID, offer_price, date_opened, last_activity)
+	  VALUES (1, 1, 0, "2025/02/03", "2025/02/04 12:30:00.125"),
+	         (2, 1, 0, "2025-02-05", "2025-02-05T08:15:00")`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO OrderUpdates (orderID, update_time)
+	  VALUES (1, "2025-02-04 12:30:00"), (2, "2025-02-05T08:15:00Z")`)
+	require.NoError(t, err)
+	_, err = db.Exec(`PRAGMA user_version = 0`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	db, err = Open(ctx, path)
+	require.NoError(t, err)
+	defer db.Close()
+	version, err = SchemaVersion(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, latest, version)
+
+	var opened, activity, updated string
+	require.NoError(t, db.QueryRow(`
+	  SELECT date_opened, last_activity, update_time
+	  FROM Orders JOIN OrderUpdates USING (orderID)
+	  WHERE orderID = 1`).Scan(&opened, &activity, &updated))
+	assert.Equal(t, "2025-02-03", opened)
+	assert.Equal(t, "2025-02-04 12:30:00", activity)
+	assert.Equal(t, "2025-02-04 12:30:00", updated)
+	require.NoError(t, db.QueryRow(`
+	  SELECT last_activity, update_time
+	  FROM Orders JOIN OrderUpdates USING (orderID)
+	  WHERE orderID = 2`).Scan(&activity, &updated))
+	assert.Equal(t, "2025-02-05 08:15:00", activity, "RFC 3339 separator replaced")
+	assert.Equal(t, "2025-02-05 08:15:00", updated, "RFC 3339 separator replaced")
+
+	_, err = db.Exec(`SELECT date_archived FROM VinylItems`)
+	assert.NoError(t, err, "migrated VinylItems has date_archived")
+	_, err = db.Exec(`SELECT status FROM OrderUpdates`)
+	assert.NoError(t, err, "migrated OrderUpdates has status")
+	_, err = db.Exec(`SELECT purchase_price, purchase_currency FROM VinylItems`)
+	assert.NoError(t, err, "migrated VinylItems has purchase_price")
+	_, err = db.Exec(`SELECT password_hash, is_curator FROM UserAccounts`)
+	assert.NoError(t, err, "migrated UserAccounts has password_hash and is_curator")
+	_, err = db.Exec(`INSERT INTO User_Tokens (userID, token_hash, kind, date_expires)
+	  VALUES (1, x'01', "api", "2030-01-01 00:00:00"), (1, x'02', "session", "2030-01-01 00:00:00")`)
+	assert.NoError(t, err, "a user may have many tokens")
+
+	var count int
+	require.NoError(t, db.QueryRow(
+		`SELECT COUNT(*) FROM MediaFormatDescriptionEnum WHERE fmt_desc = "80 RPM"`).Scan(&count))
+	assert.Equal(t, 1, count, "duplicate descriptions are removed")
+	_, err = db.Exec(`INSERT INTO MediaFormatDescriptionEnum (fmt_desc_abbr, fmt_desc) VALUES ("80 RPM", "80 RPM")`)
+	assert.Error(t, err, "descriptions are unique (with their comments)")
+}
+
+func TestMigrateTagNamesPerUser(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cratedig.db")
+	db, err := Open(ctx, path)
+	require.NoError(t, err)
+
+	_, err = db.Exec(`INSERT INTO UserAccounts (userID, username) VALUES (1, "kevin"), (2, "dj")`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO TagNames (tagID, userID, name) VALUES (7, 1, "warmup")`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO VinylTagging (userID, versionID, tagID) VALUES (1, 0, 7)`)
+	require.NoError(t, err)
+	downgrade_order_updates(t, db)
+	downgrade_vinyl_items(t, db)
+	downgrade_accounts(t, db)
+	_, err = db.Exec(`PRAGMA user_version = 2`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	db, err = Open(ctx, path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	var tagged int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM VinylTagging WHERE tagID = 7`).Scan(&tagged))
+	assert.Equal(t, 1, tagged, "the rebuild keeps existing taggings")
+	_, err = db.Exec(`INSERT INTO TagNames (userID, name) VALUES (2, "warmup")`)
+	assert.NoError(t, err, "another user may have the same tag name")
+	_, err = db.Exec(`INSERT INTO TagNames (userID, name) VALUES (1, "Warmup")`)
+	assert.Error(t, err, "tag names are unique for each user, ignoring case")
+}
+
+func TestInTxJoinsTransaction(t *testing.T) {
+	ctx := context.Background()
+	db, err := Open(ctx, ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	insert := func(tx *sql.Tx) error {
+		_, err := tx.Exec(`INSERT INTO UserAccounts (userID, username) VALUES (1, "kevin")`)
+		return err
+	}
+	count := func() (users int) {
+		require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM UserAccounts WHERE userID = 1`).Scan(&users))
+		return users
+	}
+
+	// Within a transaction, the inner function's change is rolled back with it.
+	err = InTx(ctx, db, func(tx *sql.Tx) error {
+		if err := InTx(ctx, tx, insert); err != nil {
+			return err
+		}
+		return ErrConstraint
+	})
+	assert.ErrorIs(t, err, ErrConstraint)
+	assert.Equal(t, 0, count())
+
+	require.NoError(t, InTx(ctx, db, func(tx *sql.Tx) error { return InTx(ctx, tx, insert) }))
+	assert.Equal(t, 1, count())
+	err = InTx(ctx, db, insert)
+	assert.ErrorIs(t, err, ErrDuplicate)
+}
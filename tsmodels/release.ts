@@ -23,16 +23,7 @@
 // github:kevindamm/cratedigdb/tsmodels/release.ts
 
 import { z } from "zod"
-
-export const DataQuality = z.enum([
-  "New Submission",
-  "Recently Edited",
-  "Correct",
-  "Needs Vote",
-  "Needs Major Changes",
-  "Needs Minor Changes",
-  "Entirely Incorrect",
-])
+import { DataQuality } from "./data_quality"
 
 export const ReleaseInfo = z.object({
   releaseiD: z.number().positive().int(),
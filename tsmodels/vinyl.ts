@@ -34,6 +34,8 @@ export const Grading = z.enum([
   "G",   // 6
   "F",   // 7
   "P",   // 8
+  "Generic",  // 9 (sleeve only)
+  "No Cover", // 10 (sleeve only)
 ])
 
 // An item in a vinyl collection, with details and related info.
@@ -49,10 +51,16 @@ export const VinylRecord = z.object({
   date_graded: z.string().date().optional(),
   date_sold: z.string().date().optional(),
   date_traded: z.string().date().optional(),
+  date_archived: z.string().date().optional(),
 
   media_grade: Grading.optional(),
   sleeve_grade: Grading.optional(),
 
   tags: z.set(z.string().nonempty()),
   notes: z.string().optional(),
+
+  purchase_price: z.object({
+    amount: z.string().nonempty(),
+    currency: z.string().length(3),
+  }).optional(),
 })